@@ -4,19 +4,23 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 )
 
 type AnswerBlock struct {
 	BaseBlock
-	Prompt string `json:"prompt"`
-	Answer string `json:"answer"`
-	Fuzzy  bool   `json:"fuzzy"`
-}
+	Prompt       string   `json:"prompt"`
+	Answer       string   `json:"answer"`
+	Alternatives []string `json:"alternatives"`
+	Fuzzy        bool     `json:"fuzzy"`
+	Tolerance    int      `json:"tolerance"`
+	Pattern      string   `json:"pattern"`
+	AttemptPolicy
 
-type answerBlockData struct {
-	Attempts int      `json:"attempts"`
-	Guesses  []string `json:"guesses"`
+	// pattern is compiled once when the block is loaded or updated
+	pattern *regexp.Regexp
 }
 
 // Basic Attributes Getters
@@ -44,7 +48,12 @@ func (b *AnswerBlock) GetData() json.RawMessage {
 // Data Operations
 
 func (b *AnswerBlock) ParseData() error {
-	return json.Unmarshal(b.Data, b)
+	err := json.Unmarshal(b.Data, b)
+	if err != nil {
+		return err
+	}
+	b.pattern, err = CompilePattern(b.Pattern)
+	return err
 }
 
 func (b *AnswerBlock) UpdateBlockData(input map[string][]string) error {
@@ -62,10 +71,52 @@ func (b *AnswerBlock) UpdateBlockData(input map[string][]string) error {
 	}
 	b.Prompt = input["prompt"][0]
 	b.Answer = input["answer"][0]
-	if input["fuzzy"] != nil {
-		b.Fuzzy = input["fuzzy"][0] == "on"
+	b.Fuzzy = input["fuzzy"] != nil && input["fuzzy"][0] == "on"
+
+	// Alternative answers are entered one per line
+	b.Alternatives = nil
+	if input["alternatives"] != nil {
+		for _, line := range strings.Split(input["alternatives"][0], "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				b.Alternatives = append(b.Alternatives, line)
+			}
+		}
+	}
+
+	b.Tolerance = 0
+	if input["tolerance"] != nil && input["tolerance"][0] != "" {
+		tolerance, err := strconv.Atoi(input["tolerance"][0])
+		if err != nil {
+			return errors.New("tolerance must be an integer")
+		}
+		b.Tolerance = tolerance
+	}
+
+	b.Pattern = ""
+	if input["pattern"] != nil {
+		b.Pattern = strings.TrimSpace(input["pattern"][0])
+	}
+	pattern, err := CompilePattern(b.Pattern)
+	if err != nil {
+		return err
+	}
+	b.pattern = pattern
+
+	if err := b.updatePolicy(input); err != nil {
+		return err
+	}
+
+	return b.Matcher().Validate()
+}
+
+// Matcher returns the matcher used to check player answers.
+func (b *AnswerBlock) Matcher() AnswerMatcher {
+	return AnswerMatcher{
+		Answers:   append([]string{b.Answer}, b.Alternatives...),
+		Fuzzy:     b.Fuzzy,
+		Tolerance: b.Tolerance,
+		Pattern:   b.pattern,
 	}
-	return nil
 }
 
 // Validation and Points Calculation
@@ -77,36 +128,25 @@ func (b *AnswerBlock) ValidatePlayerInput(state PlayerState, input map[string][]
 		return state, errors.New("answer is a required field")
 	}
 
-	var err error
-	newPlayerData := answerBlockData{}
-	if state.GetPlayerData() != nil {
-		err := json.Unmarshal(state.GetPlayerData(), &newPlayerData)
-		if err != nil {
-			return state, fmt.Errorf("parse player data: %w", err)
-		}
+	newPlayerData, err := parseAttemptData(state)
+	if err != nil {
+		return state, fmt.Errorf("parse player data: %w", err)
 	}
 
-	// Increment the number of attempts and save guesses
-	newPlayerData.Attempts++
-	newPlayerData.Guesses = append(newPlayerData.Guesses, input["answer"][0])
-
-	if input["answer"][0] != b.Answer {
-		// Incorrect answer, save player data and return an error
-		playerData, err := json.Marshal(newPlayerData)
-		if err != nil {
-			return state, errors.New("Error saving player data")
-		}
-		state.SetPlayerData(playerData)
-		return state, nil
+	// Any answer is accepted when a facilitator will review it
+	correct := b.Matcher().Match(input["answer"][0]) || b.RequiresReview()
+	err = b.guess(state, &newPlayerData, input["answer"][0], correct, b.Points)
+	if err != nil {
+		return state, err
 	}
+	return state, nil
+}
 
-	// Correct answer, update state to complete
-	playerData, err := json.Marshal(newPlayerData)
+// GetGuesses returns the answers a team has submitted for the block.
+func (b *AnswerBlock) GetGuesses(state PlayerState) []string {
+	data, err := parseAttemptData(state)
 	if err != nil {
-		return state, errors.New("Error saving player data")
+		return nil
 	}
-	state.SetPlayerData(playerData)
-	state.SetComplete(true)
-	state.SetPointsAwarded(b.Points)
-	return state, nil
+	return data.Guesses
 }
@@ -95,3 +95,93 @@ func TestAnswerBlock_ValidatePlayerInput(t *testing.T) {
 	assert.True(t, newState.IsComplete())
 	assert.Equal(t, 10, newState.GetPointsAwarded())
 }
+
+func TestAnswerBlock_ValidatePlayerInput_Fuzzy(t *testing.T) {
+	block := AnswerBlock{
+		BaseBlock: BaseBlock{
+			Points: 10,
+		},
+		Answer:       "Eiffel Tower",
+		Alternatives: []string{"Tour Eiffel"},
+		Fuzzy:        true,
+		Tolerance:    1,
+	}
+
+	for _, answer := range []string{"Eiffel tower ", "tour eiffel", "Eifel Tower"} {
+		state := &mockPlayerState{}
+		newState, err := block.ValidatePlayerInput(state, map[string][]string{"answer": {answer}})
+		require.NoError(t, err)
+		assert.True(t, newState.IsComplete(), "expected %q to be accepted", answer)
+	}
+
+	state := &mockPlayerState{}
+	newState, err := block.ValidatePlayerInput(state, map[string][]string{"answer": {"Sky Tower"}})
+	require.NoError(t, err)
+	assert.False(t, newState.IsComplete())
+}
+
+func TestAnswerBlock_UpdateBlockData_Matching(t *testing.T) {
+	block := AnswerBlock{}
+	err := block.UpdateBlockData(map[string][]string{
+		"prompt":       {"Where are we?"},
+		"answer":       {"Aoraki"},
+		"alternatives": {"Mount Cook\n\n Aoraki / Mount Cook "},
+		"fuzzy":        {"on"},
+		"tolerance":    {"2"},
+		"pattern":      {`^mt\.? cook$`},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Mount Cook", "Aoraki / Mount Cook"}, block.Alternatives)
+	assert.Equal(t, 2, block.Tolerance)
+	assert.Equal(t, `^mt\.? cook$`, block.Pattern)
+
+	err = block.UpdateBlockData(map[string][]string{
+		"prompt":  {"Where are we?"},
+		"answer":  {"Aoraki"},
+		"pattern": {"("},
+	})
+	assert.Error(t, err)
+
+	err = block.UpdateBlockData(map[string][]string{
+		"prompt":    {"Where are we?"},
+		"answer":    {"Aoraki"},
+		"tolerance": {"many"},
+	})
+	assert.Error(t, err)
+}
+
+func TestAnswerBlock_ValidatePlayerInput_RequiresReview(t *testing.T) {
+	block := AnswerBlock{
+		BaseBlock: BaseBlock{
+			Points:         10,
+			ReviewRequired: true,
+		},
+		Answer: "secret",
+	}
+
+	// Any answer is accepted for review
+	state := &mockPlayerState{}
+	input := map[string][]string{"answer": {"an open-ended answer"}}
+	newState, err := block.ValidatePlayerInput(state, input)
+	require.NoError(t, err)
+	assert.True(t, newState.IsComplete())
+	assert.Equal(t, []string{"an open-ended answer"}, block.GetGuesses(newState))
+}
+
+func TestAnswerBlock_ParseData_Pattern(t *testing.T) {
+	block := AnswerBlock{
+		BaseBlock: BaseBlock{
+			Data:   json.RawMessage(`{"answer":"Aoraki","pattern":"(?i)mt\\.? cook"}`),
+			Points: 10,
+		},
+	}
+	require.NoError(t, block.ParseData())
+
+	newState, err := block.ValidatePlayerInput(&mockPlayerState{}, map[string][]string{"answer": {"Mt. Cook"}})
+	require.NoError(t, err)
+	assert.True(t, newState.IsComplete())
+
+	newState, err = block.ValidatePlayerInput(&mockPlayerState{}, map[string][]string{"answer": {"Mt Cook Village"}})
+	require.NoError(t, err)
+	assert.False(t, newState.IsComplete())
+}
@@ -0,0 +1,152 @@
+package blocks
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// AnswerMatcher checks player input against the accepted answers for a block.
+type AnswerMatcher struct {
+	// Answers are the accepted answers.
+	Answers []string
+	// Fuzzy ignores case, whitespace, punctuation, and diacritics.
+	Fuzzy bool
+	// Tolerance is the number of typos (Levenshtein distance) allowed
+	// when matching fuzzily.
+	Tolerance int
+	// Pattern is an optional regular expression that also accepts input.
+	// It must match the whole input, see CompilePattern.
+	Pattern *regexp.Regexp
+}
+
+// CompilePattern compiles a pattern so that it must match the whole answer.
+// An empty pattern returns nil.
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	compiled, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return compiled, nil
+}
+
+// diacritics maps accented letters to their unaccented form.
+// Macrons are included so "Maori" matches "Māori".
+var diacritics = buildDiacritics(map[rune]string{
+	'a': "àáâãäåāăąǎ",
+	'c': "çćĉċč",
+	'd': "ďđ",
+	'e': "èéêëēĕėęě",
+	'g': "ĝğġģ",
+	'h': "ĥħ",
+	'i': "ìíîïĩīĭįı",
+	'j': "ĵ",
+	'k': "ķ",
+	'l': "ĺļľŀł",
+	'n': "ñńņňŉ",
+	'o': "òóôõöøōŏőǒ",
+	'r': "ŕŗř",
+	's': "śŝşšß",
+	't': "ţťŧ",
+	'u': "ùúûüũūŭůűųǔ",
+	'w': "ŵ",
+	'y': "ýÿŷ",
+	'z': "źżž",
+})
+
+func buildDiacritics(groups map[rune]string) map[rune]rune {
+	table := make(map[rune]rune)
+	for base, accented := range groups {
+		for _, r := range accented {
+			table[r] = base
+		}
+	}
+	return table
+}
+
+// NormaliseAnswer lowercases the input, strips diacritics and punctuation,
+// and collapses whitespace so answers can be compared loosely.
+func NormaliseAnswer(input string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range strings.ToLower(input) {
+		if base, ok := diacritics[r]; ok {
+			r = base
+		}
+		switch {
+		case unicode.IsSpace(r):
+			space = sb.Len() > 0
+			continue
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.Is(unicode.Mn, r):
+			continue
+		}
+		if space {
+			sb.WriteRune(' ')
+			space = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// levenshtein returns the number of single character edits between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// Validate checks that the matcher's settings are usable.
+func (m AnswerMatcher) Validate() error {
+	if m.Tolerance < 0 {
+		return errors.New("tolerance must not be negative")
+	}
+	return nil
+}
+
+// Match reports whether the input is an accepted answer.
+func (m AnswerMatcher) Match(input string) bool {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return false
+	}
+
+	for _, answer := range m.Answers {
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			continue
+		}
+		if input == answer {
+			return true
+		}
+		if !m.Fuzzy {
+			continue
+		}
+		given, expected := NormaliseAnswer(input), NormaliseAnswer(answer)
+		if given == expected || levenshtein(given, expected) <= m.Tolerance {
+			return true
+		}
+	}
+
+	return m.Pattern != nil && m.Pattern.MatchString(input)
+}
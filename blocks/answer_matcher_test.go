@@ -0,0 +1,94 @@
+package blocks
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormaliseAnswer(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Eiffel Tower", "eiffel tower"},
+		{"  Eiffel   tower ", "eiffel tower"},
+		{"Māori", "maori"},
+		{"ŌTĀKOU", "otakou"},
+		{"Crème brûlée!", "creme brulee"},
+		{"St. John's", "st johns"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormaliseAnswer(tt.input))
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("kiwi", "kiwi"))
+	assert.Equal(t, 1, levenshtein("kiwi", "kiwis"))
+	assert.Equal(t, 2, levenshtein("tūī", "tui"))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+	assert.Equal(t, 4, levenshtein("", "kiwi"))
+}
+
+func TestAnswerMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher AnswerMatcher
+		input   string
+		want    bool
+	}{
+		{"Exact", AnswerMatcher{Answers: []string{"Eiffel Tower"}}, "Eiffel Tower", true},
+		{"Surrounding whitespace", AnswerMatcher{Answers: []string{"Eiffel Tower"}}, "Eiffel Tower ", true},
+		{"Case without fuzzy", AnswerMatcher{Answers: []string{"Eiffel Tower"}}, "eiffel tower", false},
+		{"Case with fuzzy", AnswerMatcher{Answers: []string{"Eiffel Tower"}, Fuzzy: true}, "eiffel tower ", true},
+		{"Macrons with fuzzy", AnswerMatcher{Answers: []string{"Māori"}, Fuzzy: true}, "maori", true},
+		{"Punctuation with fuzzy", AnswerMatcher{Answers: []string{"St. John's"}, Fuzzy: true}, "st johns", true},
+		{"Typo without tolerance", AnswerMatcher{Answers: []string{"Wellington"}, Fuzzy: true}, "Welington", false},
+		{"Typo within tolerance", AnswerMatcher{Answers: []string{"Wellington"}, Fuzzy: true, Tolerance: 1}, "Welington", true},
+		{"Typos beyond tolerance", AnswerMatcher{Answers: []string{"Wellington"}, Fuzzy: true, Tolerance: 1}, "Welingtn", false},
+		{"Tolerance needs fuzzy", AnswerMatcher{Answers: []string{"Wellington"}, Tolerance: 1}, "Welington", false},
+		{"Alternative answer", AnswerMatcher{Answers: []string{"Aoraki", "Mount Cook"}}, "Mount Cook", true},
+		{"Pattern", AnswerMatcher{Pattern: mustCompilePattern(t, `(?i)mt\.? cook`)}, "MT Cook", true},
+		{"Pattern no match", AnswerMatcher{Pattern: mustCompilePattern(t, `(?i)mt\.? cook`)}, "Mount Cook", false},
+		{"Pattern matches the whole answer", AnswerMatcher{Pattern: mustCompilePattern(t, `cat`)}, "concatenate", false},
+		{"Pattern alternatives are anchored", AnswerMatcher{Pattern: mustCompilePattern(t, `cat|dog`)}, "dogs", false},
+		{"Empty input", AnswerMatcher{Answers: []string{""}, Fuzzy: true}, " ", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.matcher.Match(tt.input))
+		})
+	}
+}
+
+func TestAnswerMatcher_Validate(t *testing.T) {
+	assert.NoError(t, AnswerMatcher{Tolerance: 1}.Validate())
+	assert.Error(t, AnswerMatcher{Tolerance: -1}.Validate())
+}
+
+func TestCompilePattern(t *testing.T) {
+	pattern, err := CompilePattern("")
+	assert.NoError(t, err)
+	assert.Nil(t, pattern)
+
+	_, err = CompilePattern(`^\d+$`)
+	assert.NoError(t, err)
+	_, err = CompilePattern(`(`)
+	assert.Error(t, err)
+}
+
+func mustCompilePattern(t *testing.T, pattern string) *regexp.Regexp {
+	t.Helper()
+	compiled, err := CompilePattern(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return compiled
+}
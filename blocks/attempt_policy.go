@@ -0,0 +1,187 @@
+package blocks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExhaustedAction determines what happens when a team runs out of attempts.
+type ExhaustedAction string
+
+const (
+	// ExhaustedFail completes the block without awarding points.
+	ExhaustedFail ExhaustedAction = "fail"
+	// ExhaustedHint reveals the hint and lets the team keep guessing.
+	ExhaustedHint ExhaustedAction = "hint"
+)
+
+// now is replaced in tests to control cooldowns.
+var now = time.Now
+
+// AttemptPolicy limits how teams may guess the answer to a block.
+// The zero value allows unlimited guesses at full points.
+type AttemptPolicy struct {
+	// MaxAttempts is the number of guesses allowed. Zero is unlimited.
+	MaxAttempts int `json:"max_attempts"`
+	// CooldownAfter locks the block after this many wrong guesses in a row.
+	CooldownAfter int `json:"cooldown_after"`
+	// CooldownSeconds is how long the block stays locked.
+	CooldownSeconds int `json:"cooldown_seconds"`
+	// PointDecay is taken off the points for each wrong guess.
+	PointDecay  int             `json:"point_decay"`
+	OnExhausted ExhaustedAction `json:"on_exhausted"`
+	// Hint is revealed once the attempts run out.
+	Hint string `json:"hint"`
+}
+
+// AttemptStatus describes a team's progress against an AttemptPolicy.
+type AttemptStatus struct {
+	Attempts int
+	// Remaining is the number of guesses left, or -1 if unlimited.
+	Remaining    int
+	LockedUntil  time.Time
+	HintUnlocked bool
+	Failed       bool
+}
+
+// Locked reports whether the team must wait before guessing again.
+func (s AttemptStatus) Locked() bool {
+	return now().Before(s.LockedUntil)
+}
+
+// LockedFor returns how long until the team may guess again.
+func (s AttemptStatus) LockedFor() time.Duration {
+	if !s.Locked() {
+		return 0
+	}
+	return s.LockedUntil.Sub(now()).Round(time.Second)
+}
+
+// attemptData is the player data shared by blocks that take guesses.
+type attemptData struct {
+	Attempts     int       `json:"attempts"`
+	Guesses      []string  `json:"guesses"`
+	WrongStreak  int       `json:"wrong_streak"`
+	LockedUntil  time.Time `json:"locked_until"`
+	HintUnlocked bool      `json:"hint_unlocked"`
+	Failed       bool      `json:"failed"`
+}
+
+// updatePolicy reads the attempt settings from the admin form.
+// Missing settings are reset to their defaults.
+func (p *AttemptPolicy) updatePolicy(input map[string][]string) error {
+	settings := []struct {
+		field string
+		name  string
+		value *int
+	}{
+		{"max_attempts", "max attempts", &p.MaxAttempts},
+		{"cooldown_after", "cooldown after", &p.CooldownAfter},
+		{"cooldown_seconds", "cooldown seconds", &p.CooldownSeconds},
+		{"point_decay", "point decay", &p.PointDecay},
+	}
+	for _, setting := range settings {
+		*setting.value = 0
+		if input[setting.field] == nil || input[setting.field][0] == "" {
+			continue
+		}
+		value, err := strconv.Atoi(input[setting.field][0])
+		if err != nil || value < 0 {
+			return fmt.Errorf("%s must be a positive integer", setting.name)
+		}
+		*setting.value = value
+	}
+
+	p.OnExhausted = ExhaustedFail
+	if input["on_exhausted"] != nil && input["on_exhausted"][0] != "" {
+		switch ExhaustedAction(input["on_exhausted"][0]) {
+		case ExhaustedFail, ExhaustedHint:
+			p.OnExhausted = ExhaustedAction(input["on_exhausted"][0])
+		default:
+			return errors.New("invalid action for running out of attempts")
+		}
+	}
+
+	p.Hint = ""
+	if input["hint"] != nil {
+		p.Hint = strings.TrimSpace(input["hint"][0])
+	}
+	return nil
+}
+
+// pointsFor returns the points earned for a correct guess on the given attempt.
+func (p AttemptPolicy) pointsFor(points, attempts int) int {
+	return max(0, points-p.PointDecay*(attempts-1))
+}
+
+// parseAttemptData reads the shared attempt data from the player state.
+func parseAttemptData(state PlayerState) (attemptData, error) {
+	data := attemptData{}
+	if state == nil || state.GetPlayerData() == nil {
+		return data, nil
+	}
+	err := json.Unmarshal(state.GetPlayerData(), &data)
+	return data, err
+}
+
+// guess records a guess against the policy and updates the player state.
+// Guesses made while the block is locked are ignored.
+func (p AttemptPolicy) guess(state PlayerState, data *attemptData, guess string, correct bool, points int) error {
+	if data.Failed || now().Before(data.LockedUntil) {
+		return nil
+	}
+
+	data.Attempts++
+	data.Guesses = append(data.Guesses, guess)
+
+	if correct {
+		data.WrongStreak = 0
+		state.SetComplete(true)
+		state.SetPointsAwarded(p.pointsFor(points, data.Attempts))
+	} else {
+		data.WrongStreak++
+		if p.CooldownAfter > 0 && p.CooldownSeconds > 0 && data.WrongStreak >= p.CooldownAfter {
+			data.WrongStreak = 0
+			data.LockedUntil = now().Add(time.Duration(p.CooldownSeconds) * time.Second)
+		}
+		if p.MaxAttempts > 0 && data.Attempts >= p.MaxAttempts {
+			if p.OnExhausted == ExhaustedHint {
+				data.HintUnlocked = true
+			} else {
+				data.Failed = true
+				state.SetComplete(true)
+				state.SetPointsAwarded(0)
+			}
+		}
+	}
+
+	playerData, err := json.Marshal(data)
+	if err != nil {
+		return errors.New("Error saving player data")
+	}
+	state.SetPlayerData(playerData)
+	return nil
+}
+
+// GetAttemptStatus returns the team's progress against the policy.
+func (p AttemptPolicy) GetAttemptStatus(state PlayerState) AttemptStatus {
+	data, err := parseAttemptData(state)
+	if err != nil {
+		return AttemptStatus{Remaining: -1}
+	}
+	status := AttemptStatus{
+		Attempts:     data.Attempts,
+		Remaining:    -1,
+		LockedUntil:  data.LockedUntil,
+		HintUnlocked: data.HintUnlocked,
+		Failed:       data.Failed,
+	}
+	if p.MaxAttempts > 0 && !data.HintUnlocked {
+		status.Remaining = max(0, p.MaxAttempts-data.Attempts)
+	}
+	return status
+}
@@ -0,0 +1,150 @@
+package blocks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// freezeTime fixes the package clock for the duration of a test.
+func freezeTime(t *testing.T) *time.Time {
+	t.Helper()
+	current := time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = time.Now })
+	return &current
+}
+
+func TestAttemptPolicy_UpdatePolicy(t *testing.T) {
+	policy := AttemptPolicy{}
+	err := policy.updatePolicy(map[string][]string{
+		"max_attempts":     {"5"},
+		"cooldown_after":   {"3"},
+		"cooldown_seconds": {"60"},
+		"point_decay":      {"2"},
+		"on_exhausted":     {"hint"},
+		"hint":             {" Look up "},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, AttemptPolicy{
+		MaxAttempts:     5,
+		CooldownAfter:   3,
+		CooldownSeconds: 60,
+		PointDecay:      2,
+		OnExhausted:     ExhaustedHint,
+		Hint:            "Look up",
+	}, policy)
+
+	// Missing settings are reset
+	err = policy.updatePolicy(map[string][]string{"max_attempts": {""}})
+	require.NoError(t, err)
+	assert.Equal(t, AttemptPolicy{OnExhausted: ExhaustedFail}, policy)
+
+	assert.Error(t, policy.updatePolicy(map[string][]string{"max_attempts": {"-1"}}))
+	assert.Error(t, policy.updatePolicy(map[string][]string{"point_decay": {"lots"}}))
+	assert.Error(t, policy.updatePolicy(map[string][]string{"on_exhausted": {"explode"}}))
+}
+
+func TestAttemptPolicy_PointDecay(t *testing.T) {
+	block := PincodeBlock{
+		BaseBlock:     BaseBlock{Points: 10},
+		Pincode:       "1234",
+		AttemptPolicy: AttemptPolicy{PointDecay: 4},
+	}
+	state := PlayerState(&mockPlayerState{})
+
+	var err error
+	for _, guess := range []string{"1111", "2222", "1234"} {
+		state, err = block.ValidatePlayerInput(state, map[string][]string{"pincode": {guess}})
+		require.NoError(t, err)
+	}
+	assert.True(t, state.IsComplete())
+	assert.Equal(t, 2, state.GetPointsAwarded())
+
+	// Points never go below zero
+	assert.Equal(t, 0, block.pointsFor(10, 5))
+}
+
+func TestAttemptPolicy_Exhausted(t *testing.T) {
+	t.Run("Fail", func(t *testing.T) {
+		block := PincodeBlock{
+			BaseBlock:     BaseBlock{Points: 10},
+			Pincode:       "1234",
+			AttemptPolicy: AttemptPolicy{MaxAttempts: 2},
+		}
+		state := PlayerState(&mockPlayerState{})
+
+		state, err := block.ValidatePlayerInput(state, map[string][]string{"pincode": {"1111"}})
+		require.NoError(t, err)
+		assert.False(t, state.IsComplete())
+		assert.Equal(t, 1, block.GetAttemptStatus(state).Remaining)
+
+		state, err = block.ValidatePlayerInput(state, map[string][]string{"pincode": {"2222"}})
+		require.NoError(t, err)
+		assert.True(t, state.IsComplete(), "running out of attempts fails the block")
+		assert.Equal(t, 0, state.GetPointsAwarded())
+		status := block.GetAttemptStatus(state)
+		assert.True(t, status.Failed)
+		assert.Equal(t, 0, status.Remaining)
+	})
+
+	t.Run("Hint", func(t *testing.T) {
+		block := AnswerBlock{
+			BaseBlock: BaseBlock{Points: 10},
+			Answer:    "kiwi",
+			AttemptPolicy: AttemptPolicy{
+				MaxAttempts: 1,
+				OnExhausted: ExhaustedHint,
+				Hint:        "It cannot fly",
+			},
+		}
+		state := PlayerState(&mockPlayerState{})
+
+		state, err := block.ValidatePlayerInput(state, map[string][]string{"answer": {"moa"}})
+		require.NoError(t, err)
+		assert.False(t, state.IsComplete())
+		status := block.GetAttemptStatus(state)
+		assert.True(t, status.HintUnlocked)
+		assert.Equal(t, -1, status.Remaining, "guessing continues once the hint is shown")
+
+		state, err = block.ValidatePlayerInput(state, map[string][]string{"answer": {"kiwi"}})
+		require.NoError(t, err)
+		assert.True(t, state.IsComplete())
+		assert.Equal(t, 10, state.GetPointsAwarded())
+	})
+}
+
+func TestAttemptPolicy_Cooldown(t *testing.T) {
+	clock := freezeTime(t)
+	block := PincodeBlock{
+		Pincode: "1234",
+		AttemptPolicy: AttemptPolicy{
+			CooldownAfter:   2,
+			CooldownSeconds: 30,
+		},
+	}
+	state := PlayerState(&mockPlayerState{})
+
+	var err error
+	for _, guess := range []string{"1111", "2222"} {
+		state, err = block.ValidatePlayerInput(state, map[string][]string{"pincode": {guess}})
+		require.NoError(t, err)
+	}
+	status := block.GetAttemptStatus(state)
+	assert.True(t, status.Locked())
+	assert.Equal(t, 30*time.Second, status.LockedFor())
+
+	// Guesses are ignored while locked, even correct ones
+	state, err = block.ValidatePlayerInput(state, map[string][]string{"pincode": {"1234"}})
+	require.NoError(t, err)
+	assert.False(t, state.IsComplete())
+	assert.Equal(t, 2, block.GetAttemptStatus(state).Attempts)
+
+	*clock = clock.Add(31 * time.Second)
+	assert.False(t, block.GetAttemptStatus(state).Locked())
+	state, err = block.ValidatePlayerInput(state, map[string][]string{"pincode": {"1234"}})
+	require.NoError(t, err)
+	assert.True(t, state.IsComplete())
+}
@@ -14,8 +14,20 @@ type PlayerState interface {
 	SetComplete(complete bool)
 	GetPointsAwarded() int
 	SetPointsAwarded(points int)
+	GetReviewStatus() string
+	SetReviewStatus(status string)
+	GetReviewComment() string
+	SetReviewComment(comment string)
 }
 
+// Review statuses for blocks that require facilitator review.
+const (
+	ReviewStatusNone     = ""
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
 type Block interface {
 	// Basic Attributes Getters
 	GetID() string
@@ -35,19 +47,30 @@ type Block interface {
 	// Validation and Points Calculation
 	RequiresValidation() bool
 	ValidatePlayerInput(state PlayerState, input map[string][]string) (newState PlayerState, err error)
+
+	// Facilitator Review
+	RequiresReview() bool
+	SetRequiresReview(required bool)
 }
 
 type Blocks []Block
 
 type BaseBlock struct {
-	ID         string          `json:"-"`
-	LocationID string          `json:"-"`
-	Type       string          `json:"-"`
-	Data       json.RawMessage `json:"-"`
-	Order      int             `json:"-"`
-	Points     int             `json:"-"`
+	ID             string          `json:"-"`
+	LocationID     string          `json:"-"`
+	Type           string          `json:"-"`
+	Data           json.RawMessage `json:"-"`
+	Order          int             `json:"-"`
+	Points         int             `json:"-"`
+	ReviewRequired bool            `json:"-"`
 }
 
+// RequiresReview reports whether submissions must be approved by a facilitator.
+func (b *BaseBlock) RequiresReview() bool { return b.ReviewRequired }
+
+// SetRequiresReview sets whether submissions must be approved by a facilitator.
+func (b *BaseBlock) SetRequiresReview(required bool) { b.ReviewRequired = required }
+
 var registeredBlocks = Blocks{
 	&MarkdownBlock{},
 	&DividerBlock{},
@@ -57,7 +80,8 @@ var registeredBlocks = Blocks{
 	&AnswerBlock{},
 	&PincodeBlock{},
 	&ChecklistBlock{},
-	// &PhotoBlock{},
+	&PhotoBlock{},
+	&QuizBlock{},
 }
 
 func GetRegisteredBlocks() Blocks {
@@ -82,8 +106,10 @@ func CreateFromBaseBlock(baseBlock BaseBlock) (Block, error) {
 		return NewYoutubeBlock(baseBlock), nil
 	case "image":
 		return NewImageBlock(baseBlock), nil
-	// case "photo":
-	// 	return NewPhotoBlock(baseBlock), nil
+	case "photo":
+		return NewPhotoBlock(baseBlock), nil
+	case "quiz":
+		return NewQuizBlock(baseBlock), nil
 	default:
 		return nil, fmt.Errorf("block type %s not found", baseBlock.Type)
 	}
@@ -138,9 +164,14 @@ func NewImageBlock(base BaseBlock) *ImageBlock {
 	}
 }
 
-//
-// func NewPhotoBlock(base BaseBlock) *PhotoBlock {
-// 	return &PhotoBlock{
-// 		BaseBlock: base,
-// 	}
-// }
+func NewPhotoBlock(base BaseBlock) *PhotoBlock {
+	return &PhotoBlock{
+		BaseBlock: base,
+	}
+}
+
+func NewQuizBlock(base BaseBlock) *QuizBlock {
+	return &QuizBlock{
+		BaseBlock: base,
+	}
+}
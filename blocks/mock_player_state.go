@@ -10,6 +10,8 @@ type mockPlayerState struct {
 	playerData    json.RawMessage
 	isComplete    bool
 	pointsAwarded int
+	reviewStatus  string
+	reviewComment string
 }
 
 func (m *mockPlayerState) GetBlockID() string {
@@ -43,3 +45,19 @@ func (m *mockPlayerState) GetPointsAwarded() int {
 func (m *mockPlayerState) SetPointsAwarded(points int) {
 	m.pointsAwarded = points
 }
+
+func (m *mockPlayerState) GetReviewStatus() string {
+	return m.reviewStatus
+}
+
+func (m *mockPlayerState) SetReviewStatus(status string) {
+	m.reviewStatus = status
+}
+
+func (m *mockPlayerState) GetReviewComment() string {
+	return m.reviewComment
+}
+
+func (m *mockPlayerState) SetReviewComment(comment string) {
+	m.reviewComment = comment
+}
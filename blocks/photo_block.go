@@ -13,8 +13,11 @@ type PhotoBlock struct {
 	Prompt string `json:"prompt"`
 }
 
+// photoBlockData records the uploads a team has submitted for the block.
+// UploadIDs and URLs are kept in the same order.
 type photoBlockData struct {
-	URLs []string `json:"images"`
+	UploadIDs []string `json:"upload_ids"`
+	URLs      []string `json:"images"`
 }
 
 // Basic Attributes Getters
@@ -66,10 +69,16 @@ func (b *PhotoBlock) UpdateBlockData(input map[string][]string) error {
 
 func (b *PhotoBlock) RequiresValidation() bool { return true }
 
+// ValidatePlayerInput records the uploaded photos against the player state.
+// The input must contain matching "upload_id" and "url" values, which are
+// set by the server once the file has been stored by the upload service.
 func (b *PhotoBlock) ValidatePlayerInput(state PlayerState, input map[string][]string) (PlayerState, error) {
-	if input["url"] == nil || len(input["url"]) == 0 {
+	if len(input["upload_id"]) == 0 || len(input["url"]) == 0 {
 		return state, errors.New("photo is a required field")
 	}
+	if len(input["upload_id"]) != len(input["url"]) {
+		return state, errors.New("each photo must have an upload ID and URL")
+	}
 
 	newPlayerData := photoBlockData{}
 	if state.GetPlayerData() != nil {
@@ -79,18 +88,19 @@ func (b *PhotoBlock) ValidatePlayerInput(state PlayerState, input map[string][]s
 		}
 	}
 
-	for _, image := range input["url"] {
-		if image == "" {
+	for i, image := range input["url"] {
+		if image == "" || input["upload_id"][i] == "" {
 			return state, errors.New("photo is a required field")
 		}
 		// Check valid image URL
 		if _, err := url.ParseRequestURI(image); err != nil {
 			return state, errors.New("invalid URL")
 		}
+		newPlayerData.UploadIDs = append(newPlayerData.UploadIDs, input["upload_id"][i])
 		newPlayerData.URLs = append(newPlayerData.URLs, image)
 	}
 
-	// Correct photo, update state to complete
+	// Photo submitted, update state to complete
 	playerData, err := json.Marshal(newPlayerData)
 	if err != nil {
 		return state, errors.New("Error saving player data")
@@ -100,3 +110,15 @@ func (b *PhotoBlock) ValidatePlayerInput(state PlayerState, input map[string][]s
 	state.SetPointsAwarded(b.Points)
 	return state, nil
 }
+
+// GetImageURLs returns the URLs of the photos submitted for the block.
+func (b *PhotoBlock) GetImageURLs(state PlayerState) []string {
+	data := photoBlockData{}
+	if state == nil || state.GetPlayerData() == nil {
+		return nil
+	}
+	if err := json.Unmarshal(state.GetPlayerData(), &data); err != nil {
+		return nil
+	}
+	return data.URLs
+}
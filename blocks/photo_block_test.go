@@ -0,0 +1,105 @@
+package blocks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhotoBlock_Getters(t *testing.T) {
+	block := PhotoBlock{
+		BaseBlock: BaseBlock{
+			ID:         "test-id",
+			LocationID: "location-456",
+			Order:      2,
+			Points:     10,
+		},
+		Prompt: "Take a team photo",
+	}
+
+	assert.Equal(t, "Photo", block.GetName())
+	assert.Equal(t, "photo", block.GetType())
+	assert.Equal(t, "test-id", block.GetID())
+	assert.Equal(t, "location-456", block.GetLocationID())
+	assert.Equal(t, 2, block.GetOrder())
+	assert.Equal(t, 10, block.GetPoints())
+}
+
+func TestPhotoBlock_ParseData(t *testing.T) {
+	data := `{"prompt":"Take a team photo"}`
+	block := PhotoBlock{
+		BaseBlock: BaseBlock{
+			Data: json.RawMessage(data),
+		},
+	}
+
+	err := block.ParseData()
+	require.NoError(t, err)
+	assert.Equal(t, "Take a team photo", block.Prompt)
+}
+
+func TestPhotoBlock_UpdateBlockData(t *testing.T) {
+	block := PhotoBlock{}
+	data := map[string][]string{
+		"points": {"15"},
+		"prompt": {"Updated prompt"},
+	}
+	err := block.UpdateBlockData(data)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated prompt", block.Prompt)
+	assert.Equal(t, 15, block.Points)
+
+	// Missing prompt
+	err = block.UpdateBlockData(map[string][]string{})
+	require.Error(t, err)
+
+	// Invalid points
+	err = block.UpdateBlockData(map[string][]string{"points": {"ten"}, "prompt": {"x"}})
+	require.Error(t, err)
+}
+
+func TestPhotoBlock_ValidatePlayerInput(t *testing.T) {
+	block := PhotoBlock{
+		BaseBlock: BaseBlock{
+			Points: 10,
+		},
+	}
+
+	state := &mockPlayerState{}
+
+	// Missing upload
+	_, err := block.ValidatePlayerInput(state, map[string][]string{})
+	require.Error(t, err)
+	assert.False(t, state.IsComplete())
+
+	// URL without an upload ID
+	_, err = block.ValidatePlayerInput(state, map[string][]string{
+		"url": {"https://example.com/photo.jpg"},
+	})
+	require.Error(t, err)
+	assert.False(t, state.IsComplete())
+
+	// Invalid URL
+	_, err = block.ValidatePlayerInput(state, map[string][]string{
+		"upload_id": {"upload-1"},
+		"url":       {"not a url"},
+	})
+	require.Error(t, err)
+	assert.False(t, state.IsComplete())
+
+	// Valid upload
+	newState, err := block.ValidatePlayerInput(state, map[string][]string{
+		"upload_id": {"upload-1"},
+		"url":       {"https://example.com/photo.jpg"},
+	})
+	require.NoError(t, err)
+	assert.True(t, newState.IsComplete())
+	assert.Equal(t, 10, newState.GetPointsAwarded())
+	assert.Equal(t, []string{"https://example.com/photo.jpg"}, block.GetImageURLs(newState))
+
+	var data photoBlockData
+	require.NoError(t, json.Unmarshal(newState.GetPlayerData(), &data))
+	assert.Equal(t, []string{"upload-1"}, data.UploadIDs)
+}
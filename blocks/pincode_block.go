@@ -11,11 +11,7 @@ type PincodeBlock struct {
 	BaseBlock
 	Prompt  string `json:"prompt"`
 	Pincode string `json:"pincode"`
-}
-
-type pincodeBlockData struct {
-	Attempts int      `json:"attempts"`
-	Guesses  []string `json:"guesses"`
+	AttemptPolicy
 }
 
 // Basic Attributes Getters
@@ -61,7 +57,12 @@ func (b *PincodeBlock) UpdateBlockData(input map[string][]string) error {
 	}
 	b.Prompt = input["prompt"][0]
 	b.Pincode = input["pincode"][0]
-	return nil
+	return b.updatePolicy(input)
+}
+
+// Matcher returns the matcher used to check player pincodes.
+func (b *PincodeBlock) Matcher() AnswerMatcher {
+	return AnswerMatcher{Answers: []string{b.Pincode}}
 }
 
 // Validation and Points Calculation
@@ -73,36 +74,15 @@ func (b *PincodeBlock) ValidatePlayerInput(state PlayerState, input map[string][
 		return state, errors.New("pincode is a required field")
 	}
 
-	var err error
-	newPlayerData := pincodeBlockData{}
-	if state.GetPlayerData() != nil {
-		err := json.Unmarshal(state.GetPlayerData(), &newPlayerData)
-		if err != nil {
-			return state, fmt.Errorf("unmarshalling player data %w", err)
-		}
-	}
-
-	// Increment the number of attempts and save guesses
-	newPlayerData.Attempts++
-	newPlayerData.Guesses = append(newPlayerData.Guesses, input["pincode"][0])
-
-	if input["pincode"][0] != b.Pincode {
-		// Incorrect pincode, save player data and return an error
-		playerData, err := json.Marshal(newPlayerData)
-		if err != nil {
-			return state, errors.New("Error saving player data")
-		}
-		state.SetPlayerData(playerData)
-		return state, nil
+	newPlayerData, err := parseAttemptData(state)
+	if err != nil {
+		return state, fmt.Errorf("unmarshalling player data %w", err)
 	}
 
-	// Correct pincode, update state to complete
-	playerData, err := json.Marshal(newPlayerData)
+	correct := b.Matcher().Match(input["pincode"][0])
+	err = b.guess(state, &newPlayerData, input["pincode"][0], correct, b.Points)
 	if err != nil {
-		return state, errors.New("Error saving player data")
+		return state, err
 	}
-	state.SetPlayerData(playerData)
-	state.SetComplete(true)
-	state.SetPointsAwarded(b.Points)
 	return state, nil
 }
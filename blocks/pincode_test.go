@@ -111,7 +111,7 @@ func TestPincodeBlock_ValidatePlayerInput(t *testing.T) {
 	assert.True(t, newState.IsComplete())
 	assert.Equal(t, points, strconv.Itoa(newState.GetPointsAwarded()))
 
-	var newPlayerData pincodeBlockData
+	var newPlayerData attemptData
 	err = json.Unmarshal(newState.GetPlayerData(), &newPlayerData)
 	require.NoError(t, err)
 	assert.Equal(t, 3, newPlayerData.Attempts)
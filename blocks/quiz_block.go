@@ -0,0 +1,395 @@
+package blocks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// QuizQuestionType determines how a quiz question is answered.
+type QuizQuestionType string
+
+const (
+	QuizSingleChoice QuizQuestionType = "single"
+	QuizMultiChoice  QuizQuestionType = "multiple"
+	QuizShortAnswer  QuizQuestionType = "short"
+)
+
+// QuizRetryPolicy determines whether players may answer again.
+type QuizRetryPolicy string
+
+const (
+	// QuizRetryNone scores the first submission and completes the block.
+	QuizRetryNone QuizRetryPolicy = "none"
+	// QuizRetryIncorrect lets players answer incorrect questions again
+	// until every question is correct or the attempts run out.
+	QuizRetryIncorrect QuizRetryPolicy = "incorrect"
+)
+
+type QuizBlock struct {
+	BaseBlock
+	Content        string          `json:"content"`
+	Questions      []QuizQuestion  `json:"questions"`
+	ShuffleOptions bool            `json:"shuffle_options"`
+	RetryPolicy    QuizRetryPolicy `json:"retry_policy"`
+	// MaxAttempts limits retries. Zero allows unlimited attempts.
+	MaxAttempts int `json:"max_attempts"`
+}
+
+type QuizQuestion struct {
+	ID      string           `json:"id"`
+	Type    QuizQuestionType `json:"type"`
+	Text    string           `json:"text"`
+	Points  int              `json:"points"`
+	Options []QuizOption     `json:"options"`
+	// Answers are the accepted responses to a short answer question.
+	Answers []string `json:"answers"`
+}
+
+type QuizOption struct {
+	ID      string `json:"id"`
+	Text    string `json:"text"`
+	Correct bool   `json:"correct"`
+}
+
+// QuizResult is a team's latest answer to a question.
+type QuizResult struct {
+	Answer  []string `json:"answer"`
+	Correct bool     `json:"correct"`
+	Points  int      `json:"points"`
+}
+
+// Unexported struct for storing player progress data in a block.
+type quizPlayerData struct {
+	Attempts int                   `json:"attempts"`
+	Results  map[string]QuizResult `json:"results"`
+}
+
+// Basic Attributes Getters.
+func (b *QuizBlock) GetName() string { return "Quiz" }
+
+func (b *QuizBlock) GetDescription() string {
+	return "Players answer a set of questions for points."
+}
+
+func (b *QuizBlock) GetIconSVG() string {
+	return `<svg xmlns="http://www.w3.org/2000/svg" width="24" height="24" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" class="lucide lucide-circle-help"><circle cx="12" cy="12" r="10"/><path d="M9.09 9a3 3 0 0 1 5.83 1c0 2-3 3-3 3"/><path d="M12 17h.01"/></svg>`
+}
+
+func (b *QuizBlock) GetType() string { return "quiz" }
+
+func (b *QuizBlock) GetID() string { return b.ID }
+
+func (b *QuizBlock) GetLocationID() string { return b.LocationID }
+
+func (b *QuizBlock) GetOrder() int { return b.Order }
+
+func (b *QuizBlock) GetPoints() int { return b.Points }
+
+func (b *QuizBlock) GetData() json.RawMessage {
+	data, _ := json.Marshal(b)
+	return data
+}
+
+// Data Operations.
+func (b *QuizBlock) ParseData() error {
+	return json.Unmarshal(b.Data, b)
+}
+
+func (b *QuizBlock) UpdateBlockData(input map[string][]string) error {
+	if content, exists := input["content"]; exists && len(content) > 0 {
+		b.Content = content[0]
+	}
+
+	b.ShuffleOptions = len(input["shuffle_options"]) > 0 && input["shuffle_options"][0] == "on"
+
+	b.RetryPolicy = QuizRetryNone
+	if policy, exists := input["retry_policy"]; exists && len(policy) > 0 {
+		switch QuizRetryPolicy(policy[0]) {
+		case QuizRetryNone, QuizRetryIncorrect:
+			b.RetryPolicy = QuizRetryPolicy(policy[0])
+		default:
+			return errors.New("invalid retry policy")
+		}
+	}
+
+	b.MaxAttempts = 0
+	if attempts, exists := input["max_attempts"]; exists && len(attempts) > 0 && attempts[0] != "" {
+		maxAttempts, err := strconv.Atoi(attempts[0])
+		if err != nil || maxAttempts < 0 {
+			return errors.New("max attempts must be a positive integer")
+		}
+		b.MaxAttempts = maxAttempts
+	}
+
+	// Questions are submitted as parallel lists, one entry per question
+	ids := input["question-ids"]
+	types := input["question-types"]
+	texts := input["question-texts"]
+	points := input["question-points"]
+	options := input["question-options"]
+
+	existing := make(map[string]QuizQuestion, len(b.Questions))
+	for _, q := range b.Questions {
+		existing[q.ID] = q
+	}
+
+	questions := make([]QuizQuestion, 0, len(texts))
+	total := 0
+	for i, text := range texts {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		question := QuizQuestion{Text: text, Type: QuizSingleChoice}
+		if i < len(ids) && ids[i] != "" {
+			question.ID = ids[i]
+		} else {
+			question.ID = uuid.New().String()
+		}
+
+		if i < len(types) {
+			switch QuizQuestionType(types[i]) {
+			case QuizSingleChoice, QuizMultiChoice, QuizShortAnswer:
+				question.Type = QuizQuestionType(types[i])
+			default:
+				return fmt.Errorf("invalid question type %q", types[i])
+			}
+		}
+
+		if i < len(points) && points[i] != "" {
+			p, err := strconv.Atoi(points[i])
+			if err != nil {
+				return errors.New("points must be an integer")
+			}
+			if p < 0 {
+				return errors.New("points must not be negative")
+			}
+			question.Points = p
+		}
+
+		var lines string
+		if i < len(options) {
+			lines = options[i]
+		}
+		if question.Type == QuizShortAnswer {
+			question.Answers = parseQuizLines(lines)
+		} else {
+			question.Options = parseQuizOptions(lines, existing[question.ID].Options)
+		}
+
+		total += question.Points
+		questions = append(questions, question)
+	}
+	if len(questions) == 0 {
+		return errors.New("a quiz needs at least one question")
+	}
+	b.Questions = questions
+	b.Points = total
+
+	return nil
+}
+
+// parseQuizLines splits text into trimmed, non-empty lines.
+func parseQuizLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parseQuizOptions reads one option per line. Lines starting with an
+// asterisk are correct. Options keep their ID while their text is unchanged.
+func parseQuizOptions(text string, previous []QuizOption) []QuizOption {
+	ids := make(map[string]string, len(previous))
+	for _, option := range previous {
+		ids[option.Text] = option.ID
+	}
+
+	var options []QuizOption
+	for _, line := range parseQuizLines(text) {
+		option := QuizOption{}
+		if strings.HasPrefix(line, "*") {
+			option.Correct = true
+			line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+		}
+		if line == "" {
+			continue
+		}
+		option.Text = line
+		if id, ok := ids[line]; ok {
+			option.ID = id
+		} else {
+			option.ID = uuid.New().String()
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
+// OptionsText formats the options for editing, one per line.
+func (q QuizQuestion) OptionsText() string {
+	if q.Type == QuizShortAnswer {
+		return strings.Join(q.Answers, "\n")
+	}
+	lines := make([]string, len(q.Options))
+	for i, option := range q.Options {
+		if option.Correct {
+			lines[i] = "* " + option.Text
+		} else {
+			lines[i] = option.Text
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// IsCorrect checks an answer against the question.
+func (q QuizQuestion) IsCorrect(answer []string) bool {
+	switch q.Type {
+	case QuizShortAnswer:
+		if len(answer) == 0 {
+			return false
+		}
+		matcher := AnswerMatcher{Answers: q.Answers, Fuzzy: true}
+		return matcher.Match(answer[0])
+	default:
+		selected := make(map[string]bool, len(answer))
+		for _, id := range answer {
+			selected[id] = true
+		}
+		if q.Type == QuizSingleChoice && len(selected) != 1 {
+			return false
+		}
+		for _, option := range q.Options {
+			if option.Correct != selected[option.ID] {
+				return false
+			}
+		}
+		return len(q.Options) > 0
+	}
+}
+
+// Validation and Points Calculation.
+func (b *QuizBlock) RequiresValidation() bool { return true }
+
+func (b *QuizBlock) ValidatePlayerInput(state PlayerState, input map[string][]string) (PlayerState, error) {
+	playerData := quizPlayerData{}
+	if state.GetPlayerData() != nil {
+		err := json.Unmarshal(state.GetPlayerData(), &playerData)
+		if err != nil {
+			return state, fmt.Errorf("failed to parse player data: %w", err)
+		}
+	}
+	if playerData.Results == nil {
+		playerData.Results = make(map[string]QuizResult, len(b.Questions))
+	}
+
+	if !b.CanAttempt(state) {
+		return state, errors.New("no attempts remaining")
+	}
+	playerData.Attempts++
+
+	total := 0
+	allCorrect := true
+	for _, question := range b.Questions {
+		// Correct answers stand; only incorrect questions are marked again
+		result, answered := playerData.Results[question.ID]
+		if !answered || !result.Correct {
+			answer := input["q-"+question.ID]
+			result = QuizResult{Answer: answer}
+			if question.IsCorrect(answer) {
+				result.Correct = true
+				result.Points = question.Points
+			}
+			playerData.Results[question.ID] = result
+		}
+		total += result.Points
+		allCorrect = allCorrect && result.Correct
+	}
+
+	newPlayerData, err := json.Marshal(playerData)
+	if err != nil {
+		return state, fmt.Errorf("failed to save player data: %w", err)
+	}
+	state.SetPlayerData(newPlayerData)
+
+	// Partial credit counts once the quiz is over
+	state.SetPointsAwarded(total)
+	state.SetComplete(allCorrect || !b.canRetry(playerData.Attempts))
+
+	return state, nil
+}
+
+// canRetry reports whether another attempt is allowed after the given number.
+func (b *QuizBlock) canRetry(attempts int) bool {
+	if b.RetryPolicy != QuizRetryIncorrect {
+		return false
+	}
+	return b.MaxAttempts == 0 || attempts < b.MaxAttempts
+}
+
+// CanAttempt reports whether the team may submit answers.
+func (b *QuizBlock) CanAttempt(state PlayerState) bool {
+	if state == nil {
+		return true
+	}
+	if state.IsComplete() {
+		return false
+	}
+	attempts := b.GetAttempts(state)
+	return attempts == 0 || b.canRetry(attempts)
+}
+
+// GetAttempts returns the number of times the team has submitted the quiz.
+func (b *QuizBlock) GetAttempts(state PlayerState) int {
+	playerData := quizPlayerData{}
+	if state == nil || state.GetPlayerData() == nil {
+		return 0
+	}
+	if err := json.Unmarshal(state.GetPlayerData(), &playerData); err != nil {
+		return 0
+	}
+	return playerData.Attempts
+}
+
+// GetResults returns the team's latest answer to each question, by question ID.
+func (b *QuizBlock) GetResults(state PlayerState) map[string]QuizResult {
+	playerData := quizPlayerData{}
+	if state == nil || state.GetPlayerData() == nil {
+		return nil
+	}
+	if err := json.Unmarshal(state.GetPlayerData(), &playerData); err != nil {
+		return nil
+	}
+	return playerData.Results
+}
+
+// GetOptions returns the options for a question in the order a team sees them.
+// Shuffled options keep a stable order for each team.
+func (b *QuizBlock) GetOptions(state PlayerState, question QuizQuestion) []QuizOption {
+	if !b.ShuffleOptions || state == nil {
+		return question.Options
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(state.GetPlayerID() + question.ID))
+	rng := rand.New(rand.NewSource(int64(hash.Sum64())))
+
+	options := make([]QuizOption, len(question.Options))
+	copy(options, question.Options)
+	rng.Shuffle(len(options), func(i, j int) {
+		options[i], options[j] = options[j], options[i]
+	})
+	return options
+}
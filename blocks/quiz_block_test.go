@@ -0,0 +1,249 @@
+package blocks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQuiz() QuizBlock {
+	return QuizBlock{
+		BaseBlock: BaseBlock{
+			ID:     "quiz-1",
+			Points: 6,
+		},
+		RetryPolicy: QuizRetryNone,
+		Questions: []QuizQuestion{
+			{
+				ID:     "q1",
+				Type:   QuizSingleChoice,
+				Text:   "Capital of New Zealand?",
+				Points: 1,
+				Options: []QuizOption{
+					{ID: "a", Text: "Auckland"},
+					{ID: "b", Text: "Wellington", Correct: true},
+				},
+			},
+			{
+				ID:     "q2",
+				Type:   QuizMultiChoice,
+				Text:   "Which are birds?",
+				Points: 2,
+				Options: []QuizOption{
+					{ID: "c", Text: "Kiwi", Correct: true},
+					{ID: "d", Text: "Tuatara"},
+					{ID: "e", Text: "Tūī", Correct: true},
+				},
+			},
+			{
+				ID:      "q3",
+				Type:    QuizShortAnswer,
+				Text:    "Highest mountain?",
+				Points:  3,
+				Answers: []string{"Aoraki", "Mount Cook"},
+			},
+		},
+	}
+}
+
+func TestQuizBlock_Getters(t *testing.T) {
+	block := newTestQuiz()
+
+	assert.Equal(t, "Quiz", block.GetName())
+	assert.Equal(t, "quiz", block.GetType())
+	assert.Equal(t, "quiz-1", block.GetID())
+	assert.Equal(t, 6, block.GetPoints())
+	assert.True(t, block.RequiresValidation())
+}
+
+func TestQuizBlock_ParseData(t *testing.T) {
+	original := newTestQuiz()
+	block := QuizBlock{
+		BaseBlock: BaseBlock{
+			Data: original.GetData(),
+		},
+	}
+
+	err := block.ParseData()
+	require.NoError(t, err)
+	assert.Equal(t, original.Questions, block.Questions)
+	assert.Equal(t, QuizRetryNone, block.RetryPolicy)
+}
+
+func TestQuizBlock_UpdateBlockData(t *testing.T) {
+	block := QuizBlock{}
+	err := block.UpdateBlockData(map[string][]string{
+		"content":          {"Answer the questions"},
+		"shuffle_options":  {"on"},
+		"retry_policy":     {"incorrect"},
+		"max_attempts":     {"3"},
+		"question-ids":     {"", "", ""},
+		"question-types":   {"single", "short", "multiple"},
+		"question-texts":   {"Capital?", "Mountain?", ""},
+		"question-points":  {"2", "5", "1"},
+		"question-options": {"Auckland\n* Wellington\n\n", "Aoraki\nMount Cook", "ignored"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Answer the questions", block.Content)
+	assert.True(t, block.ShuffleOptions)
+	assert.Equal(t, QuizRetryIncorrect, block.RetryPolicy)
+	assert.Equal(t, 3, block.MaxAttempts)
+	assert.Equal(t, 7, block.Points, "block points should total the question points")
+
+	require.Len(t, block.Questions, 2, "questions without text are dropped")
+	q1 := block.Questions[0]
+	assert.NotEmpty(t, q1.ID)
+	assert.Equal(t, QuizSingleChoice, q1.Type)
+	require.Len(t, q1.Options, 2)
+	assert.False(t, q1.Options[0].Correct)
+	assert.Equal(t, "Wellington", q1.Options[1].Text)
+	assert.True(t, q1.Options[1].Correct)
+	assert.Equal(t, "Auckland\n* Wellington", q1.OptionsText())
+
+	q2 := block.Questions[1]
+	assert.Equal(t, QuizShortAnswer, q2.Type)
+	assert.Equal(t, []string{"Aoraki", "Mount Cook"}, q2.Answers)
+
+	// Option IDs are kept while the text is unchanged
+	optionID := q1.Options[1].ID
+	err = block.UpdateBlockData(map[string][]string{
+		"question-ids":     {q1.ID},
+		"question-types":   {"single"},
+		"question-texts":   {"Capital?"},
+		"question-options": {"* Wellington\nChristchurch"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, optionID, block.Questions[0].Options[0].ID)
+
+	// Invalid input
+	err = block.UpdateBlockData(map[string][]string{"retry_policy": {"forever"}})
+	assert.Error(t, err)
+	err = block.UpdateBlockData(map[string][]string{
+		"question-texts": {"Capital?"},
+		"question-types": {"essay"},
+	})
+	assert.Error(t, err)
+	err = block.UpdateBlockData(map[string][]string{
+		"question-texts":  {"Capital?"},
+		"question-points": {"lots"},
+	})
+	assert.Error(t, err)
+	err = block.UpdateBlockData(map[string][]string{
+		"question-texts":  {"Capital?"},
+		"question-points": {"-2"},
+	})
+	assert.EqualError(t, err, "points must not be negative")
+	err = block.UpdateBlockData(map[string][]string{
+		"question-texts": {" ", ""},
+	})
+	assert.EqualError(t, err, "a quiz needs at least one question")
+	assert.Len(t, block.Questions, 1, "the questions are unchanged after an error")
+}
+
+func TestQuizQuestion_IsCorrect(t *testing.T) {
+	quiz := newTestQuiz()
+	single, multi, short := quiz.Questions[0], quiz.Questions[1], quiz.Questions[2]
+
+	assert.True(t, single.IsCorrect([]string{"b"}))
+	assert.False(t, single.IsCorrect([]string{"a"}))
+	assert.False(t, single.IsCorrect([]string{"a", "b"}))
+	assert.False(t, single.IsCorrect(nil))
+
+	assert.True(t, multi.IsCorrect([]string{"e", "c"}))
+	assert.False(t, multi.IsCorrect([]string{"c"}))
+	assert.False(t, multi.IsCorrect([]string{"c", "d", "e"}))
+
+	assert.True(t, short.IsCorrect([]string{" mount cook "}))
+	assert.False(t, short.IsCorrect([]string{"Ruapehu"}))
+	assert.False(t, short.IsCorrect(nil))
+}
+
+func TestQuizBlock_ValidatePlayerInput(t *testing.T) {
+	t.Run("Partial credit without retries", func(t *testing.T) {
+		block := newTestQuiz()
+		state := &mockPlayerState{}
+
+		state2, err := block.ValidatePlayerInput(state, map[string][]string{
+			"q-q1": {"b"},
+			"q-q2": {"c"},
+			"q-q3": {"Aoraki"},
+		})
+		require.NoError(t, err)
+		assert.True(t, state2.IsComplete(), "quiz is over after one attempt")
+		assert.Equal(t, 4, state2.GetPointsAwarded())
+
+		results := block.GetResults(state2)
+		assert.True(t, results["q1"].Correct)
+		assert.False(t, results["q2"].Correct)
+		assert.Equal(t, []string{"c"}, results["q2"].Answer)
+		assert.Equal(t, 3, results["q3"].Points)
+
+		assert.False(t, block.CanAttempt(state2))
+		_, err = block.ValidatePlayerInput(state2, map[string][]string{"q-q2": {"c", "e"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("Retry incorrect questions", func(t *testing.T) {
+		block := newTestQuiz()
+		block.RetryPolicy = QuizRetryIncorrect
+		block.MaxAttempts = 3
+		state := &mockPlayerState{}
+
+		state2, err := block.ValidatePlayerInput(state, map[string][]string{
+			"q-q1": {"b"},
+			"q-q3": {"Ruapehu"},
+		})
+		require.NoError(t, err)
+		assert.False(t, state2.IsComplete())
+		assert.Equal(t, 1, state2.GetPointsAwarded())
+		assert.True(t, block.CanAttempt(state2))
+
+		// Correct answers are kept even if they are not resubmitted
+		state3, err := block.ValidatePlayerInput(state2, map[string][]string{
+			"q-q2": {"c", "e"},
+			"q-q3": {"Aoraki"},
+		})
+		require.NoError(t, err)
+		assert.True(t, state3.IsComplete())
+		assert.Equal(t, 6, state3.GetPointsAwarded())
+		assert.Equal(t, 2, block.GetAttempts(state3))
+	})
+
+	t.Run("Attempts run out", func(t *testing.T) {
+		block := newTestQuiz()
+		block.RetryPolicy = QuizRetryIncorrect
+		block.MaxAttempts = 2
+		state := PlayerState(&mockPlayerState{})
+
+		var err error
+		for i := 0; i < 2; i++ {
+			state, err = block.ValidatePlayerInput(state, map[string][]string{"q-q1": {"b"}})
+			require.NoError(t, err)
+		}
+		assert.True(t, state.IsComplete())
+		assert.Equal(t, 1, state.GetPointsAwarded())
+	})
+
+	t.Run("Invalid player data", func(t *testing.T) {
+		block := newTestQuiz()
+		state := &mockPlayerState{playerData: json.RawMessage(`{"results":`)}
+		_, err := block.ValidatePlayerInput(state, map[string][]string{})
+		assert.Error(t, err)
+	})
+}
+
+func TestQuizBlock_GetOptions(t *testing.T) {
+	block := newTestQuiz()
+	question := block.Questions[1]
+	state := &mockPlayerState{playerID: "TEAM1"}
+
+	assert.Equal(t, question.Options, block.GetOptions(state, question), "options are in order unless shuffled")
+
+	block.ShuffleOptions = true
+	shuffled := block.GetOptions(state, question)
+	assert.ElementsMatch(t, question.Options, shuffled)
+	assert.Equal(t, shuffled, block.GetOptions(state, question), "shuffled order is stable for a team")
+}
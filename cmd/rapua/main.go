@@ -11,6 +11,7 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
 	"github.com/nathanhollows/Rapua/v3/internal/migrations"
 	"github.com/nathanhollows/Rapua/v3/internal/server"
 	"github.com/nathanhollows/Rapua/v3/internal/services"
@@ -44,6 +45,7 @@ func main() {
 		Version:     "3.4.0",
 		Commands: []*cli.Command{
 			newDBCommand(migrator),
+			newInstanceCommand(db),
 		},
 		Action: func(c *cli.Context) error {
 			// Default action: run the app
@@ -154,20 +156,115 @@ func newDBCommand(migrator *migrate.Migrator) *cli.Command {
 	}
 }
 
+func newInstanceCommand(dbc *bun.DB) *cli.Command {
+	newBundleService := func() services.InstanceBundleService {
+		uploadRepo := repositories.NewUploadRepository(dbc)
+		return services.NewInstanceBundleService(
+			db.NewTransactor(dbc),
+			services.NewUploadService(uploadRepo, storage.NewLocalStorage("static/uploads/")),
+			repositories.NewAuditRepository(dbc),
+			repositories.NewBlockRepository(dbc, repositories.NewBlockStateRepository(dbc)),
+			repositories.NewBonusRepository(dbc),
+			repositories.NewClueRepository(dbc),
+			repositories.NewHintRepository(dbc),
+			repositories.NewInstanceRepository(dbc),
+			repositories.NewInstanceSettingsRepository(dbc),
+			repositories.NewLocationRepository(dbc),
+			repositories.NewMarkerRepository(dbc),
+			repositories.NewRouteRepository(dbc),
+			uploadRepo,
+		)
+	}
+
+	return &cli.Command{
+		Name:  "instance",
+		Usage: "export and import instances",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "export",
+				Usage: "export an instance to a bundle",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "id", Usage: "ID of the instance to export", Required: true},
+					&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "file to write the bundle to", Required: true},
+					&cli.BoolFlag{Name: "json", Usage: "write JSON only, without uploads"},
+				},
+				Action: func(c *cli.Context) error {
+					f, err := os.Create(c.String("output"))
+					if err != nil {
+						return err
+					}
+					defer f.Close()
+
+					bundleService := newBundleService()
+					if c.Bool("json") {
+						err = bundleService.ExportJSON(c.Context, c.String("id"), f)
+					} else {
+						err = bundleService.Export(c.Context, c.String("id"), f)
+					}
+					if err != nil {
+						return err
+					}
+					fmt.Printf("exported instance %s to %s\n", c.String("id"), c.String("output"))
+					return nil
+				},
+			},
+			{
+				Name:      "import",
+				Usage:     "import a bundle as a new instance",
+				ArgsUsage: "<bundle>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "email", Usage: "email of the user who will own the instance", Required: true},
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("expected a bundle to import")
+					}
+
+					user, err := repositories.NewUserRepository(dbc).GetByEmail(c.Context, c.String("email"))
+					if err != nil {
+						return fmt.Errorf("finding user: %w", err)
+					}
+
+					f, err := os.Open(c.Args().First())
+					if err != nil {
+						return err
+					}
+					defer f.Close()
+
+					instance, err := newBundleService().Import(c.Context, user, f)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("imported %q as instance %s\n", instance.Name, instance.ID)
+					return nil
+				},
+			},
+		},
+	}
+}
+
 func runApp(logger *slog.Logger, dbc *bun.DB) {
 	initialiseFolders(logger)
 
 	// Initialize repositories
+	auditRepo := repositories.NewAuditRepository(dbc)
 	blockStateRepo := repositories.NewBlockStateRepository(dbc)
 	blockRepo := repositories.NewBlockRepository(dbc, blockStateRepo)
+	bonusRepo := repositories.NewBonusRepository(dbc)
 	checkInRepo := repositories.NewCheckInRepository(dbc)
 	clueRepo := repositories.NewClueRepository(dbc)
 	facilitatorRepo := repositories.NewFacilitatorTokenRepo(dbc)
+	hintRepo := repositories.NewHintRepository(dbc)
 	instanceRepo := repositories.NewInstanceRepository(dbc)
+	instanceMemberRepo := repositories.NewInstanceMemberRepository(dbc)
 	instanceSettingsRepo := repositories.NewInstanceSettingsRepository(dbc)
 	locationRepo := repositories.NewLocationRepository(dbc)
 	markerRepo := repositories.NewMarkerRepository(dbc)
 	notificationRepo := repositories.NewNotificationRepository(dbc)
+	organisationRepo := repositories.NewOrganisationRepository(dbc)
+	playerRepo := repositories.NewPlayerRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	routeRepo := repositories.NewRouteRepository(dbc)
 	teamRepo := repositories.NewTeamRepository(dbc)
 	userRepo := repositories.NewUserRepository(dbc)
 	uploadRepo := repositories.NewUploadRepository(dbc)
@@ -178,51 +275,86 @@ func runApp(logger *slog.Logger, dbc *bun.DB) {
 	// Storage for the upload service
 	localStorage := storage.NewLocalStorage("static/uploads/")
 
+	// In-process hub for pushing live game updates to open pages
+	eventBroker := events.NewBroker()
+
 	// Initialize services
 	uploadService := services.NewUploadService(uploadRepo, localStorage)
 	facilitatorService := services.NewFacilitatorService(facilitatorRepo)
 	assetGenerator := services.NewAssetGenerator()
+	auditService := services.NewAuditService(auditRepo)
 	authService := services.NewAuthService(userRepo)
-	blockService := services.NewBlockService(transactor, blockRepo, blockStateRepo)
 	checkInService := services.NewCheckInService(checkInRepo, locationRepo, teamRepo)
 	clueService := services.NewClueService(clueRepo, locationRepo)
 	emailService := services.NewEmailService()
-	locationService := services.NewLocationService(transactor, clueRepo, locationRepo, markerRepo, blockRepo)
+	collaboratorService := services.NewCollaboratorService(emailService, instanceRepo, locationRepo, instanceMemberRepo, organisationRepo, userRepo)
+	geofenceService := services.NewGeofenceService()
+	locationService := services.NewLocationService(transactor, clueRepo, hintRepo, locationRepo, markerRepo, blockRepo, auditRepo)
 	navigationService := services.NewNavigationService()
-	notificationService := services.NewNotificationService(notificationRepo, teamRepo)
-	teamService := services.NewTeamService(transactor, teamRepo, checkInRepo, blockStateRepo, locationRepo)
+	notificationService := services.NewNotificationService(eventBroker, notificationRepo, teamRepo)
+	leaderboardService := services.NewLeaderboardService(instanceRepo, instanceSettingsRepo, teamRepo)
+	organisationService := services.NewOrganisationService(auditRepo, instanceRepo, organisationRepo, userRepo)
+	markerService := services.NewMarkerService(collaboratorService, locationService, locationRepo, markerRepo, organisationRepo)
+	playerService := services.NewPlayerService(playerRepo)
+	teamService := services.NewTeamService(transactor, eventBroker, teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	bonusService := services.NewBonusService(transactor, eventBroker, bonusRepo, checkInRepo, hintRepo, locationRepo, pointsRepo, teamRepo, teamService)
+	blockService := services.NewBlockService(transactor, eventBroker, auditRepo, blockRepo, blockStateRepo, checkInRepo, pointsRepo, teamRepo, bonusService)
+	hintService := services.NewHintService(transactor, hintRepo, teamService)
+	routeService := services.NewRouteService(transactor, routeRepo, locationRepo, teamRepo)
+	reviewService := services.NewReviewService(transactor, eventBroker, auditRepo, blockStateRepo, checkInRepo, pointsRepo, teamRepo, blockService, bonusService, notificationService)
 	userService := services.NewUserService(transactor, userRepo, instanceRepo)
 	instanceService := services.NewInstanceService(
 		transactor,
-		locationService, userService, teamService, instanceRepo, instanceSettingsRepo,
+		locationService, userService, teamService, collaboratorService, instanceRepo, instanceSettingsRepo, auditRepo, instanceMemberRepo, organisationRepo,
 	)
+	instanceBundleService := services.NewInstanceBundleService(
+		transactor, uploadService,
+		auditRepo, blockRepo, bonusRepo, clueRepo, hintRepo, instanceRepo, instanceSettingsRepo, locationRepo, markerRepo, routeRepo, uploadRepo,
+	)
+	templateService := services.NewTemplateService(transactor, instanceService, auditRepo, instanceRepo)
 	gameplayService := services.NewGameplayService(
-		checkInService, locationService, teamService, blockService, navigationService, markerRepo,
+		eventBroker,
+		checkInService, geofenceService, locationService, teamService, blockService, bonusService, navigationService, markerRepo,
 	)
+	stationService := services.NewStationService(blockService, gameplayService, teamService, auditRepo, checkInRepo, locationRepo)
 	gameManagerService := services.NewGameManagerService(
 		transactor,
 		locationService, userService, teamService,
 		markerRepo, clueRepo, instanceRepo, instanceSettingsRepo,
-		instanceService,
+		instanceService, auditRepo,
 	)
 
 	sessions.Start()
 	server.Start(
 		logger,
 		assetGenerator,
+		auditService,
 		authService,
 		blockService,
+		bonusService,
 		checkInService,
 		clueService,
+		collaboratorService,
 		emailService,
+		eventBroker,
 		facilitatorService,
 		gameManagerService,
 		gameplayService,
+		hintService,
+		instanceBundleService,
 		instanceService,
+		leaderboardService,
 		locationService,
+		markerService,
 		navigationService,
 		notificationService,
+		organisationService,
+		playerService,
+		reviewService,
+		routeService,
+		stationService,
 		teamService,
+		templateService,
 		uploadService,
 		userService,
 	)
@@ -3,6 +3,8 @@ package contextkeys
 type contextKey string
 
 const (
-	UserKey contextKey = "user"
-	TeamKey contextKey = "team"
+	UserKey   contextKey = "user"
+	TeamKey   contextKey = "team"
+	ActorKey  contextKey = "actor"
+	PlayerKey contextKey = "player"
 )
@@ -0,0 +1,117 @@
+package events
+
+import (
+	"sync"
+)
+
+// EventType identifies what happened in a game.
+// It doubles as the SSE event name sent to browsers.
+type EventType string
+
+const (
+	NotificationSent   EventType = "notification"
+	TeamCheckedIn      EventType = "check-in"
+	TeamCheckedOut     EventType = "check-out"
+	BlockStateUpdated  EventType = "block-state"
+	PointsChanged      EventType = "points"
+	LeaderboardUpdated EventType = "leaderboard"
+)
+
+// subscriptionBuffer is the number of events held for a slow subscriber
+// before new events are dropped.
+const subscriptionBuffer = 16
+
+// Event is a change in a game instance that subscribers may want to know about.
+type Event struct {
+	Type       EventType
+	InstanceID string
+	// TeamCode is the team the event concerns.
+	// Events without a team code are broadcast to the whole instance.
+	TeamCode string
+	// Payload carries the record that changed, e.g. a models.Notification.
+	Payload any
+}
+
+// Subscription receives events for a game instance.
+type Subscription struct {
+	instanceID string
+	teamCode   string
+	events     chan Event
+}
+
+// Events returns the channel events are delivered on.
+// The channel is closed when the subscription is cancelled.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// matches reports whether the subscription should receive the event.
+func (s *Subscription) matches(event Event) bool {
+	if s.instanceID != event.InstanceID {
+		return false
+	}
+	// Subscriptions without a team code see every event in the instance
+	if s.teamCode == "" || event.TeamCode == "" {
+		return true
+	}
+	return s.teamCode == event.TeamCode
+}
+
+// Broker is an in-process hub that fans game events out to subscribers.
+type Broker struct {
+	mu            sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+}
+
+// NewBroker creates a new event broker.
+func NewBroker() *Broker {
+	return &Broker{
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe registers interest in the events of an instance.
+// If teamCode is set, only that team's events and instance-wide
+// broadcasts are delivered; otherwise all instance events are.
+func (b *Broker) Subscribe(instanceID, teamCode string) *Subscription {
+	sub := &Subscription{
+		instanceID: instanceID,
+		teamCode:   teamCode,
+		events:     make(chan Event, subscriptionBuffer),
+	}
+
+	b.mu.Lock()
+	b.subscriptions[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub
+}
+
+// Unsubscribe removes the subscription and closes its channel.
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscriptions[sub]; !ok {
+		return
+	}
+	delete(b.subscriptions, sub)
+	close(sub.events)
+}
+
+// Publish delivers the event to every matching subscriber.
+// Publish never blocks; subscribers that have fallen behind miss the event.
+func (b *Broker) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscriptions {
+		if !sub.matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
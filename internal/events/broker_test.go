@@ -0,0 +1,137 @@
+package events_test
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receive(t *testing.T, sub *events.Subscription) (events.Event, bool) {
+	t.Helper()
+	select {
+	case event := <-sub.Events():
+		return event, true
+	case <-time.After(50 * time.Millisecond):
+		return events.Event{}, false
+	}
+}
+
+func TestBroker_Publish(t *testing.T) {
+	broker := events.NewBroker()
+
+	admin := broker.Subscribe("instance-1", "")
+	teamA := broker.Subscribe("instance-1", "AAAAA")
+	teamB := broker.Subscribe("instance-1", "BBBBB")
+	other := broker.Subscribe("instance-2", "")
+
+	t.Run("Team event", func(t *testing.T) {
+		broker.Publish(events.Event{Type: events.TeamCheckedIn, InstanceID: "instance-1", TeamCode: "AAAAA"})
+
+		event, ok := receive(t, admin)
+		assert.True(t, ok, "admin should receive team events")
+		assert.Equal(t, events.TeamCheckedIn, event.Type)
+
+		_, ok = receive(t, teamA)
+		assert.True(t, ok, "team should receive its own events")
+
+		_, ok = receive(t, teamB)
+		assert.False(t, ok, "team should not receive other teams' events")
+
+		_, ok = receive(t, other)
+		assert.False(t, ok, "other instances should not receive events")
+	})
+
+	t.Run("Broadcast", func(t *testing.T) {
+		broker.Publish(events.Event{Type: events.NotificationSent, InstanceID: "instance-1"})
+
+		for _, sub := range []*events.Subscription{admin, teamA, teamB} {
+			_, ok := receive(t, sub)
+			assert.True(t, ok)
+		}
+		_, ok := receive(t, other)
+		assert.False(t, ok)
+	})
+}
+
+func TestBroker_Unsubscribe(t *testing.T) {
+	broker := events.NewBroker()
+	sub := broker.Subscribe("instance-1", "")
+
+	broker.Unsubscribe(sub)
+	// Unsubscribing twice must not panic
+	broker.Unsubscribe(sub)
+
+	_, open := <-sub.Events()
+	assert.False(t, open, "channel should be closed")
+
+	// Publishing after unsubscribing must not panic
+	broker.Publish(events.Event{Type: events.TeamCheckedIn, InstanceID: "instance-1"})
+}
+
+func TestBroker_PublishDoesNotBlock(t *testing.T) {
+	broker := events.NewBroker()
+	broker.Subscribe("instance-1", "")
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			broker.Publish(events.Event{Type: events.BlockStateUpdated, InstanceID: "instance-1"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a slow subscriber")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	err := events.Write(&buf, events.NotificationSent, "<div>\nHello\n</div>")
+	require.NoError(t, err)
+	assert.Equal(t, "event: notification\ndata: <div>\ndata: Hello\ndata: </div>\n\n", buf.String())
+}
+
+func TestBroker_Stream(t *testing.T) {
+	broker := events.NewBroker()
+	sub := broker.Subscribe("instance-1", "AAAAA")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := httptest.NewRequest("GET", "/events", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	done := make(chan error)
+	go func() {
+		done <- broker.Stream(w, r, sub, func(event events.Event) (string, error) {
+			if event.Type == events.BlockStateUpdated {
+				return "", nil
+			}
+			return event.Payload.(string), nil
+		})
+	}()
+
+	broker.Publish(events.Event{Type: events.BlockStateUpdated, InstanceID: "instance-1", TeamCode: "AAAAA", Payload: "skipped"})
+	broker.Publish(events.Event{Type: events.NotificationSent, InstanceID: "instance-1", TeamCode: "AAAAA", Payload: "Hello"})
+
+	// Give the stream a moment to write before disconnecting
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "event: notification\ndata: Hello\n\n", w.Body.String())
+	assert.False(t, strings.Contains(w.Body.String(), "skipped"))
+
+	_, open := <-sub.Events()
+	assert.False(t, open, "subscription should be cancelled when the stream ends")
+}
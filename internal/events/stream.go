@@ -0,0 +1,76 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// keepAliveInterval is how often a comment is sent to hold the connection open.
+const keepAliveInterval = 30 * time.Second
+
+// RenderFunc turns an event into the data sent to the browser.
+// Returning an empty string skips the event.
+type RenderFunc func(event Event) (string, error)
+
+// Write writes a single event in the Server-Sent Events wire format.
+func Write(w io.Writer, eventType EventType, data string) error {
+	var sb strings.Builder
+	sb.WriteString("event: " + string(eventType) + "\n")
+	for _, line := range strings.Split(data, "\n") {
+		sb.WriteString("data: " + line + "\n")
+	}
+	sb.WriteString("\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Stream writes events from the subscription to the response until the
+// client disconnects. The subscription is cancelled when Stream returns.
+func (b *Broker) Stream(w http.ResponseWriter, r *http.Request, sub *Subscription, render RenderFunc) error {
+	defer b.Unsubscribe(sub)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errors.New("streaming unsupported")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return fmt.Errorf("writing keep-alive: %w", err)
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				return nil
+			}
+			data, err := render(event)
+			if err != nil {
+				return fmt.Errorf("rendering %s event: %w", event.Type, err)
+			}
+			if data == "" {
+				continue
+			}
+			if err := Write(w, event.Type, data); err != nil {
+				return fmt.Errorf("writing %s event: %w", event.Type, err)
+			}
+		}
+		flusher.Flush()
+	}
+}
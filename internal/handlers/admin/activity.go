@@ -5,8 +5,10 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
 	"github.com/nathanhollows/Rapua/v3/internal/services"
 	templates "github.com/nathanhollows/Rapua/v3/internal/templates/admin"
+	"github.com/nathanhollows/Rapua/v3/models"
 )
 
 // Activity displays the activity tracker page.
@@ -42,12 +44,30 @@ func (h *AdminHandler) ActivityTeamsOverview(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+// ActivityEvents streams check-ins and block updates for the current instance
+// so the activity tracker can refresh as teams play.
+func (h *AdminHandler) ActivityEvents(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	sub := h.EventBroker.Subscribe(user.CurrentInstanceID, "")
+	err := h.EventBroker.Stream(w, r, sub, func(event events.Event) (string, error) {
+		// The page only needs to know which team changed
+		return event.TeamCode, nil
+	})
+	if err != nil {
+		h.Logger.Error("ActivityEvents: streaming", "error", err, "instance_id", user.CurrentInstanceID)
+	}
+}
+
 // TeamActivity displays the activity tracker page.
 // It accepts HTMX requests to update the team activity.
 func (h *AdminHandler) TeamActivity(w http.ResponseWriter, r *http.Request) {
-	user := h.UserFromContext(r.Context())
+	h.renderTeamActivity(w, r, chi.URLParam(r, "teamCode"), "")
+}
 
-	teamCode := chi.URLParam(r, "teamCode")
+// renderTeamActivity shows a team's activity and, if set, a success message.
+func (h *AdminHandler) renderTeamActivity(w http.ResponseWriter, r *http.Request, teamCode string, message string) {
+	user := h.UserFromContext(r.Context())
 
 	team, err := h.GameplayService.GetTeamByCode(r.Context(), teamCode)
 	if err != nil || team.InstanceID != user.CurrentInstanceID {
@@ -75,9 +95,47 @@ func (h *AdminHandler) TeamActivity(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = templates.TeamActivity(user.CurrentInstance.Settings, *team, notifications, locations).Render(r.Context(), w)
+	uploads, err := h.UploadService.Search(r.Context(), map[string]string{
+		"instance_id": team.InstanceID,
+		"team_code":   team.Code,
+		"type":        string(models.MediaTypeImage),
+	})
+	if err != nil {
+		h.handleError(w, r, "TeamActivity: getting uploads", "Error getting photos", "Could not load data", err)
+		return
+	}
+
+	hints, err := h.HintService.FindRevealed(r.Context(), team.Code)
+	if err != nil {
+		h.handleError(w, r, "TeamActivity: getting hints", "Error getting hints", "Could not load data", err)
+		return
+	}
+
+	ledger, err := h.TeamService.FindPoints(r.Context(), team.Code)
+	if err != nil {
+		h.handleError(w, r, "TeamActivity: getting points", "Error getting points", "Could not load data", err)
+		return
+	}
+
+	incomplete, err := h.BlockService.FindIncomplete(r.Context(), team)
+	if err != nil {
+		h.handleError(w, r, "TeamActivity: getting unfinished blocks", "Error getting activities", "Could not load data", err)
+		return
+	}
+
+	players, err := h.PlayerService.Contributions(r.Context(), team.Code)
+	if err != nil {
+		h.handleError(w, r, "TeamActivity: getting players", "Error getting players", "Could not load data", err)
+		return
+	}
+
+	err = templates.TeamActivity(user.CurrentInstance.Settings, *team, notifications, locations, uploads, hints, ledger, incomplete, players).Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("TeamActivity: rendering template", "error", err)
+		return
 	}
 
+	if message != "" {
+		h.handleSuccess(w, r, message)
+	}
 }
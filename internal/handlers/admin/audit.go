@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	templates "github.com/nathanhollows/Rapua/v3/internal/templates/admin"
+	"github.com/nathanhollows/Rapua/v3/models"
+)
+
+// AuditLog lists the changes made to the current instance.
+// Events can be filtered by actor and by a range of days (UTC).
+func (h *AdminHandler) AuditLog(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	query := r.URL.Query()
+	filter := models.AuditFilter{ActorID: query.Get("actor")}
+	if from, err := time.Parse(time.DateOnly, query.Get("from")); err == nil {
+		filter.Since = from
+	}
+	if to, err := time.Parse(time.DateOnly, query.Get("to")); err == nil {
+		filter.Until = to.AddDate(0, 0, 1)
+	}
+
+	events, err := h.AuditService.Find(r.Context(), user.CurrentInstanceID, filter)
+	if err != nil {
+		h.handleError(w, r, "AuditLog: finding events", "Error loading audit log", "error", err)
+		return
+	}
+
+	actors, err := h.AuditService.FindActors(r.Context(), user.CurrentInstanceID)
+	if err != nil {
+		h.handleError(w, r, "AuditLog: finding actors", "Error loading audit log", "error", err)
+		return
+	}
+
+	c := templates.AuditLog(events, actors, query.Get("actor"), query.Get("from"), query.Get("to"))
+	err = templates.Layout(c, *user, "Audit log", "Audit log").Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("AuditLog: rendering template", "error", err)
+	}
+}
@@ -5,13 +5,14 @@ import (
 
 	"github.com/go-chi/chi"
 	templates "github.com/nathanhollows/Rapua/v3/internal/templates/blocks"
+	"github.com/nathanhollows/Rapua/v3/models"
 )
 
 // BlockEdit shows the form to edit a block.
 func (h *AdminHandler) BlockEdit(w http.ResponseWriter, r *http.Request) {
 	user := h.UserFromContext(r.Context())
 	location := chi.URLParam(r, "location")
-	if !h.GameManagerService.ValidateLocationID(user, location) {
+	if h.CollaboratorService.AuthorizeLocation(r.Context(), user, location, models.PermissionEdit) != nil {
 		h.handleError(w, r, "BlockEdit: invalid location", "Could not find block", "location", location)
 		return
 	}
@@ -41,7 +42,7 @@ func (h *AdminHandler) BlockEditPost(w http.ResponseWriter, r *http.Request) {
 	user := h.UserFromContext(r.Context())
 
 	location := chi.URLParam(r, "location")
-	if !h.GameManagerService.ValidateLocationID(user, location) {
+	if h.CollaboratorService.AuthorizeLocation(r.Context(), user, location, models.PermissionEdit) != nil {
 		h.handleError(w, r, "BlockEditPost: invalid location", "Could not update block. Invalid location", "location", location)
 		return
 	}
@@ -86,7 +87,7 @@ func (h *AdminHandler) BlockNewPost(w http.ResponseWriter, r *http.Request) {
 	blockType := chi.URLParam(r, "type")
 
 	locationID := chi.URLParam(r, "location")
-	if !h.GameManagerService.ValidateLocationID(user, locationID) {
+	if h.CollaboratorService.AuthorizeLocation(r.Context(), user, locationID, models.PermissionEdit) != nil {
 		h.handleError(w, r, "BlockNewPost: invalid location", "Could not create block. Invalid location", "location", locationID)
 		return
 	}
@@ -103,7 +104,7 @@ func (h *AdminHandler) BlockNewPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = templates.RenderAdminBlock(user.CurrentInstance.Settings, block, true).Render(r.Context(), w)
+	err = templates.RenderAdminBlock(user.CurrentInstance.Settings, block, nil, true).Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("BlockNewPost: rendering template", "error", err)
 	}
@@ -123,7 +124,7 @@ func (h *AdminHandler) BlockDelete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if the user has access to the location
-	if !h.GameManagerService.ValidateLocationID(user, location) {
+	if h.CollaboratorService.AuthorizeLocation(r.Context(), user, location, models.PermissionEdit) != nil {
 		h.handleError(w, r, "BlockDelete: invalid location", "Could not delete block. Invalid location", "location", location)
 		return
 	}
@@ -154,7 +155,7 @@ func (h *AdminHandler) ReorderBlocks(w http.ResponseWriter, r *http.Request) {
 	user := h.UserFromContext(r.Context())
 
 	location := chi.URLParam(r, "location")
-	if !h.GameManagerService.ValidateLocationID(user, location) {
+	if h.CollaboratorService.AuthorizeLocation(r.Context(), user, location, models.PermissionEdit) != nil {
 		h.handleError(w, r, "ReorderBlocks: invalid location", "Could not reorder blocks. Invalid location", "location", location)
 		return
 	}
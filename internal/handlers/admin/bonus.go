@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+)
+
+// BonusRuleNew creates a bonus rule with sensible defaults for its type.
+func (h *AdminHandler) BonusRuleNew(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.handleError(w, r, "BonusRuleNew parsing form", "Error parsing form", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	ruleType, err := models.ParseBonusRuleType(r.FormValue("type"))
+	if err != nil {
+		h.handleError(w, r, "BonusRuleNew parsing type", "Unknown bonus type", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	threshold := 0
+	switch ruleType {
+	case models.FirstToArriveBonus:
+		threshold = 1
+	case models.QuickFinishBonus:
+		threshold = 60
+	case models.StreakBonus:
+		threshold = 3
+	}
+
+	_, err = h.BonusService.CreateRule(r.Context(), user.CurrentInstanceID, ruleType, 10, threshold)
+	if err != nil {
+		h.handleError(w, r, "BonusRuleNew creating rule", "Error creating bonus", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/experience")
+}
+
+// BonusRuleEditPost updates the points and threshold of a bonus rule.
+func (h *AdminHandler) BonusRuleEditPost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.handleError(w, r, "BonusRuleEditPost parsing form", "Error parsing form", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	ruleID := chi.URLParam(r, "id")
+	points, err := strconv.Atoi(r.FormValue("points"))
+	if err != nil {
+		h.handleError(w, r, "BonusRuleEditPost parsing points", "Points must be a number", "error", err, "instance_id", user.CurrentInstanceID, "rule_id", ruleID)
+		return
+	}
+
+	threshold := 0
+	if r.FormValue("threshold") != "" {
+		threshold, err = strconv.Atoi(r.FormValue("threshold"))
+		if err != nil {
+			h.handleError(w, r, "BonusRuleEditPost parsing threshold", "Please enter a whole number", "error", err, "instance_id", user.CurrentInstanceID, "rule_id", ruleID)
+			return
+		}
+	}
+
+	_, err = h.BonusService.UpdateRule(r.Context(), user.CurrentInstanceID, ruleID, points, threshold)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidArgument) {
+			h.handleError(w, r, "BonusRuleEditPost updating rule", "Points and thresholds must be greater than zero", "error", err, "instance_id", user.CurrentInstanceID, "rule_id", ruleID)
+			return
+		}
+		h.handleError(w, r, "BonusRuleEditPost updating rule", "Error updating bonus", "error", err, "instance_id", user.CurrentInstanceID, "rule_id", ruleID)
+		return
+	}
+
+	h.handleSuccess(w, r, "Bonus updated")
+}
+
+// BonusRuleDelete removes a bonus rule. Points already awarded are kept.
+func (h *AdminHandler) BonusRuleDelete(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	ruleID := chi.URLParam(r, "id")
+	err := h.BonusService.DeleteRule(r.Context(), user.CurrentInstanceID, ruleID)
+	if err != nil {
+		h.handleError(w, r, "BonusRuleDelete deleting rule", "Error deleting bonus", "error", err, "instance_id", user.CurrentInstanceID, "rule_id", ruleID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/experience")
+}
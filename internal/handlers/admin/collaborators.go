@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	templates "github.com/nathanhollows/Rapua/v3/internal/templates/admin"
+	"github.com/nathanhollows/Rapua/v3/models"
+)
+
+// Collaborators lists the users the current instance is shared with.
+func (h *AdminHandler) Collaborators(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	members, err := h.CollaboratorService.FindMembers(r.Context(), user.CurrentInstanceID)
+	if err != nil {
+		h.handleError(w, r, "Collaborators: finding members", "Error loading collaborators", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	c := templates.Collaborators(*user, members)
+	err = templates.Layout(c, *user, "Collaborators", "Collaborators").Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("Collaborators: rendering template", "error", err)
+	}
+}
+
+// CollaboratorInvitePost emails an invitation to collaborate on the current instance.
+func (h *AdminHandler) CollaboratorInvitePost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		h.handleError(w, r, "CollaboratorInvitePost: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	role := models.InstanceRole(r.Form.Get("role"))
+	_, err = h.CollaboratorService.Invite(r.Context(), user, user.CurrentInstanceID, r.Form.Get("email"), role)
+	if err != nil {
+		h.handleError(w, r, "CollaboratorInvitePost: inviting", collaboratorErrorMessage(err, "Error sending invitation"), "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/collaborators")
+}
+
+// CollaboratorRolePost changes a collaborator's role.
+func (h *AdminHandler) CollaboratorRolePost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		h.handleError(w, r, "CollaboratorRolePost: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	memberID := chi.URLParam(r, "id")
+	role := models.InstanceRole(r.Form.Get("role"))
+	err = h.CollaboratorService.UpdateRole(r.Context(), user, user.CurrentInstanceID, memberID, role)
+	if err != nil {
+		h.handleError(w, r, "CollaboratorRolePost: updating role", collaboratorErrorMessage(err, "Error updating role"), "error", err, "instance_id", user.CurrentInstanceID, "member_id", memberID)
+		return
+	}
+
+	h.handleSuccess(w, r, "Role updated")
+}
+
+// CollaboratorDelete removes a collaborator or cancels an invitation.
+func (h *AdminHandler) CollaboratorDelete(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	memberID := chi.URLParam(r, "id")
+	err := h.CollaboratorService.Remove(r.Context(), user, user.CurrentInstanceID, memberID)
+	if err != nil {
+		h.handleError(w, r, "CollaboratorDelete: removing member", collaboratorErrorMessage(err, "Error removing collaborator"), "error", err, "instance_id", user.CurrentInstanceID, "member_id", memberID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/collaborators")
+}
+
+// InstanceLeave removes the user from an instance that was shared with them.
+func (h *AdminHandler) InstanceLeave(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	instanceID := chi.URLParam(r, "id")
+	err := h.CollaboratorService.Leave(r.Context(), user, instanceID)
+	if err != nil {
+		h.handleError(w, r, "InstanceLeave: leaving instance", "Error leaving instance", "error", err, "instance_id", instanceID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/instances")
+}
+
+// InviteAccept accepts an invitation and switches to the shared instance.
+func (h *AdminHandler) InviteAccept(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	member, err := h.CollaboratorService.AcceptInvite(r.Context(), user, chi.URLParam(r, "token"))
+	if err != nil {
+		h.Logger.Error("InviteAccept: accepting invitation", "error", err, "user_id", user.ID)
+		c := templates.InviteProblem(collaboratorErrorMessage(err, "Error accepting invitation"))
+		err = templates.Layout(c, *user, "Instances", "Invitation").Render(r.Context(), w)
+		if err != nil {
+			h.Logger.Error("InviteAccept: rendering template", "error", err)
+		}
+		return
+	}
+
+	_, err = h.IntanceService.SwitchInstance(r.Context(), user, member.InstanceID)
+	if err != nil {
+		h.Logger.Error("InviteAccept: switching instance", "error", err, "instance_id", member.InstanceID)
+		http.Redirect(w, r, "/admin/instances", http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/admin/activity", http.StatusSeeOther)
+}
+
+// collaboratorErrorMessage explains why a collaborator change failed.
+func collaboratorErrorMessage(err error, fallback string) string {
+	switch {
+	case errors.Is(err, services.ErrPermissionDenied):
+		return "Only the owner can manage collaborators"
+	case errors.Is(err, services.ErrAlreadyCollaborator):
+		return "That person already has access or has been invited"
+	case errors.Is(err, services.ErrInviteNotFound):
+		return "This invitation has already been used or cancelled"
+	case errors.Is(err, services.ErrInviteWrongEmail):
+		return "This invitation was sent to a different email address"
+	case errors.Is(err, services.ErrInvalidArgument):
+		return "Please enter a valid email address and role"
+	}
+	return fallback
+}
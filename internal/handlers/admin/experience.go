@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/nathanhollows/Rapua/v3/helpers"
 	admin "github.com/nathanhollows/Rapua/v3/internal/templates/admin"
 	players "github.com/nathanhollows/Rapua/v3/internal/templates/players"
 	"github.com/nathanhollows/Rapua/v3/models"
@@ -19,7 +20,18 @@ func (h *AdminHandler) Experience(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c := admin.Experience(user.CurrentInstance.Settings, len(locations))
+	rules, err := h.BonusService.FindRules(r.Context(), user.CurrentInstanceID)
+	if err != nil {
+		h.handleError(w, r, "Experience: getting bonus rules", "Error getting bonus rules", "error", err)
+		return
+	}
+
+	leaderboardURL := ""
+	if user.CurrentInstance.Settings.LeaderboardToken != "" {
+		leaderboardURL = helpers.URL("/leaderboard/" + user.CurrentInstance.Settings.LeaderboardToken)
+	}
+
+	c := admin.Experience(user.CurrentInstance.Settings, len(locations), rules, leaderboardURL)
 	err = admin.Layout(c, *user, "Experience", "Experience").Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("rendering navigation page", "error", err.Error())
@@ -93,8 +105,21 @@ func (h *AdminHandler) ExperiencePreview(w http.ResponseWriter, r *http.Request)
 	}
 
 	// data["notifications"], _ = h.NotificationService.GetNotifications(r.Context(), team.Code)
-	err = players.Next(team, locations).Render(r.Context(), w)
+	err = players.Next(team, locations, nil).Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("rendering template", "error", err)
 	}
 }
+
+// LeaderboardLinkPost creates a new link for sharing the leaderboard.
+func (h *AdminHandler) LeaderboardLinkPost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	err := h.LeaderboardService.CreateShareToken(r.Context(), &user.CurrentInstance.Settings)
+	if err != nil {
+		h.handleError(w, r, "LeaderboardLinkPost creating link", "Error creating link", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/experience")
+}
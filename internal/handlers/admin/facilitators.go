@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/nathanhollows/Rapua/v3/helpers"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
 	templates "github.com/nathanhollows/Rapua/v3/internal/templates/admin"
 	public "github.com/nathanhollows/Rapua/v3/internal/templates/public"
 	"github.com/nathanhollows/Rapua/v3/models"
@@ -13,7 +16,15 @@ import (
 
 // FacilitatorShowModal renders the modal for creating a facilitator token.
 func (h *AdminHandler) FacilitatorShowModal(w http.ResponseWriter, r *http.Request) {
-	err := templates.FacilitatorLinkModal().Render(r.Context(), w)
+	user := h.UserFromContext(r.Context())
+
+	locations, err := h.LocationService.FindByInstance(r.Context(), user.CurrentInstanceID)
+	if err != nil {
+		h.handleError(w, r, "FacilitatorShowModal: fetching locations", "Error fetching locations", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	err = templates.FacilitatorLinkModal(locations).Render(r.Context(), w)
 	if err != nil {
 		h.handleError(w, r, "rendering template", "Error rendering template", "error", err)
 	}
@@ -43,12 +54,24 @@ func (h *AdminHandler) FacilitatorCreateTokenLink(w http.ResponseWriter, r *http
 		duration = 24 * time.Hour
 	}
 
+	// Only keep locations from the current instance
+	instanceLocations, err := h.LocationService.FindByInstance(r.Context(), user.CurrentInstanceID)
+	if err != nil {
+		h.handleError(w, r, "fetching locations", "Error fetching locations", "error", err)
+		return
+	}
+	selected := make(map[string]bool)
+	for _, id := range r.Form["locations"] {
+		selected[id] = true
+	}
 	var locations []string
-	if r.Form.Get("locations") != "" {
-		locations = append(locations, r.Form.Get("locations"))
+	for _, location := range instanceLocations {
+		if selected[location.ID] {
+			locations = append(locations, location.ID)
+		}
 	}
 
-	token, err := h.FacilitatorService.CreateFacilitatorToken(r.Context(), user.CurrentInstanceID, locations, duration)
+	token, err := h.FacilitatorService.CreateFacilitatorToken(r.Context(), user.CurrentInstanceID, r.Form.Get("name"), locations, duration)
 	if err != nil {
 		h.handleError(w, r, "creating facilitator token", "Error creating facilitator token")
 		return
@@ -63,6 +86,43 @@ func (h *AdminHandler) FacilitatorCreateTokenLink(w http.ResponseWriter, r *http
 
 }
 
+// FacilitatorTokens lists the active facilitator links for the current instance.
+func (h *AdminHandler) FacilitatorTokens(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	tokens, err := h.FacilitatorService.FindActiveTokens(r.Context(), user.CurrentInstanceID)
+	if err != nil {
+		h.handleError(w, r, "FacilitatorTokens: finding tokens", "Error loading facilitator links", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	locations, err := h.LocationService.FindByInstance(r.Context(), user.CurrentInstanceID)
+	if err != nil {
+		h.handleError(w, r, "FacilitatorTokens: fetching locations", "Error fetching locations", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	c := templates.FacilitatorTokens(tokens, locations)
+	err = templates.Layout(c, *user, "Facilitator links", "Facilitator links").Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("FacilitatorTokens: rendering template", "error", err)
+	}
+}
+
+// FacilitatorTokenRevoke deletes a facilitator link so it can no longer be used.
+func (h *AdminHandler) FacilitatorTokenRevoke(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	tokenID := chi.URLParam(r, "id")
+	err := h.FacilitatorService.RevokeToken(r.Context(), user.CurrentInstanceID, tokenID)
+	if err != nil {
+		h.handleError(w, r, "FacilitatorTokenRevoke: revoking token", "Error revoking link", "error", err, "instance_id", user.CurrentInstanceID, "token_id", tokenID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/facilitator/tokens")
+}
+
 const facilitatorSessionCookie = "rapua_facilitator"
 
 // FacilitatorLogin accepts a token and creates a session cookie.
@@ -94,6 +154,34 @@ func (h *AdminHandler) FacilitatorLogin(w http.ResponseWriter, r *http.Request)
 	http.Redirect(w, r, "/facilitator/dashboard", http.StatusSeeOther)
 }
 
+// facilitatorTokenFromRequest validates the facilitator session cookie.
+func (h *AdminHandler) facilitatorTokenFromRequest(r *http.Request) (*models.FacilitatorToken, error) {
+	token, err := r.Cookie(facilitatorSessionCookie)
+	if err != nil {
+		return nil, err
+	}
+	return h.FacilitatorService.ValidateToken(r.Context(), token.Value)
+}
+
+// facilitatorContext records changes made during the request as the facilitator's.
+func facilitatorContext(r *http.Request, facToken *models.FacilitatorToken) context.Context {
+	return services.WithActor(r.Context(), services.FacilitatorActor(facToken))
+}
+
+// facilitatorCanAccessLocation reports whether the token grants access to a location.
+// Tokens without any locations grant access to the whole instance.
+func facilitatorCanAccessLocation(facToken *models.FacilitatorToken, locationID string) bool {
+	if len(facToken.Locations) == 0 {
+		return true
+	}
+	for _, loc := range facToken.Locations {
+		if loc == locationID {
+			return true
+		}
+	}
+	return false
+}
+
 // FacilitatorDashboard renders the facilitator dashboard.
 func (h *AdminHandler) FacilitatorDashboard(w http.ResponseWriter, r *http.Request) {
 	token, err := r.Cookie(facilitatorSessionCookie)
@@ -174,9 +262,154 @@ func (h *AdminHandler) FacilitatorDashboard(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	c := templates.FacilitatorDashboard(locations, overview)
+	c := templates.FacilitatorDashboard(filteredLocations, overview)
 	err = public.AuthLayout(c, "Facilitator Dashboard").Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("Activity: rendering template", "error", err)
 	}
 }
+
+// FacilitatorStation shows the teams at a location and the teams heading there.
+func (h *AdminHandler) FacilitatorStation(w http.ResponseWriter, r *http.Request) {
+	facToken, err := h.facilitatorTokenFromRequest(r)
+	if err != nil {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		return
+	}
+
+	locationID := chi.URLParam(r, "locationID")
+	if !facilitatorCanAccessLocation(facToken, locationID) {
+		http.Error(w, "You cannot manage this location", http.StatusForbidden)
+		return
+	}
+
+	queue, err := h.StationService.Queue(r.Context(), facToken.InstanceID, locationID)
+	if err != nil {
+		h.handleError(w, r, "FacilitatorStation: finding queue", "Error loading location", "error", err, "location", locationID)
+		return
+	}
+
+	c := templates.FacilitatorStation(*queue)
+	err = public.AuthLayout(c, queue.Location.Name).Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("FacilitatorStation: rendering template", "error", err)
+	}
+}
+
+// FacilitatorStationCheckInPost checks a team in on their behalf.
+func (h *AdminHandler) FacilitatorStationCheckInPost(w http.ResponseWriter, r *http.Request) {
+	facToken, locationID, ok := h.facilitatorStationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	teamCode := r.Form.Get("team")
+	err := h.StationService.CheckIn(facilitatorContext(r, facToken), facToken.InstanceID, teamCode, locationID)
+	if err != nil {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "FacilitatorStationCheckInPost: checking in", stationErrorMessage(err, "Error checking the team in"), "error", err, "team", teamCode, "location", locationID)
+		return
+	}
+
+	h.renderStationQueue(w, r, facToken, locationID, "Team checked in")
+}
+
+// FacilitatorStationCheckOutPost checks a team out on their behalf.
+func (h *AdminHandler) FacilitatorStationCheckOutPost(w http.ResponseWriter, r *http.Request) {
+	facToken, locationID, ok := h.facilitatorStationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	teamCode := r.Form.Get("team")
+	err := h.StationService.CheckOut(facilitatorContext(r, facToken), facToken.InstanceID, teamCode, locationID)
+	if err != nil {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "FacilitatorStationCheckOutPost: checking out", stationErrorMessage(err, "Error checking the team out"), "error", err, "team", teamCode, "location", locationID)
+		return
+	}
+
+	h.renderStationQueue(w, r, facToken, locationID, "Team checked out")
+}
+
+// FacilitatorStationCompletePost marks a team's activities at the location complete.
+func (h *AdminHandler) FacilitatorStationCompletePost(w http.ResponseWriter, r *http.Request) {
+	facToken, locationID, ok := h.facilitatorStationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	teamCode := r.Form.Get("team")
+	actor := services.FacilitatorActor(facToken)
+	err := h.StationService.CompleteBlocks(facilitatorContext(r, facToken), facToken.InstanceID, teamCode, locationID, actor.ID)
+	if err != nil {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "FacilitatorStationCompletePost: completing blocks", stationErrorMessage(err, "Error completing activities"), "error", err, "team", teamCode, "location", locationID)
+		return
+	}
+
+	h.renderStationQueue(w, r, facToken, locationID, "Activities marked complete")
+}
+
+// facilitatorStationRequest validates the facilitator's token and checks
+// they may manage the location. It writes an error if not.
+func (h *AdminHandler) facilitatorStationRequest(w http.ResponseWriter, r *http.Request) (*models.FacilitatorToken, string, bool) {
+	w.Header().Set("HX-Reswap", "none")
+
+	facToken, err := h.facilitatorTokenFromRequest(r)
+	if err != nil {
+		h.handleError(w, r, "facilitator session expired", "Your session has expired. Please ask for another login link.", "error", err)
+		return nil, "", false
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		h.handleError(w, r, "parsing form", "Error parsing form", "error", err)
+		return nil, "", false
+	}
+
+	locationID := chi.URLParam(r, "locationID")
+	if !facilitatorCanAccessLocation(facToken, locationID) {
+		h.handleError(w, r, "facilitator location not permitted", "You cannot manage this location", "location", locationID)
+		return nil, "", false
+	}
+
+	w.Header().Del("HX-Reswap")
+	return facToken, locationID, true
+}
+
+// renderStationQueue replaces the location's queue and shows a success message.
+func (h *AdminHandler) renderStationQueue(w http.ResponseWriter, r *http.Request, facToken *models.FacilitatorToken, locationID string, message string) {
+	queue, err := h.StationService.Queue(r.Context(), facToken.InstanceID, locationID)
+	if err != nil {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "renderStationQueue: finding queue", "Error loading location", "error", err, "location", locationID)
+		return
+	}
+
+	err = templates.FacilitatorStationQueue(*queue).Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("renderStationQueue: rendering template", "error", err)
+		return
+	}
+	h.handleSuccess(w, r, message)
+}
+
+// stationErrorMessage explains why an action on a team's behalf failed.
+func stationErrorMessage(err error, fallback string) string {
+	switch {
+	case errors.Is(err, services.ErrPermissionDenied), errors.Is(err, services.ErrTeamNotFound):
+		return "Team not found. Please check the team code."
+	case errors.Is(err, services.ErrInvalidArgument):
+		return "Please enter a team code"
+	case errors.Is(err, services.ErrAlreadyCheckedIn):
+		return "The team has already checked in here, or must check out somewhere else first"
+	case errors.Is(err, services.ErrTimeExpired):
+		return "The team has run out of time"
+	case errors.Is(err, services.ErrUnfinishedCheckIn):
+		return "The team must finish the activities here before checking out"
+	case errors.Is(err, services.ErrUnecessaryCheckOut), errors.Is(err, services.ErrCheckOutAtWrongLocation), errors.Is(err, services.ErrNotCheckedIn):
+		return "The team is not checked in here"
+	}
+	return fallback
+}
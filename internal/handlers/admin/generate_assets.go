@@ -36,7 +36,7 @@ func (h *AdminHandler) QRCode(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if the location exists
-	if !h.GameManagerService.ValidateLocationMarker(user, id) {
+	if h.CollaboratorService.AuthorizeLocationCode(r.Context(), user, id, models.PermissionEdit) != nil {
 		h.Logger.Error("QRCodeHandler: Location not found", "location", id)
 		http.Error(w, "Location not found", http.StatusNotFound)
 		return
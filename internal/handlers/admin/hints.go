@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/nathanhollows/Rapua/v3/models"
+)
+
+// hintsFromForm reads the hints submitted by the hint editor.
+// Hints are submitted as parallel lists, one entry per hint.
+func hintsFromForm(form url.Values) ([]models.Hint, error) {
+	contents := form["hints"]
+	ids := form["hint-ids"]
+	penalties := form["hint-penalties"]
+
+	hints := make([]models.Hint, 0, len(contents))
+	for i, content := range contents {
+		hint := models.Hint{Content: content}
+		if i < len(ids) {
+			hint.ID = ids[i]
+		}
+		if i < len(penalties) && penalties[i] != "" {
+			penalty, err := strconv.Atoi(penalties[i])
+			if err != nil {
+				return nil, fmt.Errorf("parsing penalty: %w", err)
+			}
+			hint.Penalty = penalty
+		}
+		hints = append(hints, hint)
+	}
+	return hints, nil
+}
+
+// BlockHintsPost replaces the hints for a block.
+func (h *AdminHandler) BlockHintsPost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	location := chi.URLParam(r, "location")
+	if h.CollaboratorService.AuthorizeLocation(r.Context(), user, location, models.PermissionEdit) != nil {
+		h.handleError(w, r, "BlockHintsPost: invalid location", "Could not update hints. Invalid location", "location", location)
+		return
+	}
+
+	blockID := chi.URLParam(r, "blockID")
+	block, err := h.BlockService.GetByBlockID(r.Context(), blockID)
+	if err != nil {
+		h.handleError(w, r, "BlockHintsPost: getting block", "Could not update hints", "error", err)
+		return
+	}
+
+	if block.GetLocationID() != location {
+		h.handleError(w, r, "BlockHintsPost: block does not belong to location", "Could not update hints", "blockID", blockID, "location", location)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		h.handleError(w, r, "BlockHintsPost: parsing form", "Could not update hints", "error", err)
+		return
+	}
+
+	hints, err := hintsFromForm(r.Form)
+	if err != nil {
+		h.handleError(w, r, "BlockHintsPost: parsing hints", "Hint penalties must be whole numbers", "error", err)
+		return
+	}
+
+	err = h.HintService.UpdateHints(r.Context(), user.CurrentInstanceID, location, blockID, hints)
+	if err != nil {
+		h.handleError(w, r, "BlockHintsPost: updating hints", "Could not update hints", "error", err)
+		return
+	}
+
+	h.handleSuccess(w, r, "Hints updated")
+}
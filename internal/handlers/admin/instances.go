@@ -1,19 +1,30 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi"
 	"github.com/nathanhollows/Rapua/v3/internal/flash"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
 	templates "github.com/nathanhollows/Rapua/v3/internal/templates/admin"
+	"github.com/nathanhollows/Rapua/v3/models"
 )
 
 // Instances shows admin the instances.
 func (h *AdminHandler) Instances(w http.ResponseWriter, r *http.Request) {
 	user := h.UserFromContext(r.Context())
 
-	c := templates.Instances(user.Instances, user.CurrentInstance)
-	err := templates.Layout(c, *user, "Instances", "Instances").Render(r.Context(), w)
+	published, err := h.TemplateService.FindByUser(r.Context(), user.ID)
+	if err != nil {
+		h.handleError(w, r, "Instances: finding templates", "Error loading templates", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	c := templates.Instances(*user, published)
+	err = templates.Layout(c, *user, "Instances", "Instances").Render(r.Context(), w)
 	if err != nil {
 		h.handleError(w, r, "Instances: rendering template", "Error rendering template", "error", err, "instance_id", user.CurrentInstanceID)
 	}
@@ -125,3 +136,82 @@ func (h *AdminHandler) InstanceDelete(w http.ResponseWriter, r *http.Request) {
 
 	h.redirect(w, r, "/admin/instances")
 }
+
+// InstanceExport downloads an instance as a bundle.
+// The bundle is a ZIP file including uploads, or JSON when format=json.
+func (h *AdminHandler) InstanceExport(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	instanceID := chi.URLParam(r, "id")
+	var instance *models.Instance
+	for _, instances := range [][]models.Instance{user.Instances, user.SharedInstances, user.OrganisationInstances} {
+		for i := range instances {
+			if instances[i].ID == instanceID {
+				instance = &instances[i]
+			}
+		}
+	}
+	if instance == nil || h.CollaboratorService.Authorize(r.Context(), user, instanceID, models.PermissionEdit) != nil {
+		h.handleError(w, r, "InstanceExport: instance not found", "Instance not found", "instance_id", instanceID)
+		return
+	}
+
+	filename := strings.ReplaceAll(instance.Name, "\"", "")
+	var buf bytes.Buffer
+	var err error
+	if r.URL.Query().Get("format") == "json" {
+		err = h.InstanceBundleService.ExportJSON(r.Context(), instance.ID, &buf)
+		w.Header().Set("Content-Type", "application/json")
+		filename += ".json"
+	} else {
+		err = h.InstanceBundleService.Export(r.Context(), instance.ID, &buf)
+		w.Header().Set("Content-Type", "application/zip")
+		filename += ".zip"
+	}
+	if err != nil {
+		h.handleError(w, r, "InstanceExport: exporting instance", "Error exporting instance", "error", err, "instance_id", instance.ID)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	_, err = buf.WriteTo(w)
+	if err != nil {
+		h.Logger.Error("InstanceExport: writing bundle", "error", err, "instance_id", instance.ID)
+	}
+}
+
+// InstanceImport creates a new instance from an uploaded bundle.
+func (h *AdminHandler) InstanceImport(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	// Allow a little extra for the rest of the form
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxBundleSize+1<<20)
+	err := r.ParseMultipartForm(services.MaxBundleSize)
+	if err != nil {
+		h.handleError(w, r, "InstanceImport: parsing form", "File too large", "error", err)
+		return
+	}
+
+	file, _, err := r.FormFile("bundle")
+	if err != nil {
+		h.handleError(w, r, "InstanceImport: reading file", "Please choose a bundle to import", "error", err)
+		return
+	}
+	defer file.Close()
+
+	_, err = h.InstanceBundleService.Import(r.Context(), user, file)
+	if err != nil {
+		if errors.Is(err, services.ErrUnsupportedBundle) {
+			h.handleError(w, r, "InstanceImport: importing instance", "This bundle was made by a newer version of Rapua", "error", err)
+			return
+		}
+		if errors.Is(err, services.ErrInvalidArgument) {
+			h.handleError(w, r, "InstanceImport: importing instance", "This file is not a valid bundle", "error", err)
+			return
+		}
+		h.handleError(w, r, "InstanceImport: importing instance", "Error importing instance", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/instances")
+}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/go-chi/chi"
@@ -25,7 +28,12 @@ func (h *AdminHandler) Locations(w http.ResponseWriter, r *http.Request) {
 		user.CurrentInstance.Locations[i] = location
 	}
 
-	c := templates.LocationsIndex(user.CurrentInstance.Settings, user.CurrentInstance.Locations)
+	var problems []services.UnlockRuleProblem
+	if user.CurrentInstance.Settings.NavigationMode == models.ConditionalNav {
+		problems = h.NavigationService.ValidateUnlockRules(user.CurrentInstance.Locations)
+	}
+
+	c := templates.LocationsIndex(user.CurrentInstance.Settings, user.CurrentInstance.Locations, problems)
 	err := templates.Layout(c, *user, "Locations", "Locations").Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("Locations: rendering template", "error", err)
@@ -140,7 +148,25 @@ func (h *AdminHandler) LocationEdit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c := templates.EditLocation(*location, user.CurrentInstance.Settings, blocks)
+	location.Hints, err = h.HintService.FindByLocation(r.Context(), location.ID)
+	if err != nil {
+		h.handleError(w, r, "LocationEdit: loading hints", "Error loading hints", "error", err, "instance_id", user.CurrentInstanceID, "location_id", location.ID)
+		return
+	}
+
+	// Unlock rules may refer to any location or block in the instance
+	locations := user.CurrentInstance.Locations
+	if user.CurrentInstance.Settings.NavigationMode == models.ConditionalNav {
+		for i := range locations {
+			err := h.LocationService.LoadRelations(r.Context(), &locations[i])
+			if err != nil {
+				h.handleError(w, r, "LocationEdit: loading relations", "Error loading locations", "error", err, "instance_id", user.CurrentInstanceID)
+				return
+			}
+		}
+	}
+
+	c := templates.EditLocation(*location, user.CurrentInstance.Settings, blocks, locations)
 	err = templates.Layout(c, *user, "Locations", "Edit Location").Render(r.Context(), w)
 	if err != nil {
 		h.handleError(w, r, "LocationEdit: rendering template", "Error rendering template", "error", err)
@@ -169,6 +195,24 @@ func (h *AdminHandler) LocationEditPost(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	radius := -1
+	if r.FormValue("checkInRadius") != "" {
+		radius, err = strconv.Atoi(r.FormValue("checkInRadius"))
+		if err != nil || radius < 0 {
+			h.handleError(w, r, "LocationEditPost: converting check-in radius", "Check-in radius must be a whole number of metres", "error", err)
+			return
+		}
+	}
+
+	capacity := -1
+	if r.FormValue("capacity") != "" {
+		capacity, err = strconv.Atoi(r.FormValue("capacity"))
+		if err != nil || capacity < 0 {
+			h.handleError(w, r, "LocationEditPost: converting capacity", "Capacity must be a whole number of teams", "error", err)
+			return
+		}
+	}
+
 	// These are out of range values that will be ignored
 	lat, lng := 200.0, 200.0
 	if r.FormValue("latitude") != "" {
@@ -192,11 +236,23 @@ func (h *AdminHandler) LocationEditPost(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	var rules models.UnlockRules
+	if r.Form.Has("unlock-rules") {
+		rules, err = unlockRulesFromForm(r.Form)
+		if err != nil {
+			h.handleError(w, r, "LocationEditPost: parsing unlock rules", "Could not read the unlock rules", "error", err)
+			return
+		}
+	}
+
 	data := services.LocationUpdateData{
-		Name:      r.FormValue("name"),
-		Latitude:  lat,
-		Longitude: lng,
-		Points:    points,
+		Name:          r.FormValue("name"),
+		Latitude:      lat,
+		Longitude:     lng,
+		Points:        points,
+		CheckInRadius: radius,
+		Capacity:      capacity,
+		UnlockRules:   rules,
 	}
 
 	location, err := h.LocationService.GetByInstanceAndCode(r.Context(), user.CurrentInstanceID, locationCode)
@@ -206,6 +262,10 @@ func (h *AdminHandler) LocationEditPost(w http.ResponseWriter, r *http.Request)
 	}
 
 	err = h.LocationService.UpdateLocation(r.Context(), location, data)
+	if errors.Is(err, services.ErrInvalidArgument) {
+		h.handleError(w, r, "LocationEditPost: updating location", "Unlock rules are incomplete. Check each rule has its locations or block selected", "error", err)
+		return
+	}
 	if err != nil {
 		h.handleError(w, r, "LocationEditPost: updating location", "Error updating location", "error", err)
 		return
@@ -233,6 +293,17 @@ func (h *AdminHandler) LocationEditPost(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	hints, err := hintsFromForm(r.Form)
+	if err != nil {
+		h.handleError(w, r, "LocationEditPost: parsing hints", "Hint penalties must be whole numbers", "error", err)
+		return
+	}
+	err = h.HintService.UpdateHints(r.Context(), user.CurrentInstanceID, location.ID, "", hints)
+	if err != nil {
+		h.handleError(w, r, "LocationEditPost: updating hints", "Error updating hints", "error", err, "instance_id", user.CurrentInstanceID, "location_id", location.ID)
+		return
+	}
+
 	h.handleSuccess(w, r, "Location updated")
 }
 
@@ -291,9 +362,37 @@ func (h *AdminHandler) LocationPreview(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	err = playerTemplates.CheckInView(user.CurrentInstance.Settings, scan, contentBlocks, blockStates).Render(r.Context(), w)
+	err = playerTemplates.CheckInView(user.CurrentInstance.Settings, scan, contentBlocks, blockStates, nil, nil).Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("LocationPreview: rendering template", "error", err)
 	}
 
 }
+
+// unlockRulesFromForm reads the unlock rules submitted by the rule editor.
+// Each rule is identified by an index, with its fields suffixed by that index.
+func unlockRulesFromForm(form url.Values) (models.UnlockRules, error) {
+	rules := models.UnlockRules{}
+	for _, i := range form["rule-index"] {
+		rule := models.UnlockRule{
+			Type:      models.UnlockRuleType(form.Get("rule-type-" + i)),
+			Locations: form["rule-locations-"+i],
+		}
+		switch rule.Type {
+		case models.RequireAnyLocations:
+			rule.Count = 1
+			if count := form.Get("rule-count-" + i); count != "" {
+				var err error
+				rule.Count, err = strconv.Atoi(count)
+				if err != nil {
+					return nil, fmt.Errorf("parsing count: %w", err)
+				}
+			}
+		case models.RequireBlock:
+			rule.Locations = nil
+			rule.BlockID = form.Get("rule-block-" + i)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	templates "github.com/nathanhollows/Rapua/v3/internal/templates/admin"
+)
+
+// Markers shows the marker library for the user's current workspace.
+func (h *AdminHandler) Markers(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	query := r.URL.Query().Get("q")
+	tag := r.URL.Query().Get("tag")
+	markers, err := h.MarkerService.Search(r.Context(), user, query, tag)
+	if err != nil {
+		h.handleError(w, r, "Markers: searching markers", "Error loading markers", "error", err)
+		return
+	}
+
+	c := templates.MarkerLibrary(*user, markers, query, tag)
+	err = templates.Layout(c, *user, "Markers", "Marker library").Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("Markers: rendering template", "error", err)
+	}
+}
+
+// MarkerCreate adds a marker to the library.
+func (h *AdminHandler) MarkerCreate(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	data, err := h.markerData(r)
+	if err != nil {
+		h.handleError(w, r, "MarkerCreate: reading form", markerErrorMessage(err, "Error reading form"), "error", err)
+		return
+	}
+
+	_, err = h.MarkerService.Create(r.Context(), user, data)
+	if err != nil {
+		h.handleError(w, r, "MarkerCreate: creating marker", markerErrorMessage(err, "Error creating marker"), "error", err)
+		return
+	}
+
+	h.redirect(w, r, "/admin/markers")
+}
+
+// MarkerUpdate saves changes to a library marker.
+func (h *AdminHandler) MarkerUpdate(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	code := chi.URLParam(r, "code")
+	data, err := h.markerData(r)
+	if err != nil {
+		h.handleError(w, r, "MarkerUpdate: reading form", markerErrorMessage(err, "Error reading form"), "error", err, "marker", code)
+		return
+	}
+
+	_, err = h.MarkerService.Update(r.Context(), user, code, data)
+	if err != nil {
+		h.handleError(w, r, "MarkerUpdate: updating marker", markerErrorMessage(err, "Error updating marker"), "error", err, "marker", code)
+		return
+	}
+
+	h.redirect(w, r, "/admin/markers")
+}
+
+// MarkerDelete removes a marker from the library.
+func (h *AdminHandler) MarkerDelete(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	code := chi.URLParam(r, "code")
+	err := h.MarkerService.Remove(r.Context(), user, code)
+	if err != nil {
+		h.handleError(w, r, "MarkerDelete: removing marker", markerErrorMessage(err, "Error removing marker"), "error", err, "marker", code)
+		return
+	}
+
+	h.redirect(w, r, "/admin/markers")
+}
+
+// MarkerAttach adds a library marker to the current instance as a location.
+func (h *AdminHandler) MarkerAttach(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		h.handleError(w, r, "MarkerAttach: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	points := 10
+	if r.Form.Get("points") != "" {
+		points, err = strconv.Atoi(r.Form.Get("points"))
+		if err != nil {
+			h.handleError(w, r, "MarkerAttach: parsing points", "Points must be a number", "error", err)
+			return
+		}
+	}
+
+	code := chi.URLParam(r, "code")
+	location, err := h.MarkerService.AttachToInstance(r.Context(), user, code, r.Form.Get("name"), points)
+	if err != nil {
+		h.handleError(w, r, "MarkerAttach: adding marker", markerErrorMessage(err, "Error adding marker"), "error", err, "marker", code, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/locations/"+location.MarkerID)
+}
+
+// markerData reads a library marker form, uploading the photo if one is given.
+func (h *AdminHandler) markerData(r *http.Request) (services.MarkerData, error) {
+	err := r.ParseMultipartForm(10 << 20) // 10MB max
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return services.MarkerData{}, err
+	}
+
+	data := services.MarkerData{
+		Name:  r.Form.Get("name"),
+		Notes: r.Form.Get("notes"),
+		Tags:  strings.Split(r.Form.Get("tags"), ","),
+	}
+	data.Latitude, err = strconv.ParseFloat(r.Form.Get("latitude"), 64)
+	if err != nil {
+		return services.MarkerData{}, services.NewValidationError("latitude")
+	}
+	data.Longitude, err = strconv.ParseFloat(r.Form.Get("longitude"), 64)
+	if err != nil {
+		return services.MarkerData{}, services.NewValidationError("longitude")
+	}
+
+	file, fileHeader, err := r.FormFile("photo")
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		return data, nil
+	}
+	if err != nil {
+		return services.MarkerData{}, err
+	}
+	defer file.Close()
+	upload, err := h.UploadService.UploadFile(r.Context(), file, fileHeader, services.UploadMetadata{})
+	if err != nil {
+		return services.MarkerData{}, err
+	}
+	data.Photo = upload.OriginalURL
+	return data, nil
+}
+
+// markerErrorMessage explains why a marker library change failed.
+func markerErrorMessage(err error, fallback string) string {
+	switch {
+	case errors.Is(err, services.ErrPermissionDenied):
+		return "You don't have permission to do that"
+	case errors.Is(err, services.ErrMarkerNotInLibrary):
+		return "That marker is not in the library"
+	case errors.Is(err, services.ErrMarkerInInstance):
+		return "That marker is already used in this instance"
+	case errors.Is(err, services.ErrInvalidArgument):
+		return "Please check the name and coordinates and try again"
+	}
+	return fallback
+}
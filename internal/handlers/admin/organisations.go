@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	templates "github.com/nathanhollows/Rapua/v3/internal/templates/admin"
+	"github.com/nathanhollows/Rapua/v3/models"
+)
+
+// Organisation shows the user's current organisation, or the organisations
+// they belong to when they are in their personal workspace.
+func (h *AdminHandler) Organisation(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	if user.CurrentOrganisation() == nil {
+		c := templates.NoOrganisation(*user)
+		err := templates.Layout(c, *user, "Organisation", "Organisations").Render(r.Context(), w)
+		if err != nil {
+			h.Logger.Error("Organisation: rendering template", "error", err)
+		}
+		return
+	}
+
+	orgID := user.CurrentOrganisationID
+	role, err := h.OrganisationService.Role(r.Context(), user, orgID)
+	if err != nil {
+		h.handleError(w, r, "Organisation: finding role", "Error loading organisation", "error", err, "organisation_id", orgID)
+		return
+	}
+	members, err := h.OrganisationService.FindMembers(r.Context(), orgID)
+	if err != nil {
+		h.handleError(w, r, "Organisation: finding members", "Error loading members", "error", err, "organisation_id", orgID)
+		return
+	}
+	instances, err := h.OrganisationService.FindInstances(r.Context(), orgID)
+	if err != nil {
+		h.handleError(w, r, "Organisation: finding instances", "Error loading instances", "error", err, "organisation_id", orgID)
+		return
+	}
+	usage, err := h.OrganisationService.Usage(r.Context(), orgID)
+	if err != nil {
+		h.handleError(w, r, "Organisation: finding usage", "Error loading usage", "error", err, "organisation_id", orgID)
+		return
+	}
+
+	c := templates.Organisation(*user, role, members, instances, *usage)
+	err = templates.Layout(c, *user, "Organisation", user.CurrentOrganisation().Name).Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("Organisation: rendering template", "error", err)
+	}
+}
+
+// OrganisationCreate creates an organisation and switches to its workspace.
+func (h *AdminHandler) OrganisationCreate(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		h.handleError(w, r, "OrganisationCreate: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	organisation, err := h.OrganisationService.Create(r.Context(), user, r.Form.Get("name"))
+	if err != nil {
+		h.handleError(w, r, "OrganisationCreate: creating organisation", organisationErrorMessage(err, "Error creating organisation"), "error", err)
+		return
+	}
+
+	err = h.OrganisationService.SwitchWorkspace(r.Context(), user, organisation.ID)
+	if err != nil {
+		h.handleError(w, r, "OrganisationCreate: switching workspace", "Error switching workspace", "error", err, "organisation_id", organisation.ID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/organisation")
+}
+
+// OrganisationMemberPost adds a member to the current organisation.
+func (h *AdminHandler) OrganisationMemberPost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		h.handleError(w, r, "OrganisationMemberPost: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	role := models.OrganisationRole(r.Form.Get("role"))
+	_, err = h.OrganisationService.AddMember(r.Context(), user, user.CurrentOrganisationID, r.Form.Get("email"), role)
+	if err != nil {
+		h.handleError(w, r, "OrganisationMemberPost: adding member", organisationErrorMessage(err, "Error adding member"), "error", err, "organisation_id", user.CurrentOrganisationID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/organisation")
+}
+
+// OrganisationMemberRolePost changes a member's role.
+func (h *AdminHandler) OrganisationMemberRolePost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		h.handleError(w, r, "OrganisationMemberRolePost: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	memberID := chi.URLParam(r, "id")
+	role := models.OrganisationRole(r.Form.Get("role"))
+	err = h.OrganisationService.UpdateMemberRole(r.Context(), user, user.CurrentOrganisationID, memberID, role)
+	if err != nil {
+		h.handleError(w, r, "OrganisationMemberRolePost: updating role", organisationErrorMessage(err, "Error updating role"), "error", err, "organisation_id", user.CurrentOrganisationID, "member_id", memberID)
+		return
+	}
+
+	h.handleSuccess(w, r, "Role updated")
+}
+
+// OrganisationMemberDelete removes a member from the current organisation.
+func (h *AdminHandler) OrganisationMemberDelete(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	memberID := chi.URLParam(r, "id")
+	err := h.OrganisationService.RemoveMember(r.Context(), user, user.CurrentOrganisationID, memberID)
+	if err != nil {
+		h.handleError(w, r, "OrganisationMemberDelete: removing member", organisationErrorMessage(err, "Error removing member"), "error", err, "organisation_id", user.CurrentOrganisationID, "member_id", memberID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/organisation")
+}
+
+// OrganisationInstanceTransfer gives an organisation instance to another member.
+func (h *AdminHandler) OrganisationInstanceTransfer(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		h.handleError(w, r, "OrganisationInstanceTransfer: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	instanceID := chi.URLParam(r, "id")
+	err = h.OrganisationService.TransferInstance(r.Context(), user, instanceID, r.Form.Get("owner"))
+	if err != nil {
+		h.handleError(w, r, "OrganisationInstanceTransfer: transferring instance", organisationErrorMessage(err, "Error transferring instance"), "error", err, "instance_id", instanceID)
+		return
+	}
+
+	h.handleSuccess(w, r, "Instance transferred")
+}
+
+// InstanceMove moves one of the user's instances to another workspace.
+func (h *AdminHandler) InstanceMove(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		h.handleError(w, r, "InstanceMove: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	instanceID := chi.URLParam(r, "id")
+	err = h.OrganisationService.MoveInstance(r.Context(), user, instanceID, r.Form.Get("organisation"))
+	if err != nil {
+		h.handleError(w, r, "InstanceMove: moving instance", organisationErrorMessage(err, "Error moving instance"), "error", err, "instance_id", instanceID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/instances")
+}
+
+// WorkspaceSwitch switches between the user's personal workspace and their
+// organisations.
+func (h *AdminHandler) WorkspaceSwitch(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	orgID := chi.URLParam(r, "id")
+	if orgID == "personal" {
+		orgID = ""
+	}
+
+	err := h.OrganisationService.SwitchWorkspace(r.Context(), user, orgID)
+	if err != nil {
+		h.handleError(w, r, "WorkspaceSwitch: switching workspace", "Error switching workspace", "error", err, "organisation_id", orgID)
+		return
+	}
+
+	if redirect := r.URL.Query().Get("redirect"); isLocalPath(redirect) {
+		h.redirect(w, r, redirect)
+		return
+	}
+	h.redirect(w, r, "/admin/instances")
+}
+
+// isLocalPath reports whether a redirect stays on this site.
+// Browsers treat "//" and "/\" as the start of another host.
+func isLocalPath(path string) bool {
+	return strings.HasPrefix(path, "/") &&
+		!strings.HasPrefix(path, "//") &&
+		!strings.HasPrefix(path, "/\\")
+}
+
+// organisationErrorMessage explains why an organisation change failed.
+func organisationErrorMessage(err error, fallback string) string {
+	switch {
+	case errors.Is(err, services.ErrPermissionDenied):
+		return "Only organisation admins can do that"
+	case errors.Is(err, services.ErrAlreadyOrganisationMember):
+		return "That person is already a member"
+	case errors.Is(err, services.ErrOrganisationUserNotFound):
+		return "No account uses that email. Ask them to sign up first"
+	case errors.Is(err, services.ErrLastOrganisationAdmin):
+		return "The organisation needs at least one other admin first"
+	case errors.Is(err, services.ErrInvalidArgument):
+		return "Please check the details and try again"
+	}
+	return fallback
+}
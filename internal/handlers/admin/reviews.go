@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	templates "github.com/nathanhollows/Rapua/v3/internal/templates/admin"
+	public "github.com/nathanhollows/Rapua/v3/internal/templates/public"
+	"github.com/nathanhollows/Rapua/v3/models"
+)
+
+// Reviews displays the queue of submissions awaiting review.
+func (h *AdminHandler) Reviews(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	submissions, err := h.ReviewService.FindPending(r.Context(), user.CurrentInstanceID)
+	if err != nil {
+		h.handleError(w, r, "Reviews: finding pending reviews", "Error loading reviews", "error", err)
+		return
+	}
+
+	c := templates.Reviews(user.CurrentInstance.Locations, submissions, "/admin/reviews")
+	err = templates.Layout(c, *user, "Reviews", "Reviews").Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("Reviews: rendering template", "error", err)
+	}
+}
+
+// ReviewApprovePost approves a submission and awards the block's points.
+func (h *AdminHandler) ReviewApprovePost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "ReviewApprovePost: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	err = h.ReviewService.Approve(r.Context(), user.CurrentInstanceID, r.Form.Get("block"), r.Form.Get("team"), r.Form.Get("comment"))
+	if err != nil {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "ReviewApprovePost: approving submission", "Error approving submission", "error", err)
+		return
+	}
+
+	h.handleSuccess(w, r, "Submission approved")
+}
+
+// ReviewRejectPost rejects a submission and notifies the team.
+func (h *AdminHandler) ReviewRejectPost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "ReviewRejectPost: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	err = h.ReviewService.Reject(r.Context(), user.CurrentInstanceID, r.Form.Get("block"), r.Form.Get("team"), r.Form.Get("comment"))
+	if err != nil {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "ReviewRejectPost: rejecting submission", "Error rejecting submission. A comment is required.", "error", err)
+		return
+	}
+
+	h.handleSuccess(w, r, "Submission rejected")
+}
+
+// BlockReviewPost sets whether a block's submissions must be reviewed.
+func (h *AdminHandler) BlockReviewPost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	location := chi.URLParam(r, "location")
+	if h.CollaboratorService.AuthorizeLocation(r.Context(), user, location, models.PermissionEdit) != nil {
+		h.handleError(w, r, "BlockReviewPost: invalid location", "Could not update block. Invalid location", "location", location)
+		return
+	}
+
+	blockID := chi.URLParam(r, "blockID")
+	block, err := h.BlockService.GetByBlockID(r.Context(), blockID)
+	if err != nil {
+		h.handleError(w, r, "BlockReviewPost: getting block", "Could not update block", "error", err)
+		return
+	}
+
+	if block.GetLocationID() != location {
+		h.handleError(w, r, "BlockReviewPost: block does not belong to location", "Could not update block", "blockID", blockID, "location", location)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		h.handleError(w, r, "BlockReviewPost: parsing form", "Could not update block", "error", err)
+		return
+	}
+
+	_, err = h.BlockService.SetRequiresReview(r.Context(), block, r.Form.Get("review_required") == "on")
+	if err != nil {
+		h.handleError(w, r, "BlockReviewPost: updating block", "Could not update block", "error", err)
+		return
+	}
+
+	h.handleSuccess(w, r, "Block updated")
+}
+
+// FacilitatorReviews displays the review queue for a facilitator.
+// Only submissions for the facilitator's locations are shown.
+func (h *AdminHandler) FacilitatorReviews(w http.ResponseWriter, r *http.Request) {
+	facToken, err := h.facilitatorTokenFromRequest(r)
+	if err != nil {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		return
+	}
+
+	locations, err := h.LocationService.FindByInstance(r.Context(), facToken.InstanceID)
+	if err != nil {
+		h.handleError(w, r, "FacilitatorReviews: fetching locations", "Error fetching locations", "error", err)
+		return
+	}
+
+	submissions, err := h.ReviewService.FindPending(r.Context(), facToken.InstanceID)
+	if err != nil {
+		h.handleError(w, r, "FacilitatorReviews: finding pending reviews", "Error loading reviews", "error", err)
+		return
+	}
+
+	if len(facToken.Locations) > 0 {
+		filtered := submissions[:0]
+		for _, submission := range submissions {
+			if facilitatorCanAccessLocation(facToken, submission.Block.GetLocationID()) {
+				filtered = append(filtered, submission)
+			}
+		}
+		submissions = filtered
+	}
+
+	c := templates.Reviews(locations, submissions, "/facilitator/reviews")
+	err = public.AuthLayout(c, "Reviews").Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("FacilitatorReviews: rendering template", "error", err)
+	}
+}
+
+// FacilitatorReviewApprovePost approves a submission on behalf of a facilitator.
+func (h *AdminHandler) FacilitatorReviewApprovePost(w http.ResponseWriter, r *http.Request) {
+	facToken, ok := h.facilitatorReviewRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.ReviewService.Approve(facilitatorContext(r, facToken), facToken.InstanceID, r.Form.Get("block"), r.Form.Get("team"), r.Form.Get("comment"))
+	if err != nil {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "FacilitatorReviewApprovePost: approving submission", "Error approving submission", "error", err)
+		return
+	}
+
+	h.handleSuccess(w, r, "Submission approved")
+}
+
+// FacilitatorReviewRejectPost rejects a submission on behalf of a facilitator.
+func (h *AdminHandler) FacilitatorReviewRejectPost(w http.ResponseWriter, r *http.Request) {
+	facToken, ok := h.facilitatorReviewRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.ReviewService.Reject(facilitatorContext(r, facToken), facToken.InstanceID, r.Form.Get("block"), r.Form.Get("team"), r.Form.Get("comment"))
+	if err != nil {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "FacilitatorReviewRejectPost: rejecting submission", "Error rejecting submission. A comment is required.", "error", err)
+		return
+	}
+
+	h.handleSuccess(w, r, "Submission rejected")
+}
+
+// facilitatorReviewRequest validates the facilitator's token and checks
+// they may review the submitted block. It writes an error if not.
+func (h *AdminHandler) facilitatorReviewRequest(w http.ResponseWriter, r *http.Request) (*models.FacilitatorToken, bool) {
+	w.Header().Set("HX-Reswap", "none")
+
+	facToken, err := h.facilitatorTokenFromRequest(r)
+	if err != nil {
+		h.handleError(w, r, "facilitator session expired", "Your session has expired. Please ask for another login link.", "error", err)
+		return nil, false
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		h.handleError(w, r, "parsing form", "Error parsing form", "error", err)
+		return nil, false
+	}
+
+	block, err := h.BlockService.GetByBlockID(r.Context(), r.Form.Get("block"))
+	if err != nil {
+		h.handleError(w, r, "getting block", "Could not find submission", "error", err)
+		return nil, false
+	}
+
+	if !facilitatorCanAccessLocation(facToken, block.GetLocationID()) {
+		h.handleError(w, r, "facilitator location not permitted", "You cannot review submissions for this location", "location", block.GetLocationID())
+		return nil, false
+	}
+
+	w.Header().Del("HX-Reswap")
+	return facToken, true
+}
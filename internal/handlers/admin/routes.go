@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+)
+
+// teamRoutes returns the routes for the current instance.
+// Routes only apply in Ordered navigation mode, so none are returned otherwise.
+func (h *AdminHandler) teamRoutes(ctx context.Context, user *models.User) ([]models.Route, error) {
+	if user.CurrentInstance.Settings.NavigationMode != models.OrderedNav {
+		return nil, nil
+	}
+	return h.RouteService.FindByInstance(ctx, user.CurrentInstanceID)
+}
+
+// RoutesGenerate replaces the routes with rotated orderings and spreads the teams between them.
+func (h *AdminHandler) RoutesGenerate(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.handleError(w, r, "RoutesGenerate parsing form", "Error parsing form", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	count, err := strconv.Atoi(r.FormValue("count"))
+	if err != nil {
+		h.handleError(w, r, "RoutesGenerate parsing count", "Please enter the number of routes", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	_, err = h.RouteService.GenerateRoutes(r.Context(), user.CurrentInstanceID, count)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidArgument) {
+			h.handleError(w, r, "RoutesGenerate generating routes", "Routes need at least one route and one location", "error", err, "instance_id", user.CurrentInstanceID)
+			return
+		}
+		h.handleError(w, r, "RoutesGenerate generating routes", "Error generating routes", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/teams")
+}
+
+// RouteNew creates a route following the default order.
+func (h *AdminHandler) RouteNew(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	routes, err := h.RouteService.FindByInstance(r.Context(), user.CurrentInstanceID)
+	if err != nil {
+		h.handleError(w, r, "RouteNew finding routes", "Error creating route", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	_, err = h.RouteService.CreateRoute(r.Context(), user.CurrentInstanceID, "Route "+strconv.Itoa(len(routes)+1))
+	if err != nil {
+		h.handleError(w, r, "RouteNew creating route", "Error creating route", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/teams")
+}
+
+// RouteEditPost renames and reorders a route.
+func (h *AdminHandler) RouteEditPost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.handleError(w, r, "RouteEditPost parsing form", "Error parsing form", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	routeID := chi.URLParam(r, "id")
+	_, err := h.RouteService.UpdateRoute(r.Context(), user.CurrentInstanceID, routeID, r.FormValue("name"), r.Form["location"])
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidArgument) {
+			h.handleError(w, r, "RouteEditPost updating route", "Routes need a name", "error", err, "instance_id", user.CurrentInstanceID, "route_id", routeID)
+			return
+		}
+		h.handleError(w, r, "RouteEditPost updating route", "Error updating route", "error", err, "instance_id", user.CurrentInstanceID, "route_id", routeID)
+		return
+	}
+
+	h.handleSuccess(w, r, "Route updated")
+}
+
+// RouteDelete removes a route and returns its teams to the default order.
+func (h *AdminHandler) RouteDelete(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	routeID := chi.URLParam(r, "id")
+	err := h.RouteService.DeleteRoute(r.Context(), user.CurrentInstanceID, routeID)
+	if err != nil {
+		h.handleError(w, r, "RouteDelete deleting route", "Error deleting route", "error", err, "instance_id", user.CurrentInstanceID, "route_id", routeID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/teams")
+}
+
+// TeamRoutePost assigns a team to a route.
+func (h *AdminHandler) TeamRoutePost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.handleError(w, r, "TeamRoutePost parsing form", "Error parsing form", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	teamCode := chi.URLParam(r, "code")
+	err := h.RouteService.AssignRoute(r.Context(), user.CurrentInstanceID, teamCode, r.FormValue("route"))
+	if err != nil {
+		h.handleError(w, r, "TeamRoutePost assigning route", "Error assigning route", "error", err, "instance_id", user.CurrentInstanceID, "team_code", teamCode)
+		return
+	}
+
+	h.handleSuccess(w, r, "Route assigned")
+}
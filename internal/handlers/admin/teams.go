@@ -1,17 +1,26 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/go-chi/chi"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
 	admin "github.com/nathanhollows/Rapua/v3/internal/templates/admin"
 )
 
 func (h *AdminHandler) Teams(w http.ResponseWriter, r *http.Request) {
 	user := h.UserFromContext(r.Context())
 
-	c := admin.Teams(user.CurrentInstance.Teams)
-	err := admin.Layout(c, *user, "Teams", "Teams").Render(r.Context(), w)
+	routes, err := h.teamRoutes(r.Context(), user)
+	if err != nil {
+		h.Logger.Error("Teams finding routes", "error", err, "instance_id", user.CurrentInstanceID)
+	}
+
+	c := admin.Teams(user.CurrentInstance.Settings, user.CurrentInstance.Teams, routes, user.CurrentInstance.Locations)
+	err = admin.Layout(c, *user, "Teams", "Teams").Render(r.Context(), w)
 
 	if err != nil {
 		h.Logger.Error("rendering teams page", "error", err.Error())
@@ -40,7 +49,18 @@ func (h *AdminHandler) TeamsAdd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = admin.TeamsList(teams).Render(r.Context(), w)
+	// Spread the new teams across any routes
+	err = h.RouteService.BalanceTeams(r.Context(), user.CurrentInstanceID, teams)
+	if err != nil {
+		h.Logger.Error("TeamsAdd assigning routes", "error", err, "instance_id", user.CurrentInstanceID)
+	}
+
+	routes, err := h.teamRoutes(r.Context(), user)
+	if err != nil {
+		h.Logger.Error("TeamsAdd finding routes", "error", err, "instance_id", user.CurrentInstanceID)
+	}
+
+	err = admin.TeamsList(teams, routes).Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("TeamsAdd rendering teams list", "error", err.Error(), "instance_id", user.CurrentInstanceID)
 	}
@@ -74,7 +94,12 @@ func (h *AdminHandler) TeamsDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = admin.TeamsTable(teams).Render(r.Context(), w)
+	routes, err := h.teamRoutes(r.Context(), user)
+	if err != nil {
+		h.Logger.Error("finding routes", "error", err, "instance_id", user.CurrentInstanceID)
+	}
+
+	err = admin.TeamsTable(teams, routes).Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("TeamsReset rendering teams list", "error", err.Error(), "instance_id", user.CurrentInstanceID)
 	}
@@ -107,9 +132,165 @@ func (h *AdminHandler) TeamsReset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = admin.TeamsTable(teams).Render(r.Context(), w)
+	routes, err := h.teamRoutes(r.Context(), user)
+	if err != nil {
+		h.Logger.Error("finding routes", "error", err, "instance_id", user.CurrentInstanceID)
+	}
+
+	err = admin.TeamsTable(teams, routes).Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("TeamsReset rendering teams list", "error", err.Error(), "instance_id", user.CurrentInstanceID)
 	}
 	h.handleSuccess(w, r, "Reset team(s)")
 }
+
+// TeamExtraTimePost grants a team extra time to play.
+func (h *AdminHandler) TeamExtraTimePost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.handleError(w, r, "TeamExtraTimePost parsing form", "Error parsing form", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	teamCode := chi.URLParam(r, "code")
+	minutes, err := strconv.Atoi(r.FormValue("minutes"))
+	if err != nil {
+		h.handleError(w, r, "TeamExtraTimePost parsing minutes", "Please enter a number of minutes", "error", err, "instance_id", user.CurrentInstanceID, "team_code", teamCode)
+		return
+	}
+
+	_, err = h.TeamService.GrantExtraTime(r.Context(), user.CurrentInstanceID, teamCode, minutes)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidArgument) {
+			h.handleError(w, r, "TeamExtraTimePost granting time", "Extra time must be at least one minute", "error", err, "instance_id", user.CurrentInstanceID, "team_code", teamCode)
+			return
+		}
+		h.handleError(w, r, "TeamExtraTimePost granting time", "Error granting extra time", "error", err, "instance_id", user.CurrentInstanceID, "team_code", teamCode)
+		return
+	}
+
+	h.handleSuccess(w, r, fmt.Sprintf("Added %d minutes for %s", minutes, teamCode))
+}
+
+// TeamPointsPost adds or removes points from a team.
+func (h *AdminHandler) TeamPointsPost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.handleError(w, r, "TeamPointsPost parsing form", "Error parsing form", "error", err, "instance_id", user.CurrentInstanceID)
+		return
+	}
+
+	teamCode := chi.URLParam(r, "code")
+	amount, err := strconv.Atoi(r.FormValue("amount"))
+	if err != nil {
+		h.handleError(w, r, "TeamPointsPost parsing amount", "Please enter a number of points", "error", err, "instance_id", user.CurrentInstanceID, "team_code", teamCode)
+		return
+	}
+
+	_, err = h.TeamService.AdjustPoints(r.Context(), user.CurrentInstanceID, teamCode, user.ID, amount, r.FormValue("reason"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidArgument) {
+			h.handleError(w, r, "TeamPointsPost adjusting points", "Please enter a number of points and a reason", "error", err, "instance_id", user.CurrentInstanceID, "team_code", teamCode)
+			return
+		}
+		h.handleError(w, r, "TeamPointsPost adjusting points", "Error adjusting points", "error", err, "instance_id", user.CurrentInstanceID, "team_code", teamCode)
+		return
+	}
+
+	h.renderTeamPoints(w, r, teamCode, "Points updated")
+}
+
+// TeamPointsUndo reverses a manual change to a team's points.
+func (h *AdminHandler) TeamPointsUndo(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	teamCode := chi.URLParam(r, "code")
+	transactionID := chi.URLParam(r, "id")
+	err := h.TeamService.UndoPoints(r.Context(), user.CurrentInstanceID, transactionID, user.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidArgument) {
+			h.handleError(w, r, "TeamPointsUndo undoing points", "This change cannot be undone", "error", err, "instance_id", user.CurrentInstanceID, "transaction_id", transactionID)
+			return
+		}
+		h.handleError(w, r, "TeamPointsUndo undoing points", "Error undoing points", "error", err, "instance_id", user.CurrentInstanceID, "transaction_id", transactionID)
+		return
+	}
+
+	h.renderTeamPoints(w, r, teamCode, "Points change undone")
+}
+
+// TeamBlockCompletePost marks a block complete for a team.
+func (h *AdminHandler) TeamBlockCompletePost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	teamCode := chi.URLParam(r, "code")
+	blockID := chi.URLParam(r, "blockID")
+	err := h.BlockService.CompleteBlock(r.Context(), user.CurrentInstanceID, blockID, teamCode, user.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotCheckedIn) {
+			h.handleError(w, r, "TeamBlockCompletePost completing block", "The team has not checked in at this location", "error", err, "instance_id", user.CurrentInstanceID, "team_code", teamCode, "block_id", blockID)
+			return
+		}
+		h.handleError(w, r, "TeamBlockCompletePost completing block", "Error completing activity", "error", err, "instance_id", user.CurrentInstanceID, "team_code", teamCode, "block_id", blockID)
+		return
+	}
+
+	h.renderTeamActivity(w, r, teamCode, "Activity marked complete")
+}
+
+// TeamCheckInRevokePost removes a team's check-in at a location.
+func (h *AdminHandler) TeamCheckInRevokePost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	teamCode := chi.URLParam(r, "code")
+	locationID := chi.URLParam(r, "locationID")
+	err := h.TeamService.RevokeCheckIn(r.Context(), user.CurrentInstanceID, teamCode, locationID, user.ID)
+	if err != nil {
+		h.handleError(w, r, "TeamCheckInRevokePost revoking check in", "Error revoking check-in", "error", err, "instance_id", user.CurrentInstanceID, "team_code", teamCode, "location_id", locationID)
+		return
+	}
+
+	h.renderTeamActivity(w, r, teamCode, "Check-in revoked")
+}
+
+// TeamCheckOutClearPost lets a team continue without checking out of their current location.
+func (h *AdminHandler) TeamCheckOutClearPost(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	teamCode := chi.URLParam(r, "code")
+	err := h.TeamService.ClearCheckOut(r.Context(), user.CurrentInstanceID, teamCode, user.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidArgument) {
+			h.handleError(w, r, "TeamCheckOutClearPost clearing check out", "The team does not need to check out", "error", err, "instance_id", user.CurrentInstanceID, "team_code", teamCode)
+			return
+		}
+		h.handleError(w, r, "TeamCheckOutClearPost clearing check out", "Error checking the team out", "error", err, "instance_id", user.CurrentInstanceID, "team_code", teamCode)
+		return
+	}
+
+	h.renderTeamActivity(w, r, teamCode, "Team checked out")
+}
+
+// renderTeamPoints replaces a team's points ledger and shows a success message.
+func (h *AdminHandler) renderTeamPoints(w http.ResponseWriter, r *http.Request, teamCode string, message string) {
+	team, err := h.TeamService.FindTeamByCode(r.Context(), teamCode)
+	if err != nil {
+		h.handleError(w, r, "renderTeamPoints finding team", "Error loading points", "error", err, "team_code", teamCode)
+		return
+	}
+
+	ledger, err := h.TeamService.FindPoints(r.Context(), teamCode)
+	if err != nil {
+		h.handleError(w, r, "renderTeamPoints finding points", "Error loading points", "error", err, "team_code", teamCode)
+		return
+	}
+
+	err = admin.TeamPoints(*team, ledger, true).Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("renderTeamPoints rendering template", "error", err)
+		return
+	}
+	h.handleSuccess(w, r, message)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	templates "github.com/nathanhollows/Rapua/v3/internal/templates/admin"
+)
+
+// TemplateGallery lists the public templates.
+// Templates can be searched by text and tag, and filtered by their number of locations.
+func (h *AdminHandler) TemplateGallery(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	query := r.URL.Query()
+	filter := services.TemplateFilter{
+		Search: query.Get("q"),
+		Tag:    query.Get("tag"),
+	}
+	filter.MinLocations, _ = strconv.Atoi(query.Get("min"))
+	filter.MaxLocations, _ = strconv.Atoi(query.Get("max"))
+
+	gallery, err := h.TemplateService.Gallery(r.Context(), filter)
+	if err != nil {
+		h.handleError(w, r, "TemplateGallery: finding templates", "Error loading templates", "error", err)
+		return
+	}
+
+	c := templates.TemplateGallery(gallery, query.Get("q"), query.Get("tag"), query.Get("min"), query.Get("max"))
+	err = templates.Layout(c, *user, "Templates", "Templates").Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("TemplateGallery: rendering template", "error", err)
+	}
+}
+
+// TemplatePreview shows a read-only view of a shared template.
+func (h *AdminHandler) TemplatePreview(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	template, err := h.TemplateService.GetByShareToken(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		h.Logger.Error("TemplatePreview: finding template", "error", err)
+		h.NotFound(w, r)
+		return
+	}
+
+	c := templates.TemplatePreview(*template, template.UserID == user.ID)
+	err = templates.Layout(c, *user, "Templates", template.Name).Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("TemplatePreview: rendering template", "error", err)
+	}
+}
+
+// TemplateClone copies a shared template into the user's account and switches to it.
+func (h *AdminHandler) TemplateClone(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.handleError(w, r, "TemplateClone: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	instance, err := h.TemplateService.Clone(r.Context(), user, chi.URLParam(r, "token"), r.Form.Get("name"))
+	if err != nil {
+		h.handleError(w, r, "TemplateClone: cloning template", "Error copying template", "error", err)
+		return
+	}
+
+	_, err = h.IntanceService.SwitchInstance(r.Context(), user, instance.ID)
+	if err != nil {
+		h.handleError(w, r, "TemplateClone: switching instance", "Error switching instance", "error", err, "instance_id", instance.ID)
+		return
+	}
+
+	h.redirect(w, r, "/admin/instances")
+}
+
+// TemplatePublish publishes one of the user's instances as a template.
+func (h *AdminHandler) TemplatePublish(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.handleError(w, r, "TemplatePublish: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	_, err := h.TemplateService.Publish(r.Context(), user, r.Form.Get("id"), templateData(r))
+	if err != nil {
+		h.handleError(w, r, "TemplatePublish: publishing template", "Error publishing template", "error", err, "instance_id", r.Form.Get("id"))
+		return
+	}
+
+	h.redirect(w, r, "/admin/instances")
+}
+
+// TemplateUpdate changes the details of one of the user's templates.
+func (h *AdminHandler) TemplateUpdate(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.handleError(w, r, "TemplateUpdate: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	_, err := h.TemplateService.Update(r.Context(), user, r.Form.Get("id"), templateData(r))
+	if err != nil {
+		h.handleError(w, r, "TemplateUpdate: updating template", "Error saving template", "error", err, "template_id", r.Form.Get("id"))
+		return
+	}
+
+	h.redirect(w, r, "/admin/instances")
+}
+
+// TemplateDelete deletes one of the user's templates.
+func (h *AdminHandler) TemplateDelete(w http.ResponseWriter, r *http.Request) {
+	user := h.UserFromContext(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.handleError(w, r, "TemplateDelete: parsing form", "Error parsing form", "error", err)
+		return
+	}
+
+	err := h.TemplateService.Delete(r.Context(), user, r.Form.Get("id"))
+	if err != nil {
+		h.handleError(w, r, "TemplateDelete: deleting template", "Error deleting template", "error", err, "template_id", r.Form.Get("id"))
+		return
+	}
+
+	h.redirect(w, r, "/admin/instances")
+}
+
+// templateData reads the template details from a submitted form.
+func templateData(r *http.Request) services.TemplateData {
+	return services.TemplateData{
+		Name:        r.Form.Get("name"),
+		Description: r.Form.Get("description"),
+		Tags:        strings.Split(r.Form.Get("tags"), ","),
+		IsPublic:    r.Form.Get("public") == "on",
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/nathanhollows/Rapua/v3/internal/contextkeys"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
 	"github.com/nathanhollows/Rapua/v3/internal/flash"
 	"github.com/nathanhollows/Rapua/v3/internal/services"
 	templates "github.com/nathanhollows/Rapua/v3/internal/templates/admin"
@@ -13,53 +14,98 @@ import (
 )
 
 type AdminHandler struct {
-	Logger              *slog.Logger
-	AssetGenerator      services.AssetGenerator
-	AuthService         services.AuthService
-	BlockService        services.BlockService
-	ClueService         services.ClueService
-	FacilitatorService  services.FacilitatorService
-	GameManagerService  services.GameManagerService
-	GameplayService     services.GameplayService
-	IntanceService      services.InstanceService
-	LocationService     services.LocationService
-	NotificationService services.NotificationService
-	TeamService         services.TeamService
-	UploadService       services.UploadService
-	UserService         services.UserService
+	Logger                *slog.Logger
+	AssetGenerator        services.AssetGenerator
+	AuditService          services.AuditService
+	AuthService           services.AuthService
+	BlockService          services.BlockService
+	BonusService          services.BonusService
+	ClueService           services.ClueService
+	CollaboratorService   services.CollaboratorService
+	EventBroker           *events.Broker
+	FacilitatorService    services.FacilitatorService
+	GameManagerService    services.GameManagerService
+	GameplayService       services.GameplayService
+	HintService           services.HintService
+	InstanceBundleService services.InstanceBundleService
+	IntanceService        services.InstanceService
+	LeaderboardService    services.LeaderboardService
+	LocationService       services.LocationService
+	MarkerService         services.MarkerService
+	NavigationService     services.NavigationService
+	NotificationService   services.NotificationService
+	OrganisationService   services.OrganisationService
+	PlayerService         services.PlayerService
+	ReviewService         services.ReviewService
+	RouteService          services.RouteService
+	StationService        services.StationService
+	TeamService           services.TeamService
+	TemplateService       services.TemplateService
+	UploadService         services.UploadService
+	UserService           services.UserService
 }
 
 func NewAdminHandler(
 	logger *slog.Logger,
 	assetGenerator services.AssetGenerator,
+	auditService services.AuditService,
 	authService services.AuthService,
 	blockService services.BlockService,
+	bonusService services.BonusService,
 	clueService services.ClueService,
+	collaboratorService services.CollaboratorService,
+	eventBroker *events.Broker,
 	facilitatorService services.FacilitatorService,
 	gameManagerService services.GameManagerService,
 	gameplayService services.GameplayService,
+	hintService services.HintService,
+	instanceBundleService services.InstanceBundleService,
 	instanceService services.InstanceService,
+	leaderboardService services.LeaderboardService,
 	locationService services.LocationService,
+	markerService services.MarkerService,
+	navigationService services.NavigationService,
 	notificationService services.NotificationService,
+	organisationService services.OrganisationService,
+	playerService services.PlayerService,
+	reviewService services.ReviewService,
+	routeService services.RouteService,
+	stationService services.StationService,
 	teamService services.TeamService,
+	templateService services.TemplateService,
 	uploadService services.UploadService,
 	userService services.UserService,
 ) *AdminHandler {
 	return &AdminHandler{
-		Logger:              logger,
-		AssetGenerator:      assetGenerator,
-		AuthService:         authService,
-		BlockService:        blockService,
-		ClueService:         clueService,
-		FacilitatorService:  facilitatorService,
-		GameManagerService:  gameManagerService,
-		GameplayService:     gameplayService,
-		IntanceService:      instanceService,
-		LocationService:     locationService,
-		NotificationService: notificationService,
-		TeamService:         teamService,
-		UploadService:       uploadService,
-		UserService:         userService,
+		Logger:                logger,
+		AssetGenerator:        assetGenerator,
+		AuditService:          auditService,
+		AuthService:           authService,
+		BlockService:          blockService,
+		BonusService:          bonusService,
+		ClueService:           clueService,
+		CollaboratorService:   collaboratorService,
+		EventBroker:           eventBroker,
+		FacilitatorService:    facilitatorService,
+		GameManagerService:    gameManagerService,
+		GameplayService:       gameplayService,
+		HintService:           hintService,
+		InstanceBundleService: instanceBundleService,
+		IntanceService:        instanceService,
+		LeaderboardService:    leaderboardService,
+		LocationService:       locationService,
+		MarkerService:         markerService,
+		NavigationService:     navigationService,
+		NotificationService:   notificationService,
+		OrganisationService:   organisationService,
+		PlayerService:         playerService,
+		ReviewService:         reviewService,
+		RouteService:          routeService,
+		StationService:        stationService,
+		TeamService:           teamService,
+		TemplateService:       templateService,
+		UploadService:         uploadService,
+		UserService:           userService,
 	}
 }
 
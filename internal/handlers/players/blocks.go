@@ -1,13 +1,21 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/nathanhollows/Rapua/v3/blocks"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
 	"github.com/nathanhollows/Rapua/v3/internal/sessions"
 	templates "github.com/nathanhollows/Rapua/v3/internal/templates/blocks"
+	"github.com/nathanhollows/Rapua/v3/models"
 )
 
+// maxBlockUploadSize is the largest file a player may submit to a block.
+const maxBlockUploadSize = 20 << 20 // 20MB
+
 // ValidateBlock runs input validation on the block.
 func (h *PlayerHandler) ValidateBlock(w http.ResponseWriter, r *http.Request) {
 	session, _ := sessions.Get(r, "scanscout")
@@ -25,7 +33,12 @@ func (h *PlayerHandler) ValidateBlock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = r.ParseForm()
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBlockUploadSize)
+		err = r.ParseMultipartForm(maxBlockUploadSize)
+	} else {
+		err = r.ParseForm()
+	}
 	if err != nil {
 		h.handleError(w, r, fmt.Errorf("validateBlock: parsing form: %v", err).Error(), "Something went wrong!")
 		return
@@ -35,9 +48,22 @@ func (h *PlayerHandler) ValidateBlock(w http.ResponseWriter, r *http.Request) {
 		data[key] = value
 	}
 
+	// Upload references are only ever set by the server
+	delete(data, "upload_id")
+	delete(data, "url")
+	if r.MultipartForm != nil && len(r.MultipartForm.File["file"]) > 0 {
+		upload, err := h.uploadBlockFile(r, *team)
+		if err != nil {
+			h.handleError(w, r, fmt.Errorf("validateBlock: uploading file: %v", err).Error(), "Could not upload your file. Please try again.")
+			return
+		}
+		data["upload_id"] = []string{upload.ID}
+		data["url"] = []string{upload.OriginalURL}
+	}
+
 	state, block, err := h.GameplayService.ValidateAndUpdateBlockState(r.Context(), *team, data)
 	if err != nil {
-		h.Logger.Error("validateBlock: validating and updating block state", "Something went wrong. Please try again.", err, "block", block.GetID(), "team", team.Code)
+		h.handleError(w, r, fmt.Errorf("validateBlock: validating and updating block state: %v", err).Error(), "Something went wrong. Please try again.", "team", team.Code)
 		return
 	}
 
@@ -47,3 +73,57 @@ func (h *PlayerHandler) ValidateBlock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// uploadBlockFile stores the file submitted to a block and tags it with
+// the team, block, location, and instance it belongs to.
+func (h *PlayerHandler) uploadBlockFile(r *http.Request, team models.Team) (*models.Upload, error) {
+	blockID := r.Form.Get("block")
+	if blockID == "" {
+		return nil, errors.New("blockID must be set")
+	}
+
+	block, state, err := h.BlockService.GetBlockWithStateByBlockIDAndTeamCode(r.Context(), blockID, team.Code)
+	if err != nil {
+		return nil, fmt.Errorf("getting block with state: %w", err)
+	}
+	if block.GetType() != "photo" {
+		return nil, errors.New("block does not accept files")
+	}
+	// Submissions that would not be accepted are turned away before the
+	// file is stored, so they do not leave orphaned files behind
+	if state.IsComplete() {
+		return nil, errors.New("block already complete")
+	}
+	if state.GetReviewStatus() == blocks.ReviewStatusPending {
+		return nil, errors.New("block is awaiting review")
+	}
+
+	// The team must have checked in at the block's location in their own game
+	err = h.TeamService.LoadRelation(r.Context(), &team, "Scans")
+	if err != nil {
+		return nil, fmt.Errorf("loading check-ins: %w", err)
+	}
+	checkedIn := false
+	for _, checkIn := range team.CheckIns {
+		if checkIn.LocationID == block.GetLocationID() && checkIn.InstanceID == team.InstanceID {
+			checkedIn = true
+			break
+		}
+	}
+	if !checkedIn {
+		return nil, errors.New("team has not checked in at the block's location")
+	}
+
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("getting file: %w", err)
+	}
+	defer file.Close()
+
+	return h.UploadService.UploadFile(r.Context(), file, fileHeader, services.UploadMetadata{
+		InstanceID: team.InstanceID,
+		TeamID:     team.Code,
+		BlockID:    block.GetID(),
+		LocationID: block.GetLocationID(),
+	})
+}
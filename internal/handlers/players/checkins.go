@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi"
@@ -43,8 +45,18 @@ func (h *PlayerHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c := templates.CheckIn(*marker, team.Code, team.BlockingLocation)
-	err = templates.Layout(c, "Check In: "+marker.Name, team.Messages).Render(r.Context(), w)
+	// Ask for the player's position if the check-in may be geofenced.
+	// Without a team we cannot tell, so ask whenever the marker is mapped
+	requestPosition := marker.IsMapped()
+	if team.Code != "" {
+		radius, err := h.GameplayService.CheckInRadius(r.Context(), team, code)
+		if err == nil {
+			requestPosition = radius > 0
+		}
+	}
+
+	c := templates.CheckIn(*marker, team.Code, team.BlockingLocation, requestPosition)
+	err = templates.Layout(c, "Check In: "+marker.Name, team).Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("rendering checkin", "error", err.Error())
 	}
@@ -69,15 +81,27 @@ func (h *PlayerHandler) CheckInPost(w http.ResponseWriter, r *http.Request) {
 			h.handleError(w, r, "CheckInPost: getting team by code", "Error finding team. Please double check your team code.", "error", err, "team", r.FormValue("team"))
 			return
 		}
-		err = h.startSession(w, r, team.Code)
+		err = h.startSession(w, r, team.Code, "")
 		if err != nil {
 			h.handleError(w, r, "CheckInPost: starting session", "Error starting session. Please try again.", "error", err, "team", team.Code)
 			return
 		}
 	}
 
-	err = h.GameplayService.CheckIn(r.Context(), team, locationCode)
+	err = h.GameplayService.CheckIn(r.Context(), team, locationCode, positionFromForm(r))
 	if err != nil {
+		if errors.Is(err, services.ErrTimeExpired) {
+			h.redirect(w, r, "/finish")
+			return
+		}
+		if errors.Is(err, services.ErrPositionRequired) {
+			h.handleError(w, r, "CheckInPost: checking in", "You need to share your location to check in here. Please allow location access and try again.", "error", err, "team", team.Code, "location", locationCode)
+			return
+		}
+		if errors.Is(err, services.ErrOutsideGeofence) {
+			h.handleError(w, r, "CheckInPost: checking in", "You're too far away to check in here. Move closer to the location and try again.", "error", err, "team", team.Code, "location", locationCode)
+			return
+		}
 		if errors.Is(err, services.ErrLocationNotFound) {
 			h.handleError(w, r, "CheckInPost: checking in", "Location not found. Please try agian.", "error", err, "team", team.Code, "location", locationCode)
 			return
@@ -86,6 +110,10 @@ func (h *PlayerHandler) CheckInPost(w http.ResponseWriter, r *http.Request) {
 			h.handleError(w, r, "CheckInPost: checking in", "You have already checked in here.", "error", err, "team", team.Code, "location", locationCode)
 			return
 		}
+		if errors.Is(err, services.ErrLocationLocked) {
+			h.handleError(w, r, "CheckInPost: checking in", "This location is still locked. Visit other locations and complete their activities to unlock it.", "error", err, "team", team.Code, "location", locationCode)
+			return
+		}
 		h.handleError(w, r, "CheckInPost: checking in", "Error checking in", "error", err, "team", team.Code, "location", locationCode)
 		return
 	}
@@ -93,6 +121,32 @@ func (h *PlayerHandler) CheckInPost(w http.ResponseWriter, r *http.Request) {
 	h.redirect(w, r, "/checkins/"+locationCode)
 }
 
+// positionFromForm reads the position reported by the player's browser.
+// It returns nil if no position was submitted.
+func positionFromForm(r *http.Request) *services.Position {
+	lat, err := strconv.ParseFloat(r.FormValue("lat"), 64)
+	if err != nil {
+		return nil
+	}
+	lng, err := strconv.ParseFloat(r.FormValue("lng"), 64)
+	if err != nil {
+		return nil
+	}
+	accuracy, _ := strconv.ParseFloat(r.FormValue("accuracy"), 64)
+	// ParseFloat accepts "NaN" and "Inf", which would slip past every
+	// distance comparison in the geofence check.
+	for _, f := range []float64{lat, lng, accuracy} {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil
+		}
+	}
+	return &services.Position{
+		Lat:      lat,
+		Lng:      lng,
+		Accuracy: accuracy,
+	}
+}
+
 func (h *PlayerHandler) CheckOut(w http.ResponseWriter, r *http.Request) {
 	code := chi.URLParam(r, "code")
 	code = strings.ToUpper(code)
@@ -125,7 +179,7 @@ func (h *PlayerHandler) CheckOut(w http.ResponseWriter, r *http.Request) {
 	}
 
 	c := templates.CheckOut(*marker, team.Code, team.BlockingLocation)
-	err = templates.Layout(c, "Check Out: "+marker.Name, team.Messages).Render(r.Context(), w)
+	err = templates.Layout(c, "Check Out: "+marker.Name, team).Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("rendering checkin", "error", err.Error())
 	}
@@ -150,7 +204,7 @@ func (h *PlayerHandler) CheckOutPost(w http.ResponseWriter, r *http.Request) {
 			h.handleError(w, r, "CheckInPost: getting team by code", "Error finding team. Please double check your team code.", "error", err, "team", r.FormValue("team"))
 			return
 		}
-		err = h.startSession(w, r, team.Code)
+		err = h.startSession(w, r, team.Code, "")
 		if err != nil {
 			h.handleError(w, r, "CheckInPost: starting session", "Error starting session. Please try again.", "error", err, "team", team.Code)
 			return
@@ -209,7 +263,7 @@ func (h *PlayerHandler) MyCheckins(w http.ResponseWriter, r *http.Request) {
 	}
 
 	c := templates.MyCheckins(*team)
-	err = templates.Layout(c, "My Check-ins", team.Messages).Render(r.Context(), w)
+	err = templates.Layout(c, "My Check-ins", team).Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("rendering checkins", "error", err.Error())
 	}
@@ -260,8 +314,20 @@ func (h *PlayerHandler) CheckInView(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c := templates.CheckInView(team.Instance.Settings, team.CheckIns[index], blocks, blockStates)
-	err = templates.Layout(c, team.CheckIns[index].Location.Name, team.Messages).Render(r.Context(), w)
+	hints, err := h.HintService.FindByLocation(r.Context(), team.CheckIns[index].Location.ID)
+	if err != nil {
+		h.handleError(w, r, "CheckInView: getting hints", "Error loading hints", "error", err, "team", team.Code, "location", locationCode)
+		return
+	}
+
+	revealed, err := h.HintService.FindRevealed(r.Context(), team.Code)
+	if err != nil {
+		h.handleError(w, r, "CheckInView: getting revealed hints", "Error loading hints", "error", err, "team", team.Code)
+		return
+	}
+
+	c := templates.CheckInView(team.Instance.Settings, team.CheckIns[index], blocks, blockStates, hints, revealed)
+	err = templates.Layout(c, team.CheckIns[index].Location.Name, team).Render(r.Context(), w)
 	if err != nil {
 		h.Logger.Error("rendering checkin view", "error", err.Error())
 	}
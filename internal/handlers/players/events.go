@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/a-h/templ"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	templates "github.com/nathanhollows/Rapua/v3/internal/templates/players"
+	"github.com/nathanhollows/Rapua/v3/models"
+)
+
+// Events streams live updates for the team over Server-Sent Events:
+// announcements, changes to the team's points, check-ins and leaderboard
+// refreshes.
+func (h *PlayerHandler) Events(w http.ResponseWriter, r *http.Request) {
+	team, err := h.getTeamFromContext(r.Context())
+	if err != nil {
+		// 204 tells the browser not to reconnect
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	sub := h.EventBroker.Subscribe(team.InstanceID, team.Code)
+	err = h.EventBroker.Stream(w, r, sub, func(event events.Event) (string, error) {
+		// The leaderboard fetches its own update, so it only needs a nudge
+		if event.Type == events.LeaderboardUpdated {
+			return string(event.Type), nil
+		}
+		component := liveUpdate(event)
+		if component == nil {
+			return "", nil
+		}
+		var buf bytes.Buffer
+		err := component.Render(r.Context(), &buf)
+		return buf.String(), err
+	})
+	if err != nil {
+		h.Logger.Error("Events: streaming", "error", err, "team", team.Code)
+	}
+}
+
+// liveUpdate returns what to show the team for an event, or nil if there is
+// nothing to show.
+func liveUpdate(event events.Event) templ.Component {
+	switch payload := event.Payload.(type) {
+	case models.Notification:
+		return templates.Message(payload)
+	case models.PointsTransaction:
+		if payload.Amount != 0 {
+			return templates.PointsUpdate(payload)
+		}
+	case models.Location:
+		return templates.CheckInUpdate(payload, event.Type == events.TeamCheckedIn)
+	}
+	return nil
+}
@@ -22,14 +22,15 @@ func (h *PlayerHandler) Finish(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	if len(locations) > 0 {
+	// Teams that are out of time finish early
+	if len(locations) > 0 && !team.TimeExpired() {
 		h.redirect(w, r, "/next")
 		return
 	}
 
 	// data["notifications"], _ = h.NotificationService.GetNotifications(r.Context(), team.Code)
 	c := templates.Finish(*team, locations)
-	err = templates.Layout(c, "The End", team.Messages).Render(r.Context(), w)
+	err = templates.Layout(c, "The End", team).Render(r.Context(), w)
 	if err != nil {
 		h.handleError(w, r, "Next: rendering template", "Error rendering template", "Could not render template", err)
 	}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	templates "github.com/nathanhollows/Rapua/v3/internal/templates/players"
+)
+
+// RevealHint reveals the team's next hint for a location or block.
+func (h *PlayerHandler) RevealHint(w http.ResponseWriter, r *http.Request) {
+	team, err := h.getTeamFromContext(r.Context())
+	if err != nil {
+		h.redirect(w, r, "/play")
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "RevealHint: parsing form", "Something went wrong!", "error", err)
+		return
+	}
+	locationID := r.Form.Get("location")
+	blockID := r.Form.Get("block")
+
+	_, err = h.HintService.RevealHint(r.Context(), team, locationID, blockID)
+	if err != nil && !errors.Is(err, services.ErrNoHintsRemaining) {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "RevealHint: revealing hint", "Could not reveal a hint. Please try again.", "error", err, "team", team.Code)
+		return
+	}
+
+	hints, err := h.HintService.FindByLocation(r.Context(), locationID)
+	if err != nil {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "RevealHint: finding hints", "Something went wrong!", "error", err)
+		return
+	}
+
+	revealed, err := h.HintService.FindRevealed(r.Context(), team.Code)
+	if err != nil {
+		w.Header().Set("HX-Reswap", "none")
+		h.handleError(w, r, "RevealHint: finding revealed hints", "Something went wrong!", "error", err)
+		return
+	}
+
+	err = templates.Hints(team.Instance.Settings, locationID, blockID, hints, revealed).Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("RevealHint: rendering template", "error", err)
+	}
+}
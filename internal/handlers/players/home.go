@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/nathanhollows/Rapua/v3/internal/services"
 	"github.com/nathanhollows/Rapua/v3/internal/sessions"
 	templates "github.com/nathanhollows/Rapua/v3/internal/templates/players"
 	"github.com/nathanhollows/Rapua/v3/models"
@@ -78,7 +80,23 @@ func (h *PlayerHandler) PlayPost(w http.ResponseWriter, r *http.Request) {
 
 	team := response.Data["team"].(*models.Team)
 
-	err = h.startSession(w, r, team.Code)
+	// Players may give their name so the team knows who did what,
+	// or play anonymously with just the team code
+	playerID := ""
+	player, err := h.PlayerService.Join(r.Context(), team, r.FormValue("playerName"), r.FormValue("playerEmail"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidArgument) {
+			h.handleError(w, r, "HomePost: joining team", "Please check your name and email, or leave both blank.", "error", err, "team", team.Code)
+			return
+		}
+		h.handleError(w, r, "HomePost: joining team", "Error joining team. Please try again.", "error", err, "team", team.Code)
+		return
+	}
+	if player != nil {
+		playerID = player.ID
+	}
+
+	err = h.startSession(w, r, team.Code, playerID)
 	if err != nil {
 		h.handleError(w, r, "HomePost: starting session", "Error starting session. Please try again.", "error", err, "team", team.Code)
 		return
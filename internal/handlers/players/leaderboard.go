@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	templates "github.com/nathanhollows/Rapua/v3/internal/templates/players"
+)
+
+// Leaderboard shows the leaderboard to a team.
+func (h *PlayerHandler) Leaderboard(w http.ResponseWriter, r *http.Request) {
+	team, err := h.getTeamFromContext(r.Context())
+	if err != nil {
+		h.redirect(w, r, "/play")
+		return
+	}
+
+	board, err := h.LeaderboardService.ForTeam(r.Context(), team)
+	if err != nil {
+		if errors.Is(err, services.ErrLeaderboardHidden) {
+			h.redirect(w, r, "/next")
+			return
+		}
+		h.handleError(w, r, "Leaderboard: getting leaderboard", "Error loading leaderboard", "error", err, "team", team.Code)
+		return
+	}
+
+	c := templates.Leaderboard(*team, *board)
+	err = templates.Layout(c, "Leaderboard", team).Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("Leaderboard: rendering template", "error", err)
+	}
+}
+
+// LeaderboardScreen shows a shared leaderboard full screen.
+// The link does not need a team or an account so it can be put on a projector.
+func (h *PlayerHandler) LeaderboardScreen(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	board, err := h.LeaderboardService.ForToken(r.Context(), token)
+	if err != nil {
+		h.Logger.Debug("LeaderboardScreen: getting leaderboard", "error", err)
+		http.NotFound(w, r)
+		return
+	}
+
+	err = templates.LeaderboardScreen(*board, "/leaderboard/"+token).Render(r.Context(), w)
+	if err != nil {
+		h.Logger.Error("LeaderboardScreen: rendering template", "error", err)
+	}
+}
+
+// LeaderboardJSON returns a shared leaderboard as JSON.
+func (h *PlayerHandler) LeaderboardJSON(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	board, err := h.LeaderboardService.ForToken(r.Context(), token)
+	if err != nil {
+		h.Logger.Debug("LeaderboardJSON: getting leaderboard", "error", err)
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(board)
+	if err != nil {
+		h.Logger.Error("LeaderboardJSON: encoding leaderboard", "error", err)
+	}
+}
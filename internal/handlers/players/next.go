@@ -25,9 +25,23 @@ func (h *PlayerHandler) Next(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	for i := range locations {
+		locations[i].Hints, err = h.HintService.FindByLocation(r.Context(), locations[i].ID)
+		if err != nil {
+			h.handleError(w, r, "Next: getting hints", "Error getting hints", "Could not load data", err)
+			return
+		}
+	}
+
+	revealed, err := h.HintService.FindRevealed(r.Context(), team.Code)
+	if err != nil {
+		h.handleError(w, r, "Next: getting revealed hints", "Error getting hints", "Could not load data", err)
+		return
+	}
+
 	// data["notifications"], _ = h.NotificationService.GetNotifications(r.Context(), team.Code)
-	c := templates.Next(*team, locations)
-	err = templates.Layout(c, "Next stops", team.Messages).Render(r.Context(), w)
+	c := templates.Next(*team, locations, revealed)
+	err = templates.Layout(c, "Next stops", team).Render(r.Context(), w)
 	if err != nil {
 		h.handleError(w, r, "Next: rendering template", "Error rendering template", "Could not render template", err)
 	}
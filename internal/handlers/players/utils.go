@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	"github.com/nathanhollows/Rapua/v3/internal/contextkeys"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
 	"github.com/nathanhollows/Rapua/v3/internal/flash"
 	"github.com/nathanhollows/Rapua/v3/internal/services"
 	"github.com/nathanhollows/Rapua/v3/internal/sessions"
@@ -18,24 +19,39 @@ import (
 type PlayerHandler struct {
 	Logger              *slog.Logger
 	BlockService        services.BlockService
+	EventBroker         *events.Broker
 	GameplayService     services.GameplayService
+	HintService         services.HintService
+	LeaderboardService  services.LeaderboardService
 	NotificationService services.NotificationService
+	PlayerService       services.PlayerService
 	TeamService         services.TeamService
+	UploadService       services.UploadService
 }
 
 func NewPlayerHandler(
 	logger *slog.Logger,
 	blockService services.BlockService,
+	eventBroker *events.Broker,
 	gameplayService services.GameplayService,
+	hintService services.HintService,
+	leaderboardService services.LeaderboardService,
 	notificationService services.NotificationService,
+	playerService services.PlayerService,
 	teamService services.TeamService,
+	uploadService services.UploadService,
 ) *PlayerHandler {
 	return &PlayerHandler{
 		Logger:              logger,
 		BlockService:        blockService,
+		EventBroker:         eventBroker,
 		GameplayService:     gameplayService,
+		HintService:         hintService,
+		LeaderboardService:  leaderboardService,
 		NotificationService: notificationService,
+		PlayerService:       playerService,
 		TeamService:         teamService,
+		UploadService:       uploadService,
 	}
 }
 
@@ -72,12 +88,19 @@ func (h PlayerHandler) redirect(w http.ResponseWriter, r *http.Request, path str
 	http.Redirect(w, r, path, http.StatusFound)
 }
 
-func (h *PlayerHandler) startSession(w http.ResponseWriter, r *http.Request, teamCode string) error {
+// startSession remembers the team, and the player if they gave their name.
+// An empty playerID plays anonymously.
+func (h *PlayerHandler) startSession(w http.ResponseWriter, r *http.Request, teamCode, playerID string) error {
 	session, err := sessions.Get(r, "scanscout")
 	if err != nil {
 		return fmt.Errorf("getting session: %w", err)
 	}
 	session.Values["team"] = teamCode
+	if playerID != "" {
+		session.Values["player"] = playerID
+	} else {
+		delete(session.Values, "player")
+	}
 	session.Options.Path = "/"
 	err = session.Save(r, w)
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"regexp"
+	"strings"
 
 	"github.com/nathanhollows/Rapua/v3/internal/contextkeys"
 	"github.com/nathanhollows/Rapua/v3/internal/services"
@@ -28,25 +29,100 @@ func AdminAuthMiddleware(authService services.AuthService, next http.Handler) ht
 			return
 		}
 
-		// Add the user to the context
+		// Add the user to the context, recording any changes they make as theirs
 		ctx := context.WithValue(r.Context(), contextkeys.UserKey, user)
+		ctx = services.WithActor(ctx, services.UserActor(user))
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-// AdminCheckInstanceMiddleware ensures the user has an instance selected.
-func AdminCheckInstanceMiddleware(next http.Handler) http.Handler {
+// workspaceRoute matches the admin pages that work without a current instance.
+var workspaceRoute = regexp.MustCompile(`^/admin/(instances|templates|invites|organisation|markers)(/|$)`)
+
+// instanceRoutes are the workspace routes that change an instance named in
+// the path, and the permission each needs in that instance.
+var instanceRoutes = []struct {
+	pattern    *regexp.Regexp
+	permission models.Permission
+}{
+	{regexp.MustCompile(`^/admin/instances/([^/]+)/export$`), models.PermissionEdit},
+	{regexp.MustCompile(`^/admin/instances/([^/]+)/move$`), models.PermissionManage},
+}
+
+// instanceFormRoutes are the workspace routes that change the instance
+// named by the id form field, and the permission each needs in it.
+var instanceFormRoutes = map[string]models.Permission{
+	"/admin/instances/delete":    models.PermissionManage,
+	"/admin/instances/duplicate": models.PermissionEdit,
+	"/admin/instances/publish":   models.PermissionEdit,
+}
+
+// instancePermission returns the instance a workspace request changes and the
+// permission it needs there. ok is false if the request does not change an
+// instance.
+func instancePermission(r *http.Request, user *models.User) (instanceID string, permission models.Permission, ok bool) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	for _, route := range instanceRoutes {
+		if match := route.pattern.FindStringSubmatch(path); match != nil {
+			return match[1], route.permission, true
+		}
+	}
+	if r.Method != http.MethodPost {
+		return "", 0, false
+	}
+	if permission, found := instanceFormRoutes[path]; found {
+		return r.FormValue("id"), permission, true
+	}
+	// Library markers are added to the current instance
+	if strings.HasPrefix(path, "/admin/markers/") && strings.HasSuffix(path, "/attach") {
+		return user.CurrentInstanceID, models.PermissionEdit, true
+	}
+	return "", 0, false
+}
+
+// AdminCheckInstanceMiddleware ensures the user has an instance selected and
+// limits them to what their role in it allows.
+func AdminCheckInstanceMiddleware(collaboratorService services.CollaboratorService, organisationService services.OrganisationService, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := r.Context().Value(contextkeys.UserKey).(*models.User)
 
-		// Check if the route contains /admin/instances
-		reg := regexp.MustCompile(`/admin/instances/?`)
-		if reg.MatchString(r.URL.Path) {
+		// Users who have left an organisation go back to their own workspace
+		organisations, err := organisationService.FindForUser(r.Context(), user.ID)
+		if err == nil {
+			user.Organisations = organisations
+			if user.CurrentOrganisationID != "" && user.CurrentOrganisation() == nil {
+				_ = organisationService.SwitchWorkspace(r.Context(), user, "")
+			}
+		}
+
+		// Instances shared with the user are listed alongside their own
+		shared, err := collaboratorService.FindSharedInstances(r.Context(), user.ID)
+		if err == nil {
+			user.SharedInstances = shared
+		}
+		loadWorkspaceInstances(r, organisationService, user)
+		if user.CurrentInstanceID != "" {
+			role, err := collaboratorService.Role(r.Context(), user, user.CurrentInstanceID)
+			if err == nil {
+				user.CurrentRole = role
+			}
+		}
+
+		// Instances, shared templates, invitations, organisations and the
+		// marker library can be used without a current instance, but changes
+		// to a particular instance still need the right role in it
+		if workspaceRoute.MatchString(r.URL.Path) {
+			instanceID, permission, ok := instancePermission(r, user)
+			if ok && collaboratorService.Authorize(r.Context(), user, instanceID, permission) != nil {
+				http.Error(w, "You do not have permission to make changes", http.StatusForbidden)
+				return
+			}
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		if user.CurrentInstanceID == "" {
+		// Users who no longer have access must choose another instance
+		if user.CurrentInstanceID == "" || user.CurrentRole == "" {
 			// flash.Message{
 			// 	Title:   "Error",
 			// 	Message: "Please select an instance to continue",
@@ -56,6 +132,55 @@ func AdminCheckInstanceMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Viewers can only watch the game's activity
+		if !user.CurrentRole.Can(models.PermissionEdit) && !viewerAllowed(r) {
+			if r.Method != http.MethodGet {
+				http.Error(w, "You do not have permission to make changes", http.StatusForbidden)
+				return
+			}
+			http.Redirect(w, r, "/admin/activity", http.StatusSeeOther)
+			return
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
+
+// loadWorkspaceInstances limits the user's instances to their current workspace
+// and adds the rest of their organisation's instances that are not already
+// shared with them.
+func loadWorkspaceInstances(r *http.Request, organisationService services.OrganisationService, user *models.User) {
+	own := make([]models.Instance, 0, len(user.Instances))
+	for _, instance := range user.Instances {
+		if instance.OrganisationID == user.CurrentOrganisationID {
+			own = append(own, instance)
+		}
+	}
+	user.Instances = own
+
+	if user.CurrentOrganisationID == "" {
+		return
+	}
+	instances, err := organisationService.FindInstances(r.Context(), user.CurrentOrganisationID)
+	if err != nil {
+		return
+	}
+	shared := map[string]bool{}
+	for _, instance := range user.SharedInstances {
+		shared[instance.ID] = true
+	}
+	for _, instance := range instances {
+		if instance.UserID != user.ID && !shared[instance.ID] {
+			user.OrganisationInstances = append(user.OrganisationInstances, instance)
+		}
+	}
+}
+
+// viewerAllowed reports whether viewers may make the request.
+func viewerAllowed(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	return path == "/admin" || path == "/admin/activity" || strings.HasPrefix(path, "/admin/activity/")
+}
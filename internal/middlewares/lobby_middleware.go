@@ -11,7 +11,8 @@ import (
 	"github.com/nathanhollows/Rapua/v3/models"
 )
 
-// LobbyMiddleware redirects to the lobby if the game is scheduled to start.
+// LobbyMiddleware redirects to the lobby if the game is scheduled to start,
+// and to the finish page if the team has run out of time.
 func LobbyMiddleware(teamService services.TeamService, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Extract the session
@@ -43,6 +44,12 @@ func LobbyMiddleware(teamService services.TeamService, next http.Handler) http.H
 			return
 		}
 
+		// Redirect to the finish page if the team is out of time
+		if team.TimeExpired() {
+			http.Redirect(w, r, "/finish", http.StatusFound)
+			return
+		}
+
 		// Add team to context
 		ctx := context.WithValue(r.Context(), contextkeys.TeamKey, team)
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -45,6 +45,11 @@ func TeamMiddleware(teamService services.TeamService, next http.Handler) http.Ha
 
 		// Add team to context
 		ctx := context.WithValue(r.Context(), contextkeys.TeamKey, team)
+
+		// Named players are credited with what they do for the team
+		if playerID, ok := session.Values["player"].(string); ok && playerID != "" {
+			ctx = services.WithPlayer(ctx, playerID)
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
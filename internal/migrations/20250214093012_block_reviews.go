@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250214093012_Block struct {
+	bun.BaseModel `bun:"table:blocks"`
+
+	ID                 string          `bun:"id,pk,notnull"`
+	LocationID         string          `bun:"location_id,notnull"`
+	Type               string          `bun:"type,type:int"`
+	Data               json.RawMessage `bun:"data,type:jsonb"`
+	Ordering           int             `bun:"ordering,type:int"`
+	Points             int             `bun:"points,type:int"`
+	ValidationRequired bool            `bun:"validation_required,type:bool"`
+	ReviewRequired     bool            `bun:"review_required,type:bool"`
+}
+
+type m20250214093012_TeamBlockState struct {
+	bun.BaseModel `bun:"table:team_block_states"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+
+	TeamCode      string          `bun:"team_code,pk,notnull"`
+	BlockID       string          `bun:"block_id,pk,notnull"`
+	IsComplete    bool            `bun:"is_complete,type:bool"`
+	PointsAwarded int             `bun:"points_awarded,type:int"`
+	PlayerData    json.RawMessage `bun:"player_data,type:jsonb"`
+	ReviewStatus  string          `bun:"review_status,nullzero"`
+	ReviewComment string          `bun:"review_comment,nullzero"`
+}
+
+func init() {
+	// Adds facilitator review to blocks and team block states.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewAddColumn().Model((*m20250214093012_Block)(nil)).ColumnExpr("review_required BOOLEAN DEFAULT false").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column review_required: %w", err)
+		}
+		_, err = db.NewAddColumn().Model((*m20250214093012_TeamBlockState)(nil)).ColumnExpr("review_status VARCHAR").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column review_status: %w", err)
+		}
+		_, err = db.NewAddColumn().Model((*m20250214093012_TeamBlockState)(nil)).ColumnExpr("review_comment VARCHAR").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column review_comment: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropColumn().Model((*m20250214093012_TeamBlockState)(nil)).Column("review_comment").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column review_comment: %w", err)
+		}
+		_, err = db.NewDropColumn().Model((*m20250214093012_TeamBlockState)(nil)).Column("review_status").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column review_status: %w", err)
+		}
+		_, err = db.NewDropColumn().Model((*m20250214093012_Block)(nil)).Column("review_required").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column review_required: %w", err)
+		}
+		return nil
+	})
+}
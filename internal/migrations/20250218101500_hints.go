@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250218101500_Hint struct {
+	bun.BaseModel `bun:"table:hints"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+
+	ID         string `bun:"id,pk,type:varchar(36)"`
+	InstanceID string `bun:"instance_id,notnull"`
+	LocationID string `bun:"location_id,notnull"`
+	BlockID    string `bun:"block_id,nullzero"`
+	Order      int    `bun:"ordering,type:int"`
+	Content    string `bun:"content,type:text"`
+	Penalty    int    `bun:"penalty,type:int"`
+}
+
+type m20250218101500_TeamHint struct {
+	bun.BaseModel `bun:"table:team_hints"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+
+	TeamCode   string `bun:"team_code,pk,notnull"`
+	HintID     string `bun:"hint_id,pk,notnull"`
+	InstanceID string `bun:"instance_id,notnull"`
+	LocationID string `bun:"location_id,notnull"`
+	BlockID    string `bun:"block_id,nullzero"`
+	Penalty    int    `bun:"penalty,type:int"`
+}
+
+func init() {
+	// Adds hints for locations and blocks, and records which hints teams reveal.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewCreateTable().Model((*m20250218101500_Hint)(nil)).IfNotExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("create table hints: %w", err)
+		}
+		_, err = db.NewCreateTable().Model((*m20250218101500_TeamHint)(nil)).IfNotExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("create table team_hints: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropTable().Model((*m20250218101500_TeamHint)(nil)).IfExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop table team_hints: %w", err)
+		}
+		_, err = db.NewDropTable().Model((*m20250218101500_Hint)(nil)).IfExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop table hints: %w", err)
+		}
+		return nil
+	})
+}
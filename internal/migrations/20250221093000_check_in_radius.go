@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250221093000_InstanceSettings struct {
+	bun.BaseModel `bun:"table:instance_settings"`
+
+	InstanceID    string `bun:"instance_id,pk,type:varchar(36)"`
+	CheckInRadius int    `bun:"check_in_radius,type:int"`
+}
+
+type m20250221093000_Location struct {
+	bun.BaseModel `bun:"table:locations"`
+
+	ID            string `bun:"id,pk,notnull"`
+	CheckInRadius int    `bun:"check_in_radius,type:int"`
+}
+
+func init() {
+	// Adds geofenced check-ins to instance settings and locations.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewAddColumn().Model((*m20250221093000_InstanceSettings)(nil)).ColumnExpr("check_in_radius INTEGER DEFAULT 0").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column instance_settings.check_in_radius: %w", err)
+		}
+		_, err = db.NewAddColumn().Model((*m20250221093000_Location)(nil)).ColumnExpr("check_in_radius INTEGER DEFAULT 0").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column locations.check_in_radius: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropColumn().Model((*m20250221093000_Location)(nil)).Column("check_in_radius").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column locations.check_in_radius: %w", err)
+		}
+		_, err = db.NewDropColumn().Model((*m20250221093000_InstanceSettings)(nil)).Column("check_in_radius").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column instance_settings.check_in_radius: %w", err)
+		}
+		return nil
+	})
+}
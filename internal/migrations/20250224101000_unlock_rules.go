@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250224101000_Location struct {
+	bun.BaseModel `bun:"table:locations"`
+
+	ID          string `bun:"id,pk,notnull"`
+	UnlockRules string `bun:"unlock_rules,type:text"`
+}
+
+func init() {
+	// Adds unlock rules to locations for conditional navigation.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewAddColumn().Model((*m20250224101000_Location)(nil)).ColumnExpr("unlock_rules TEXT").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column unlock_rules: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropColumn().Model((*m20250224101000_Location)(nil)).Column("unlock_rules").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column unlock_rules: %w", err)
+		}
+		return nil
+	})
+}
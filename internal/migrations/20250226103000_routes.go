@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250226103000_Route struct {
+	bun.BaseModel `bun:"table:routes"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+
+	ID         string `bun:"id,pk,type:varchar(36)"`
+	InstanceID string `bun:"instance_id,notnull"`
+	Name       string `bun:"name,type:varchar(255)"`
+	Locations  string `bun:"locations,type:text"`
+}
+
+type m20250226103000_Team struct {
+	bun.BaseModel `bun:"table:teams"`
+
+	ID      string `bun:"id,pk"`
+	RouteID string `bun:"route_id,nullzero"`
+}
+
+func init() {
+	// Adds routes so teams can follow their own ordering in Ordered navigation mode.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewCreateTable().Model((*m20250226103000_Route)(nil)).IfNotExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("create table routes: %w", err)
+		}
+		_, err = db.NewAddColumn().Model((*m20250226103000_Team)(nil)).ColumnExpr("route_id VARCHAR(36)").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column route_id: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropColumn().Model((*m20250226103000_Team)(nil)).Column("route_id").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column route_id: %w", err)
+		}
+		_, err = db.NewDropTable().Model((*m20250226103000_Route)(nil)).IfExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop table routes: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250228091500_Location struct {
+	bun.BaseModel `bun:"table:locations"`
+
+	ID       string `bun:"id,pk,notnull"`
+	Capacity int    `bun:"capacity,type:int"`
+}
+
+func init() {
+	// Adds a capacity to locations for balanced navigation.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewAddColumn().Model((*m20250228091500_Location)(nil)).ColumnExpr("capacity INTEGER DEFAULT 0").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column capacity: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropColumn().Model((*m20250228091500_Location)(nil)).Column("capacity").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column capacity: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250302094500_InstanceSettings struct {
+	bun.BaseModel `bun:"table:instance_settings"`
+
+	InstanceID   string `bun:"instance_id,pk,type:varchar(36)"`
+	TeamDuration int    `bun:"team_duration,type:int"`
+}
+
+type m20250302094500_Team struct {
+	bun.BaseModel `bun:"table:teams"`
+
+	ID        string    `bun:"id,pk"`
+	StartedAt time.Time `bun:"started_at,nullzero"`
+	ExtraTime int       `bun:"extra_time,type:int"`
+}
+
+func init() {
+	// Adds a per-team time limit to instances, and tracks when each team started.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewAddColumn().Model((*m20250302094500_InstanceSettings)(nil)).ColumnExpr("team_duration INTEGER DEFAULT 0").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column team_duration: %w", err)
+		}
+		_, err = db.NewAddColumn().Model((*m20250302094500_Team)(nil)).ColumnExpr("started_at TIMESTAMP").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column started_at: %w", err)
+		}
+		_, err = db.NewAddColumn().Model((*m20250302094500_Team)(nil)).ColumnExpr("extra_time INTEGER DEFAULT 0").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column extra_time: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropColumn().Model((*m20250302094500_InstanceSettings)(nil)).Column("team_duration").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column team_duration: %w", err)
+		}
+		_, err = db.NewDropColumn().Model((*m20250302094500_Team)(nil)).Column("started_at").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column started_at: %w", err)
+		}
+		_, err = db.NewDropColumn().Model((*m20250302094500_Team)(nil)).Column("extra_time").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column extra_time: %w", err)
+		}
+		return nil
+	})
+}
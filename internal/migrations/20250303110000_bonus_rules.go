@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250303110000_BonusRule struct {
+	bun.BaseModel `bun:"table:bonus_rules"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+
+	ID         string `bun:"id,pk,type:varchar(36)"`
+	InstanceID string `bun:"instance_id,notnull"`
+	Type       int    `bun:"type,type:int"`
+	Points     int    `bun:"points,type:int"`
+	Threshold  int    `bun:"threshold,type:int"`
+}
+
+type m20250303110000_BonusAward struct {
+	bun.BaseModel `bun:"table:bonus_awards"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+
+	ID         string `bun:"id,pk,type:varchar(36)"`
+	InstanceID string `bun:"instance_id,notnull"`
+	TeamCode   string `bun:"team_code,notnull"`
+	RuleID     string `bun:"rule_id,notnull"`
+	LocationID string `bun:"location_id,nullzero"`
+	Points     int    `bun:"points,type:int"`
+	Reason     string `bun:"reason,type:text"`
+}
+
+func init() {
+	// Adds bonus rules and a record of the bonus points awarded to teams.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewCreateTable().Model((*m20250303110000_BonusRule)(nil)).IfNotExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("create table bonus_rules: %w", err)
+		}
+		_, err = db.NewCreateTable().Model((*m20250303110000_BonusAward)(nil)).IfNotExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("create table bonus_awards: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropTable().Model((*m20250303110000_BonusAward)(nil)).IfExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop table bonus_awards: %w", err)
+		}
+		_, err = db.NewDropTable().Model((*m20250303110000_BonusRule)(nil)).IfExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop table bonus_rules: %w", err)
+		}
+		return nil
+	})
+}
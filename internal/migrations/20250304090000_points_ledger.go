@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type m20250304090000_PointsTransaction struct {
+	bun.BaseModel `bun:"table:points_transactions"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+
+	ID         string    `bun:"id,pk,type:varchar(36)"`
+	InstanceID string    `bun:"instance_id,notnull"`
+	TeamCode   string    `bun:"team_code,notnull"`
+	Amount     int       `bun:"amount,type:int"`
+	Reason     string    `bun:"reason,type:text"`
+	Source     int       `bun:"source,type:int"`
+	SourceID   string    `bun:"source_id,nullzero"`
+	ActorID    string    `bun:"actor_id,nullzero"`
+	UndoneAt   time.Time `bun:"undone_at,nullzero"`
+}
+
+type m20250304090000_Team struct {
+	bun.BaseModel `bun:"table:teams"`
+
+	Code       string `bun:"code,unique"`
+	InstanceID string `bun:"instance_id,notnull"`
+	Points     int    `bun:"points,"`
+}
+
+func init() {
+	// Adds a ledger of points transactions.
+	// Existing points are carried over as an opening balance so totals match the ledger.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewCreateTable().Model((*m20250304090000_PointsTransaction)(nil)).IfNotExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("create table points_transactions: %w", err)
+		}
+
+		var teams []m20250304090000_Team
+		err = db.NewSelect().Model(&teams).Where("points != 0").Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("find teams with points: %w", err)
+		}
+		if len(teams) == 0 {
+			return nil
+		}
+
+		balances := make([]m20250304090000_PointsTransaction, len(teams))
+		for i, team := range teams {
+			balances[i] = m20250304090000_PointsTransaction{
+				ID:         uuid.New().String(),
+				InstanceID: team.InstanceID,
+				TeamCode:   team.Code,
+				Amount:     team.Points,
+				Reason:     "Opening balance",
+				Source:     4, // Manual
+			}
+		}
+		_, err = db.NewInsert().Model(&balances).Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("insert opening balances: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropTable().Model((*m20250304090000_PointsTransaction)(nil)).IfExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop table points_transactions: %w", err)
+		}
+		return nil
+	})
+}
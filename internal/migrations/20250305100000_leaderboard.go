@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250305100000_InstanceSettings struct {
+	bun.BaseModel `bun:"table:instance_settings"`
+
+	InstanceID           string `bun:"instance_id,pk,type:varchar(36)"`
+	LeaderboardAnonymise bool   `bun:"leaderboard_anonymise,type:bool"`
+	LeaderboardFreeze    int    `bun:"leaderboard_freeze,type:int"`
+	LeaderboardFreezeTop int    `bun:"leaderboard_freeze_top,type:int"`
+	LeaderboardToken     string `bun:"leaderboard_token,nullzero"`
+}
+
+func init() {
+	// Adds leaderboard visibility options and a key for sharing the leaderboard.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		columns := []string{
+			"leaderboard_anonymise BOOLEAN DEFAULT FALSE",
+			"leaderboard_freeze INTEGER DEFAULT 0",
+			"leaderboard_freeze_top INTEGER DEFAULT 0",
+			"leaderboard_token VARCHAR(64)",
+		}
+		for _, column := range columns {
+			_, err := db.NewAddColumn().Model((*m20250305100000_InstanceSettings)(nil)).ColumnExpr(column).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("add column %s: %w", column, err)
+			}
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		columns := []string{"leaderboard_anonymise", "leaderboard_freeze", "leaderboard_freeze_top", "leaderboard_token"}
+		for _, column := range columns {
+			_, err := db.NewDropColumn().Model((*m20250305100000_InstanceSettings)(nil)).Column(column).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("drop column %s: %w", column, err)
+			}
+		}
+		return nil
+	})
+}
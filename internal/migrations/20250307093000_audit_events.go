@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250307093000_AuditEvent struct {
+	bun.BaseModel `bun:"table:audit_events"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+
+	ID         string `bun:"id,pk,type:varchar(36)"`
+	InstanceID string `bun:"instance_id,notnull"`
+	ActorID    string `bun:"actor_id,notnull"`
+	Action     string `bun:"action,notnull"`
+	EntityType string `bun:"entity_type,notnull"`
+	EntityID   string `bun:"entity_id,notnull"`
+	TeamCode   string `bun:"team_code,nullzero"`
+	Reason     string `bun:"reason,type:text"`
+}
+
+func init() {
+	// Adds an audit log of changes made by admins.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewCreateTable().Model((*m20250307093000_AuditEvent)(nil)).IfNotExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("create table audit_events: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropTable().Model((*m20250307093000_AuditEvent)(nil)).IfExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop table audit_events: %w", err)
+		}
+		return nil
+	})
+}
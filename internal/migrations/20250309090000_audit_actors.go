@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250309090000_AuditEvent struct {
+	bun.BaseModel `bun:"table:audit_events"`
+
+	ID             string `bun:"id,pk,type:varchar(36)"`
+	ActorType      string `bun:"actor_type,notnull,default:'user'"`
+	ActorName      string `bun:"actor_name,nullzero"`
+	SnapshotBefore string `bun:"snapshot_before,type:text,nullzero"`
+	SnapshotAfter  string `bun:"snapshot_after,type:text,nullzero"`
+}
+
+func init() {
+	// Records who made each audited change and what the entity looked like
+	// before and after.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		columns := []string{
+			"actor_type VARCHAR(16) NOT NULL DEFAULT 'user'",
+			"actor_name VARCHAR(255)",
+			"snapshot_before TEXT",
+			"snapshot_after TEXT",
+		}
+		for _, column := range columns {
+			_, err := db.NewAddColumn().Model((*m20250309090000_AuditEvent)(nil)).ColumnExpr(column).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("add column %s: %w", column, err)
+			}
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		columns := []string{"actor_type", "actor_name", "snapshot_before", "snapshot_after"}
+		for _, column := range columns {
+			_, err := db.NewDropColumn().Model((*m20250309090000_AuditEvent)(nil)).Column(column).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("drop column %s: %w", column, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250311090000_Instance struct {
+	bun.BaseModel `bun:"table:instances"`
+
+	ID          string `bun:"id,pk,type:varchar(36)"`
+	IsTemplate  bool   `bun:"is_template,type:bool"`
+	IsPublic    bool   `bun:"is_public,type:bool"`
+	ShareToken  string `bun:"share_token,nullzero"`
+	Description string `bun:"description,type:text"`
+	Tags        string `bun:"tags,type:text"`
+}
+
+func init() {
+	// Adds read-only templates that can be shared by link and listed in the gallery.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		columns := []string{
+			"is_template BOOLEAN DEFAULT FALSE",
+			"is_public BOOLEAN DEFAULT FALSE",
+			"share_token VARCHAR(64)",
+			"description TEXT",
+			"tags TEXT",
+		}
+		for _, column := range columns {
+			_, err := db.NewAddColumn().Model((*m20250311090000_Instance)(nil)).ColumnExpr(column).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("add column %s: %w", column, err)
+			}
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		columns := []string{"is_template", "is_public", "share_token", "description", "tags"}
+		for _, column := range columns {
+			_, err := db.NewDropColumn().Model((*m20250311090000_Instance)(nil)).Column(column).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("drop column %s: %w", column, err)
+			}
+		}
+		return nil
+	})
+}
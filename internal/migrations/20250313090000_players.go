@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250313090000_Player struct {
+	bun.BaseModel `bun:"table:players"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+
+	ID         string    `bun:"id,pk,type:varchar(36)"`
+	InstanceID string    `bun:"instance_id,notnull"`
+	TeamCode   string    `bun:"team_code,notnull"`
+	Name       string    `bun:"name,type:varchar(255)"`
+	Email      string    `bun:"email,nullzero"`
+	LastSeenAt time.Time `bun:"last_seen_at,nullzero"`
+}
+
+type m20250313090000_CheckIn struct {
+	bun.BaseModel `bun:"table:check_ins"`
+
+	PlayerID string `bun:"player_id,nullzero"`
+}
+
+type m20250313090000_TeamBlockState struct {
+	bun.BaseModel `bun:"table:team_block_states"`
+
+	PlayerID string `bun:"player_id,nullzero"`
+}
+
+func init() {
+	// Adds optional named players and records who made each check-in and block submission.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewCreateTable().Model((*m20250313090000_Player)(nil)).IfNotExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("create table players: %w", err)
+		}
+		_, err = db.NewAddColumn().Model((*m20250313090000_CheckIn)(nil)).ColumnExpr("player_id VARCHAR(36)").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column check_ins.player_id: %w", err)
+		}
+		_, err = db.NewAddColumn().Model((*m20250313090000_TeamBlockState)(nil)).ColumnExpr("player_id VARCHAR(36)").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column team_block_states.player_id: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropColumn().Model((*m20250313090000_TeamBlockState)(nil)).Column("player_id").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column team_block_states.player_id: %w", err)
+		}
+		_, err = db.NewDropColumn().Model((*m20250313090000_CheckIn)(nil)).Column("player_id").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column check_ins.player_id: %w", err)
+		}
+		_, err = db.NewDropTable().Model((*m20250313090000_Player)(nil)).IfExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop table players: %w", err)
+		}
+		return nil
+	})
+}
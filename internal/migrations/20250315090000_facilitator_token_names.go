@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250315090000_FacilitatorToken struct {
+	bun.BaseModel `bun:"table:facilitator_tokens"`
+
+	Name       string    `bun:"name,type:varchar(255)"`
+	CreatedAt  time.Time `bun:"created_at,nullzero"`
+	LastUsedAt time.Time `bun:"last_used_at,nullzero"`
+}
+
+func init() {
+	// Adds names and usage times to facilitator tokens so they can be managed.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		columns := []string{
+			"name VARCHAR(255)",
+			"created_at DATETIME",
+			"last_used_at DATETIME",
+		}
+		for _, column := range columns {
+			_, err := db.NewAddColumn().Model((*m20250315090000_FacilitatorToken)(nil)).ColumnExpr(column).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("add column facilitator_tokens.%s: %w", column, err)
+			}
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		for _, column := range []string{"last_used_at", "created_at", "name"} {
+			_, err := db.NewDropColumn().Model((*m20250315090000_FacilitatorToken)(nil)).Column(column).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("drop column facilitator_tokens.%s: %w", column, err)
+			}
+		}
+		return nil
+	})
+}
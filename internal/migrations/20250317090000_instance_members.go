@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250317090000_InstanceMember struct {
+	bun.BaseModel `bun:"table:instance_members"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+
+	ID          string    `bun:"id,pk,type:varchar(36)"`
+	InstanceID  string    `bun:"instance_id,notnull"`
+	UserID      string    `bun:"user_id,nullzero,type:varchar(36)"`
+	Email       string    `bun:"email,notnull"`
+	Role        string    `bun:"role,type:varchar(16),notnull"`
+	InvitedBy   string    `bun:"invited_by,type:varchar(36)"`
+	InviteToken string    `bun:"invite_token,nullzero"`
+	AcceptedAt  time.Time `bun:"accepted_at,nullzero"`
+}
+
+func init() {
+	// Adds collaborators so instances can be shared with other users.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewCreateTable().Model((*m20250317090000_InstanceMember)(nil)).IfNotExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("create table instance_members: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropTable().Model((*m20250317090000_InstanceMember)(nil)).IfExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop table instance_members: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,82 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250319090000_Organisation struct {
+	bun.BaseModel `bun:"table:organisations"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+
+	ID   string `bun:"id,pk,type:varchar(36)"`
+	Name string `bun:"name,type:varchar(255)"`
+}
+
+type m20250319090000_OrganisationMember struct {
+	bun.BaseModel `bun:"table:organisation_members"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+
+	ID             string `bun:"id,pk,type:varchar(36)"`
+	OrganisationID string `bun:"organisation_id,notnull"`
+	UserID         string `bun:"user_id,notnull"`
+	Role           string `bun:"role,type:varchar(16)"`
+}
+
+type m20250319090000_Instance struct {
+	bun.BaseModel `bun:"table:instances"`
+
+	OrganisationID string `bun:"organisation_id,type:varchar(36)"`
+}
+
+type m20250319090000_User struct {
+	bun.BaseModel `bun:"table:users"`
+
+	CurrentOrganisationID string `bun:"current_organisation_id,type:varchar(36)"`
+}
+
+func init() {
+	// Adds organisations so users and instances can share a workspace.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewCreateTable().Model((*m20250319090000_Organisation)(nil)).IfNotExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("create table organisations: %w", err)
+		}
+		_, err = db.NewCreateTable().Model((*m20250319090000_OrganisationMember)(nil)).IfNotExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("create table organisation_members: %w", err)
+		}
+		_, err = db.NewAddColumn().Model((*m20250319090000_Instance)(nil)).ColumnExpr("organisation_id VARCHAR(36) NOT NULL DEFAULT ''").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column instances.organisation_id: %w", err)
+		}
+		_, err = db.NewAddColumn().Model((*m20250319090000_User)(nil)).ColumnExpr("current_organisation_id VARCHAR(36) NOT NULL DEFAULT ''").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("add column users.current_organisation_id: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropColumn().Model((*m20250319090000_User)(nil)).Column("current_organisation_id").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column users.current_organisation_id: %w", err)
+		}
+		_, err = db.NewDropColumn().Model((*m20250319090000_Instance)(nil)).Column("organisation_id").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop column instances.organisation_id: %w", err)
+		}
+		_, err = db.NewDropTable().Model((*m20250319090000_OrganisationMember)(nil)).IfExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop table organisation_members: %w", err)
+		}
+		_, err = db.NewDropTable().Model((*m20250319090000_Organisation)(nil)).IfExists().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop table organisations: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250321090000_Marker struct {
+	bun.BaseModel `bun:"table:markers"`
+
+	InLibrary      bool   `bun:"in_library,type:bool"`
+	UserID         string `bun:"user_id,type:varchar(36)"`
+	OrganisationID string `bun:"organisation_id,type:varchar(36)"`
+	Notes          string `bun:"notes,type:text"`
+	Photo          string `bun:"photo,type:varchar(255)"`
+	Tags           string `bun:"tags,type:text"`
+}
+
+func init() {
+	// Adds a marker library so markers can be kept and reused across instances.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		columns := []string{
+			"in_library BOOLEAN DEFAULT FALSE",
+			"user_id VARCHAR(36) NOT NULL DEFAULT ''",
+			"organisation_id VARCHAR(36) NOT NULL DEFAULT ''",
+			"notes TEXT",
+			"photo VARCHAR(255)",
+			"tags TEXT",
+		}
+		for _, column := range columns {
+			_, err := db.NewAddColumn().Model((*m20250321090000_Marker)(nil)).ColumnExpr(column).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("add column %s: %w", column, err)
+			}
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		columns := []string{"in_library", "user_id", "organisation_id", "notes", "photo", "tags"}
+		for _, column := range columns {
+			_, err := db.NewDropColumn().Model((*m20250321090000_Marker)(nil)).Column(column).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("drop column %s: %w", column, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+)
+
+type m20250323090000_BonusAward struct {
+	bun.BaseModel `bun:"table:bonus_awards"`
+
+	ID         string `bun:"id,pk,type:varchar(36)"`
+	TeamCode   string `bun:"team_code,notnull"`
+	RuleID     string `bun:"rule_id,notnull"`
+	LocationID string `bun:"location_id,nullzero"`
+}
+
+func init() {
+	// Ensures a bonus rule only rewards a team once at each location.
+	// Awards without a location are compared as an empty location.
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		keep := db.NewSelect().
+			Model((*m20250323090000_BonusAward)(nil)).
+			ColumnExpr("MIN(id)").
+			Group("team_code", "rule_id", "location_id")
+		_, err := db.NewDelete().
+			Model((*m20250323090000_BonusAward)(nil)).
+			Where("id NOT IN (?)", keep).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("remove duplicate bonus awards: %w", err)
+		}
+
+		_, err = db.NewCreateIndex().
+			Model((*m20250323090000_BonusAward)(nil)).
+			Unique().
+			Index("bonus_awards_team_rule_location_idx").
+			ColumnExpr("team_code, rule_id, COALESCE(location_id, '')").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("create index bonus_awards_team_rule_location_idx: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		_, err := db.NewDropIndex().
+			Model((*m20250323090000_BonusAward)(nil)).
+			Index("bonus_awards_team_rule_location_idx").
+			IfExists().
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("drop index bonus_awards_team_rule_location_idx: %w", err)
+		}
+		return nil
+	})
+}
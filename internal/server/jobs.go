@@ -0,0 +1,31 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+)
+
+// tokenCleanupInterval is how often expired facilitator tokens are removed.
+const tokenCleanupInterval = time.Hour
+
+// cleanupFacilitatorTokens removes expired facilitator tokens until ctx is done.
+func cleanupFacilitatorTokens(ctx context.Context, logger *slog.Logger, facilitatorService services.FacilitatorService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := facilitatorService.CleanupExpiredTokens(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("cleaning up expired facilitator tokens", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
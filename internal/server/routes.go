@@ -68,6 +68,14 @@ func setupPlayerRoutes(router chi.Router, playerHandler *players.PlayerHandler)
 		r.Post("/validate", playerHandler.ValidateBlock)
 	})
 
+	router.Route("/hints", func(r chi.Router) {
+		r.Use(func(next http.Handler) http.Handler {
+			return middlewares.TeamMiddleware(playerHandler.TeamService,
+				middlewares.LobbyMiddleware(playerHandler.TeamService, next))
+		})
+		r.Post("/reveal", playerHandler.RevealHint)
+	})
+
 	// Show the lobby page
 	router.Route("/lobby", func(r chi.Router) {
 		r.Use(func(next http.Handler) http.Handler {
@@ -115,8 +123,25 @@ func setupPlayerRoutes(router chi.Router, playerHandler *players.PlayerHandler)
 		r.Get("/{id}", playerHandler.CheckInView)
 	})
 
+	router.Route("/leaderboard", func(r chi.Router) {
+		r.With(func(next http.Handler) http.Handler {
+			return middlewares.TeamMiddleware(playerHandler.TeamService, next)
+		}).Get("/", playerHandler.Leaderboard)
+		// Shared links for projectors and other apps
+		r.Get("/{token}", playerHandler.LeaderboardScreen)
+		r.Get("/{token}/json", playerHandler.LeaderboardJSON)
+	})
+
 	router.Post("/dismiss/{ID}", playerHandler.DismissNotificationPost)
 
+	// Live updates for the team
+	router.Route("/events", func(r chi.Router) {
+		r.Use(func(next http.Handler) http.Handler {
+			return middlewares.TeamMiddleware(playerHandler.TeamService, next)
+		})
+		r.Get("/", playerHandler.Events)
+	})
+
 }
 
 func setupPublicRoutes(router chi.Router, publicHandler *public.PublicHandler) {
@@ -164,7 +189,9 @@ func setupAdminRoutes(router chi.Router, adminHandler *admin.AdminHandler) {
 		r.Use(func(next http.Handler) http.Handler {
 			return middlewares.AdminAuthMiddleware(adminHandler.AuthService, next)
 		})
-		r.Use(middlewares.AdminCheckInstanceMiddleware)
+		r.Use(func(next http.Handler) http.Handler {
+			return middlewares.AdminCheckInstanceMiddleware(adminHandler.CollaboratorService, adminHandler.OrganisationService, next)
+		})
 
 		r.Route("/quickstart", func(r chi.Router) {
 			r.Get("/", adminHandler.Quickstart)
@@ -176,6 +203,7 @@ func setupAdminRoutes(router chi.Router, adminHandler *admin.AdminHandler) {
 			r.Get("/", adminHandler.Activity)
 			r.Get("/teams", adminHandler.ActivityTeamsOverview)
 			r.Get("/team/{teamCode}", adminHandler.TeamActivity)
+			r.Get("/events", adminHandler.ActivityEvents)
 		})
 
 		r.Route("/locations", func(r chi.Router) {
@@ -200,21 +228,73 @@ func setupAdminRoutes(router chi.Router, adminHandler *admin.AdminHandler) {
 				r.Post("/new/{type}", adminHandler.BlockNewPost)
 				r.Get("/{blockID}/edit", adminHandler.BlockEdit)
 				r.Post("/{blockID}/update", adminHandler.BlockEditPost)
+				r.Post("/{blockID}/review", adminHandler.BlockReviewPost)
+				r.Post("/{blockID}/hints", adminHandler.BlockHintsPost)
 				r.Delete("/{blockID}/delete", adminHandler.BlockDelete)
 			})
 		})
 
+		r.Get("/audit", adminHandler.AuditLog)
+
+		r.Route("/collaborators", func(r chi.Router) {
+			r.Get("/", adminHandler.Collaborators)
+			r.Post("/invite", adminHandler.CollaboratorInvitePost)
+			r.Post("/{id}/role", adminHandler.CollaboratorRolePost)
+			r.Delete("/{id}", adminHandler.CollaboratorDelete)
+		})
+		r.Get("/invites/{token}", adminHandler.InviteAccept)
+
+		r.Route("/organisation", func(r chi.Router) {
+			r.Get("/", adminHandler.Organisation)
+			r.Post("/", adminHandler.OrganisationCreate)
+			r.Post("/members", adminHandler.OrganisationMemberPost)
+			r.Post("/members/{id}/role", adminHandler.OrganisationMemberRolePost)
+			r.Delete("/members/{id}", adminHandler.OrganisationMemberDelete)
+			r.Post("/instances/{id}/transfer", adminHandler.OrganisationInstanceTransfer)
+		})
+
+		r.Route("/markers", func(r chi.Router) {
+			r.Get("/", adminHandler.Markers)
+			r.Post("/", adminHandler.MarkerCreate)
+			r.Post("/{code}", adminHandler.MarkerUpdate)
+			r.Delete("/{code}", adminHandler.MarkerDelete)
+			r.Post("/{code}/attach", adminHandler.MarkerAttach)
+		})
+
+		r.Route("/reviews", func(r chi.Router) {
+			r.Get("/", adminHandler.Reviews)
+			r.Post("/approve", adminHandler.ReviewApprovePost)
+			r.Post("/reject", adminHandler.ReviewRejectPost)
+		})
+
 		r.Route("/teams", func(r chi.Router) {
 			r.Get("/", adminHandler.Teams)
 			r.Post("/add", adminHandler.TeamsAdd)
 			r.Delete("/delete", adminHandler.TeamsDelete)
 			r.Post("/reset", adminHandler.TeamsReset)
+			r.Post("/{code}/route", adminHandler.TeamRoutePost)
+			r.Post("/{code}/extra-time", adminHandler.TeamExtraTimePost)
+			r.Post("/{code}/points", adminHandler.TeamPointsPost)
+			r.Post("/{code}/points/{id}/undo", adminHandler.TeamPointsUndo)
+			r.Post("/{code}/blocks/{blockID}/complete", adminHandler.TeamBlockCompletePost)
+			r.Post("/{code}/checkins/{locationID}/revoke", adminHandler.TeamCheckInRevokePost)
+			r.Post("/{code}/check-out/clear", adminHandler.TeamCheckOutClearPost)
+			r.Route("/routes", func(r chi.Router) {
+				r.Post("/generate", adminHandler.RoutesGenerate)
+				r.Post("/new", adminHandler.RouteNew)
+				r.Post("/{id}", adminHandler.RouteEditPost)
+				r.Delete("/{id}", adminHandler.RouteDelete)
+			})
 		})
 
 		r.Route("/experience", func(r chi.Router) {
 			r.Get("/", adminHandler.Experience)
 			r.Post("/", adminHandler.ExperiencePost)
 			r.Post("/preview", adminHandler.ExperiencePreview)
+			r.Post("/bonus", adminHandler.BonusRuleNew)
+			r.Post("/bonus/{id}", adminHandler.BonusRuleEditPost)
+			r.Delete("/bonus/{id}", adminHandler.BonusRuleDelete)
+			r.Post("/leaderboard-link", adminHandler.LeaderboardLinkPost)
 		})
 
 		r.Route("/instances", func(r chi.Router) {
@@ -223,8 +303,22 @@ func setupAdminRoutes(router chi.Router, adminHandler *admin.AdminHandler) {
 			r.Get("/{id}", adminHandler.Instances)
 			r.Post("/{id}", adminHandler.Instances)
 			r.Get("/{id}/switch", adminHandler.InstanceSwitch)
+			r.Get("/{id}/export", adminHandler.InstanceExport)
+			r.Post("/{id}/leave", adminHandler.InstanceLeave)
+			r.Post("/{id}/move", adminHandler.InstanceMove)
+			r.Get("/workspace/{id}", adminHandler.WorkspaceSwitch)
 			r.Post("/delete", adminHandler.InstanceDelete)
 			r.Post("/duplicate", adminHandler.InstanceDuplicate)
+			r.Post("/import", adminHandler.InstanceImport)
+			r.Post("/publish", adminHandler.TemplatePublish)
+		})
+
+		r.Route("/templates", func(r chi.Router) {
+			r.Get("/", adminHandler.TemplateGallery)
+			r.Post("/update", adminHandler.TemplateUpdate)
+			r.Post("/delete", adminHandler.TemplateDelete)
+			r.Get("/{token}", adminHandler.TemplatePreview)
+			r.Post("/{token}/clone", adminHandler.TemplateClone)
 		})
 
 		r.Route("/markdown", func(r chi.Router) {
@@ -245,6 +339,8 @@ func setupAdminRoutes(router chi.Router, adminHandler *admin.AdminHandler) {
 		r.Route("/facilitator", func(r chi.Router) {
 			r.Get("/create-link", adminHandler.FacilitatorShowModal)
 			r.Post("/create-link", adminHandler.FacilitatorCreateTokenLink)
+			r.Get("/tokens", adminHandler.FacilitatorTokens)
+			r.Delete("/tokens/{id}", adminHandler.FacilitatorTokenRevoke)
 		})
 
 		r.Route("/media", func(r chi.Router) {
@@ -259,5 +355,16 @@ func setupFacilitatorRoutes(router chi.Router, adminHandler *admin.AdminHandler)
 	router.Route("/facilitator", func(r chi.Router) {
 		r.Get("/login/{token}", adminHandler.FacilitatorLogin)
 		r.Get("/dashboard", adminHandler.FacilitatorDashboard)
+		r.Route("/locations/{locationID}", func(r chi.Router) {
+			r.Get("/", adminHandler.FacilitatorStation)
+			r.Post("/check-in", adminHandler.FacilitatorStationCheckInPost)
+			r.Post("/check-out", adminHandler.FacilitatorStationCheckOutPost)
+			r.Post("/complete", adminHandler.FacilitatorStationCompletePost)
+		})
+		r.Route("/reviews", func(r chi.Router) {
+			r.Get("/", adminHandler.FacilitatorReviews)
+			r.Post("/approve", adminHandler.FacilitatorReviewApprovePost)
+			r.Post("/reject", adminHandler.FacilitatorReviewRejectPost)
+		})
 	})
 }
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
 	admin "github.com/nathanhollows/Rapua/v3/internal/handlers/admin"
 	players "github.com/nathanhollows/Rapua/v3/internal/handlers/players"
 	public "github.com/nathanhollows/Rapua/v3/internal/handlers/public"
@@ -23,19 +24,33 @@ var server *http.Server
 
 func Start(logger *slog.Logger,
 	assetGenerator services.AssetGenerator,
+	auditService services.AuditService,
 	authService services.AuthService,
 	blockService services.BlockService,
+	bonusService services.BonusService,
 	checkInService services.CheckInService,
 	clueService services.ClueService,
+	collaboratorService services.CollaboratorService,
 	emailService services.EmailService,
+	eventBroker *events.Broker,
 	facilitatorService services.FacilitatorService,
 	gameManagerService services.GameManagerService,
 	gameplayService services.GameplayService,
+	hintService services.HintService,
+	instanceBundleService services.InstanceBundleService,
 	instanceService services.InstanceService,
+	leaderboardService services.LeaderboardService,
 	locationService services.LocationService,
+	markerService services.MarkerService,
 	navigationService services.NavigationService,
 	notificationService services.NotificationService,
+	organisationService services.OrganisationService,
+	playerService services.PlayerService,
+	reviewService services.ReviewService,
+	routeService services.RouteService,
+	stationService services.StationService,
 	teamService services.TeamService,
+	templateService services.TemplateService,
 	uploadService services.UploadService,
 	userService services.UserService,
 ) {
@@ -51,25 +66,45 @@ func Start(logger *slog.Logger,
 	playerHandler := players.NewPlayerHandler(
 		logger,
 		blockService,
+		eventBroker,
 		gameplayService,
+		hintService,
+		leaderboardService,
 		notificationService,
+		playerService,
 		teamService,
+		uploadService,
 	)
 
 	// Admin routes
 	adminHandler := admin.NewAdminHandler(
 		logger,
 		assetGenerator,
+		auditService,
 		authService,
 		blockService,
+		bonusService,
 		clueService,
+		collaboratorService,
+		eventBroker,
 		facilitatorService,
 		gameManagerService,
 		gameplayService,
+		hintService,
+		instanceBundleService,
 		instanceService,
+		leaderboardService,
 		locationService,
+		markerService,
+		navigationService,
 		notificationService,
+		organisationService,
+		playerService,
+		reviewService,
+		routeService,
+		stationService,
 		teamService,
+		templateService,
 		uploadService,
 		userService,
 	)
@@ -94,8 +129,14 @@ func Start(logger *slog.Logger,
 		}
 	}()
 
+	// Background jobs stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go cleanupFacilitatorTokens(jobsCtx, logger, facilitatorService, tokenCleanupInterval)
+
 	logger.Info("Server started", "addr", os.Getenv("SERVER_ADDR"))
 	<-killSig
+	stopJobs()
 
 	slog.Info("Shutting down server")
 
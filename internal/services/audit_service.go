@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nathanhollows/Rapua/v3/blocks"
+	"github.com/nathanhollows/Rapua/v3/internal/contextkeys"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/uptrace/bun"
+)
+
+type AuditService interface {
+	// Find returns the audit log for an instance, newest first
+	Find(ctx context.Context, instanceID string, filter models.AuditFilter) ([]models.AuditEvent, error)
+	// FindActors returns everyone who has changed an instance
+	FindActors(ctx context.Context, instanceID string) ([]models.Actor, error)
+}
+
+type auditService struct {
+	auditRepo repositories.AuditRepository
+}
+
+// NewAuditService creates a new AuditService.
+func NewAuditService(auditRepo repositories.AuditRepository) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// Find returns the audit log for an instance, newest first.
+func (s *auditService) Find(ctx context.Context, instanceID string, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+	return s.auditRepo.Find(ctx, instanceID, filter)
+}
+
+// FindActors returns everyone who has changed an instance.
+func (s *auditService) FindActors(ctx context.Context, instanceID string) ([]models.Actor, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+	return s.auditRepo.FindActors(ctx, instanceID)
+}
+
+// WithActor returns a context that records changes as made by the actor.
+func WithActor(ctx context.Context, actor models.Actor) context.Context {
+	return context.WithValue(ctx, contextkeys.ActorKey, actor)
+}
+
+// ActorFromContext returns the actor making changes, if there is one.
+// Players never have an actor, so their changes are not audited.
+func ActorFromContext(ctx context.Context) (models.Actor, bool) {
+	actor, ok := ctx.Value(contextkeys.ActorKey).(models.Actor)
+	return actor, ok && actor.ID != ""
+}
+
+// UserActor describes an admin making changes.
+func UserActor(user *models.User) models.Actor {
+	return models.Actor{
+		Type:       models.ActorUser,
+		ID:         user.ID,
+		Name:       user.Name,
+		InstanceID: user.CurrentInstanceID,
+	}
+}
+
+// FacilitatorActor describes a facilitator making changes.
+// The token is hashed so the audit log cannot be used to log in.
+func FacilitatorActor(token *models.FacilitatorToken) models.Actor {
+	id := FacilitatorTokenID(token)
+	name := token.Name
+	if name == "" {
+		name = "Facilitator " + id[:6]
+	}
+	return models.Actor{
+		Type:       models.ActorFacilitator,
+		ID:         id,
+		Name:       name,
+		InstanceID: token.InstanceID,
+	}
+}
+
+// describeActor fills in the actor's type and name from the context.
+func describeActor(ctx context.Context, event *models.AuditEvent) *models.AuditEvent {
+	actor, ok := ActorFromContext(ctx)
+	if ok && actor.ID == event.ActorID {
+		event.ActorType = actor.Type
+		event.ActorName = actor.Name
+	}
+	return event
+}
+
+// recordAudit adds an event to the audit log if the context has an actor.
+// The event is written within tx when one is given so it is only kept if the
+// change is. Events without an instance fall back to the actor's instance.
+func recordAudit(ctx context.Context, repo repositories.AuditRepository, tx *bun.Tx, event models.AuditEvent, before, after any) error {
+	actor, ok := ActorFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	event.ActorID = actor.ID
+	event.ActorType = actor.Type
+	event.ActorName = actor.Name
+	if event.InstanceID == "" {
+		event.InstanceID = actor.InstanceID
+	}
+	if event.InstanceID == "" {
+		return nil
+	}
+
+	err := event.SetChange(before, after)
+	if err != nil {
+		return fmt.Errorf("recording change: %w", err)
+	}
+	if tx != nil {
+		return repo.Create(ctx, tx, &event)
+	}
+	return repo.Log(ctx, &event)
+}
+
+// auditLocation returns the parts of a location shown in the audit log.
+func auditLocation(location *models.Location) map[string]any {
+	return map[string]any{
+		"Name":          location.Name,
+		"Points":        location.Points,
+		"Latitude":      location.Marker.Lat,
+		"Longitude":     location.Marker.Lng,
+		"CheckInRadius": location.CheckInRadius,
+		"Capacity":      location.Capacity,
+		"UnlockRules":   location.UnlockRules,
+	}
+}
+
+// auditTeam returns the parts of a team shown in the audit log.
+func auditTeam(team *models.Team) map[string]any {
+	return map[string]any{
+		"Code":       team.Code,
+		"Name":       team.Name,
+		"Points":     team.Points,
+		"HasStarted": team.HasStarted,
+		"ExtraTime":  team.ExtraTime,
+		"RouteID":    team.RouteID,
+	}
+}
+
+// auditInstance returns the parts of an instance shown in the audit log.
+func auditInstance(instance *models.Instance) map[string]any {
+	return map[string]any{
+		"Name":      instance.Name,
+		"StartTime": instance.StartTime.Time,
+		"EndTime":   instance.EndTime.Time,
+	}
+}
+
+// auditBlock returns the parts of a block shown in the audit log.
+func auditBlock(block blocks.Block) map[string]any {
+	return map[string]any{
+		"Type":           block.GetType(),
+		"Points":         block.GetPoints(),
+		"ReviewRequired": block.RequiresReview(),
+		"Data":           block.GetData(),
+	}
+}
@@ -0,0 +1,79 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditService(t *testing.T) {
+	dbc, cleanup := setupDB(t)
+	defer cleanup()
+
+	transactor := db.NewTransactor(dbc)
+	auditRepo := repositories.NewAuditRepository(dbc)
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	blockRepo := repositories.NewBlockRepository(dbc, blockStateRepo)
+	locationService := services.NewLocationService(
+		transactor,
+		repositories.NewClueRepository(dbc),
+		repositories.NewHintRepository(dbc),
+		repositories.NewLocationRepository(dbc),
+		repositories.NewMarkerRepository(dbc),
+		blockRepo,
+		auditRepo,
+	)
+	auditService := services.NewAuditService(auditRepo)
+
+	instanceID := gofakeit.UUID()
+	actor := models.Actor{Type: models.ActorUser, ID: gofakeit.UUID(), Name: "Sam", InstanceID: instanceID}
+
+	// Changes without an actor, such as those made by players, are not recorded
+	_, err := locationService.CreateLocation(context.Background(), instanceID, "Quiet", 0, 0, 10)
+	require.NoError(t, err)
+	events, err := auditService.Find(context.Background(), instanceID, models.AuditFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	ctx := services.WithActor(context.Background(), actor)
+	location, err := locationService.CreateLocation(ctx, instanceID, "Library", 0, 0, 10)
+	require.NoError(t, err)
+	err = locationService.UpdateLocation(ctx, &location, services.LocationUpdateData{
+		Name:          "Old Library",
+		Points:        20,
+		CheckInRadius: -1,
+		Capacity:      -1,
+		Latitude:      location.Marker.Lat,
+		Longitude:     location.Marker.Lng,
+	})
+	require.NoError(t, err)
+
+	events, err = auditService.Find(context.Background(), instanceID, models.AuditFilter{ActorID: actor.ID})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, models.AuditLocationUpdated, events[0].Action)
+	assert.Equal(t, models.AuditLocationCreated, events[1].Action)
+	assert.Equal(t, "Sam", events[0].ActorName)
+	assert.Equal(t, []models.AuditChange{
+		{Field: "Name", Before: "Library", After: "Old Library"},
+		{Field: "Points", Before: "10", After: "20"},
+	}, events[0].Changes())
+
+	// Facilitators are recorded without their login token
+	token := &models.FacilitatorToken{Token: "secret-token", InstanceID: instanceID}
+	facilitator := services.FacilitatorActor(token)
+	assert.Equal(t, models.ActorFacilitator, facilitator.Type)
+	assert.NotContains(t, facilitator.ID, token.Token)
+
+	actors, err := auditService.FindActors(context.Background(), instanceID)
+	require.NoError(t, err)
+	require.Len(t, actors, 1)
+	assert.Equal(t, actor.ID, actors[0].ID)
+}
@@ -9,10 +9,14 @@ import (
 
 	"github.com/nathanhollows/Rapua/v3/blocks"
 	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
 	"github.com/nathanhollows/Rapua/v3/models"
 	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/uptrace/bun"
 )
 
+var ErrNotCheckedIn = errors.New("team has not checked in at this location")
+
 type BlockService interface {
 	// NewBlock creates a new content block of the specified type for the given location
 	NewBlock(ctx context.Context, locationID string, blockType string) (blocks.Block, error)
@@ -31,11 +35,21 @@ type BlockService interface {
 	// FindByLocationIDAndTeamCodeWithState fetches all blocks and their states
 	// for the given location and team
 	FindByLocationIDAndTeamCodeWithState(ctx context.Context, locationID, teamCode string) ([]blocks.Block, map[string]blocks.PlayerState, error)
+	// FindIncomplete fetches the interactive blocks a team has not completed
+	// at the locations they have checked in to
+	FindIncomplete(ctx context.Context, team *models.Team) ([]IncompleteBlock, error)
 
 	// UpdateBlock updates the data for the given block
 	UpdateBlock(ctx context.Context, block blocks.Block, data map[string][]string) (blocks.Block, error)
 	// UpdateState updates the player state for a block
 	UpdateState(ctx context.Context, state blocks.PlayerState) (blocks.PlayerState, error)
+	// CompleteState saves a team's completed state for a block and pays its points
+	// It reports whether the state was saved, which it is not if the block was already complete
+	CompleteState(ctx context.Context, instanceID string, block blocks.Block, state blocks.PlayerState) (bool, error)
+	// CompleteBlock marks a block complete for a team on an admin's behalf
+	CompleteBlock(ctx context.Context, instanceID, blockID, teamCode, actorID string) error
+	// SetRequiresReview sets whether submissions for a block must be reviewed by a facilitator
+	SetRequiresReview(ctx context.Context, block blocks.Block, required bool) (blocks.Block, error)
 	// ReorderBlocks changes the display/order of blocks at a location
 	ReorderBlocks(ctx context.Context, locationID string, blockIDs []string) error
 
@@ -47,17 +61,47 @@ type BlockService interface {
 	// CheckValidationRequiredForCheckIn checks if any blocks still require validation for a check-in
 	CheckValidationRequiredForCheckIn(ctx context.Context, locationID, teamCode string) (bool, error)
 }
+
+// IncompleteBlock is an interactive block a team has not completed.
+type IncompleteBlock struct {
+	Location models.Location
+	Block    blocks.Block
+	State    blocks.PlayerState
+}
+
 type blockService struct {
 	transactor     db.Transactor
+	broker         *events.Broker
+	auditRepo      repositories.AuditRepository
 	blockRepo      repositories.BlockRepository
 	blockStateRepo repositories.BlockStateRepository
+	checkInRepo    repositories.CheckInRepository
+	pointsRepo     repositories.PointsRepository
+	teamRepo       repositories.TeamRepository
+	bonusService   BonusService
 }
 
-func NewBlockService(transactor db.Transactor, blockRepo repositories.BlockRepository, blockStateRepo repositories.BlockStateRepository) BlockService {
+func NewBlockService(
+	transactor db.Transactor,
+	broker *events.Broker,
+	auditRepo repositories.AuditRepository,
+	blockRepo repositories.BlockRepository,
+	blockStateRepo repositories.BlockStateRepository,
+	checkInRepo repositories.CheckInRepository,
+	pointsRepo repositories.PointsRepository,
+	teamRepo repositories.TeamRepository,
+	bonusService BonusService,
+) BlockService {
 	return &blockService{
 		transactor:     transactor,
+		broker:         broker,
+		auditRepo:      auditRepo,
 		blockRepo:      blockRepo,
 		blockStateRepo: blockStateRepo,
+		checkInRepo:    checkInRepo,
+		pointsRepo:     pointsRepo,
+		teamRepo:       teamRepo,
+		bonusService:   bonusService,
 	}
 }
 
@@ -99,6 +143,11 @@ func (s *blockService) NewBlock(ctx context.Context, locationID string, blockTyp
 		return nil, fmt.Errorf("failed to store block of type %s: %w", blockType, err)
 	}
 
+	err = s.audit(ctx, nil, models.AuditBlockCreated, newBlock, nil, auditBlock(newBlock))
+	if err != nil {
+		return nil, err
+	}
+
 	return newBlock, nil
 }
 
@@ -136,11 +185,35 @@ func (s *blockService) NewMockBlockState(ctx context.Context, blockID, teamCode
 
 // UpdateBlock updates a block.
 func (s *blockService) UpdateBlock(ctx context.Context, block blocks.Block, data map[string][]string) (blocks.Block, error) {
+	before := auditBlock(block)
 	err := block.UpdateBlockData(data)
 	if err != nil {
 		return nil, fmt.Errorf("updating block data: %w", err)
 	}
-	return s.blockRepo.Update(ctx, block)
+	return s.updateBlock(ctx, block, before)
+}
+
+// SetRequiresReview sets whether submissions for a block must be reviewed by a facilitator.
+func (s *blockService) SetRequiresReview(ctx context.Context, block blocks.Block, required bool) (blocks.Block, error) {
+	if required && !block.RequiresValidation() {
+		return nil, errors.New("only interactive blocks can require review")
+	}
+	before := auditBlock(block)
+	block.SetRequiresReview(required)
+	return s.updateBlock(ctx, block, before)
+}
+
+// updateBlock saves a block and records the change.
+func (s *blockService) updateBlock(ctx context.Context, block blocks.Block, before map[string]any) (blocks.Block, error) {
+	block, err := s.blockRepo.Update(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	err = s.audit(ctx, nil, models.AuditBlockUpdated, block, before, auditBlock(block))
+	if err != nil {
+		return nil, err
+	}
+	return block, nil
 }
 
 // DeleteBlock deletes a block.
@@ -159,11 +232,25 @@ func (s *blockService) DeleteBlock(ctx context.Context, blockID string) error {
 		}
 	}()
 
+	// Deleting a missing block is not an error, but there is nothing to record
+	block, err := s.blockRepo.GetByID(ctx, blockID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		tx.Rollback()
+		return fmt.Errorf("finding block: %w", err)
+	}
+
 	if err := s.blockRepo.Delete(ctx, tx, blockID); err != nil {
 		tx.Rollback()
 		return fmt.Errorf("deleting block: %w", err)
 	}
 
+	if block != nil {
+		if err := s.audit(ctx, tx, models.AuditBlockDeleted, block, auditBlock(block), nil); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
 	if err := s.blockStateRepo.DeleteByBlockID(ctx, tx, blockID); err != nil {
 		tx.Rollback()
 		return fmt.Errorf("deleting block state: %w", err)
@@ -174,7 +261,15 @@ func (s *blockService) DeleteBlock(ctx context.Context, blockID string) error {
 
 // ReorderBlocks reorders the blocks in a location.
 func (s *blockService) ReorderBlocks(ctx context.Context, locationID string, blockIDs []string) error {
-	return s.blockRepo.Reorder(ctx, locationID, blockIDs)
+	err := s.blockRepo.Reorder(ctx, locationID, blockIDs)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, s.auditRepo, nil, models.AuditEvent{
+		Action:     models.AuditBlockReordered,
+		EntityType: "location",
+		EntityID:   locationID,
+	}, nil, blockIDs)
 }
 
 func (s *blockService) FindByLocationIDAndTeamCodeWithState(ctx context.Context, locationID, teamCode string) ([]blocks.Block, map[string]blocks.PlayerState, error) {
@@ -195,6 +290,34 @@ func (s *blockService) FindByLocationIDAndTeamCodeWithState(ctx context.Context,
 	return foundBlocks, blockStates, nil
 }
 
+// FindIncomplete fetches the interactive blocks a team has not completed
+// at the locations they have checked in to.
+// The team's check-ins must be loaded with their locations.
+func (s *blockService) FindIncomplete(ctx context.Context, team *models.Team) ([]IncompleteBlock, error) {
+	var incomplete []IncompleteBlock
+	for _, checkIn := range team.CheckIns {
+		found, states, err := s.FindByLocationIDAndTeamCodeWithState(ctx, checkIn.LocationID, team.Code)
+		if err != nil {
+			return nil, fmt.Errorf("finding blocks for location %s: %w", checkIn.LocationID, err)
+		}
+		for _, block := range found {
+			if !block.RequiresValidation() {
+				continue
+			}
+			state := states[block.GetID()]
+			if state != nil && state.IsComplete() {
+				continue
+			}
+			incomplete = append(incomplete, IncompleteBlock{
+				Location: checkIn.Location,
+				Block:    block,
+				State:    state,
+			})
+		}
+	}
+	return incomplete, nil
+}
+
 func (s *blockService) GetBlockWithStateByBlockIDAndTeamCode(ctx context.Context, blockID, teamCode string) (blocks.Block, blocks.PlayerState, error) {
 	if blockID == "" || teamCode == "" {
 		return nil, nil, fmt.Errorf("blockID and teamCode must be set, got blockID: %s, teamCode: %s", blockID, teamCode)
@@ -213,6 +336,7 @@ func (s *blockService) ConvertBlockToModel(block blocks.Block) models.Block {
 		Data:               block.GetData(),
 		Points:             block.GetPoints(),
 		ValidationRequired: block.RequiresValidation(),
+		ReviewRequired:     block.RequiresReview(),
 	}
 }
 
@@ -256,5 +380,271 @@ func (s *blockService) CheckValidationRequiredForCheckIn(ctx context.Context, lo
 
 // UpdateState updates the player state for a block.
 func (s *blockService) UpdateState(ctx context.Context, state blocks.PlayerState) (blocks.PlayerState, error) {
-	return s.blockStateRepo.Update(ctx, state)
+	state, err := s.blockStateRepo.Update(ctx, state)
+	if err != nil {
+		return state, err
+	}
+
+	// Credit the submission to the player who made it
+	if playerID := PlayerIDFromContext(ctx); playerID != "" {
+		err = s.blockStateRepo.SetPlayer(ctx, state.GetBlockID(), state.GetPlayerID(), playerID)
+		if err != nil {
+			return state, fmt.Errorf("recording player: %w", err)
+		}
+	}
+
+	// The state is already saved, so a missing team only means
+	// there is nobody to tell about the change
+	team, err := s.teamRepo.GetByCode(ctx, state.GetPlayerID())
+	if err == nil {
+		s.broker.Publish(events.Event{
+			Type:       events.BlockStateUpdated,
+			InstanceID: team.InstanceID,
+			TeamCode:   team.Code,
+			Payload:    state,
+		})
+	}
+
+	return state, nil
+}
+
+// CompleteState saves a team's completed state for a block and pays its points.
+// The state and the points are saved together, so a block completed by two
+// requests at once only pays out once.
+func (s *blockService) CompleteState(ctx context.Context, instanceID string, block blocks.Block, state blocks.PlayerState) (bool, error) {
+	if instanceID == "" {
+		return false, NewValidationError("instanceID")
+	}
+	if !state.IsComplete() {
+		return false, errors.New("state must be complete")
+	}
+
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return false, fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err := tx.Rollback()
+			log.Printf("recovered from panic, rolling back transaction: %v", err)
+			panic(p)
+		}
+	}()
+
+	saved, transactions, err := s.saveCompletion(ctx, tx, instanceID, "", block, state)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if !saved {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	// Credit the submission to the player who made it
+	if playerID := PlayerIDFromContext(ctx); playerID != "" {
+		err = s.blockStateRepo.SetPlayer(ctx, state.GetBlockID(), state.GetPlayerID(), playerID)
+		if err != nil {
+			return true, fmt.Errorf("recording player: %w", err)
+		}
+	}
+
+	s.broker.Publish(events.Event{
+		Type:       events.BlockStateUpdated,
+		InstanceID: instanceID,
+		TeamCode:   state.GetPlayerID(),
+		Payload:    state,
+	})
+	publishPoints(s.broker, transactions...)
+	return true, nil
+}
+
+// saveCompletion saves a completed state and records the points it earned
+// as part of a larger change. It reports whether the state was saved, which
+// it is not if the block was already complete, and returns any points
+// transactions it recorded.
+func (s *blockService) saveCompletion(ctx context.Context, tx *bun.Tx, instanceID, actorID string, block blocks.Block, state blocks.PlayerState) (bool, []models.PointsTransaction, error) {
+	saved, err := s.blockStateRepo.SaveCompletion(ctx, tx, state)
+	if err != nil {
+		return false, nil, fmt.Errorf("saving block state: %w", err)
+	}
+	if !saved || state.GetPointsAwarded() == 0 {
+		return saved, nil, nil
+	}
+
+	transaction := models.PointsTransaction{
+		InstanceID: instanceID,
+		TeamCode:   state.GetPlayerID(),
+		Amount:     state.GetPointsAwarded(),
+		Reason:     fmt.Sprint("Completed block ", block.GetName()),
+		Source:     models.BlockSource,
+		SourceID:   block.GetID(),
+		ActorID:    actorID,
+	}
+	err = s.pointsRepo.Create(ctx, tx, &transaction)
+	if err != nil {
+		return false, nil, fmt.Errorf("recording points: %w", err)
+	}
+	if err := s.teamRepo.ReconcilePoints(ctx, tx, instanceID, []string{state.GetPlayerID()}); err != nil {
+		return false, nil, fmt.Errorf("reconciling points: %w", err)
+	}
+	return true, []models.PointsTransaction{transaction}, nil
+}
+
+// CompleteBlock marks a block complete for a team on an admin's behalf.
+// The block's points are awarded, a pending review is approved, and the
+// change is recorded in the audit log. Completing the last block at a
+// location earns the same bonuses as it would for players.
+func (s *blockService) CompleteBlock(ctx context.Context, instanceID, blockID, teamCode, actorID string) error {
+	if instanceID == "" {
+		return NewValidationError("instanceID")
+	}
+	if actorID == "" {
+		return NewValidationError("actorID")
+	}
+
+	team, err := s.teamRepo.GetByCode(ctx, teamCode)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrTeamNotFound, err)
+	}
+	if team.InstanceID != instanceID {
+		return ErrPermissionDenied
+	}
+	teamCode = team.Code
+
+	block, err := s.blockRepo.GetByID(ctx, blockID)
+	if err != nil {
+		return fmt.Errorf("getting block: %w", err)
+	}
+
+	// Blocks are only shown to teams who have checked in at the location
+	checkedIn := false
+	for _, checkIn := range team.CheckIns {
+		if checkIn.LocationID == block.GetLocationID() {
+			checkedIn = true
+			break
+		}
+	}
+	if !checkedIn {
+		return ErrNotCheckedIn
+	}
+
+	found, states, err := s.FindByLocationIDAndTeamCodeWithState(ctx, block.GetLocationID(), teamCode)
+	if err != nil {
+		return fmt.Errorf("finding blocks for location: %w", err)
+	}
+
+	state := states[blockID]
+	if state == nil {
+		state, err = s.blockStateRepo.NewBlockState(ctx, blockID, teamCode)
+		if err != nil {
+			return fmt.Errorf("creating new block state: %w", err)
+		}
+	}
+	if state.IsComplete() {
+		return nil
+	}
+
+	// A pending submission keeps the points it earned, otherwise the
+	// block's full points are awarded
+	if state.GetReviewStatus() == blocks.ReviewStatusPending {
+		state.SetReviewStatus(blocks.ReviewStatusApproved)
+	} else {
+		state.SetPointsAwarded(block.GetPoints())
+	}
+	state.SetComplete(true)
+
+	// The check in is complete if this was the last block holding it up
+	unfinishedCheckIn := false
+	for _, other := range found {
+		if other.GetID() == blockID || !other.RequiresValidation() {
+			continue
+		}
+		if states[other.GetID()] == nil || !states[other.GetID()].IsComplete() {
+			unfinishedCheckIn = true
+			break
+		}
+	}
+
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err := tx.Rollback()
+			log.Printf("recovered from panic, rolling back transaction: %v", err)
+			panic(p)
+		}
+	}()
+
+	saved, transactions, err := s.saveCompletion(ctx, tx, instanceID, actorID, block, state)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if !saved {
+		// The block was completed while this request was running
+		tx.Rollback()
+		return nil
+	}
+
+	if !unfinishedCheckIn {
+		if err := s.checkInRepo.CompleteBlocks(ctx, tx, teamCode, block.GetLocationID()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("completing check in: %w", err)
+		}
+	}
+
+	err = s.auditRepo.Create(ctx, tx, describeActor(ctx, &models.AuditEvent{
+		InstanceID: instanceID,
+		ActorID:    actorID,
+		Action:     models.AuditBlockCompleted,
+		EntityType: "block",
+		EntityID:   blockID,
+		TeamCode:   teamCode,
+	}))
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	s.broker.Publish(events.Event{
+		Type:       events.BlockStateUpdated,
+		InstanceID: instanceID,
+		TeamCode:   teamCode,
+		Payload:    state,
+	})
+	publishPoints(s.broker, transactions...)
+
+	if !unfinishedCheckIn {
+		err = s.bonusService.BlocksCompleted(ctx, team, block.GetLocationID())
+		if err != nil {
+			return fmt.Errorf("awarding bonus points: %w", err)
+		}
+	}
+	return nil
+}
+
+// audit records a change to a block.
+func (s *blockService) audit(ctx context.Context, tx *bun.Tx, action models.AuditAction, block blocks.Block, before, after any) error {
+	err := recordAudit(ctx, s.auditRepo, tx, models.AuditEvent{
+		Action:     action,
+		EntityType: "block",
+		EntityID:   block.GetID(),
+	}, before, after)
+	if err != nil {
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+	return nil
 }
@@ -7,9 +7,12 @@ import (
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/nathanhollows/Rapua/v3/blocks"
 	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
 	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
 	"github.com/nathanhollows/Rapua/v3/repositories"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setupBlocksService(t *testing.T) (services.BlockService, func()) {
@@ -17,10 +20,20 @@ func setupBlocksService(t *testing.T) (services.BlockService, func()) {
 
 	transactor := db.NewTransactor(dbc)
 
+	auditRepo := repositories.NewAuditRepository(dbc)
 	blockStateRepo := repositories.NewBlockStateRepository(dbc)
 	blocksRepo := repositories.NewBlockRepository(dbc, blockStateRepo)
-
-	blocksService := services.NewBlockService(transactor, blocksRepo, blockStateRepo)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	hintRepo := repositories.NewHintRepository(dbc)
+	locationRepo := repositories.NewLocationRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+
+	broker := events.NewBroker()
+	teamService := services.NewTeamService(transactor, broker, teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	bonusService := services.NewBonusService(transactor, broker, bonusRepo, checkInRepo, hintRepo, locationRepo, pointsRepo, teamRepo, teamService)
+	blocksService := services.NewBlockService(transactor, broker, auditRepo, blocksRepo, blockStateRepo, checkInRepo, pointsRepo, teamRepo, bonusService)
 
 	return blocksService, cleanup
 }
@@ -645,3 +658,148 @@ func TestBlockService_CheckValidationRequiredForCheckIn(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockService_CompleteBlock(t *testing.T) {
+	_, svc, teamService, _, dbc, cleanup := setupReviewService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	userID := gofakeit.UUID()
+	team, block := createPendingSubmission(t, dbc, svc, teamService, instanceID)
+
+	// A different instance cannot complete the block
+	err := svc.CompleteBlock(ctx, gofakeit.UUID(), block.GetID(), team.Code, userID)
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	err = svc.CompleteBlock(ctx, instanceID, block.GetID(), team.Code, "")
+	assert.ErrorIs(t, err, services.ErrInvalidArgument)
+
+	// Blocks at locations the team has not visited cannot be completed
+	other, err := svc.NewBlock(ctx, gofakeit.UUID(), "answer")
+	require.NoError(t, err)
+	err = svc.CompleteBlock(ctx, instanceID, other.GetID(), team.Code, userID)
+	assert.ErrorIs(t, err, services.ErrNotCheckedIn)
+
+	found, err := teamService.FindTeamByCode(ctx, team.Code)
+	require.NoError(t, err)
+	incomplete, err := svc.FindIncomplete(ctx, found)
+	require.NoError(t, err)
+	require.Len(t, incomplete, 1)
+	assert.Equal(t, block.GetID(), incomplete[0].Block.GetID())
+
+	err = svc.CompleteBlock(ctx, instanceID, block.GetID(), team.Code, userID)
+	require.NoError(t, err)
+
+	_, state, err := svc.GetBlockWithStateByBlockIDAndTeamCode(ctx, block.GetID(), team.Code)
+	require.NoError(t, err)
+	assert.True(t, state.IsComplete())
+	assert.Equal(t, blocks.ReviewStatusApproved, state.GetReviewStatus(), "pending reviews are approved")
+
+	found, err = teamService.FindTeamByCode(ctx, team.Code)
+	require.NoError(t, err)
+	assert.Equal(t, 10, found.Points)
+	assert.True(t, found.CheckIns[0].BlocksCompleted, "the check in is complete once its blocks are")
+
+	incomplete, err = svc.FindIncomplete(ctx, found)
+	require.NoError(t, err)
+	assert.Empty(t, incomplete)
+
+	// Completing the block again does not award more points
+	err = svc.CompleteBlock(ctx, instanceID, block.GetID(), team.Code, userID)
+	require.NoError(t, err)
+	found, err = teamService.FindTeamByCode(ctx, team.Code)
+	require.NoError(t, err)
+	assert.Equal(t, 10, found.Points)
+}
+
+func TestBlockService_CompleteBlock_AwardsBonus(t *testing.T) {
+	_, svc, teamService, _, dbc, cleanup := setupReviewService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID, locations, teams := createBonusGame(t, dbc, teamService, 1, 1)
+	team := teams[0]
+	require.NoError(t, repositories.NewBonusRepository(dbc).SaveRule(ctx, &models.BonusRule{
+		InstanceID: instanceID,
+		Type:       models.AllBlocksBonus,
+		Points:     7,
+	}))
+
+	_, err := repositories.NewCheckInRepository(dbc).LogCheckIn(ctx, team, locations[0], false, true)
+	require.NoError(t, err)
+	block, err := svc.NewBlock(ctx, locations[0].ID, "answer")
+	require.NoError(t, err)
+	block, err = svc.UpdateBlock(ctx, block, map[string][]string{
+		"points": {"10"},
+		"prompt": {"Name the ship"},
+		"answer": {"Endeavour"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.CompleteBlock(ctx, instanceID, block.GetID(), team.Code, gofakeit.UUID()))
+
+	found, err := teamService.FindTeamByCode(ctx, team.Code)
+	require.NoError(t, err)
+	assert.Equal(t, 17, found.Points, "completing the last block earns the bonus")
+}
+
+func TestBlockService_CompleteState(t *testing.T) {
+	_, svc, teamService, _, dbc, cleanup := setupReviewService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	teams, err := teamService.AddTeams(ctx, instanceID, 1)
+	require.NoError(t, err)
+	team := teams[0]
+	pointsRepo := repositories.NewPointsRepository(dbc)
+
+	tests := []struct {
+		name       string
+		points     int
+		wantLedger int
+	}{
+		{"Block with points", 10, 1},
+		{"Block without points", 0, 0},
+	}
+
+	total := 0
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			block, err := svc.NewBlock(ctx, gofakeit.UUID(), "checklist")
+			require.NoError(t, err)
+			state, err := svc.NewBlockState(ctx, block.GetID(), team.Code)
+			require.NoError(t, err)
+			state.SetPointsAwarded(tc.points)
+			state.SetComplete(true)
+
+			before, err := pointsRepo.FindByTeam(ctx, team.Code)
+			require.NoError(t, err)
+
+			saved, err := svc.CompleteState(ctx, instanceID, block, state)
+			require.NoError(t, err)
+			assert.True(t, saved)
+
+			// A second submission of the same completion is not saved or paid
+			saved, err = svc.CompleteState(ctx, instanceID, block, state)
+			require.NoError(t, err)
+			assert.False(t, saved)
+
+			after, err := pointsRepo.FindByTeam(ctx, team.Code)
+			require.NoError(t, err)
+			assert.Len(t, after, len(before)+tc.wantLedger)
+
+			total += tc.points
+			found, err := teamService.FindTeamByCode(ctx, team.Code)
+			require.NoError(t, err)
+			assert.Equal(t, total, found.Points)
+		})
+	}
+
+	block, err := svc.NewBlock(ctx, gofakeit.UUID(), "checklist")
+	require.NoError(t, err)
+	state, err := svc.NewBlockState(ctx, block.GetID(), team.Code)
+	require.NoError(t, err)
+	_, err = svc.CompleteState(ctx, instanceID, block, state)
+	assert.Error(t, err, "incomplete states cannot be completed")
+}
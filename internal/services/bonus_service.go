@@ -0,0 +1,439 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+)
+
+type BonusService interface {
+	// FindRules returns the bonus rules for an instance
+	FindRules(ctx context.Context, instanceID string) ([]models.BonusRule, error)
+	// CreateRule adds a bonus rule to an instance
+	CreateRule(ctx context.Context, instanceID string, ruleType models.BonusRuleType, points int, threshold int) (*models.BonusRule, error)
+	// UpdateRule changes the points and threshold for a bonus rule
+	UpdateRule(ctx context.Context, instanceID string, ruleID string, points int, threshold int) (*models.BonusRule, error)
+	// DeleteRule removes a bonus rule, keeping any points it has awarded
+	DeleteRule(ctx context.Context, instanceID string, ruleID string) error
+
+	// FindAwards returns the bonus points awarded to a team, oldest first
+	FindAwards(ctx context.Context, teamCode string) ([]models.BonusAward, error)
+
+	// CheckedIn awards the bonuses a team earns by checking in to a location
+	CheckedIn(ctx context.Context, team *models.Team, locationID string) error
+	// CheckedOut awards the bonuses a team earns by checking out of a location
+	CheckedOut(ctx context.Context, team *models.Team, locationID string) error
+	// BlocksCompleted awards the bonuses a team earns by completing every block at a location
+	BlocksCompleted(ctx context.Context, team *models.Team, locationID string) error
+}
+
+type bonusService struct {
+	transactor   db.Transactor
+	broker       *events.Broker
+	bonusRepo    repositories.BonusRepository
+	checkInRepo  repositories.CheckInRepository
+	hintRepo     repositories.HintRepository
+	locationRepo repositories.LocationRepository
+	pointsRepo   repositories.PointsRepository
+	teamRepo     repositories.TeamRepository
+	teamService  TeamService
+}
+
+// NewBonusService creates a new BonusService.
+func NewBonusService(
+	transactor db.Transactor,
+	broker *events.Broker,
+	bonusRepo repositories.BonusRepository,
+	checkInRepo repositories.CheckInRepository,
+	hintRepo repositories.HintRepository,
+	locationRepo repositories.LocationRepository,
+	pointsRepo repositories.PointsRepository,
+	teamRepo repositories.TeamRepository,
+	teamService TeamService,
+) BonusService {
+	return &bonusService{
+		transactor:   transactor,
+		broker:       broker,
+		bonusRepo:    bonusRepo,
+		checkInRepo:  checkInRepo,
+		hintRepo:     hintRepo,
+		locationRepo: locationRepo,
+		pointsRepo:   pointsRepo,
+		teamRepo:     teamRepo,
+		teamService:  teamService,
+	}
+}
+
+// FindRules returns the bonus rules for an instance.
+func (s *bonusService) FindRules(ctx context.Context, instanceID string) ([]models.BonusRule, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+	return s.bonusRepo.FindRulesByInstance(ctx, instanceID)
+}
+
+// validateRule checks the points and threshold for a rule.
+func validateRule(ruleType models.BonusRuleType, points int, threshold int) error {
+	if points <= 0 {
+		return NewValidationError("points")
+	}
+	if ruleType.ThresholdLabel() != "" && threshold < 1 {
+		return NewValidationError("threshold")
+	}
+	return nil
+}
+
+// CreateRule adds a bonus rule to an instance.
+func (s *bonusService) CreateRule(ctx context.Context, instanceID string, ruleType models.BonusRuleType, points int, threshold int) (*models.BonusRule, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+	if ruleType < 0 || int(ruleType) >= len(models.GetBonusRuleTypes()) {
+		return nil, NewValidationError("ruleType")
+	}
+	err := validateRule(ruleType, points, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &models.BonusRule{
+		InstanceID: instanceID,
+		Type:       ruleType,
+		Points:     points,
+		Threshold:  threshold,
+	}
+	err = s.bonusRepo.SaveRule(ctx, rule)
+	if err != nil {
+		return nil, fmt.Errorf("saving bonus rule: %w", err)
+	}
+	return rule, nil
+}
+
+// getRule returns a rule, ensuring it belongs to the instance.
+func (s *bonusService) getRule(ctx context.Context, instanceID string, ruleID string) (*models.BonusRule, error) {
+	rule, err := s.bonusRepo.GetRuleByID(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule.InstanceID != instanceID {
+		return nil, ErrPermissionDenied
+	}
+	return rule, nil
+}
+
+// UpdateRule changes the points and threshold for a bonus rule.
+func (s *bonusService) UpdateRule(ctx context.Context, instanceID string, ruleID string, points int, threshold int) (*models.BonusRule, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+	if ruleID == "" {
+		return nil, NewValidationError("ruleID")
+	}
+
+	rule, err := s.getRule(ctx, instanceID, ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("finding bonus rule: %w", err)
+	}
+	err = validateRule(rule.Type, points, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Points = points
+	rule.Threshold = threshold
+	err = s.bonusRepo.UpdateRule(ctx, rule)
+	if err != nil {
+		return nil, fmt.Errorf("updating bonus rule: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule removes a bonus rule, keeping any points it has awarded.
+func (s *bonusService) DeleteRule(ctx context.Context, instanceID string, ruleID string) error {
+	if instanceID == "" {
+		return NewValidationError("instanceID")
+	}
+	if ruleID == "" {
+		return NewValidationError("ruleID")
+	}
+
+	_, err := s.getRule(ctx, instanceID, ruleID)
+	if err != nil {
+		return fmt.Errorf("finding bonus rule: %w", err)
+	}
+	return s.bonusRepo.DeleteRule(ctx, ruleID)
+}
+
+// FindAwards returns the bonus points awarded to a team, oldest first.
+func (s *bonusService) FindAwards(ctx context.Context, teamCode string) ([]models.BonusAward, error) {
+	if teamCode == "" {
+		return nil, NewValidationError("teamCode")
+	}
+	return s.bonusRepo.FindAwardsByTeam(ctx, teamCode)
+}
+
+// activeRules returns the rules that apply to the team's instance.
+// No rules apply unless both points and bonus points are enabled.
+func (s *bonusService) activeRules(ctx context.Context, team *models.Team) ([]models.BonusRule, error) {
+	rules, err := s.bonusRepo.FindRulesByInstance(ctx, team.InstanceID)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+
+	if team.Instance.Settings.InstanceID == "" || len(team.Instance.Locations) == 0 {
+		err := s.teamService.LoadRelation(ctx, team, "Instance")
+		if err != nil {
+			return nil, fmt.Errorf("loading instance: %w", err)
+		}
+	}
+	settings := team.Instance.Settings
+	if !settings.EnablePoints || !settings.EnableBonusPoints {
+		return nil, nil
+	}
+	return rules, nil
+}
+
+// award gives a rule's points to a team once for each location.
+// The award, the points and the team's new total are saved together, and an
+// award that already exists is skipped, so the same bonus is never paid twice.
+func (s *bonusService) award(ctx context.Context, team *models.Team, rule models.BonusRule, locationID string, reason string) error {
+	awarded, err := s.bonusRepo.HasAward(ctx, team.Code, rule.ID, locationID)
+	if err != nil {
+		return err
+	}
+	if awarded {
+		return nil
+	}
+
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	saved, err := s.bonusRepo.SaveAward(ctx, tx, &models.BonusAward{
+		InstanceID: team.InstanceID,
+		TeamCode:   team.Code,
+		RuleID:     rule.ID,
+		LocationID: locationID,
+		Points:     rule.Points,
+		Reason:     reason,
+	})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("saving bonus award: %w", err)
+	}
+	if !saved {
+		// Another request has already paid this bonus
+		return tx.Rollback()
+	}
+
+	transaction := &models.PointsTransaction{
+		InstanceID: team.InstanceID,
+		TeamCode:   team.Code,
+		Amount:     rule.Points,
+		Reason:     reason,
+		Source:     models.BonusSource,
+		SourceID:   rule.ID,
+	}
+	err = s.pointsRepo.Create(ctx, tx, transaction)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("awarding points: %w", err)
+	}
+
+	err = s.teamRepo.ReconcilePoints(ctx, tx, team.InstanceID, []string{team.Code})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("reconciling points: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	team.Points += rule.Points
+	publishPoints(s.broker, *transaction)
+	return nil
+}
+
+// CheckedIn awards the bonuses a team earns by checking in to a location.
+// Locations are finished on check in unless teams must also check out.
+func (s *bonusService) CheckedIn(ctx context.Context, team *models.Team, locationID string) error {
+	rules, err := s.activeRules(ctx, team)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	location, err := s.locationRepo.GetByID(ctx, locationID)
+	if err != nil {
+		return fmt.Errorf("finding location: %w", err)
+	}
+
+	for _, rule := range rules {
+		if rule.Type != models.FirstToArriveBonus {
+			continue
+		}
+		checkIns, err := s.checkInRepo.FindByLocation(ctx, location.ID)
+		if err != nil {
+			return fmt.Errorf("finding check ins: %w", err)
+		}
+		for i, checkIn := range checkIns {
+			if i >= rule.Threshold {
+				break
+			}
+			if checkIn.TeamID == team.Code {
+				err = s.award(ctx, team, rule, location.ID, fmt.Sprintf("%s to arrive at %s", ordinal(i+1), location.Name))
+				if err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+
+	if team.Instance.Settings.CompletionMethod == models.CheckInAndOut {
+		return nil
+	}
+	return s.finished(ctx, team, rules)
+}
+
+// CheckedOut awards the bonuses a team earns by checking out of a location.
+func (s *bonusService) CheckedOut(ctx context.Context, team *models.Team, locationID string) error {
+	rules, err := s.activeRules(ctx, team)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	return s.finished(ctx, team, rules)
+}
+
+// BlocksCompleted awards the bonuses a team earns by completing every block at a location.
+func (s *bonusService) BlocksCompleted(ctx context.Context, team *models.Team, locationID string) error {
+	rules, err := s.activeRules(ctx, team)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	location, err := s.locationRepo.GetByID(ctx, locationID)
+	if err != nil {
+		return fmt.Errorf("finding location: %w", err)
+	}
+
+	for _, rule := range rules {
+		if rule.Type != models.AllBlocksBonus {
+			continue
+		}
+		err = s.award(ctx, team, rule, location.ID, fmt.Sprint("Completed every activity at ", location.Name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finished awards the bonuses a team earns by finishing a location.
+func (s *bonusService) finished(ctx context.Context, team *models.Team, rules []models.BonusRule) error {
+	err := s.teamService.LoadRelation(ctx, team, "Scans")
+	if err != nil {
+		return fmt.Errorf("loading check ins: %w", err)
+	}
+
+	// Check ins are loaded newest first
+	var finished []models.CheckIn
+	for _, checkIn := range team.CheckIns {
+		if !checkIn.MustCheckOut {
+			finished = append(finished, checkIn)
+		}
+	}
+	if len(finished) == 0 {
+		return nil
+	}
+
+	for _, rule := range rules {
+		switch rule.Type {
+		case models.QuickFinishBonus:
+			err = s.quickFinish(ctx, team, rule, finished)
+		case models.StreakBonus:
+			err = s.streak(ctx, team, rule, finished)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quickFinish rewards a team that has visited every location within the rule's time limit.
+func (s *bonusService) quickFinish(ctx context.Context, team *models.Team, rule models.BonusRule, finished []models.CheckIn) error {
+	if len(finished) < len(team.Instance.Locations) {
+		return nil
+	}
+
+	start := team.StartedAt
+	if start.IsZero() {
+		start = finished[len(finished)-1].TimeIn
+	}
+	elapsed := time.Since(start)
+	if elapsed > time.Duration(rule.Threshold)*time.Minute {
+		return nil
+	}
+
+	minutes := max(int(elapsed.Minutes()), 1)
+	return s.award(ctx, team, rule, "", fmt.Sprintf("Visited every location in %d minutes", minutes))
+}
+
+// streak rewards a team each time their run of locations without a hint
+// reaches a multiple of the rule's threshold.
+func (s *bonusService) streak(ctx context.Context, team *models.Team, rule models.BonusRule, finished []models.CheckIn) error {
+	hints, err := s.hintRepo.FindTeamHints(ctx, team.Code)
+	if err != nil {
+		return fmt.Errorf("finding hints: %w", err)
+	}
+	hinted := make(map[string]bool, len(hints))
+	for _, hint := range hints {
+		hinted[hint.LocationID] = true
+	}
+
+	run := 0
+	for _, checkIn := range finished {
+		if hinted[checkIn.LocationID] {
+			break
+		}
+		run++
+	}
+	if run == 0 || run%rule.Threshold != 0 {
+		return nil
+	}
+
+	// The streak is recorded against the location that completed it
+	return s.award(ctx, team, rule, finished[0].LocationID, fmt.Sprintf("%d locations in a row without a hint", run))
+}
+
+// ordinal returns the ordinal form of a number, e.g. 1st, 2nd, 3rd.
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprint(n, suffix)
+}
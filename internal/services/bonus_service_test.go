@@ -0,0 +1,236 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func setupBonusService(t *testing.T) (services.BonusService, services.TeamService, *bun.DB, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	auditRepo := repositories.NewAuditRepository(dbc)
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	hintRepo := repositories.NewHintRepository(dbc)
+	locationRepo := repositories.NewLocationRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+
+	broker := events.NewBroker()
+	teamService := services.NewTeamService(transactor, broker, teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	bonusService := services.NewBonusService(transactor, broker, bonusRepo, checkInRepo, hintRepo, locationRepo, pointsRepo, teamRepo, teamService)
+
+	return bonusService, teamService, dbc, cleanup
+}
+
+// createBonusGame creates an instance with bonus points enabled, its locations and teams.
+func createBonusGame(t *testing.T, dbc *bun.DB, teamService services.TeamService, locationCount, teamCount int) (string, []models.Location, []models.Team) {
+	t.Helper()
+	ctx := context.Background()
+
+	instance := &models.Instance{Name: gofakeit.Name(), UserID: gofakeit.UUID()}
+	require.NoError(t, repositories.NewInstanceRepository(dbc).Create(ctx, instance))
+	require.NoError(t, repositories.NewInstanceSettingsRepository(dbc).Create(ctx, &models.InstanceSettings{
+		InstanceID:        instance.ID,
+		EnablePoints:      true,
+		EnableBonusPoints: true,
+	}))
+
+	locationRepo := repositories.NewLocationRepository(dbc)
+	locations := make([]models.Location, locationCount)
+	for i := range locations {
+		locations[i] = models.Location{
+			InstanceID: instance.ID,
+			MarkerID:   gofakeit.LetterN(5),
+			ContentID:  gofakeit.UUID(),
+			Name:       gofakeit.Name(),
+		}
+		require.NoError(t, locationRepo.Create(ctx, &locations[i]))
+	}
+
+	teams, err := teamService.AddTeams(ctx, instance.ID, teamCount)
+	require.NoError(t, err)
+	return instance.ID, locations, teams
+}
+
+// bonusCheckIn checks a team in to a location and runs the check in rules.
+func bonusCheckIn(t *testing.T, dbc *bun.DB, bonusService services.BonusService, teamService services.TeamService, teamCode string, location models.Location) *models.Team {
+	t.Helper()
+	ctx := context.Background()
+
+	team, err := teamService.FindTeamByCode(ctx, teamCode)
+	require.NoError(t, err)
+	_, err = repositories.NewCheckInRepository(dbc).LogCheckIn(ctx, *team, location, false, false)
+	require.NoError(t, err)
+	require.NoError(t, bonusService.CheckedIn(ctx, team, location.ID))
+	return team
+}
+
+func TestBonusService_Rules(t *testing.T) {
+	svc, _, _, cleanup := setupBonusService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+
+	rule, err := svc.CreateRule(ctx, instanceID, models.FirstToArriveBonus, 10, 3)
+	require.NoError(t, err)
+	_, err = svc.CreateRule(ctx, instanceID, models.AllBlocksBonus, 5, 0)
+	require.NoError(t, err, "rules without a threshold accept 0")
+
+	_, err = svc.CreateRule(ctx, instanceID, models.StreakBonus, 5, 0)
+	assert.ErrorIs(t, err, services.ErrInvalidArgument)
+	_, err = svc.CreateRule(ctx, instanceID, models.StreakBonus, 0, 3)
+	assert.ErrorIs(t, err, services.ErrInvalidArgument)
+	_, err = svc.CreateRule(ctx, instanceID, models.BonusRuleType(99), 5, 3)
+	assert.ErrorIs(t, err, services.ErrInvalidArgument)
+
+	rule, err = svc.UpdateRule(ctx, instanceID, rule.ID, 20, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 20, rule.Points)
+
+	_, err = svc.UpdateRule(ctx, gofakeit.UUID(), rule.ID, 20, 1)
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	err = svc.DeleteRule(ctx, gofakeit.UUID(), rule.ID)
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+	err = svc.DeleteRule(ctx, instanceID, rule.ID)
+	require.NoError(t, err)
+	rules, err := svc.FindRules(ctx, instanceID)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, models.AllBlocksBonus, rules[0].Type)
+}
+
+func TestBonusService_FirstToArrive(t *testing.T) {
+	svc, teamService, dbc, cleanup := setupBonusService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID, locations, teams := createBonusGame(t, dbc, teamService, 2, 3)
+	_, err := svc.CreateRule(ctx, instanceID, models.FirstToArriveBonus, 10, 2)
+	require.NoError(t, err)
+
+	for _, team := range teams {
+		bonusCheckIn(t, dbc, svc, teamService, team.Code, locations[0])
+	}
+
+	want := []int{10, 10, 0}
+	for i, team := range teams {
+		found, err := teamService.FindTeamByCode(ctx, team.Code)
+		require.NoError(t, err)
+		assert.Equal(t, want[i], found.Points, "team %d", i+1)
+	}
+
+	awards, err := svc.FindAwards(ctx, teams[1].Code)
+	require.NoError(t, err)
+	require.Len(t, awards, 1)
+	assert.Equal(t, "2nd to arrive at "+locations[0].Name, awards[0].Reason)
+
+	// Running the rules again does not pay out twice
+	team, err := teamService.FindTeamByCode(ctx, teams[0].Code)
+	require.NoError(t, err)
+	require.NoError(t, svc.CheckedIn(ctx, team, locations[0].ID))
+	team, err = teamService.FindTeamByCode(ctx, teams[0].Code)
+	require.NoError(t, err)
+	assert.Equal(t, 10, team.Points)
+
+	// Resetting the team clears their awards
+	require.NoError(t, teamService.Reset(ctx, instanceID, []string{teams[0].Code}))
+	awards, err = svc.FindAwards(ctx, teams[0].Code)
+	require.NoError(t, err)
+	assert.Empty(t, awards)
+}
+
+func TestBonusService_Disabled(t *testing.T) {
+	svc, teamService, dbc, cleanup := setupBonusService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID, locations, teams := createBonusGame(t, dbc, teamService, 1, 1)
+	_, err := svc.CreateRule(ctx, instanceID, models.FirstToArriveBonus, 10, 1)
+	require.NoError(t, err)
+
+	settings := &models.InstanceSettings{InstanceID: instanceID, EnablePoints: true}
+	settings.CreatedAt = time.Now()
+	require.NoError(t, repositories.NewInstanceSettingsRepository(dbc).Update(ctx, settings))
+
+	team := bonusCheckIn(t, dbc, svc, teamService, teams[0].Code, locations[0])
+	assert.Equal(t, 0, team.Points)
+}
+
+func TestBonusService_QuickFinish(t *testing.T) {
+	svc, teamService, dbc, cleanup := setupBonusService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID, locations, teams := createBonusGame(t, dbc, teamService, 2, 1)
+	_, err := svc.CreateRule(ctx, instanceID, models.QuickFinishBonus, 25, 60)
+	require.NoError(t, err)
+
+	team := bonusCheckIn(t, dbc, svc, teamService, teams[0].Code, locations[0])
+	assert.Equal(t, 0, team.Points, "not every location has been visited")
+
+	team = bonusCheckIn(t, dbc, svc, teamService, teams[0].Code, locations[1])
+	assert.Equal(t, 25, team.Points)
+}
+
+func TestBonusService_AllBlocks(t *testing.T) {
+	svc, teamService, dbc, cleanup := setupBonusService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID, locations, teams := createBonusGame(t, dbc, teamService, 1, 1)
+	_, err := svc.CreateRule(ctx, instanceID, models.AllBlocksBonus, 15, 0)
+	require.NoError(t, err)
+
+	team, err := teamService.FindTeamByCode(ctx, teams[0].Code)
+	require.NoError(t, err)
+	require.NoError(t, svc.BlocksCompleted(ctx, team, locations[0].ID))
+	require.NoError(t, svc.BlocksCompleted(ctx, team, locations[0].ID))
+	assert.Equal(t, 15, team.Points)
+}
+
+func TestBonusService_Streak(t *testing.T) {
+	svc, teamService, dbc, cleanup := setupBonusService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID, locations, teams := createBonusGame(t, dbc, teamService, 5, 1)
+	_, err := svc.CreateRule(ctx, instanceID, models.StreakBonus, 5, 2)
+	require.NoError(t, err)
+
+	team := bonusCheckIn(t, dbc, svc, teamService, teams[0].Code, locations[0])
+	assert.Equal(t, 0, team.Points)
+	team = bonusCheckIn(t, dbc, svc, teamService, teams[0].Code, locations[1])
+	assert.Equal(t, 5, team.Points, "two locations in a row")
+
+	// A hint breaks the streak
+	require.NoError(t, repositories.NewHintRepository(dbc).SaveTeamHint(ctx, &models.TeamHint{
+		TeamCode:   teams[0].Code,
+		HintID:     gofakeit.UUID(),
+		InstanceID: instanceID,
+		LocationID: locations[2].ID,
+	}))
+	team = bonusCheckIn(t, dbc, svc, teamService, teams[0].Code, locations[2])
+	assert.Equal(t, 5, team.Points)
+	team = bonusCheckIn(t, dbc, svc, teamService, teams[0].Code, locations[3])
+	assert.Equal(t, 5, team.Points)
+	team = bonusCheckIn(t, dbc, svc, teamService, teams[0].Code, locations[4])
+	assert.Equal(t, 10, team.Points)
+}
@@ -57,11 +57,20 @@ func (s *checkInService) CompleteBlocks(ctx context.Context, teamCode string, lo
 }
 
 // CheckIn logs a check in for a team at a location.
+// The check in is credited to the player in the context, if there is one.
 func (s *checkInService) CheckIn(ctx context.Context, team models.Team, location models.Location, mustCheckOut bool, validationRequired bool) (models.CheckIn, error) {
 	scan, err := s.checkInRepo.LogCheckIn(ctx, team, location, mustCheckOut, validationRequired)
 	if err != nil {
 		return models.CheckIn{}, fmt.Errorf("logging check in: %w", err)
 	}
+
+	if playerID := PlayerIDFromContext(ctx); playerID != "" {
+		scan.PlayerID = playerID
+		err = s.checkInRepo.Update(ctx, &scan)
+		if err != nil {
+			return models.CheckIn{}, fmt.Errorf("recording player: %w", err)
+		}
+	}
 	return scan, nil
 }
 
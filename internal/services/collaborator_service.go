@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+)
+
+var (
+	ErrInviteNotFound      = errors.New("invitation not found")
+	ErrInviteWrongEmail    = errors.New("invitation was sent to a different email address")
+	ErrAlreadyCollaborator = errors.New("user already has access to this instance")
+)
+
+type CollaboratorService interface {
+	// Role returns the user's role in an instance
+	Role(ctx context.Context, user *models.User, instanceID string) (models.InstanceRole, error)
+	// Authorize checks the user has a permission in an instance
+	Authorize(ctx context.Context, user *models.User, instanceID string, permission models.Permission) error
+	// AuthorizeLocation checks a location belongs to the user's current
+	// instance and the user has a permission there
+	AuthorizeLocation(ctx context.Context, user *models.User, locationID string, permission models.Permission) error
+	// AuthorizeLocationCode checks a location code belongs to the user's
+	// current instance and the user has a permission there
+	AuthorizeLocationCode(ctx context.Context, user *models.User, code string, permission models.Permission) error
+
+	// FindMembers returns the collaborators and invitations for an instance
+	FindMembers(ctx context.Context, instanceID string) ([]models.InstanceMember, error)
+	// FindSharedInstances returns the instances shared with a user
+	FindSharedInstances(ctx context.Context, userID string) ([]models.Instance, error)
+
+	// Invite emails an invitation to collaborate on an instance
+	Invite(ctx context.Context, user *models.User, instanceID, email string, role models.InstanceRole) (*models.InstanceMember, error)
+	// AcceptInvite gives the user access to the invitation's instance
+	AcceptInvite(ctx context.Context, user *models.User, token string) (*models.InstanceMember, error)
+	// UpdateRole changes a collaborator's role
+	UpdateRole(ctx context.Context, user *models.User, instanceID, memberID string, role models.InstanceRole) error
+	// Remove removes a collaborator or cancels an invitation.
+	// Collaborators may remove themselves to leave an instance
+	Remove(ctx context.Context, user *models.User, instanceID, memberID string) error
+	// Leave removes the user from an instance shared with them
+	Leave(ctx context.Context, user *models.User, instanceID string) error
+}
+
+type collaboratorService struct {
+	emailService EmailService
+	instanceRepo repositories.InstanceRepository
+	locationRepo repositories.LocationRepository
+	memberRepo   repositories.InstanceMemberRepository
+	orgRepo      repositories.OrganisationRepository
+	userRepo     repositories.UserRepository
+}
+
+// NewCollaboratorService creates a new CollaboratorService.
+func NewCollaboratorService(
+	emailService EmailService,
+	instanceRepo repositories.InstanceRepository,
+	locationRepo repositories.LocationRepository,
+	memberRepo repositories.InstanceMemberRepository,
+	orgRepo repositories.OrganisationRepository,
+	userRepo repositories.UserRepository,
+) CollaboratorService {
+	return &collaboratorService{
+		emailService: emailService,
+		instanceRepo: instanceRepo,
+		locationRepo: locationRepo,
+		memberRepo:   memberRepo,
+		orgRepo:      orgRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// Role returns the user's role in an instance.
+// Users without access get ErrPermissionDenied.
+func (s *collaboratorService) Role(ctx context.Context, user *models.User, instanceID string) (models.InstanceRole, error) {
+	if user == nil {
+		return "", ErrUserNotAuthenticated
+	}
+
+	// The current instance is already loaded with the user
+	if user.CurrentInstance.ID == instanceID && user.CurrentInstance.UserID == user.ID {
+		return models.RoleOwner, nil
+	}
+
+	instance, err := s.instanceRepo.GetByID(ctx, instanceID)
+	if err != nil {
+		return "", fmt.Errorf("finding instance: %w", err)
+	}
+	role := instanceRole(ctx, s.memberRepo, s.orgRepo, user, instance)
+	if role == "" {
+		return "", ErrPermissionDenied
+	}
+	return role, nil
+}
+
+// Authorize checks the user has a permission in an instance.
+func (s *collaboratorService) Authorize(ctx context.Context, user *models.User, instanceID string, permission models.Permission) error {
+	role, err := s.Role(ctx, user, instanceID)
+	if err != nil {
+		return err
+	}
+	if !role.Can(permission) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// AuthorizeLocation checks a location belongs to the user's current instance
+// and the user has a permission there.
+func (s *collaboratorService) AuthorizeLocation(ctx context.Context, user *models.User, locationID string, permission models.Permission) error {
+	if user == nil {
+		return ErrUserNotAuthenticated
+	}
+
+	location, err := s.locationRepo.GetByID(ctx, locationID)
+	if err != nil || location.InstanceID != user.CurrentInstanceID {
+		return ErrPermissionDenied
+	}
+	return s.Authorize(ctx, user, location.InstanceID, permission)
+}
+
+// AuthorizeLocationCode checks a location code belongs to the user's current
+// instance and the user has a permission there.
+func (s *collaboratorService) AuthorizeLocationCode(ctx context.Context, user *models.User, code string, permission models.Permission) error {
+	if user == nil {
+		return ErrUserNotAuthenticated
+	}
+
+	_, err := s.locationRepo.GetByInstanceAndCode(ctx, user.CurrentInstanceID, code)
+	if err != nil {
+		return ErrPermissionDenied
+	}
+	return s.Authorize(ctx, user, user.CurrentInstanceID, permission)
+}
+
+// FindMembers returns the collaborators and invitations for an instance.
+func (s *collaboratorService) FindMembers(ctx context.Context, instanceID string) ([]models.InstanceMember, error) {
+	members, err := s.memberRepo.FindByInstanceID(ctx, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("finding members: %w", err)
+	}
+	return members, nil
+}
+
+// FindSharedInstances returns the instances shared with a user.
+func (s *collaboratorService) FindSharedInstances(ctx context.Context, userID string) ([]models.Instance, error) {
+	instances, err := s.memberRepo.FindInstancesByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("finding shared instances: %w", err)
+	}
+	return instances, nil
+}
+
+// Invite emails an invitation to collaborate on an instance.
+// Only the owner may invite, and the invitation is removed if it cannot be sent.
+func (s *collaboratorService) Invite(ctx context.Context, user *models.User, instanceID, email string, role models.InstanceRole) (*models.InstanceMember, error) {
+	err := s.Authorize(ctx, user, instanceID, models.PermissionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return nil, fmt.Errorf("%w: email is not valid", ErrInvalidArgument)
+	}
+	email = strings.ToLower(address.Address)
+	if _, ok := models.ParseInstanceRole(string(role)); !ok {
+		return nil, NewValidationError("role")
+	}
+	if strings.EqualFold(email, user.Email) {
+		return nil, ErrAlreadyCollaborator
+	}
+
+	members, err := s.memberRepo.FindByInstanceID(ctx, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("finding members: %w", err)
+	}
+	for _, member := range members {
+		if member.Email == email {
+			return nil, ErrAlreadyCollaborator
+		}
+	}
+
+	instance, err := s.instanceRepo.GetByID(ctx, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("finding instance: %w", err)
+	}
+
+	invite := &models.InstanceMember{
+		InstanceID:  instanceID,
+		Email:       email,
+		Role:        role,
+		InvitedBy:   user.ID,
+		InviteToken: uuid.New().String(),
+	}
+	err = s.memberRepo.Create(ctx, invite)
+	if err != nil {
+		return nil, fmt.Errorf("saving invitation: %w", err)
+	}
+
+	_, err = s.emailService.SendInviteEmail(ctx, *invite, instance.Name, user.Name)
+	if err != nil {
+		if delErr := s.memberRepo.Delete(ctx, invite.ID); delErr != nil {
+			return nil, fmt.Errorf("removing unsent invitation: %w", delErr)
+		}
+		return nil, fmt.Errorf("sending invitation: %w", err)
+	}
+
+	return invite, nil
+}
+
+// AcceptInvite gives the user access to the invitation's instance.
+// The user must be signed in with the address the invitation was sent to.
+func (s *collaboratorService) AcceptInvite(ctx context.Context, user *models.User, token string) (*models.InstanceMember, error) {
+	if user == nil {
+		return nil, ErrUserNotAuthenticated
+	}
+	if token == "" {
+		return nil, ErrInviteNotFound
+	}
+
+	invite, err := s.memberRepo.GetByInviteToken(ctx, token)
+	if err != nil {
+		return nil, ErrInviteNotFound
+	}
+	if !strings.EqualFold(invite.Email, user.Email) {
+		return nil, ErrInviteWrongEmail
+	}
+
+	invite.UserID = user.ID
+	invite.InviteToken = ""
+	invite.AcceptedAt = time.Now().UTC()
+	err = s.memberRepo.Update(ctx, invite)
+	if err != nil {
+		return nil, fmt.Errorf("accepting invitation: %w", err)
+	}
+	return invite, nil
+}
+
+// UpdateRole changes a collaborator's role.
+func (s *collaboratorService) UpdateRole(ctx context.Context, user *models.User, instanceID, memberID string, role models.InstanceRole) error {
+	err := s.Authorize(ctx, user, instanceID, models.PermissionManage)
+	if err != nil {
+		return err
+	}
+	if _, ok := models.ParseInstanceRole(string(role)); !ok {
+		return NewValidationError("role")
+	}
+
+	member, err := s.member(ctx, instanceID, memberID)
+	if err != nil {
+		return err
+	}
+
+	member.Role = role
+	err = s.memberRepo.Update(ctx, member)
+	if err != nil {
+		return fmt.Errorf("updating member: %w", err)
+	}
+	return nil
+}
+
+// Remove removes a collaborator or cancels an invitation.
+func (s *collaboratorService) Remove(ctx context.Context, user *models.User, instanceID, memberID string) error {
+	if user == nil {
+		return ErrUserNotAuthenticated
+	}
+
+	member, err := s.member(ctx, instanceID, memberID)
+	if err != nil {
+		return err
+	}
+
+	// Collaborators can always leave
+	if member.UserID != user.ID {
+		err = s.Authorize(ctx, user, instanceID, models.PermissionManage)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.memberRepo.Delete(ctx, member.ID)
+	if err != nil {
+		return fmt.Errorf("removing member: %w", err)
+	}
+
+	// Move the collaborator off the instance so they are not locked out
+	if member.UserID == "" {
+		return nil
+	}
+	collaborator, err := s.userRepo.GetByID(ctx, member.UserID)
+	if err != nil {
+		return fmt.Errorf("finding collaborator: %w", err)
+	}
+	if collaborator.CurrentInstanceID == instanceID {
+		collaborator.CurrentInstanceID = ""
+		err = s.userRepo.Update(ctx, collaborator)
+		if err != nil {
+			return fmt.Errorf("updating collaborator: %w", err)
+		}
+	}
+	return nil
+}
+
+// Leave removes the user from an instance shared with them.
+func (s *collaboratorService) Leave(ctx context.Context, user *models.User, instanceID string) error {
+	if user == nil {
+		return ErrUserNotAuthenticated
+	}
+	member, err := s.memberRepo.GetByInstanceAndUser(ctx, instanceID, user.ID)
+	if err != nil {
+		return ErrPermissionDenied
+	}
+	return s.Remove(ctx, user, instanceID, member.ID)
+}
+
+// member finds a member of the given instance.
+func (s *collaboratorService) member(ctx context.Context, instanceID, memberID string) (*models.InstanceMember, error) {
+	if memberID == "" {
+		return nil, NewValidationError("memberID")
+	}
+	member, err := s.memberRepo.GetByID(ctx, memberID)
+	if err != nil || member.InstanceID != instanceID {
+		return nil, ErrPermissionDenied
+	}
+	return member, nil
+}
+
+// instanceRole returns the user's role in an instance, or none if they have no
+// access. Organisation admins may manage every instance in their organisation,
+// and other members may at least view them.
+func instanceRole(ctx context.Context, memberRepo repositories.InstanceMemberRepository, orgRepo repositories.OrganisationRepository, user *models.User, instance *models.Instance) models.InstanceRole {
+	if instance.UserID == user.ID {
+		return models.RoleOwner
+	}
+
+	var role models.InstanceRole
+	member, err := memberRepo.GetByInstanceAndUser(ctx, instance.ID, user.ID)
+	if err == nil {
+		role = member.Role
+	}
+	if instance.OrganisationID == "" {
+		return role
+	}
+
+	orgMember, err := orgRepo.GetMember(ctx, instance.OrganisationID, user.ID)
+	if err != nil {
+		return role
+	}
+	if orgMember.Role == models.OrgRoleAdmin {
+		return models.RoleOwner
+	}
+	if role == "" {
+		return models.RoleViewer
+	}
+	return role
+}
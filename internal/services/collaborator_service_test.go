@@ -0,0 +1,226 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/sendgrid/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockEmailService records invitations instead of sending them.
+type mockEmailService struct {
+	services.EmailService
+	invites []models.InstanceMember
+	err     error
+}
+
+func (m *mockEmailService) SendInviteEmail(ctx context.Context, invite models.InstanceMember, instanceName, inviterName string) (*rest.Response, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.invites = append(m.invites, invite)
+	return &rest.Response{}, nil
+}
+
+func setupCollaboratorService(t *testing.T) (services.CollaboratorService, services.InstanceService, services.LocationService, services.UserService, *mockEmailService, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+
+	auditRepo := repositories.NewAuditRepository(dbc)
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	blockRepo := repositories.NewBlockRepository(dbc, blockStateRepo)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	clueRepo := repositories.NewClueRepository(dbc)
+	hintRepo := repositories.NewHintRepository(dbc)
+	instanceRepo := repositories.NewInstanceRepository(dbc)
+	instanceSettingsRepo := repositories.NewInstanceSettingsRepository(dbc)
+	locationRepo := repositories.NewLocationRepository(dbc)
+	markerRepo := repositories.NewMarkerRepository(dbc)
+	memberRepo := repositories.NewInstanceMemberRepository(dbc)
+	orgRepo := repositories.NewOrganisationRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+	userRepo := repositories.NewUserRepository(dbc)
+
+	email := &mockEmailService{}
+	locationService := services.NewLocationService(transactor, clueRepo, hintRepo, locationRepo, markerRepo, blockRepo, auditRepo)
+	teamService := services.NewTeamService(transactor, events.NewBroker(), teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	userService := services.NewUserService(transactor, userRepo, instanceRepo)
+	collaboratorService := services.NewCollaboratorService(email, instanceRepo, locationRepo, memberRepo, orgRepo, userRepo)
+	instanceService := services.NewInstanceService(
+		transactor,
+		locationService, userService, teamService, collaboratorService, instanceRepo, instanceSettingsRepo, auditRepo,
+		memberRepo, orgRepo,
+	)
+
+	return collaboratorService, instanceService, locationService, userService, email, cleanup
+}
+
+func TestCollaboratorService(t *testing.T) {
+	svc, instanceService, locationService, userService, email, cleanup := setupCollaboratorService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	owner := createTestUser(t, userService, "owner@example.com")
+	editor := createTestUser(t, userService, "editor@example.com")
+	viewer := createTestUser(t, userService, "viewer@example.com")
+	stranger := createTestUser(t, userService, "stranger@example.com")
+
+	instance, err := instanceService.CreateInstance(ctx, "Shared game", owner)
+	require.NoError(t, err)
+	location, err := locationService.CreateLocation(ctx, instance.ID, "Library", -45.86, 170.51, 10)
+	require.NoError(t, err)
+
+	// invite sends an invitation and accepts it as the given user
+	invite := func(t *testing.T, user *models.User, role models.InstanceRole) *models.InstanceMember {
+		t.Helper()
+		member, err := svc.Invite(ctx, owner, instance.ID, " "+user.Email+" ", role)
+		require.NoError(t, err)
+		accepted, err := svc.AcceptInvite(ctx, user, member.InviteToken)
+		require.NoError(t, err)
+		return accepted
+	}
+
+	t.Run("Roles limit permissions", func(t *testing.T) {
+		assert.True(t, models.RoleOwner.Can(models.PermissionManage))
+		assert.True(t, models.RoleEditor.Can(models.PermissionEdit))
+		assert.False(t, models.RoleEditor.Can(models.PermissionManage))
+		assert.True(t, models.RoleViewer.Can(models.PermissionView))
+		assert.False(t, models.RoleViewer.Can(models.PermissionEdit))
+		assert.False(t, models.InstanceRole("").Can(models.PermissionView))
+	})
+
+	t.Run("Owners have every permission", func(t *testing.T) {
+		role, err := svc.Role(ctx, owner, instance.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleOwner, role)
+
+		_, err = svc.Role(ctx, stranger, instance.ID)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	})
+
+	t.Run("Only the owner can invite", func(t *testing.T) {
+		_, err := svc.Invite(ctx, stranger, instance.ID, "someone@example.com", models.RoleEditor)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		_, err = svc.Invite(ctx, owner, instance.ID, "not an email", models.RoleEditor)
+		assert.ErrorIs(t, err, services.ErrInvalidArgument)
+
+		_, err = svc.Invite(ctx, owner, instance.ID, "someone@example.com", models.RoleOwner)
+		assert.ErrorIs(t, err, services.ErrInvalidArgument)
+	})
+
+	t.Run("Invitations are emailed and accepted by the invitee", func(t *testing.T) {
+		member, err := svc.Invite(ctx, owner, instance.ID, "Editor@Example.com", models.RoleEditor)
+		require.NoError(t, err)
+		require.Len(t, email.invites, 1)
+		assert.Equal(t, "editor@example.com", email.invites[0].Email)
+		assert.True(t, member.IsPending())
+
+		_, err = svc.Invite(ctx, owner, instance.ID, "editor@example.com", models.RoleViewer)
+		assert.ErrorIs(t, err, services.ErrAlreadyCollaborator)
+
+		_, err = svc.AcceptInvite(ctx, stranger, member.InviteToken)
+		assert.ErrorIs(t, err, services.ErrInviteWrongEmail)
+
+		accepted, err := svc.AcceptInvite(ctx, editor, member.InviteToken)
+		require.NoError(t, err)
+		assert.False(t, accepted.IsPending())
+
+		_, err = svc.AcceptInvite(ctx, editor, member.InviteToken)
+		assert.ErrorIs(t, err, services.ErrInviteNotFound)
+
+		role, err := svc.Role(ctx, editor, instance.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleEditor, role)
+
+		shared, err := svc.FindSharedInstances(ctx, editor.ID)
+		require.NoError(t, err)
+		require.Len(t, shared, 1)
+		assert.Equal(t, instance.ID, shared[0].ID)
+	})
+
+	t.Run("Unsent invitations are removed", func(t *testing.T) {
+		email.err = errors.New("mail server down")
+		defer func() { email.err = nil }()
+
+		_, err := svc.Invite(ctx, owner, instance.ID, "later@example.com", models.RoleViewer)
+		assert.Error(t, err)
+
+		members, err := svc.FindMembers(ctx, instance.ID)
+		require.NoError(t, err)
+		for _, member := range members {
+			assert.NotEqual(t, "later@example.com", member.Email)
+		}
+	})
+
+	t.Run("Location checks use the role", func(t *testing.T) {
+		viewerMember := invite(t, viewer, models.RoleViewer)
+		defer svc.Remove(ctx, owner, instance.ID, viewerMember.ID)
+
+		_, err := instanceService.SwitchInstance(ctx, viewer, instance.ID)
+		require.NoError(t, err)
+		_, err = instanceService.SwitchInstance(ctx, editor, instance.ID)
+		require.NoError(t, err)
+
+		assert.NoError(t, svc.AuthorizeLocation(ctx, editor, location.ID, models.PermissionEdit))
+		assert.NoError(t, svc.AuthorizeLocationCode(ctx, editor, location.MarkerID, models.PermissionEdit))
+		assert.ErrorIs(t, svc.AuthorizeLocation(ctx, viewer, location.ID, models.PermissionEdit), services.ErrPermissionDenied)
+		assert.NoError(t, svc.AuthorizeLocation(ctx, viewer, location.ID, models.PermissionView))
+
+		// Locations outside the current instance are never allowed
+		other, err := instanceService.CreateInstance(ctx, "Other game", stranger)
+		require.NoError(t, err)
+		otherLocation, err := locationService.CreateLocation(ctx, other.ID, "Museum", -45.87, 170.52, 10)
+		require.NoError(t, err)
+		assert.ErrorIs(t, svc.AuthorizeLocation(ctx, editor, otherLocation.ID, models.PermissionView), services.ErrPermissionDenied)
+	})
+
+	t.Run("Editors cannot delete the instance or manage collaborators", func(t *testing.T) {
+		_, err := instanceService.DeleteInstance(ctx, editor, instance.ID, instance.Name)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		_, err = svc.Invite(ctx, editor, instance.ID, "friend@example.com", models.RoleViewer)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		members, err := svc.FindMembers(ctx, instance.ID)
+		require.NoError(t, err)
+		require.NotEmpty(t, members)
+		err = svc.UpdateRole(ctx, editor, instance.ID, members[0].ID, models.RoleViewer)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	})
+
+	t.Run("Removed collaborators lose access", func(t *testing.T) {
+		member := invite(t, stranger, models.RoleViewer)
+		_, err := instanceService.SwitchInstance(ctx, stranger, instance.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, svc.UpdateRole(ctx, owner, instance.ID, member.ID, models.RoleEditor))
+		role, err := svc.Role(ctx, stranger, instance.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleEditor, role)
+
+		require.NoError(t, svc.Leave(ctx, stranger, instance.ID))
+		_, err = svc.Role(ctx, stranger, instance.ID)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		_, err = instanceService.SwitchInstance(ctx, stranger, instance.ID)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		// The collaborator is moved off the instance they left
+		refreshed, err := userService.GetUserByEmail(ctx, stranger.Email)
+		require.NoError(t, err)
+		assert.Empty(t, refreshed.CurrentInstanceID)
+	})
+}
@@ -1,11 +1,28 @@
 package services
 
+import "github.com/nathanhollows/Rapua/v3/models"
+
 // LocationData is the data required to update a new location. Blank
 // fields are ignored, with the exception of Clues and ClueIDs which
 // are always required.
 type LocationUpdateData struct {
+	Name          string
+	Latitude      float64
+	Longitude     float64
+	Points        int
+	CheckInRadius int
+	Capacity      int
+	// UnlockRules are left unchanged when nil
+	UnlockRules models.UnlockRules
+}
+
+// MarkerData is the data saved with a library marker.
+type MarkerData struct {
 	Name      string
 	Latitude  float64
 	Longitude float64
-	Points    int
+	Notes     string
+	// Photo is left unchanged when empty
+	Photo string
+	Tags  []string
 }
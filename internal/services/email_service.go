@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"html"
 	"os"
+	"strings"
 
 	"github.com/a-h/templ"
 	templates "github.com/nathanhollows/Rapua/v3/internal/templates/emails"
@@ -19,6 +21,8 @@ type EmailService interface {
 	SendVerificationEmail(ctx context.Context, user models.User) (*rest.Response, error)
 	// SendContactEmail sends an email to the site owner from the contact form
 	SendContactEmail(ctx context.Context, name, contactEmail, content string) (*rest.Response, error)
+	// SendInviteEmail invites someone to collaborate on an instance
+	SendInviteEmail(ctx context.Context, invite models.InstanceMember, instanceName, inviterName string) (*rest.Response, error)
 }
 
 type emailService struct{}
@@ -71,3 +75,38 @@ Nathan`
 	response, err := client.Send(message)
 	return response, err
 }
+
+func (s emailService) SendInviteEmail(ctx context.Context, invite models.InstanceMember, instanceName, inviterName string) (*rest.Response, error) {
+	from := mail.NewEmail(os.Getenv("SENDGRID_FROM_NAME"), os.Getenv("SENDGRID_FROM_EMAIL"))
+	to := mail.NewEmail("", invite.Email)
+	subject := fmt.Sprintf("%s invited you to %s on Rapua", inviterName, instanceName)
+
+	url := os.Getenv("SITE_URL") + "/admin/invites/" + invite.InviteToken
+
+	plainTextContent := `%v has invited you to help run %v on Rapua as %v.
+
+Tap the link below to accept. You will need to log in or register with this email address first.
+
+	%v
+
+Cheers,
+Nathan`
+	plainTextContent = fmt.Sprintf(plainTextContent, inviterName, instanceName, strings.ToLower(invite.Role.String()), url)
+
+	htmlTemplate := `
+	<p>%v has invited you to help run <strong>%v</strong> on Rapua as %v.</p>
+	<p>You will need to log in or register with this email address first.</p>
+	<p><a href="%v">Accept the invitation</a></p>
+	`
+	htmlContent := fmt.Sprintf(htmlTemplate,
+		html.EscapeString(inviterName),
+		html.EscapeString(instanceName),
+		strings.ToLower(invite.Role.String()),
+		url,
+	)
+
+	message := mail.NewSingleEmail(from, subject, to, plainTextContent, htmlContent)
+	client := sendgrid.NewSendClient(os.Getenv("SENDGRID_API_KEY"))
+	response, err := client.Send(message)
+	return response, err
+}
@@ -3,16 +3,22 @@ package services
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/nathanhollows/Rapua/v3/models"
 	"github.com/nathanhollows/Rapua/v3/repositories"
 )
 
+// lastUsedInterval limits how often a token's last used time is written.
+const lastUsedInterval = time.Minute
+
 type FacilitatorService struct {
 	repo repositories.FacilitatorTokenRepo
 }
@@ -32,16 +38,25 @@ func (s *FacilitatorService) generateToken() string {
 	return base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(b)
 }
 
+// FacilitatorTokenID identifies a token without revealing it.
+func FacilitatorTokenID(token *models.FacilitatorToken) string {
+	sum := sha256.Sum256([]byte(token.Token))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
 // CreateFacilitatorToken generates and stores a facilitator access token.
-func (s *FacilitatorService) CreateFacilitatorToken(ctx context.Context, instanceID string, locations []string, duration time.Duration) (string, error) {
+// Tokens without any locations grant access to the whole instance.
+func (s *FacilitatorService) CreateFacilitatorToken(ctx context.Context, instanceID string, name string, locations []string, duration time.Duration) (string, error) {
 	token := s.generateToken()
 	expiry := time.Now().Add(duration)
 
 	newToken := models.FacilitatorToken{
 		Token:      token,
 		InstanceID: instanceID,
+		Name:       strings.TrimSpace(name),
 		Locations:  locations,
 		ExpiresAt:  expiry,
+		CreatedAt:  time.Now().UTC(),
 	}
 
 	err := s.repo.SaveToken(ctx, newToken)
@@ -63,9 +78,51 @@ func (s *FacilitatorService) ValidateToken(ctx context.Context, token string) (*
 		return nil, errors.New("token has expired")
 	}
 
+	// Record the use, but not on every request
+	now := time.Now().UTC()
+	if now.Sub(facToken.LastUsedAt) > lastUsedInterval {
+		err = s.repo.UpdateLastUsed(ctx, facToken.Token, now)
+		if err != nil {
+			return nil, fmt.Errorf("updating last used: %w", err)
+		}
+		facToken.LastUsedAt = now
+	}
+
 	return facToken, nil
 }
 
+// FindActiveTokens returns the unexpired tokens for an instance.
+func (s *FacilitatorService) FindActiveTokens(ctx context.Context, instanceID string) ([]models.FacilitatorToken, error) {
+	tokens, err := s.repo.FindByInstanceID(ctx, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("finding tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeToken deletes the instance's token with the given ID.
+func (s *FacilitatorService) RevokeToken(ctx context.Context, instanceID string, tokenID string) error {
+	if tokenID == "" {
+		return NewValidationError("tokenID")
+	}
+
+	tokens, err := s.repo.FindByInstanceID(ctx, instanceID)
+	if err != nil {
+		return fmt.Errorf("finding tokens: %w", err)
+	}
+
+	for _, token := range tokens {
+		if FacilitatorTokenID(&token) == tokenID {
+			err = s.repo.DeleteToken(ctx, token.Token)
+			if err != nil {
+				return fmt.Errorf("deleting token: %w", err)
+			}
+			return nil
+		}
+	}
+	return ErrPermissionDenied
+}
+
 // CleanupExpiredTokens removes all expired facilitator tokens.
 func (s *FacilitatorService) CleanupExpiredTokens(ctx context.Context) error {
 	return s.repo.CleanUpExpiredTokens(ctx)
@@ -9,6 +9,7 @@ import (
 	"github.com/nathanhollows/Rapua/v3/internal/services"
 	"github.com/nathanhollows/Rapua/v3/repositories"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setupFacilitatorService(t *testing.T) (services.FacilitatorService, func()) {
@@ -24,7 +25,7 @@ func TestFacilitatorService_CreateAndValidateToken(t *testing.T) {
 	ctx := context.Background()
 
 	// Create a new facilitator token
-	token, err := service.CreateFacilitatorToken(ctx, "game123", []string{"Park", "Tower"}, 24*time.Hour)
+	token, err := service.CreateFacilitatorToken(ctx, "game123", "", []string{"Park", "Tower"}, 24*time.Hour)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
@@ -42,7 +43,7 @@ func TestFacilitatorService_ExpiredToken(t *testing.T) {
 	ctx := context.Background()
 
 	// Create a token that expires immediately
-	token, err := service.CreateFacilitatorToken(ctx, "gameExpired", []string{"Lab"}, -1*time.Second)
+	token, err := service.CreateFacilitatorToken(ctx, "gameExpired", "", []string{"Lab"}, -1*time.Second)
 	assert.NoError(t, err)
 
 	// Validate expired token
@@ -57,12 +58,12 @@ func TestFacilitatorService_CleanupExpiredTokens(t *testing.T) {
 	ctx := context.Background()
 
 	// Create expired token
-	token, err := service.CreateFacilitatorToken(ctx, "gameX", []string{"Castle"}, -24*time.Hour)
+	token, err := service.CreateFacilitatorToken(ctx, "gameX", "", []string{"Castle"}, -24*time.Hour)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
 	// Create valid token
-	validToken, _ := service.CreateFacilitatorToken(ctx, "gameY", []string{"Castle"}, 24*time.Hour)
+	validToken, _ := service.CreateFacilitatorToken(ctx, "gameY", "", []string{"Castle"}, 24*time.Hour)
 
 	// Cleanup expired tokens
 	err = service.CleanupExpiredTokens(ctx)
@@ -78,3 +79,47 @@ func TestFacilitatorService_CleanupExpiredTokens(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, validTokenData)
 }
+
+func TestFacilitatorService_ManageTokens(t *testing.T) {
+	service, cleanup := setupFacilitatorService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	token, err := service.CreateFacilitatorToken(ctx, "gameM", " Gate staff ", []string{"North", "South"}, 24*time.Hour)
+	require.NoError(t, err)
+	_, err = service.CreateFacilitatorToken(ctx, "gameM", "", nil, -time.Hour)
+	require.NoError(t, err)
+	_, err = service.CreateFacilitatorToken(ctx, "gameOther", "Other", nil, time.Hour)
+	require.NoError(t, err)
+
+	// Only unexpired tokens for the instance are listed
+	tokens, err := service.FindActiveTokens(ctx, "gameM")
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, "Gate staff", tokens[0].Name)
+	assert.ElementsMatch(t, []string{"North", "South"}, tokens[0].Locations)
+	assert.True(t, tokens[0].LastUsedAt.IsZero())
+
+	// Logging in records when the token was last used
+	_, err = service.ValidateToken(ctx, token)
+	require.NoError(t, err)
+	tokens, err = service.FindActiveTokens(ctx, "gameM")
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.False(t, tokens[0].LastUsedAt.IsZero())
+
+	// Audit entries use the token's name
+	assert.Equal(t, "Gate staff", services.FacilitatorActor(&tokens[0]).Name)
+
+	// Tokens can only be revoked from their own instance
+	id := services.FacilitatorTokenID(&tokens[0])
+	err = service.RevokeToken(ctx, "gameOther", id)
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+	require.NoError(t, service.RevokeToken(ctx, "gameM", id))
+	_, err = service.ValidateToken(ctx, token)
+	assert.Error(t, err)
+	tokens, err = service.FindActiveTokens(ctx, "gameM")
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+}
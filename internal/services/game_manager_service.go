@@ -25,6 +25,7 @@ type gameManagerService struct {
 	instanceRepo         repositories.InstanceRepository
 	instanceSettingsRepo repositories.InstanceSettingsRepository
 	instanceService      InstanceService
+	auditRepo            repositories.AuditRepository
 }
 
 // TODO: Split this service into smaller services.
@@ -41,10 +42,6 @@ type GameManagerService interface {
 	CreateLocation(ctx context.Context, user *models.User, data map[string]string) (models.Location, error)
 	SaveLocation(ctx context.Context, location *models.Location, lat, lng, name string) error
 
-	// Marker & Validation
-	ValidateLocationMarker(user *models.User, id string) bool
-	ValidateLocationID(user *models.User, id string) bool
-
 	// Settings & Utilities
 	UpdateSettings(ctx context.Context, settings *models.InstanceSettings, form url.Values) error
 	DismissQuickstart(ctx context.Context, instanceID string) error
@@ -60,6 +57,7 @@ func NewGameManagerService(
 	instanceRepo repositories.InstanceRepository,
 	instanceSettingsRepo repositories.InstanceSettingsRepository,
 	instanceService InstanceService,
+	auditRepo repositories.AuditRepository,
 ) GameManagerService {
 	return &gameManagerService{
 		transactor:           transactor,
@@ -71,6 +69,7 @@ func NewGameManagerService(
 		instanceRepo:         instanceRepo,
 		instanceSettingsRepo: instanceSettingsRepo,
 		instanceService:      instanceService,
+		auditRepo:            auditRepo,
 	}
 }
 
@@ -195,26 +194,10 @@ func (s *gameManagerService) CreateLocation(
 	)
 }
 
-func (s *gameManagerService) ValidateLocationMarker(user *models.User, id string) bool {
-	for _, loc := range user.CurrentInstance.Locations {
-		if loc.MarkerID == id {
-			return true
-		}
-	}
-	return false
-}
-
-func (s *gameManagerService) ValidateLocationID(user *models.User, id string) bool {
-	for _, loc := range user.CurrentInstance.Locations {
-		if loc.ID == id {
-			return true
-		}
-	}
-	return false
-}
-
 // UpdateSettings parses the form values and updates the instance settings.
 func (s *gameManagerService) UpdateSettings(ctx context.Context, settings *models.InstanceSettings, form url.Values) error {
+	before := *settings
+
 	// Navigation mode
 	navMode, err := models.ParseNavigationMode(form.Get("navigationMode"))
 	if err != nil {
@@ -250,6 +233,32 @@ func (s *gameManagerService) UpdateSettings(ctx context.Context, settings *model
 		settings.MaxNextLocations = maxLocInt
 	}
 
+	// Check-in radius
+	radius := form.Get("checkInRadius")
+	if radius != "" {
+		radiusInt, err := strconv.Atoi(radius)
+		if err != nil {
+			return fmt.Errorf("parsing check-in radius: %w", err)
+		}
+		if radiusInt < 0 {
+			return NewValidationError("checkInRadius")
+		}
+		settings.CheckInRadius = radiusInt
+	}
+
+	// Team time limit
+	duration := form.Get("teamDuration")
+	if duration != "" {
+		durationInt, err := strconv.Atoi(duration)
+		if err != nil {
+			return fmt.Errorf("parsing team duration: %w", err)
+		}
+		if durationInt < 0 {
+			return NewValidationError("teamDuration")
+		}
+		settings.TeamDuration = durationInt
+	}
+
 	// Enable points
 	enablePoints := form.Has("enablePoints")
 	settings.EnablePoints = enablePoints
@@ -258,11 +267,47 @@ func (s *gameManagerService) UpdateSettings(ctx context.Context, settings *model
 	enableBonusPoints := form.Has("enableBonusPoints")
 	settings.EnableBonusPoints = enableBonusPoints
 
+	// Leaderboard
+	settings.ShowLeaderboard = form.Has("showLeaderboard")
+	settings.LeaderboardAnonymise = form.Has("leaderboardAnonymise")
+	freeze := form.Get("leaderboardFreeze")
+	if freeze != "" {
+		freezeInt, err := strconv.Atoi(freeze)
+		if err != nil {
+			return fmt.Errorf("parsing leaderboard freeze: %w", err)
+		}
+		if freezeInt < 0 {
+			return NewValidationError("leaderboardFreeze")
+		}
+		settings.LeaderboardFreeze = freezeInt
+	}
+	freezeTop := form.Get("leaderboardFreezeTop")
+	if freezeTop != "" {
+		freezeTopInt, err := strconv.Atoi(freezeTop)
+		if err != nil {
+			return fmt.Errorf("parsing leaderboard freeze top: %w", err)
+		}
+		if freezeTopInt < 0 {
+			return NewValidationError("leaderboardFreezeTop")
+		}
+		settings.LeaderboardFreezeTop = freezeTopInt
+	}
+
 	// Save settings
 	if err := s.instanceSettingsRepo.Update(ctx, settings); err != nil {
 		return fmt.Errorf("updating settings: %w", err)
 	}
 
+	err = recordAudit(ctx, s.auditRepo, nil, models.AuditEvent{
+		InstanceID: settings.InstanceID,
+		Action:     models.AuditSettingsUpdated,
+		EntityType: "instance_settings",
+		EntityID:   settings.InstanceID,
+	}, before, settings)
+	if err != nil {
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+
 	return nil
 }
 
@@ -287,6 +332,8 @@ func (s *gameManagerService) SetStartTime(ctx context.Context, user *models.User
 		return response
 	}
 
+	before := auditInstance(&user.CurrentInstance)
+
 	// Update the start time
 	user.CurrentInstance.StartTime = bun.NullTime{Time: time}
 	if !user.CurrentInstance.EndTime.After(time) {
@@ -299,6 +346,15 @@ func (s *gameManagerService) SetStartTime(ctx context.Context, user *models.User
 		return response
 	}
 
+	action := models.AuditGameScheduled
+	if user.CurrentInstance.GetStatus() == models.Active {
+		action = models.AuditGameStarted
+	}
+	if err := s.auditInstance(ctx, action, &user.CurrentInstance, before); err != nil {
+		response.Error = err
+		return response
+	}
+
 	msg := flash.NewSuccess("Game scheduled to start at " + time.Format("2006-01-02 15:04:05"))
 	response.AddFlashMessage(*msg)
 	return response
@@ -322,6 +378,8 @@ func (s *gameManagerService) SetEndTime(ctx context.Context, user *models.User,
 		return response
 	}
 
+	before := auditInstance(&user.CurrentInstance)
+
 	// Update the end time
 	user.CurrentInstance.EndTime = bun.NullTime{Time: time}
 	if err := s.instanceRepo.Update(ctx, &user.CurrentInstance); err != nil {
@@ -330,6 +388,15 @@ func (s *gameManagerService) SetEndTime(ctx context.Context, user *models.User,
 		return response
 	}
 
+	action := models.AuditGameScheduled
+	if user.CurrentInstance.GetStatus() == models.Closed {
+		action = models.AuditGameStopped
+	}
+	if err := s.auditInstance(ctx, action, &user.CurrentInstance, before); err != nil {
+		response.Error = err
+		return response
+	}
+
 	msg := flash.NewSuccess("Game scheduled to end at " + time.Format("2006-01-02 15:04:05"))
 	response.AddFlashMessage(*msg)
 	return response
@@ -348,6 +415,7 @@ func (s *gameManagerService) ScheduleGame(ctx context.Context, user *models.User
 		return response
 	}
 
+	before := auditInstance(&instance)
 	instance.StartTime = bun.NullTime{Time: start}
 	instance.EndTime = bun.NullTime{Time: end}
 
@@ -359,9 +427,27 @@ func (s *gameManagerService) ScheduleGame(ctx context.Context, user *models.User
 	}
 
 	user.CurrentInstance = instance
+
+	if err := s.auditInstance(ctx, models.AuditGameScheduled, &instance, before); err != nil {
+		response.Error = err
+	}
 	return response
 }
 
+// auditInstance records a change to an instance's schedule.
+func (s *gameManagerService) auditInstance(ctx context.Context, action models.AuditAction, instance *models.Instance, before map[string]any) error {
+	err := recordAudit(ctx, s.auditRepo, nil, models.AuditEvent{
+		InstanceID: instance.ID,
+		Action:     action,
+		EntityType: "instance",
+		EntityID:   instance.ID,
+	}, before, auditInstance(instance))
+	if err != nil {
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+	return nil
+}
+
 // DismissQuickstart marks the user as having dismissed the quickstart.
 func (s *gameManagerService) DismissQuickstart(ctx context.Context, instanceID string) error {
 	return s.instanceRepo.DismissQuickstart(ctx, instanceID)
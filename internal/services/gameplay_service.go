@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/nathanhollows/Rapua/v3/blocks"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
 	"github.com/nathanhollows/Rapua/v3/internal/flash"
 	"github.com/nathanhollows/Rapua/v3/models"
 	"github.com/nathanhollows/Rapua/v3/repositories"
@@ -21,6 +23,7 @@ var (
 	ErrAlreadyCheckedIn         = errors.New("player has already scanned in")
 	ErrUnecessaryCheckOut       = errors.New("player does not need to scan out")
 	ErrInstanceSettingsNotFound = errors.New("instance settings not found")
+	ErrTimeExpired              = errors.New("team has run out of time")
 )
 
 type GameplayService interface {
@@ -32,34 +35,49 @@ type GameplayService interface {
 	// CheckIn checks a team in at a location
 	// It also manages the points and mustScanOut fields
 	// As well as checking if any blocks must be completed
-	CheckIn(ctx context.Context, team *models.Team, locationCode string) error
+	// and that the team is close enough when check-ins are geofenced
+	CheckIn(ctx context.Context, team *models.Team, locationCode string, position *Position) error
+	// CheckInOnBehalf checks a team in for staff at the location, so the
+	// team's position is not checked
+	CheckInOnBehalf(ctx context.Context, team *models.Team, locationCode string) error
 	CheckOut(ctx context.Context, team *models.Team, locationCode string) error
+	// CheckInRadius returns the check-in radius for a location in metres, or 0 if it is not geofenced
+	CheckInRadius(ctx context.Context, team *models.Team, locationCode string) (int, error)
 	CheckValidLocation(ctx context.Context, team *models.Team, locationCode string) (bool, error)
 	ValidateAndUpdateBlockState(ctx context.Context, team models.Team, data map[string][]string) (blocks.PlayerState, blocks.Block, error)
 }
 
 type gameplayService struct {
+	Broker            *events.Broker
 	CheckInService    CheckInService
+	GeofenceService   GeofenceService
 	LocationService   LocationService
 	TeamService       TeamService
 	BlockService      BlockService
+	BonusService      BonusService
 	NavigationService NavigationService
 	MarkerRepository  repositories.MarkerRepository
 }
 
 func NewGameplayService(
+	broker *events.Broker,
 	checkInService CheckInService,
+	geofenceService GeofenceService,
 	locationService LocationService,
 	teamService TeamService,
 	blockService BlockService,
+	bonusService BonusService,
 	navigationService NavigationService,
 	markerRepository repositories.MarkerRepository,
 ) GameplayService {
 	return &gameplayService{
+		Broker:            broker,
 		CheckInService:    checkInService,
+		GeofenceService:   geofenceService,
 		LocationService:   locationService,
 		TeamService:       teamService,
 		BlockService:      blockService,
+		BonusService:      bonusService,
 		NavigationService: navigationService,
 		MarkerRepository:  markerRepository,
 	}
@@ -120,6 +138,10 @@ func (s *gameplayService) StartPlaying(ctx context.Context, teamCode, customTeam
 	if !team.HasStarted || customTeamName != "" {
 		team.Name = customTeamName
 		team.HasStarted = true
+		// Start the team's clock the first time they start
+		if team.StartedAt.IsZero() {
+			team.StartedAt = time.Now().UTC()
+		}
 		err = s.TeamService.Update(ctx, team)
 		if err != nil {
 			response.Error = fmt.Errorf("StartPlaying update team: %w", err)
@@ -156,13 +178,31 @@ func (s *gameplayService) SuggestNextLocations(ctx context.Context, team *models
 	return locations, nil
 }
 
-func (s *gameplayService) CheckIn(ctx context.Context, team *models.Team, locationCode string) error {
+func (s *gameplayService) CheckIn(ctx context.Context, team *models.Team, locationCode string, position *Position) error {
+	return s.checkIn(ctx, team, locationCode, position, true)
+}
+
+// CheckInOnBehalf checks a team in for staff at the location.
+// The staff member vouches for the team being there, so the team's
+// position is not checked. All other rules still apply.
+func (s *gameplayService) CheckInOnBehalf(ctx context.Context, team *models.Team, locationCode string) error {
+	return s.checkIn(ctx, team, locationCode, nil, false)
+}
+
+// checkIn checks a team in at a location, checking their position when
+// checkPosition is set.
+func (s *gameplayService) checkIn(ctx context.Context, team *models.Team, locationCode string, position *Position, checkPosition bool) error {
 	// Load team relations
 	err := s.TeamService.LoadRelations(ctx, team)
 	if err != nil {
 		return fmt.Errorf("loading relations: %w", err)
 	}
 
+	// A team may not check in once their time is up
+	if team.TimeExpired() {
+		return ErrTimeExpired
+	}
+
 	// A team may not check in if they must check out at a different location
 	if team.MustCheckOut != "" && locationCode != team.MustCheckOut {
 		return ErrAlreadyCheckedIn
@@ -194,6 +234,14 @@ func (s *gameplayService) CheckIn(ctx context.Context, team *models.Team, locati
 		return errors.New("location not valid for team")
 	}
 
+	// The team must be within the check-in radius if the location is geofenced
+	if checkPosition {
+		err = s.GeofenceService.CheckPosition(*location, team.Instance.Settings, position)
+		if err != nil {
+			return fmt.Errorf("checking position: %w", err)
+		}
+	}
+
 	// Check if any blocks require validation (e.g. a checklist)
 	validationRequired, err := s.BlockService.CheckValidationRequiredForLocation(ctx, location.ID)
 	if err != nil {
@@ -216,17 +264,54 @@ func (s *gameplayService) CheckIn(ctx context.Context, team *models.Team, locati
 	// If the team must check out, the location is saved to the team
 	if mustCheckOut {
 		team.MustCheckOut = location.ID
-	} else {
-		team.Points += location.Points
+		err = s.TeamService.Update(ctx, team)
+		if err != nil {
+			return fmt.Errorf("updating team: %w", err)
+		}
+	} else if location.Points != 0 {
+		err = s.TeamService.AwardPoints(ctx, team, &models.PointsTransaction{
+			Amount:   location.Points,
+			Reason:   fmt.Sprint("Checked in at ", location.Name),
+			Source:   models.LocationSource,
+			SourceID: location.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("awarding points: %w", err)
+		}
 	}
-	err = s.TeamService.Update(ctx, team)
+
+	err = s.BonusService.CheckedIn(ctx, team, location.ID)
 	if err != nil {
-		return fmt.Errorf("updating team: %w", err)
+		return fmt.Errorf("awarding bonus points: %w", err)
 	}
 
+	s.Broker.Publish(events.Event{
+		Type:       events.TeamCheckedIn,
+		InstanceID: team.InstanceID,
+		TeamCode:   team.Code,
+		Payload:    *location,
+	})
+
 	return nil
 }
 
+// CheckInRadius returns the check-in radius for a location in metres.
+func (s *gameplayService) CheckInRadius(ctx context.Context, team *models.Team, locationCode string) (int, error) {
+	if team.Instance.Settings.InstanceID == "" {
+		err := s.TeamService.LoadRelation(ctx, team, "Instance")
+		if err != nil {
+			return 0, fmt.Errorf("loading instance: %w", err)
+		}
+	}
+
+	location, err := s.LocationService.GetByInstanceAndCode(ctx, team.InstanceID, locationCode)
+	if err != nil {
+		return 0, fmt.Errorf("%w: finding location: %w", ErrLocationNotFound, err)
+	}
+
+	return s.GeofenceService.Radius(*location, team.Instance.Settings), nil
+}
+
 func (s *gameplayService) CheckOut(ctx context.Context, team *models.Team, locationCode string) error {
 
 	location, err := s.LocationService.GetByInstanceAndCode(ctx, team.InstanceID, locationCode)
@@ -262,6 +347,18 @@ func (s *gameplayService) CheckOut(ctx context.Context, team *models.Team, locat
 		return fmt.Errorf("logging scan out: %w", err)
 	}
 
+	err = s.BonusService.CheckedOut(ctx, team, location.ID)
+	if err != nil {
+		return fmt.Errorf("awarding bonus points: %w", err)
+	}
+
+	s.Broker.Publish(events.Event{
+		Type:       events.TeamCheckedOut,
+		InstanceID: team.InstanceID,
+		TeamCode:   team.Code,
+		Payload:    *location,
+	})
+
 	return nil
 }
 
@@ -304,7 +401,7 @@ func (s *gameplayService) ValidateAndUpdateBlockState(ctx context.Context, team
 
 	// Returning early here prevents the block from being updated
 	// And points from being added to the team multiple times
-	if state.IsComplete() {
+	if state.IsComplete() || state.GetReviewStatus() == blocks.ReviewStatusPending {
 		return state, block, nil
 	}
 
@@ -314,19 +411,34 @@ func (s *gameplayService) ValidateAndUpdateBlockState(ctx context.Context, team
 		return nil, nil, fmt.Errorf("validating block: %w", err)
 	}
 
-	state, err = s.BlockService.UpdateState(ctx, state)
-	if err != nil {
-		return nil, nil, fmt.Errorf("updating block state: %w", err)
+	// Completed submissions that require review are held for a
+	// facilitator instead of completing the block. The points earned
+	// are kept on the state and paid once the submission is approved
+	if block.RequiresReview() && state.IsComplete() {
+		state.SetComplete(false)
+		state.SetReviewStatus(blocks.ReviewStatusPending)
+		state.SetReviewComment("")
 	}
 
-	// Assign points on completion
 	if !state.IsComplete() {
+		state, err = s.BlockService.UpdateState(ctx, state)
+		if err != nil {
+			return nil, nil, fmt.Errorf("updating block state: %w", err)
+		}
 		return state, block, nil
 	}
-	err = s.TeamService.AwardPoints(ctx, &team, block.GetPoints(), fmt.Sprint("Completed block ", block.GetName()))
+
+	// The state and its points are saved together. Blocks may award
+	// partial credit, so the points come from the state
+	saved, err := s.BlockService.CompleteState(ctx, team.InstanceID, block, state)
 	if err != nil {
-		return nil, nil, fmt.Errorf("awarding points: %w", err)
+		return nil, nil, fmt.Errorf("completing block: %w", err)
 	}
+	if !saved {
+		// Another submission completed the block first
+		return state, block, nil
+	}
+	team.Points += state.GetPointsAwarded()
 
 	// Update the check in all blocks have been completed
 	unfinishedCheckIn, err := s.BlockService.CheckValidationRequiredForCheckIn(ctx, block.GetLocationID(), team.Code)
@@ -343,5 +455,10 @@ func (s *gameplayService) ValidateAndUpdateBlockState(ctx context.Context, team
 		return nil, nil, fmt.Errorf("completing blocks: %w", err)
 	}
 
+	err = s.BonusService.BlocksCompleted(ctx, &team, block.GetLocationID())
+	if err != nil {
+		return nil, nil, fmt.Errorf("awarding bonus points: %w", err)
+	}
+
 	return state, block, nil
 }
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/nathanhollows/Rapua/v3/models"
+)
+
+var (
+	ErrPositionRequired = errors.New("position required to check in")
+	ErrOutsideGeofence  = errors.New("position is outside the check-in radius")
+)
+
+const (
+	// earthRadius is the mean radius of the Earth in metres
+	earthRadius = 6371000.0
+	// maxAccuracyAllowance caps how much a poor GPS fix can extend the radius, in metres
+	maxAccuracyAllowance = 50.0
+)
+
+// Position is a location reported by a player's device.
+type Position struct {
+	Lat float64
+	Lng float64
+	// Accuracy is the reported accuracy of the position in metres
+	Accuracy float64
+}
+
+type GeofenceService interface {
+	// Radius returns the check-in radius for a location in metres, or 0 if check-ins are not geofenced
+	Radius(location models.Location, settings models.InstanceSettings) int
+	// Distance returns the great-circle distance between two points in metres
+	Distance(lat1, lng1, lat2, lng2 float64) float64
+	// CheckPosition returns an error if the position is not within the check-in radius of a location
+	CheckPosition(location models.Location, settings models.InstanceSettings, position *Position) error
+}
+
+type geofenceService struct{}
+
+func NewGeofenceService() GeofenceService {
+	return &geofenceService{}
+}
+
+// Radius returns the check-in radius for a location in metres.
+// A location's own radius takes precedence over the instance setting.
+// Locations without coordinates are never geofenced.
+func (s *geofenceService) Radius(location models.Location, settings models.InstanceSettings) int {
+	if !location.Marker.IsMapped() {
+		return 0
+	}
+	if location.CheckInRadius > 0 {
+		return location.CheckInRadius
+	}
+	return max(settings.CheckInRadius, 0)
+}
+
+// Distance returns the great-circle distance between two points in metres
+// using the haversine formula.
+func (s *geofenceService) Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lng2 - lng1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	return earthRadius * c
+}
+
+// CheckPosition returns an error if the position is not within the check-in
+// radius of a location. The reported accuracy of the position is added to the
+// radius, up to maxAccuracyAllowance, so a poor GPS fix does not lock out a
+// team standing at the marker.
+func (s *geofenceService) CheckPosition(location models.Location, settings models.InstanceSettings, position *Position) error {
+	radius := s.Radius(location, settings)
+	if radius == 0 {
+		return nil
+	}
+
+	if position == nil {
+		return ErrPositionRequired
+	}
+	if !isFinite(position.Lat) || !isFinite(position.Lng) || !isFinite(position.Accuracy) {
+		return fmt.Errorf("%w: invalid coordinates", ErrPositionRequired)
+	}
+	if position.Lat < -90 || position.Lat > 90 || position.Lng < -180 || position.Lng > 180 {
+		return fmt.Errorf("%w: invalid coordinates", ErrPositionRequired)
+	}
+
+	allowance := min(max(position.Accuracy, 0), maxAccuracyAllowance)
+	distance := s.Distance(position.Lat, position.Lng, location.Marker.Lat, location.Marker.Lng)
+	if distance > float64(radius)+allowance {
+		return fmt.Errorf("%w: %.0fm from location, radius %dm", ErrOutsideGeofence, distance, radius)
+	}
+
+	return nil
+}
+
+// isFinite reports whether f is neither NaN nor an infinity.
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
@@ -0,0 +1,107 @@
+package services_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeofenceService_Distance(t *testing.T) {
+	svc := services.NewGeofenceService()
+
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		expected               float64
+		delta                  float64
+	}{
+		{"Same point", -45.8665, 170.5146, -45.8665, 170.5146, 0, 0.001},
+		{"One degree of latitude", 0, 0, 1, 0, 111195, 1},
+		{"One degree of longitude at the equator", 0, 0, 0, 1, 111195, 1},
+		{"Across the antimeridian", 0, 179.5, 0, -179.5, 111195, 1},
+		{"Dunedin to Wellington", -45.8788, 170.5028, -41.2865, 174.7762, 615600, 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distance := svc.Distance(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+			assert.InDelta(t, tt.expected, distance, tt.delta)
+			// Distance is symmetric
+			assert.InDelta(t, distance, svc.Distance(tt.lat2, tt.lng2, tt.lat1, tt.lng1), 0.001)
+		})
+	}
+}
+
+func TestGeofenceService_Radius(t *testing.T) {
+	svc := services.NewGeofenceService()
+
+	mapped := models.Marker{Lat: -45.8665, Lng: 170.5146}
+
+	tests := []struct {
+		name     string
+		location models.Location
+		settings models.InstanceSettings
+		expected int
+	}{
+		{"Disabled", models.Location{Marker: mapped}, models.InstanceSettings{}, 0},
+		{"Instance radius", models.Location{Marker: mapped}, models.InstanceSettings{CheckInRadius: 100}, 100},
+		{"Location overrides instance", models.Location{Marker: mapped, CheckInRadius: 30}, models.InstanceSettings{CheckInRadius: 100}, 30},
+		{"Location radius without instance radius", models.Location{Marker: mapped, CheckInRadius: 30}, models.InstanceSettings{}, 30},
+		{"Unmapped location", models.Location{CheckInRadius: 30}, models.InstanceSettings{CheckInRadius: 100}, 0},
+		{"Negative instance radius", models.Location{Marker: mapped}, models.InstanceSettings{CheckInRadius: -10}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, svc.Radius(tt.location, tt.settings))
+		})
+	}
+}
+
+func TestGeofenceService_CheckPosition(t *testing.T) {
+	svc := services.NewGeofenceService()
+
+	location := models.Location{
+		Marker: models.Marker{Lat: -45.8665, Lng: 170.5146},
+	}
+	settings := models.InstanceSettings{CheckInRadius: 50}
+
+	// 0.0001 degrees of latitude is roughly 11m
+	near := &services.Position{Lat: -45.8667, Lng: 170.5146, Accuracy: 5}
+	far := &services.Position{Lat: -45.8673, Lng: 170.5146, Accuracy: 5}
+
+	tests := []struct {
+		name     string
+		location models.Location
+		settings models.InstanceSettings
+		position *services.Position
+		err      error
+	}{
+		{"Within radius", location, settings, near, nil},
+		{"Outside radius", location, settings, far, services.ErrOutsideGeofence},
+		{"Accuracy extends radius", location, settings, &services.Position{Lat: far.Lat, Lng: far.Lng, Accuracy: 60}, nil},
+		{"Accuracy allowance is capped", location, settings, &services.Position{Lat: -45.8685, Lng: 170.5146, Accuracy: 1000}, services.ErrOutsideGeofence},
+		{"Missing position", location, settings, nil, services.ErrPositionRequired},
+		{"Invalid position", location, settings, &services.Position{Lat: 200, Lng: 170.5146}, services.ErrPositionRequired},
+		{"NaN position", location, settings, &services.Position{Lat: math.NaN(), Lng: 170.5146}, services.ErrPositionRequired},
+		{"Infinite position", location, settings, &services.Position{Lat: -45.8665, Lng: math.Inf(1)}, services.ErrPositionRequired},
+		{"NaN accuracy", location, settings, &services.Position{Lat: far.Lat, Lng: far.Lng, Accuracy: math.NaN()}, services.ErrPositionRequired},
+		{"Geofencing disabled", location, models.InstanceSettings{}, nil, nil},
+		{"Unmapped location", models.Location{}, settings, nil, nil},
+		{"Location radius", models.Location{Marker: location.Marker, CheckInRadius: 200}, settings, far, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.CheckPosition(tt.location, tt.settings, tt.position)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+)
+
+var ErrNoHintsRemaining = errors.New("no hints remaining")
+
+type HintService interface {
+	// FindByLocation returns the hints for a location and its blocks, in order
+	FindByLocation(ctx context.Context, locationID string) ([]models.Hint, error)
+	// FindRevealed returns the hints a team has revealed, oldest first
+	FindRevealed(ctx context.Context, teamCode string) ([]models.TeamHint, error)
+	// UpdateHints replaces the hints for a location, or for a block when blockID is set
+	UpdateHints(ctx context.Context, instanceID, locationID, blockID string, hints []models.Hint) error
+	// RevealHint reveals the team's next hint for a location or block and deducts its penalty
+	RevealHint(ctx context.Context, team *models.Team, locationID, blockID string) (*models.Hint, error)
+}
+
+type hintService struct {
+	transactor  db.Transactor
+	hintRepo    repositories.HintRepository
+	teamService TeamService
+}
+
+func NewHintService(transactor db.Transactor, hintRepo repositories.HintRepository, teamService TeamService) HintService {
+	return &hintService{
+		transactor:  transactor,
+		hintRepo:    hintRepo,
+		teamService: teamService,
+	}
+}
+
+// FindByLocation returns the hints for a location and its blocks, in order.
+func (s *hintService) FindByLocation(ctx context.Context, locationID string) ([]models.Hint, error) {
+	if locationID == "" {
+		return nil, NewValidationError("locationID")
+	}
+	return s.hintRepo.FindByLocation(ctx, locationID)
+}
+
+// FindRevealed returns the hints a team has revealed, oldest first.
+func (s *hintService) FindRevealed(ctx context.Context, teamCode string) ([]models.TeamHint, error) {
+	if teamCode == "" {
+		return nil, NewValidationError("teamCode")
+	}
+	return s.hintRepo.FindTeamHints(ctx, teamCode)
+}
+
+// UpdateHints replaces the hints for a location, or for a block when blockID is set.
+// Hints are saved in the order given and hints without content are skipped.
+// Existing hints are updated in place so the hints teams have revealed stay linked.
+func (s *hintService) UpdateHints(ctx context.Context, instanceID, locationID, blockID string, hints []models.Hint) error {
+	if instanceID == "" {
+		return NewValidationError("instanceID")
+	}
+	if locationID == "" {
+		return NewValidationError("locationID")
+	}
+	for _, hint := range hints {
+		if hint.Penalty < 0 {
+			return errors.New("hint penalty must not be negative")
+		}
+	}
+
+	existing, err := s.hintRepo.FindByLocation(ctx, locationID)
+	if err != nil {
+		return fmt.Errorf("finding hints: %w", err)
+	}
+	current := make(map[string]bool)
+	for _, hint := range models.HintsFor(existing, blockID) {
+		current[hint.ID] = true
+	}
+
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	order := 0
+	for _, hint := range hints {
+		hint.Content = strings.TrimSpace(hint.Content)
+		if hint.Content == "" {
+			continue
+		}
+		// Only hints already at this location or block are updated, anything else is new
+		if current[hint.ID] {
+			delete(current, hint.ID)
+		} else {
+			hint.ID = ""
+		}
+		hint.InstanceID = instanceID
+		hint.LocationID = locationID
+		hint.BlockID = blockID
+		hint.Order = order
+		order++
+		err = s.hintRepo.Save(ctx, tx, &hint)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("saving hint: %w", err)
+		}
+	}
+
+	// Whatever was not resubmitted has been removed
+	for hintID := range current {
+		err = s.hintRepo.Delete(ctx, tx, hintID)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("deleting hint: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RevealHint reveals the team's next hint for a location or block and deducts its penalty.
+func (s *hintService) RevealHint(ctx context.Context, team *models.Team, locationID, blockID string) (*models.Hint, error) {
+	if team == nil {
+		return nil, NewValidationError("team")
+	}
+	if locationID == "" {
+		return nil, NewValidationError("locationID")
+	}
+
+	hints, err := s.hintRepo.FindByLocation(ctx, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("finding hints: %w", err)
+	}
+
+	revealed, err := s.hintRepo.FindTeamHints(ctx, team.Code)
+	if err != nil {
+		return nil, fmt.Errorf("finding revealed hints: %w", err)
+	}
+	seen := make(map[string]bool, len(revealed))
+	for _, teamHint := range revealed {
+		seen[teamHint.HintID] = true
+	}
+
+	// Hints are revealed one at a time, in order
+	var next *models.Hint
+	for i := range hints {
+		if hints[i].BlockID != blockID || seen[hints[i].ID] {
+			continue
+		}
+		next = &hints[i]
+		break
+	}
+	if next == nil {
+		return nil, ErrNoHintsRemaining
+	}
+	if next.InstanceID != team.InstanceID {
+		return nil, ErrPermissionDenied
+	}
+
+	err = s.hintRepo.SaveTeamHint(ctx, &models.TeamHint{
+		TeamCode:   team.Code,
+		HintID:     next.ID,
+		InstanceID: team.InstanceID,
+		LocationID: next.LocationID,
+		BlockID:    next.BlockID,
+		Penalty:    next.Penalty,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("saving revealed hint: %w", err)
+	}
+
+	if next.Penalty > 0 {
+		err = s.teamService.AwardPoints(ctx, team, &models.PointsTransaction{
+			Amount:   -next.Penalty,
+			Reason:   "Revealed a hint",
+			Source:   models.HintSource,
+			SourceID: next.ID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("deducting hint penalty: %w", err)
+		}
+	}
+
+	return next, nil
+}
@@ -0,0 +1,203 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupHintService(t *testing.T) (services.HintService, services.TeamService, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	hintRepo := repositories.NewHintRepository(dbc)
+	locationRepo := repositories.NewLocationRepository(dbc)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	auditRepo := repositories.NewAuditRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+
+	teamService := services.NewTeamService(transactor, events.NewBroker(), teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	hintService := services.NewHintService(transactor, hintRepo, teamService)
+
+	return hintService, teamService, cleanup
+}
+
+func TestHintService_UpdateHints(t *testing.T) {
+	svc, _, cleanup := setupHintService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	locationID := gofakeit.UUID()
+	blockID := gofakeit.UUID()
+
+	err := svc.UpdateHints(ctx, instanceID, locationID, "", []models.Hint{
+		{Content: "Near the river", Penalty: 2},
+		{Content: " "},
+		{Content: "Under the bridge", Penalty: 5},
+	})
+	require.NoError(t, err)
+	err = svc.UpdateHints(ctx, instanceID, locationID, blockID, []models.Hint{
+		{Content: "Count the windows"},
+	})
+	require.NoError(t, err)
+
+	hints, err := svc.FindByLocation(ctx, locationID)
+	require.NoError(t, err)
+	locationHints := models.HintsFor(hints, "")
+	require.Len(t, locationHints, 2, "empty hints are skipped")
+	assert.Equal(t, "Near the river", locationHints[0].Content)
+	assert.Equal(t, 1, locationHints[1].Order)
+	assert.Len(t, models.HintsFor(hints, blockID), 1)
+
+	// Updating the location hints replaces them, keeping IDs
+	err = svc.UpdateHints(ctx, instanceID, locationID, "", []models.Hint{
+		{ID: locationHints[1].ID, Content: "Under the bridge", Penalty: 5},
+	})
+	require.NoError(t, err)
+	hints, err = svc.FindByLocation(ctx, locationID)
+	require.NoError(t, err)
+	locationHints2 := models.HintsFor(hints, "")
+	require.Len(t, locationHints2, 1)
+	assert.Equal(t, locationHints[1].ID, locationHints2[0].ID)
+	assert.Len(t, models.HintsFor(hints, blockID), 1, "block hints are untouched")
+
+	err = svc.UpdateHints(ctx, instanceID, locationID, "", []models.Hint{{Content: "Bad", Penalty: -1}})
+	assert.Error(t, err)
+	hints, err = svc.FindByLocation(ctx, locationID)
+	require.NoError(t, err)
+	assert.Len(t, models.HintsFor(hints, ""), 1, "invalid hints leave the existing hints in place")
+
+	// Hints from another location are added rather than moved
+	otherLocationID := gofakeit.UUID()
+	err = svc.UpdateHints(ctx, instanceID, otherLocationID, "", []models.Hint{
+		{ID: locationHints[1].ID, Content: "Borrowed"},
+	})
+	require.NoError(t, err)
+	otherHints, err := svc.FindByLocation(ctx, otherLocationID)
+	require.NoError(t, err)
+	require.Len(t, otherHints, 1)
+	assert.NotEqual(t, locationHints[1].ID, otherHints[0].ID)
+	hints, err = svc.FindByLocation(ctx, locationID)
+	require.NoError(t, err)
+	assert.Equal(t, "Under the bridge", models.HintsFor(hints, "")[0].Content)
+
+	err = svc.UpdateHints(ctx, "", locationID, "", nil)
+	assert.ErrorIs(t, err, services.ErrInvalidArgument)
+}
+
+func TestHintService_RevealHint(t *testing.T) {
+	svc, teamService, cleanup := setupHintService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	locationID := gofakeit.UUID()
+	blockID := gofakeit.UUID()
+
+	teams, err := teamService.AddTeams(ctx, instanceID, 1)
+	require.NoError(t, err)
+	team := teams[0]
+	team.Points = 10
+
+	err = svc.UpdateHints(ctx, instanceID, locationID, "", []models.Hint{
+		{Content: "First", Penalty: 2},
+		{Content: "Second", Penalty: 3},
+	})
+	require.NoError(t, err)
+	err = svc.UpdateHints(ctx, instanceID, locationID, blockID, []models.Hint{
+		{Content: "Block", Penalty: 1},
+	})
+	require.NoError(t, err)
+
+	hint, err := svc.RevealHint(ctx, &team, locationID, "")
+	require.NoError(t, err)
+	assert.Equal(t, "First", hint.Content)
+	assert.Equal(t, 8, team.Points)
+
+	hint, err = svc.RevealHint(ctx, &team, locationID, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Second", hint.Content)
+	assert.Equal(t, 5, team.Points)
+
+	_, err = svc.RevealHint(ctx, &team, locationID, "")
+	assert.ErrorIs(t, err, services.ErrNoHintsRemaining)
+
+	hint, err = svc.RevealHint(ctx, &team, locationID, blockID)
+	require.NoError(t, err)
+	assert.Equal(t, "Block", hint.Content)
+	assert.Equal(t, 4, team.Points)
+
+	revealed, err := svc.FindRevealed(ctx, team.Code)
+	require.NoError(t, err)
+	require.Len(t, revealed, 3)
+	blockHints := 0
+	for _, teamHint := range revealed {
+		if teamHint.BlockID == blockID {
+			blockHints++
+		}
+	}
+	assert.Equal(t, 1, blockHints)
+
+	// Teams cannot reveal hints from other instances
+	other := models.Team{Code: "OTHER", InstanceID: gofakeit.UUID()}
+	_, err = svc.RevealHint(ctx, &other, locationID, "")
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+}
+
+func TestHintService_UpdateHints_KeepsRevealedHints(t *testing.T) {
+	svc, teamService, cleanup := setupHintService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	locationID := gofakeit.UUID()
+
+	teams, err := teamService.AddTeams(ctx, instanceID, 1)
+	require.NoError(t, err)
+	team := teams[0]
+	team.Points = 10
+
+	err = svc.UpdateHints(ctx, instanceID, locationID, "", []models.Hint{
+		{Content: "First", Penalty: 2},
+		{Content: "Second", Penalty: 3},
+	})
+	require.NoError(t, err)
+
+	first, err := svc.RevealHint(ctx, &team, locationID, "")
+	require.NoError(t, err)
+	assert.Equal(t, 8, team.Points)
+
+	// Editing a revealed hint does not make the team pay for it again
+	hints, err := svc.FindByLocation(ctx, locationID)
+	require.NoError(t, err)
+	err = svc.UpdateHints(ctx, instanceID, locationID, "", []models.Hint{
+		{ID: hints[0].ID, Content: "First, reworded", Penalty: 2},
+		{ID: hints[1].ID, Content: "Second", Penalty: 3},
+	})
+	require.NoError(t, err)
+
+	revealed, err := svc.FindRevealed(ctx, team.Code)
+	require.NoError(t, err)
+	require.Len(t, revealed, 1)
+	assert.Equal(t, first.ID, revealed[0].HintID)
+	assert.Equal(t, "First, reworded", revealed[0].Hint.Content)
+
+	next, err := svc.RevealHint(ctx, &team, locationID, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Second", next.Content)
+	assert.Equal(t, 5, team.Points)
+}
@@ -0,0 +1,742 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nathanhollows/Rapua/v3/blocks"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/uptrace/bun"
+)
+
+// BundleVersion is the version of the instance bundle format.
+// Increase it when the format changes in a way older servers cannot read.
+const BundleVersion = 1
+
+// bundleManifest is the name of the instance data within a ZIP bundle.
+const bundleManifest = "instance.json"
+
+// MaxBundleSize is the largest bundle that can be imported.
+// The files in a ZIP bundle may not add up to more than this once unzipped.
+const MaxBundleSize = 100 << 20
+
+var ErrUnsupportedBundle = errors.New("bundle was made by a newer version of Rapua")
+
+type InstanceBundleService interface {
+	// Export writes an instance as a ZIP bundle that includes its uploads
+	Export(ctx context.Context, instanceID string, w io.Writer) error
+	// ExportJSON writes an instance as a JSON bundle
+	// Uploads are referenced by URL rather than included
+	ExportJSON(ctx context.Context, instanceID string, w io.Writer) error
+	// Import rebuilds a ZIP or JSON bundle as a new instance for the user
+	Import(ctx context.Context, user *models.User, r io.Reader) (*models.Instance, error)
+}
+
+// InstanceBundle is a portable copy of an instance.
+// IDs and marker codes are only used to link parts of the bundle together;
+// importing a bundle always creates new ones.
+type InstanceBundle struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Name       string            `json:"name"`
+	Settings   BundleSettings    `json:"settings"`
+	Locations  []BundleLocation  `json:"locations"`
+	BonusRules []BundleBonusRule `json:"bonus_rules,omitempty"`
+	Routes     []BundleRoute     `json:"routes,omitempty"`
+	Uploads    []BundleUpload    `json:"uploads,omitempty"`
+}
+
+// BundleSettings are the instance settings carried in a bundle.
+// Share links such as the leaderboard token are left out.
+type BundleSettings struct {
+	NavigationMode       models.NavigationMode   `json:"navigation_mode"`
+	NavigationMethod     models.NavigationMethod `json:"navigation_method"`
+	MaxNextLocations     int                     `json:"max_next_locations"`
+	CompletionMethod     models.CompletionMethod `json:"completion_method"`
+	ShowTeamCount        bool                    `json:"show_team_count"`
+	EnablePoints         bool                    `json:"enable_points"`
+	EnableBonusPoints    bool                    `json:"enable_bonus_points"`
+	ShowLeaderboard      bool                    `json:"show_leaderboard"`
+	CheckInRadius        int                     `json:"check_in_radius"`
+	TeamDuration         int                     `json:"team_duration"`
+	LeaderboardAnonymise bool                    `json:"leaderboard_anonymise"`
+	LeaderboardFreeze    int                     `json:"leaderboard_freeze"`
+	LeaderboardFreezeTop int                     `json:"leaderboard_freeze_top"`
+}
+
+// BundleLocation is a location with its marker, clues, hints and blocks.
+type BundleLocation struct {
+	Name          string                  `json:"name"`
+	Marker        BundleMarker            `json:"marker"`
+	Order         int                     `json:"order"`
+	Points        int                     `json:"points"`
+	Completion    models.CompletionMethod `json:"completion"`
+	CheckInRadius int                     `json:"check_in_radius"`
+	Capacity      int                     `json:"capacity"`
+	UnlockRules   models.UnlockRules      `json:"unlock_rules,omitempty"`
+	Clues         []string                `json:"clues,omitempty"`
+	Blocks        []BundleBlock           `json:"blocks,omitempty"`
+	Hints         []BundleHint            `json:"hints,omitempty"`
+}
+
+// BundleMarker is the place a location is found.
+type BundleMarker struct {
+	Code string  `json:"code"`
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lng  float64 `json:"lng"`
+}
+
+// BundleBlock is a content block, in order.
+type BundleBlock struct {
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	Data           json.RawMessage `json:"data"`
+	Points         int             `json:"points"`
+	ReviewRequired bool            `json:"review_required"`
+}
+
+// BundleHint is a hint for a location, or for one of its blocks when
+// BlockID is set.
+type BundleHint struct {
+	BlockID string `json:"block_id,omitempty"`
+	Order   int    `json:"order"`
+	Content string `json:"content"`
+	Penalty int    `json:"penalty"`
+}
+
+// BundleBonusRule is a bonus rule for the instance.
+type BundleBonusRule struct {
+	Type      models.BonusRuleType `json:"type"`
+	Points    int                  `json:"points"`
+	Threshold int                  `json:"threshold"`
+}
+
+// BundleRoute is an ordering of locations, by marker code.
+type BundleRoute struct {
+	Name      string   `json:"name"`
+	Locations []string `json:"locations"`
+}
+
+// BundleUpload is a file used by the instance's blocks.
+// File is the path of the file within a ZIP bundle, and is empty when the
+// file was not included.
+type BundleUpload struct {
+	URL  string           `json:"url"`
+	Type models.MediaType `json:"type"`
+	File string           `json:"file,omitempty"`
+}
+
+type instanceBundleService struct {
+	transactor           db.Transactor
+	uploadService        UploadService
+	auditRepo            repositories.AuditRepository
+	blockRepo            repositories.BlockRepository
+	bonusRepo            repositories.BonusRepository
+	clueRepo             repositories.ClueRepository
+	hintRepo             repositories.HintRepository
+	instanceRepo         repositories.InstanceRepository
+	instanceSettingsRepo repositories.InstanceSettingsRepository
+	locationRepo         repositories.LocationRepository
+	markerRepo           repositories.MarkerRepository
+	routeRepo            repositories.RouteRepository
+	uploadRepo           repositories.UploadsRepository
+}
+
+// NewInstanceBundleService creates a new InstanceBundleService.
+func NewInstanceBundleService(
+	transactor db.Transactor,
+	uploadService UploadService,
+	auditRepo repositories.AuditRepository,
+	blockRepo repositories.BlockRepository,
+	bonusRepo repositories.BonusRepository,
+	clueRepo repositories.ClueRepository,
+	hintRepo repositories.HintRepository,
+	instanceRepo repositories.InstanceRepository,
+	instanceSettingsRepo repositories.InstanceSettingsRepository,
+	locationRepo repositories.LocationRepository,
+	markerRepo repositories.MarkerRepository,
+	routeRepo repositories.RouteRepository,
+	uploadRepo repositories.UploadsRepository,
+) InstanceBundleService {
+	return &instanceBundleService{
+		transactor:           transactor,
+		uploadService:        uploadService,
+		auditRepo:            auditRepo,
+		blockRepo:            blockRepo,
+		bonusRepo:            bonusRepo,
+		clueRepo:             clueRepo,
+		hintRepo:             hintRepo,
+		instanceRepo:         instanceRepo,
+		instanceSettingsRepo: instanceSettingsRepo,
+		locationRepo:         locationRepo,
+		markerRepo:           markerRepo,
+		routeRepo:            routeRepo,
+		uploadRepo:           uploadRepo,
+	}
+}
+
+// Export writes an instance as a ZIP bundle that includes its uploads.
+// Uploads that cannot be read from storage are referenced by URL instead.
+func (s *instanceBundleService) Export(ctx context.Context, instanceID string, w io.Writer) error {
+	bundle, uploads, err := s.bundle(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	for i, upload := range uploads {
+		file, err := s.uploadService.Open(ctx, upload)
+		if err != nil {
+			continue
+		}
+		name := fmt.Sprintf("uploads/%d%s", i+1, path.Ext(upload.OriginalURL))
+		err = writeZipFile(archive, name, file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("adding upload to bundle: %w", err)
+		}
+		bundle.Uploads[i].File = name
+	}
+
+	manifest, err := archive.Create(bundleManifest)
+	if err != nil {
+		return fmt.Errorf("adding instance to bundle: %w", err)
+	}
+	err = writeBundle(manifest, bundle)
+	if err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// ExportJSON writes an instance as a JSON bundle.
+func (s *instanceBundleService) ExportJSON(ctx context.Context, instanceID string, w io.Writer) error {
+	bundle, _, err := s.bundle(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+	return writeBundle(w, bundle)
+}
+
+// bundle collects an instance and the uploads its blocks use.
+func (s *instanceBundleService) bundle(ctx context.Context, instanceID string) (*InstanceBundle, []*models.Upload, error) {
+	if instanceID == "" {
+		return nil, nil, NewValidationError("instanceID")
+	}
+
+	instance, err := s.instanceRepo.GetByID(ctx, instanceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("finding instance: %w", err)
+	}
+
+	settings := instance.Settings
+	bundle := &InstanceBundle{
+		Version:    BundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Name:       instance.Name,
+		Settings: BundleSettings{
+			NavigationMode:       settings.NavigationMode,
+			NavigationMethod:     settings.NavigationMethod,
+			MaxNextLocations:     settings.MaxNextLocations,
+			CompletionMethod:     settings.CompletionMethod,
+			ShowTeamCount:        settings.ShowTeamCount,
+			EnablePoints:         settings.EnablePoints,
+			EnableBonusPoints:    settings.EnableBonusPoints,
+			ShowLeaderboard:      settings.ShowLeaderboard,
+			CheckInRadius:        settings.CheckInRadius,
+			TeamDuration:         settings.TeamDuration,
+			LeaderboardAnonymise: settings.LeaderboardAnonymise,
+			LeaderboardFreeze:    settings.LeaderboardFreeze,
+			LeaderboardFreezeTop: settings.LeaderboardFreezeTop,
+		},
+		Locations: []BundleLocation{},
+	}
+
+	locations, err := s.locationRepo.FindByInstance(ctx, instanceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("finding locations: %w", err)
+	}
+	sort.SliceStable(locations, func(i, j int) bool {
+		return locations[i].Order < locations[j].Order
+	})
+
+	var blockData [][]byte
+	for _, location := range locations {
+		err := s.locationRepo.LoadRelations(ctx, &location)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading location: %w", err)
+		}
+		sort.SliceStable(location.Blocks, func(i, j int) bool {
+			return location.Blocks[i].Ordering < location.Blocks[j].Ordering
+		})
+
+		bundled := BundleLocation{
+			Name: location.Name,
+			Marker: BundleMarker{
+				Code: location.Marker.Code,
+				Name: location.Marker.Name,
+				Lat:  location.Marker.Lat,
+				Lng:  location.Marker.Lng,
+			},
+			Order:         location.Order,
+			Points:        location.Points,
+			Completion:    location.Completion,
+			CheckInRadius: location.CheckInRadius,
+			Capacity:      location.Capacity,
+			UnlockRules:   location.UnlockRules,
+		}
+		for _, clue := range location.Clues {
+			bundled.Clues = append(bundled.Clues, clue.Content)
+		}
+		for _, block := range location.Blocks {
+			bundled.Blocks = append(bundled.Blocks, BundleBlock{
+				ID:             block.ID,
+				Type:           block.Type,
+				Data:           block.Data,
+				Points:         block.Points,
+				ReviewRequired: block.ReviewRequired,
+			})
+			blockData = append(blockData, block.Data)
+		}
+
+		hints, err := s.hintRepo.FindByLocation(ctx, location.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("finding hints: %w", err)
+		}
+		for _, hint := range hints {
+			bundled.Hints = append(bundled.Hints, BundleHint{
+				BlockID: hint.BlockID,
+				Order:   hint.Order,
+				Content: hint.Content,
+				Penalty: hint.Penalty,
+			})
+		}
+		bundle.Locations = append(bundle.Locations, bundled)
+	}
+
+	rules, err := s.bonusRepo.FindRulesByInstance(ctx, instanceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("finding bonus rules: %w", err)
+	}
+	for _, rule := range rules {
+		bundle.BonusRules = append(bundle.BonusRules, BundleBonusRule{
+			Type:      rule.Type,
+			Points:    rule.Points,
+			Threshold: rule.Threshold,
+		})
+	}
+
+	routes, err := s.routeRepo.FindByInstance(ctx, instanceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("finding routes: %w", err)
+	}
+	for _, route := range routes {
+		bundle.Routes = append(bundle.Routes, BundleRoute{
+			Name:      route.Name,
+			Locations: route.Locations,
+		})
+	}
+
+	// Only uploads added by admins and used in a block are carried over
+	found, err := s.uploadRepo.SearchByCriteria(ctx, map[string]string{
+		"instance_id": instanceID,
+		"team_code":   "NULL",
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("finding uploads: %w", err)
+	}
+	uploads := []*models.Upload{}
+	for _, upload := range found {
+		if !referenced(blockData, upload.OriginalURL) {
+			continue
+		}
+		uploads = append(uploads, upload)
+		bundle.Uploads = append(bundle.Uploads, BundleUpload{
+			URL:  upload.OriginalURL,
+			Type: upload.Type,
+		})
+	}
+
+	return bundle, uploads, nil
+}
+
+// Import rebuilds a ZIP or JSON bundle as a new instance for the user.
+// Locations, markers, blocks, hints, bonus rules, routes and uploads are all
+// created afresh, and any references between them are updated to match.
+// A bundle that refers to a location or block it does not contain is rejected. The instance is created in
+// a single transaction, so a bundle that fails part way leaves nothing behind.
+func (s *instanceBundleService) Import(ctx context.Context, user *models.User, r io.Reader) (*models.Instance, error) {
+	if user == nil {
+		return nil, ErrUserNotAuthenticated
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r, MaxBundleSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading bundle: %w", err)
+	}
+	if len(content) > MaxBundleSize {
+		return nil, fmt.Errorf("%w: bundle is too large", ErrInvalidArgument)
+	}
+
+	bundle, files, err := readBundle(content)
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Version < 1 || bundle.Version > BundleVersion {
+		return nil, ErrUnsupportedBundle
+	}
+	if bundle.Name == "" {
+		return nil, NewValidationError("name")
+	}
+
+	instance := &models.Instance{
+		ID:             uuid.New().String(),
+		Name:           bundle.Name,
+		UserID:         user.ID,
+		OrganisationID: user.CurrentOrganisationID,
+	}
+
+	// Store the included uploads so blocks can point at the new copies.
+	// Files are stored before the transaction starts as storage cannot be
+	// rolled back
+	urls := map[string]string{}
+	for _, upload := range bundle.Uploads {
+		file, ok := files[upload.File]
+		if upload.File == "" || !ok {
+			continue
+		}
+		stored, err := s.uploadService.StoreFile(ctx, file, path.Base(upload.File), UploadMetadata{
+			InstanceID: instance.ID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("storing upload: %w", err)
+		}
+		urls[upload.URL] = stored.OriginalURL
+	}
+
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	err = s.importBundle(ctx, tx, instance, bundle, urls)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+	return instance, nil
+}
+
+// importBundle creates the instance and everything in it as part of the import transaction.
+// urls maps the upload URLs in the bundle to the stored copies.
+func (s *instanceBundleService) importBundle(ctx context.Context, tx *bun.Tx, instance *models.Instance, bundle *InstanceBundle, urls map[string]string) error {
+	if err := s.instanceRepo.CreateWithTransaction(ctx, tx, instance); err != nil {
+		return fmt.Errorf("creating instance: %w", err)
+	}
+
+	settings := &models.InstanceSettings{
+		InstanceID:           instance.ID,
+		NavigationMode:       bundle.Settings.NavigationMode,
+		NavigationMethod:     bundle.Settings.NavigationMethod,
+		MaxNextLocations:     bundle.Settings.MaxNextLocations,
+		CompletionMethod:     bundle.Settings.CompletionMethod,
+		ShowTeamCount:        bundle.Settings.ShowTeamCount,
+		EnablePoints:         bundle.Settings.EnablePoints,
+		EnableBonusPoints:    bundle.Settings.EnableBonusPoints,
+		ShowLeaderboard:      bundle.Settings.ShowLeaderboard,
+		CheckInRadius:        bundle.Settings.CheckInRadius,
+		TeamDuration:         bundle.Settings.TeamDuration,
+		LeaderboardAnonymise: bundle.Settings.LeaderboardAnonymise,
+		LeaderboardFreeze:    bundle.Settings.LeaderboardFreeze,
+		LeaderboardFreezeTop: bundle.Settings.LeaderboardFreezeTop,
+	}
+	if err := s.instanceSettingsRepo.CreateWithTransaction(ctx, tx, settings); err != nil {
+		return fmt.Errorf("creating settings: %w", err)
+	}
+
+	markerCodes := map[string]string{}
+	blockIDs := map[string]string{}
+	locations := make([]models.Location, len(bundle.Locations))
+	for i, bundled := range bundle.Locations {
+		marker := models.Marker{
+			Name: bundled.Marker.Name,
+			Lat:  bundled.Marker.Lat,
+			Lng:  bundled.Marker.Lng,
+		}
+		if err := s.markerRepo.CreateWithTransaction(ctx, tx, &marker); err != nil {
+			return fmt.Errorf("creating marker: %w", err)
+		}
+		markerCodes[bundled.Marker.Code] = marker.Code
+
+		location := models.Location{
+			Name:          bundled.Name,
+			InstanceID:    instance.ID,
+			MarkerID:      marker.Code,
+			Order:         bundled.Order,
+			Points:        bundled.Points,
+			Completion:    bundled.Completion,
+			CheckInRadius: bundled.CheckInRadius,
+			Capacity:      bundled.Capacity,
+		}
+		if err := s.locationRepo.CreateWithTransaction(ctx, tx, &location); err != nil {
+			return fmt.Errorf("creating location: %w", err)
+		}
+
+		for _, content := range bundled.Clues {
+			clue := models.Clue{
+				InstanceID: instance.ID,
+				LocationID: location.ID,
+				Content:    content,
+			}
+			if err := s.clueRepo.SaveWithTransaction(ctx, tx, &clue); err != nil {
+				return fmt.Errorf("creating clue: %w", err)
+			}
+		}
+
+		order := make([]string, 0, len(bundled.Blocks))
+		for _, bundledBlock := range bundled.Blocks {
+			data := replaceURLs(bundledBlock.Data, urls)
+			block, err := blocks.CreateFromBaseBlock(blocks.BaseBlock{
+				LocationID:     location.ID,
+				Type:           bundledBlock.Type,
+				Data:           data,
+				Points:         bundledBlock.Points,
+				ReviewRequired: bundledBlock.ReviewRequired,
+			})
+			if err != nil {
+				return fmt.Errorf("creating block: %w", err)
+			}
+			if err := block.ParseData(); err != nil {
+				return fmt.Errorf("reading block data: %w", err)
+			}
+			block, err = s.blockRepo.CreateWithTransaction(ctx, tx, block, location.ID)
+			if err != nil {
+				return fmt.Errorf("saving block: %w", err)
+			}
+			blockIDs[bundledBlock.ID] = block.GetID()
+			order = append(order, block.GetID())
+		}
+		if len(order) > 0 {
+			if err := s.blockRepo.ReorderWithTransaction(ctx, tx, location.ID, order); err != nil {
+				return fmt.Errorf("ordering blocks: %w", err)
+			}
+		}
+
+		for _, bundledHint := range bundled.Hints {
+			hint := models.Hint{
+				InstanceID: instance.ID,
+				LocationID: location.ID,
+				Order:      bundledHint.Order,
+				Content:    bundledHint.Content,
+				Penalty:    bundledHint.Penalty,
+			}
+			if bundledHint.BlockID != "" {
+				blockID, ok := blockIDs[bundledHint.BlockID]
+				if !ok {
+					return fmt.Errorf("%w: hint refers to unknown block %s", ErrInvalidArgument, bundledHint.BlockID)
+				}
+				hint.BlockID = blockID
+			}
+			if err := s.hintRepo.Save(ctx, tx, &hint); err != nil {
+				return fmt.Errorf("creating hint: %w", err)
+			}
+		}
+
+		locations[i] = location
+	}
+
+	// Unlock rules refer to other locations and blocks, which now exist
+	for i, bundled := range bundle.Locations {
+		if len(bundled.UnlockRules) == 0 {
+			continue
+		}
+		rules := make(models.UnlockRules, len(bundled.UnlockRules))
+		for j, rule := range bundled.UnlockRules {
+			rule.Locations = append([]string{}, rule.Locations...)
+			for k, code := range rule.Locations {
+				newCode, ok := markerCodes[code]
+				if !ok {
+					return fmt.Errorf("%w: unlock rule refers to unknown location %s", ErrInvalidArgument, code)
+				}
+				rule.Locations[k] = newCode
+			}
+			if rule.BlockID != "" {
+				blockID, ok := blockIDs[rule.BlockID]
+				if !ok {
+					return fmt.Errorf("%w: unlock rule refers to unknown block %s", ErrInvalidArgument, rule.BlockID)
+				}
+				rule.BlockID = blockID
+			}
+			rules[j] = rule
+		}
+		locations[i].UnlockRules = rules
+		if err := s.locationRepo.UpdateWithTransaction(ctx, tx, &locations[i]); err != nil {
+			return fmt.Errorf("updating unlock rules: %w", err)
+		}
+	}
+
+	ruleTypes := models.GetBonusRuleTypes()
+	for _, bundledRule := range bundle.BonusRules {
+		if bundledRule.Type < 0 || int(bundledRule.Type) >= len(ruleTypes) {
+			return fmt.Errorf("%w: unknown bonus rule type %d", ErrInvalidArgument, bundledRule.Type)
+		}
+		rule := models.BonusRule{
+			InstanceID: instance.ID,
+			Type:       bundledRule.Type,
+			Points:     bundledRule.Points,
+			Threshold:  bundledRule.Threshold,
+		}
+		if err := s.bonusRepo.SaveRuleWithTransaction(ctx, tx, &rule); err != nil {
+			return fmt.Errorf("creating bonus rule: %w", err)
+		}
+	}
+
+	routes := make([]models.Route, len(bundle.Routes))
+	for i, bundledRoute := range bundle.Routes {
+		codes := make(models.StrArray, len(bundledRoute.Locations))
+		for j, code := range bundledRoute.Locations {
+			newCode, ok := markerCodes[code]
+			if !ok {
+				return fmt.Errorf("%w: route refers to unknown location %s", ErrInvalidArgument, code)
+			}
+			codes[j] = newCode
+		}
+		routes[i] = models.Route{
+			InstanceID: instance.ID,
+			Name:       bundledRoute.Name,
+			Locations:  codes,
+		}
+	}
+	if err := s.routeRepo.InsertBatch(ctx, tx, routes); err != nil {
+		return fmt.Errorf("creating routes: %w", err)
+	}
+
+	err := recordAudit(ctx, s.auditRepo, tx, models.AuditEvent{
+		InstanceID: instance.ID,
+		Action:     models.AuditInstanceImported,
+		EntityType: "instance",
+		EntityID:   instance.ID,
+	}, nil, auditInstance(instance))
+	if err != nil {
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+	return nil
+}
+
+// readBundle reads a ZIP or JSON bundle.
+// Files included in a ZIP bundle are returned by their path.
+func readBundle(content []byte) (*InstanceBundle, map[string][]byte, error) {
+	bundle := &InstanceBundle{}
+	files := map[string][]byte{}
+
+	if !bytes.HasPrefix(content, []byte("PK")) {
+		if err := json.Unmarshal(content, bundle); err != nil {
+			return nil, nil, fmt.Errorf("%w: bundle is not valid JSON: %w", ErrInvalidArgument, err)
+		}
+		return bundle, files, nil
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: bundle is not a valid ZIP file: %w", ErrInvalidArgument, err)
+	}
+	// The sizes in a ZIP file cannot be trusted, so stop reading once the
+	// unzipped files grow too large
+	remaining := int64(MaxBundleSize)
+	for _, f := range archive.File {
+		file, err := f.Open()
+		if err != nil {
+			return nil, nil, fmt.Errorf("opening %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(io.LimitReader(file, remaining+1))
+		file.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		remaining -= int64(len(data))
+		if remaining < 0 {
+			return nil, nil, fmt.Errorf("%w: bundle is too large once unzipped", ErrInvalidArgument)
+		}
+		files[f.Name] = data
+	}
+
+	manifest, ok := files[bundleManifest]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: bundle is missing %s", ErrInvalidArgument, bundleManifest)
+	}
+	if err := json.Unmarshal(manifest, bundle); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s is not valid JSON: %w", ErrInvalidArgument, bundleManifest, err)
+	}
+	return bundle, files, nil
+}
+
+func writeBundle(w io.Writer, bundle *InstanceBundle) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+	return nil
+}
+
+func writeZipFile(archive *zip.Writer, name string, r io.Reader) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// referenced reports whether any of the block data mentions the URL.
+func referenced(blockData [][]byte, url string) bool {
+	for _, data := range blockData {
+		if bytes.Contains(data, []byte(url)) || bytes.Contains(data, []byte(escapeSlashes(url))) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceURLs points block data at the stored copies of uploads.
+// urls maps the upload URLs in the bundle to the stored copies.
+func replaceURLs(data []byte, urls map[string]string) []byte {
+	for oldURL, newURL := range urls {
+		data = bytes.ReplaceAll(data, []byte(oldURL), []byte(newURL))
+		data = bytes.ReplaceAll(data, []byte(escapeSlashes(oldURL)), []byte(escapeSlashes(newURL)))
+	}
+	return data
+}
+
+// escapeSlashes writes a URL the way some JSON encoders do, with each
+// slash escaped.
+func escapeSlashes(url string) string {
+	return strings.ReplaceAll(url, "/", `\/`)
+}
@@ -0,0 +1,433 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/blocks"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func setupInstanceBundleService(t *testing.T) (services.InstanceBundleService, services.InstanceService, services.LocationService, *bun.DB, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+
+	auditRepo := repositories.NewAuditRepository(dbc)
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	blockRepo := repositories.NewBlockRepository(dbc, blockStateRepo)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	clueRepo := repositories.NewClueRepository(dbc)
+	hintRepo := repositories.NewHintRepository(dbc)
+	instanceRepo := repositories.NewInstanceRepository(dbc)
+	instanceSettingsRepo := repositories.NewInstanceSettingsRepository(dbc)
+	locationRepo := repositories.NewLocationRepository(dbc)
+	markerRepo := repositories.NewMarkerRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	routeRepo := repositories.NewRouteRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+	uploadRepo := repositories.NewUploadRepository(dbc)
+	userRepo := repositories.NewUserRepository(dbc)
+
+	locationService := services.NewLocationService(transactor, clueRepo, hintRepo, locationRepo, markerRepo, blockRepo, auditRepo)
+	teamService := services.NewTeamService(transactor, events.NewBroker(), teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	userService := services.NewUserService(transactor, userRepo, instanceRepo)
+	memberRepo := repositories.NewInstanceMemberRepository(dbc)
+	orgRepo := repositories.NewOrganisationRepository(dbc)
+	collaboratorService := services.NewCollaboratorService(&mockEmailService{}, instanceRepo, locationRepo, memberRepo, orgRepo, userRepo)
+	instanceService := services.NewInstanceService(
+		transactor,
+		locationService, userService, teamService, collaboratorService, instanceRepo, instanceSettingsRepo, auditRepo,
+		memberRepo, orgRepo,
+	)
+	uploadService := services.NewUploadService(uploadRepo, &mockUploadStorage{})
+	bundleService := services.NewInstanceBundleService(
+		transactor, uploadService,
+		auditRepo, blockRepo, bonusRepo, clueRepo, hintRepo, instanceRepo, instanceSettingsRepo, locationRepo, markerRepo, routeRepo, uploadRepo,
+	)
+
+	return bundleService, instanceService, locationService, dbc, cleanup
+}
+
+// createBundleUser creates the user that owns and imports bundles.
+func createBundleUser(t *testing.T, dbc *bun.DB) *models.User {
+	t.Helper()
+	user := &models.User{ID: gofakeit.UUID(), Email: gofakeit.Email()}
+	require.NoError(t, repositories.NewUserRepository(dbc).Create(context.Background(), user))
+	return user
+}
+
+// createBundleFixture creates an instance with two locations, where the
+// second location is unlocked by the first, and returns its ID.
+// The instance also has a hint, a bonus rule and a route.
+func createBundleFixture(t *testing.T, dbc *bun.DB, instanceService services.InstanceService, locationService services.LocationService, user *models.User) string {
+	t.Helper()
+	ctx := context.Background()
+
+	instance, err := instanceService.CreateInstance(ctx, "Museum trail", user)
+	require.NoError(t, err)
+
+	first, err := locationService.CreateLocation(ctx, instance.ID, "Entrance", -45.86, 170.51, 10)
+	require.NoError(t, err)
+	second, err := locationService.CreateLocation(ctx, instance.ID, "Gallery", -45.87, 170.52, 20)
+	require.NoError(t, err)
+
+	err = repositories.NewClueRepository(dbc).Save(ctx, &models.Clue{InstanceID: instance.ID, LocationID: first.ID, Content: "Look up"})
+	require.NoError(t, err)
+
+	imageURL := "https://cdn.example.com/2025/01/01/photo.jpg"
+	uploadRepo := repositories.NewUploadRepository(dbc)
+	err = uploadRepo.Create(ctx, &models.Upload{
+		OriginalURL: imageURL,
+		InstanceID:  instance.ID,
+		Storage:     "mock",
+		Type:        models.MediaTypeImage,
+	})
+	require.NoError(t, err)
+
+	blockRepo := repositories.NewBlockRepository(dbc, repositories.NewBlockStateRepository(dbc))
+	var blockIDs []string
+	for _, base := range []blocks.BaseBlock{
+		{Type: "markdown", Data: json.RawMessage(`{"content":"Welcome"}`)},
+		{Type: "image", Data: json.RawMessage(`{"content":"` + imageURL + `","caption":"","link":""}`)},
+	} {
+		base.LocationID = first.ID
+		block, err := blocks.CreateFromBaseBlock(base)
+		require.NoError(t, err)
+		require.NoError(t, block.ParseData())
+		block, err = blockRepo.Create(ctx, block, first.ID)
+		require.NoError(t, err)
+		blockIDs = append(blockIDs, block.GetID())
+	}
+	require.NoError(t, blockRepo.Reorder(ctx, first.ID, blockIDs))
+
+	second.UnlockRules = models.UnlockRules{
+		{Type: models.RequireAllLocations, Locations: []string{first.MarkerID}},
+		{Type: models.RequireBlock, BlockID: blockIDs[1]},
+	}
+	require.NoError(t, repositories.NewLocationRepository(dbc).Update(ctx, &second))
+
+	tx, err := dbc.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	err = repositories.NewHintRepository(dbc).Save(ctx, &tx, &models.Hint{
+		InstanceID: instance.ID,
+		LocationID: first.ID,
+		BlockID:    blockIDs[1],
+		Content:    "Check the frame",
+		Penalty:    2,
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	err = repositories.NewBonusRepository(dbc).SaveRule(ctx, &models.BonusRule{
+		InstanceID: instance.ID,
+		Type:       models.StreakBonus,
+		Points:     5,
+		Threshold:  3,
+	})
+	require.NoError(t, err)
+	err = repositories.NewRouteRepository(dbc).Save(ctx, &models.Route{
+		InstanceID: instance.ID,
+		Name:       "Backwards",
+		Locations:  models.StrArray{second.MarkerID, first.MarkerID},
+	})
+	require.NoError(t, err)
+
+	return instance.ID
+}
+
+func TestInstanceBundleService_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		export  func(services.InstanceBundleService, string, *bytes.Buffer) error
+		hasFile bool
+	}{
+		{
+			name: "ZIP bundle",
+			export: func(s services.InstanceBundleService, id string, buf *bytes.Buffer) error {
+				return s.Export(context.Background(), id, buf)
+			},
+			hasFile: true,
+		},
+		{
+			name: "JSON bundle",
+			export: func(s services.InstanceBundleService, id string, buf *bytes.Buffer) error {
+				return s.ExportJSON(context.Background(), id, buf)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, instanceService, locationService, dbc, cleanup := setupInstanceBundleService(t)
+			defer cleanup()
+			ctx := context.Background()
+
+			user := createBundleUser(t, dbc)
+			originalID := createBundleFixture(t, dbc, instanceService, locationService, user)
+			locationRepo := repositories.NewLocationRepository(dbc)
+			original, err := locationRepo.FindByInstance(ctx, originalID)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, tc.export(svc, originalID, &buf))
+
+			imported, err := svc.Import(ctx, user, &buf)
+			require.NoError(t, err)
+			assert.NotEqual(t, originalID, imported.ID)
+			assert.Equal(t, "Museum trail", imported.Name)
+
+			locations, err := locationRepo.FindByInstance(ctx, imported.ID)
+			require.NoError(t, err)
+			require.Len(t, locations, 2)
+
+			codes := map[string]bool{}
+			for _, location := range original {
+				codes[location.MarkerID] = true
+			}
+
+			byName := map[string]models.Location{}
+			for _, location := range locations {
+				require.NoError(t, locationRepo.LoadRelations(ctx, &location))
+				assert.False(t, codes[location.MarkerID], "marker codes should be new")
+				byName[location.Name] = location
+			}
+
+			entrance := byName["Entrance"]
+			assert.Equal(t, 10, entrance.Points)
+			assert.InDelta(t, -45.86, entrance.Marker.Lat, 0.0001)
+			require.Len(t, entrance.Clues, 1)
+			assert.Equal(t, "Look up", entrance.Clues[0].Content)
+			require.Len(t, entrance.Blocks, 2)
+
+			var image models.Block
+			for _, block := range entrance.Blocks {
+				if block.Type == "image" {
+					image = block
+				}
+			}
+			if tc.hasFile {
+				assert.Contains(t, string(image.Data), "https://cdn.example.com/", "image should point at the stored copy")
+				assert.NotContains(t, string(image.Data), "2025/01/01/photo.jpg")
+			} else {
+				assert.Contains(t, string(image.Data), "2025/01/01/photo.jpg", "JSON bundles keep the original URL")
+			}
+
+			gallery := byName["Gallery"]
+			require.Len(t, gallery.UnlockRules, 2)
+			assert.Equal(t, []string{entrance.MarkerID}, gallery.UnlockRules[0].Locations)
+			assert.Equal(t, image.ID, gallery.UnlockRules[1].BlockID)
+
+			hints, err := repositories.NewHintRepository(dbc).FindByLocation(ctx, entrance.ID)
+			require.NoError(t, err)
+			require.Len(t, hints, 1)
+			assert.Equal(t, "Check the frame", hints[0].Content)
+			assert.Equal(t, 2, hints[0].Penalty)
+			assert.Equal(t, image.ID, hints[0].BlockID)
+
+			rules, err := repositories.NewBonusRepository(dbc).FindRulesByInstance(ctx, imported.ID)
+			require.NoError(t, err)
+			require.Len(t, rules, 1)
+			assert.Equal(t, models.StreakBonus, rules[0].Type)
+			assert.Equal(t, 5, rules[0].Points)
+			assert.Equal(t, 3, rules[0].Threshold)
+
+			routes, err := repositories.NewRouteRepository(dbc).FindByInstance(ctx, imported.ID)
+			require.NoError(t, err)
+			require.Len(t, routes, 1)
+			assert.Equal(t, "Backwards", routes[0].Name)
+			assert.Equal(t, models.StrArray{gallery.MarkerID, entrance.MarkerID}, routes[0].Locations)
+		})
+	}
+}
+
+func TestInstanceBundleService_Import(t *testing.T) {
+	svc, _, _, dbc, cleanup := setupInstanceBundleService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	user := createBundleUser(t, dbc)
+
+	zipped := func(files map[string]string) string {
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		for name, content := range files {
+			w, err := archive.Create(name)
+			require.NoError(t, err)
+			_, err = w.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, archive.Close())
+		return buf.String()
+	}
+
+	tests := []struct {
+		name    string
+		bundle  string
+		user    *models.User
+		wantErr error
+	}{
+		{"Nil user", `{"version":1,"name":"Game"}`, nil, services.ErrUserNotAuthenticated},
+		{"Not a bundle", "not a bundle", user, services.ErrInvalidArgument},
+		{"Newer version", `{"version":99,"name":"Game"}`, user, services.ErrUnsupportedBundle},
+		{"ZIP without manifest", zipped(map[string]string{"other.txt": "hello"}), user, services.ErrInvalidArgument},
+		{"Empty instance", `{"version":1,"name":"Game"}`, user, nil},
+		{
+			"Unlock rule with unknown block",
+			`{"version":1,"name":"Game","locations":[{"name":"A","marker":{"code":"AAAAA"},"unlock_rules":[{"type":"block","block_id":"missing"}]}]}`,
+			user, services.ErrInvalidArgument,
+		},
+		{
+			"Unlock rule with unknown location",
+			`{"version":1,"name":"Game","locations":[{"name":"A","marker":{"code":"AAAAA"},"unlock_rules":[{"type":"all","locations":["ZZZZZ"]}]}]}`,
+			user, services.ErrInvalidArgument,
+		},
+		{
+			"Hint with unknown block",
+			`{"version":1,"name":"Game","locations":[{"name":"A","marker":{"code":"AAAAA"},"hints":[{"block_id":"missing","content":"Hi"}]}]}`,
+			user, services.ErrInvalidArgument,
+		},
+		{
+			"Route with unknown location",
+			`{"version":1,"name":"Game","routes":[{"name":"Route 1","locations":["ZZZZZ"]}]}`,
+			user, services.ErrInvalidArgument,
+		},
+		{"Unknown bonus rule type", `{"version":1,"name":"Game","bonus_rules":[{"type":99,"points":5}]}`, user, services.ErrInvalidArgument},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			instance, err := svc.Import(ctx, tc.user, strings.NewReader(tc.bundle))
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Nil(t, instance)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "Game", instance.Name)
+			}
+		})
+	}
+}
+
+func TestInstanceBundleService_Import_Uploads(t *testing.T) {
+	zipped := func(t *testing.T, upload string) *bytes.Buffer {
+		t.Helper()
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		for name, content := range map[string]string{
+			// The image URL is written with escaped slashes, as some JSON encoders do
+			"instance.json": `{"version":1,"name":"Game",
+				"locations":[{"name":"A","marker":{"code":"AAAAA"},"blocks":[
+					{"id":"b1","type":"image","data":{"content":"https:\/\/example.com\/a.jpg","caption":"","link":""}}
+				]}],
+				"uploads":[{"url":"https://example.com/a.jpg","type":"image","file":"uploads/1.jpg"}]}`,
+			"uploads/1.jpg": upload,
+		} {
+			w, err := archive.Create(name)
+			require.NoError(t, err)
+			_, err = w.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, archive.Close())
+		return &buf
+	}
+
+	tests := []struct {
+		name    string
+		upload  string
+		wantErr bool
+		wantURL string
+	}{
+		{"Image", "\xff\xd8\xff\xe0 photo", false, "https://cdn.example.com/1.jpg"},
+		{"HTML posing as an image", "<html><script>alert(1)</script></html>", true, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, _, _, dbc, cleanup := setupInstanceBundleService(t)
+			defer cleanup()
+			ctx := context.Background()
+			user := createBundleUser(t, dbc)
+
+			instance, err := svc.Import(ctx, user, zipped(t, tc.upload))
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, instance)
+				return
+			}
+			require.NoError(t, err)
+
+			locationRepo := repositories.NewLocationRepository(dbc)
+			locations, err := locationRepo.FindByInstance(ctx, instance.ID)
+			require.NoError(t, err)
+			require.Len(t, locations, 1)
+			require.NoError(t, locationRepo.LoadRelations(ctx, &locations[0]))
+			require.Len(t, locations[0].Blocks, 1)
+
+			var data struct {
+				Content string `json:"content"`
+			}
+			require.NoError(t, json.Unmarshal(locations[0].Blocks[0].Data, &data))
+			assert.Equal(t, tc.wantURL, data.Content)
+		})
+	}
+}
+
+func TestInstanceBundleService_Import_RollsBack(t *testing.T) {
+	svc, instanceService, _, dbc, cleanup := setupInstanceBundleService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	user := createBundleUser(t, dbc)
+
+	// The second location has a block that cannot be created, so the first
+	// location and the instance must not be kept either
+	bundle := `{"version":1,"name":"Broken","locations":[
+		{"name":"First","marker":{"code":"AAAAA","name":"First"}},
+		{"name":"Second","marker":{"code":"BBBBB","name":"Second"},"blocks":[{"id":"b1","type":"not-a-block","data":{}}]}
+	]}`
+	instance, err := svc.Import(ctx, user, strings.NewReader(bundle))
+	require.Error(t, err)
+	assert.Nil(t, instance)
+
+	ids, err := instanceService.FindInstanceIDsForUser(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestInstanceBundleService_Import_TooLarge(t *testing.T) {
+	svc, _, _, dbc, cleanup := setupInstanceBundleService(t)
+	defer cleanup()
+	user := createBundleUser(t, dbc)
+
+	// A small ZIP file that unzips to more than the limit
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, err := archive.Create("padding.txt")
+	require.NoError(t, err)
+	chunk := bytes.Repeat([]byte{'a'}, 1<<20)
+	for written := 0; written <= services.MaxBundleSize; written += len(chunk) {
+		_, err = w.Write(chunk)
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+	require.Less(t, buf.Len(), services.MaxBundleSize)
+
+	instance, err := svc.Import(context.Background(), user, &buf)
+	assert.ErrorIs(t, err, services.ErrInvalidArgument)
+	assert.Nil(t, instance)
+}
@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/google/uuid"
 	"github.com/nathanhollows/Rapua/v3/db"
 	"github.com/nathanhollows/Rapua/v3/models"
 	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/uptrace/bun"
 )
 
 type instanceService struct {
@@ -16,23 +18,37 @@ type instanceService struct {
 	locationService      LocationService
 	userService          UserService
 	teamService          TeamService
+	collaboratorService  CollaboratorService
 	instanceRepo         repositories.InstanceRepository
 	instanceSettingsRepo repositories.InstanceSettingsRepository
+	auditRepo            repositories.AuditRepository
+	memberRepo           repositories.InstanceMemberRepository
+	orgRepo              repositories.OrganisationRepository
 }
 
 type InstanceService interface {
 	// CreateInstance creates a new instance for the given user
 	CreateInstance(ctx context.Context, name string, user *models.User) (*models.Instance, error)
-	// DuplicateInstance duplicates an instance for the given user
+	// DuplicateInstance duplicates an instance for the given user.
+	// The user must be able to edit the instance
 	DuplicateInstance(ctx context.Context, user *models.User, id, name string) (*models.Instance, error)
+	// CopyTemplate copies a template for the given user.
+	// The caller must have found the template by its share link
+	CopyTemplate(ctx context.Context, user *models.User, template *models.Instance, name string) (*models.Instance, error)
+	// DuplicateInstanceWithTransaction saves a copy of an instance as part of a larger change.
+	// newInstance holds the details of the copy, such as its name and owner.
+	// The caller must check the user may copy the instance
+	DuplicateInstanceWithTransaction(ctx context.Context, tx *bun.Tx, oldInstance *models.Instance, newInstance *models.Instance) error
 
 	// FindInstanceIDsForUser returns the IDs of all instances for the given user
 	FindInstanceIDsForUser(ctx context.Context, userID string) ([]string, error)
 
-	// DeleteInstance deletes an instance for the given user
+	// DeleteInstance deletes an instance for the given user.
+	// The user must be able to manage the instance
 	DeleteInstance(ctx context.Context, user *models.User, instanceID, confirmName string) (bool, error)
 
-	// SwitchInstance switches the user's current instance
+	// SwitchInstance switches the user's current instance.
+	// Owners and collaborators may switch to an instance
 	SwitchInstance(ctx context.Context, user *models.User, instanceID string) (*models.Instance, error)
 }
 
@@ -41,16 +57,24 @@ func NewInstanceService(
 	locationService LocationService,
 	userService UserService,
 	teamService TeamService,
+	collaboratorService CollaboratorService,
 	instanceRepo repositories.InstanceRepository,
 	instanceSettingsRepo repositories.InstanceSettingsRepository,
+	auditRepo repositories.AuditRepository,
+	memberRepo repositories.InstanceMemberRepository,
+	orgRepo repositories.OrganisationRepository,
 ) InstanceService {
 	return &instanceService{
 		transactor:           transactor,
 		locationService:      locationService,
 		userService:          userService,
 		teamService:          teamService,
+		collaboratorService:  collaboratorService,
 		instanceRepo:         instanceRepo,
 		instanceSettingsRepo: instanceSettingsRepo,
+		auditRepo:            auditRepo,
+		memberRepo:           memberRepo,
+		orgRepo:              orgRepo,
 	}
 }
 
@@ -65,8 +89,9 @@ func (s *instanceService) CreateInstance(ctx context.Context, name string, user
 	}
 
 	instance := &models.Instance{
-		Name:   name,
-		UserID: user.ID,
+		Name:           name,
+		UserID:         user.ID,
+		OrganisationID: user.CurrentOrganisationID,
 	}
 
 	if err := s.instanceRepo.Create(ctx, instance); err != nil {
@@ -86,6 +111,11 @@ func (s *instanceService) CreateInstance(ctx context.Context, name string, user
 		return nil, fmt.Errorf("creating instance settings: %w", err)
 	}
 
+	err = s.audit(ctx, nil, models.AuditInstanceCreated, instance, nil, auditInstance(instance))
+	if err != nil {
+		return nil, err
+	}
+
 	return instance, nil
 }
 
@@ -100,45 +130,100 @@ func (s *instanceService) DuplicateInstance(ctx context.Context, user *models.Us
 		return nil, fmt.Errorf("finding instance: %w", err)
 	}
 
-	locations, err := s.locationService.FindByInstance(ctx, oldInstance.ID)
-	if err != nil {
-		return nil, fmt.Errorf("finding locations: %w", err)
+	if !s.role(ctx, user, oldInstance).Can(models.PermissionEdit) {
+		return nil, ErrPermissionDenied
 	}
 
+	return s.duplicate(ctx, user, oldInstance, name)
+}
+
+// CopyTemplate implements InstanceService.
+// Templates are shared by link, so anyone with the link may copy them.
+func (s *instanceService) CopyTemplate(ctx context.Context, user *models.User, template *models.Instance, name string) (*models.Instance, error) {
+	if user == nil {
+		return nil, ErrUserNotAuthenticated
+	}
+	if template == nil || !template.IsTemplate {
+		return nil, ErrPermissionDenied
+	}
+
+	return s.duplicate(ctx, user, template, name)
+}
+
+// duplicate copies an instance and its locations into the user's account.
+func (s *instanceService) duplicate(ctx context.Context, user *models.User, oldInstance *models.Instance, name string) (*models.Instance, error) {
 	if name == "" {
 		return nil, NewValidationError("name")
 	}
-	if id == "" {
-		return nil, NewValidationError("id")
-	}
 
 	newInstance := &models.Instance{
-		Name:   name,
-		UserID: user.ID,
+		Name:           name,
+		UserID:         user.ID,
+		OrganisationID: user.CurrentOrganisationID,
 	}
 
-	if err := s.instanceRepo.Create(ctx, newInstance); err != nil {
-		return nil, fmt.Errorf("creating instance: %w", err)
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("starting transaction: %w", err)
 	}
 
-	// Copy locations
-	for _, location := range locations {
-		_, err := s.locationService.DuplicateLocation(ctx, location, newInstance.ID)
-		if err != nil {
-			return nil, fmt.Errorf("duplicating location: %w", err)
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
 		}
+	}()
+
+	err = s.DuplicateInstanceWithTransaction(ctx, tx, oldInstance, newInstance)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = s.audit(ctx, tx, models.AuditInstanceCopied, newInstance, auditInstance(oldInstance), auditInstance(newInstance))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+	return newInstance, nil
+}
+
+// DuplicateInstanceWithTransaction implements InstanceService.
+func (s *instanceService) DuplicateInstanceWithTransaction(ctx context.Context, tx *bun.Tx, oldInstance *models.Instance, newInstance *models.Instance) error {
+	locations, err := s.locationService.FindByInstance(ctx, oldInstance.ID)
+	if err != nil {
+		return fmt.Errorf("finding locations: %w", err)
+	}
+
+	// Locations are copied first as they are read before anything is written
+	if newInstance.ID == "" {
+		newInstance.ID = uuid.New().String()
+	}
+	_, err = s.locationService.DuplicateLocationsWithTransaction(ctx, tx, locations, newInstance.ID)
+	if err != nil {
+		return fmt.Errorf("duplicating locations: %w", err)
+	}
+
+	if err := s.instanceRepo.CreateWithTransaction(ctx, tx, newInstance); err != nil {
+		return fmt.Errorf("creating instance: %w", err)
 	}
 
 	// Copy settings
 	settings := oldInstance.Settings
 	settings.InstanceID = newInstance.ID
-	if err := s.instanceSettingsRepo.Create(ctx, &settings); err != nil {
-		return nil, fmt.Errorf("creating settings: %w", err)
+	// Share links belong to the original instance
+	settings.LeaderboardToken = ""
+	if err := s.instanceSettingsRepo.CreateWithTransaction(ctx, tx, &settings); err != nil {
+		return fmt.Errorf("creating settings: %w", err)
 	}
 
-	// TODO: Copy blocks and clues
-
-	return newInstance, nil
+	return nil
 }
 
 // FindInstanceIDsForUser implements InstanceService.
@@ -161,14 +246,15 @@ func (s *instanceService) DeleteInstance(ctx context.Context, user *models.User,
 		return false, ErrUserNotAuthenticated
 	}
 
-	// Check if the user has permission to delete the instance
-	instance, err := s.instanceRepo.GetByID(ctx, instanceID)
+	// Owners and organisation admins may delete the instance
+	err := s.collaboratorService.Authorize(ctx, user, instanceID, models.PermissionManage)
 	if err != nil {
-		return false, fmt.Errorf("finding instance: %w", err)
+		return false, err
 	}
 
-	if user.ID != instance.UserID {
-		return false, ErrPermissionDenied
+	instance, err := s.instanceRepo.GetByID(ctx, instanceID)
+	if err != nil {
+		return false, fmt.Errorf("finding instance: %w", err)
 	}
 
 	// If the name does not match the confirmation, return an error
@@ -206,6 +292,12 @@ func (s *instanceService) DeleteInstance(ctx context.Context, user *models.User,
 		return false, fmt.Errorf("deleting teams: %w", err)
 	}
 
+	err = s.audit(ctx, tx, models.AuditInstanceDeleted, instance, auditInstance(instance), nil)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		tx.Rollback()
@@ -227,11 +319,16 @@ func (s *instanceService) SwitchInstance(ctx context.Context, user *models.User,
 	}
 
 	// Make sure the user has permission to switch to this instance
-	if instance.UserID != user.ID {
+	// Templates are read-only, so they can never be switched to
+	if !s.role(ctx, user, instance).Can(models.PermissionView) || instance.IsTemplate {
 		return nil, ErrPermissionDenied
 	}
 
 	user.CurrentInstanceID = instance.ID
+	// Follow the instance into its workspace when the user belongs there
+	if s.inWorkspace(ctx, user, instance) {
+		user.CurrentOrganisationID = instance.OrganisationID
+	}
 	err = s.userService.UpdateUser(ctx, user)
 	if err != nil {
 		return nil, fmt.Errorf("updating user: %w", err)
@@ -239,3 +336,32 @@ func (s *instanceService) SwitchInstance(ctx context.Context, user *models.User,
 
 	return instance, nil
 }
+
+// role returns the user's role in an instance, or none if they have no access.
+func (s *instanceService) role(ctx context.Context, user *models.User, instance *models.Instance) models.InstanceRole {
+	return instanceRole(ctx, s.memberRepo, s.orgRepo, user, instance)
+}
+
+// inWorkspace reports whether the instance belongs to one of the user's
+// workspaces, rather than only being shared with them.
+func (s *instanceService) inWorkspace(ctx context.Context, user *models.User, instance *models.Instance) bool {
+	if instance.OrganisationID == "" {
+		return instance.UserID == user.ID
+	}
+	_, err := s.orgRepo.GetMember(ctx, instance.OrganisationID, user.ID)
+	return err == nil
+}
+
+// audit records a change to an instance.
+func (s *instanceService) audit(ctx context.Context, tx *bun.Tx, action models.AuditAction, instance *models.Instance, before, after any) error {
+	err := recordAudit(ctx, s.auditRepo, tx, models.AuditEvent{
+		InstanceID: instance.ID,
+		Action:     action,
+		EntityType: "instance",
+		EntityID:   instance.ID,
+	}, before, after)
+	if err != nil {
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+	return nil
+}
@@ -5,10 +5,12 @@ import (
 	"testing"
 
 	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
 	"github.com/nathanhollows/Rapua/v3/internal/services"
 	"github.com/nathanhollows/Rapua/v3/models"
 	"github.com/nathanhollows/Rapua/v3/repositories"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setupInstanceService(t *testing.T) (services.InstanceService, services.UserService, func()) {
@@ -22,20 +24,28 @@ func setupInstanceService(t *testing.T) (services.InstanceService, services.User
 	blockRepo := repositories.NewBlockRepository(dbc, blockStateRepo)
 	checkInRepo := repositories.NewCheckInRepository(dbc)
 	clueRepo := repositories.NewClueRepository(dbc)
+	hintRepo := repositories.NewHintRepository(dbc)
 	instanceRepo := repositories.NewInstanceRepository(dbc)
 	instanceSettingsRepo := repositories.NewInstanceSettingsRepository(dbc)
 	locationRepo := repositories.NewLocationRepository(dbc)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	auditRepo := repositories.NewAuditRepository(dbc)
 	markerRepo := repositories.NewMarkerRepository(dbc)
 	teamRepo := repositories.NewTeamRepository(dbc)
 	userRepo := repositories.NewUserRepository(dbc)
 
 	// Initialize services
-	locationService := services.NewLocationService(transactor, clueRepo, locationRepo, markerRepo, blockRepo)
-	teamService := services.NewTeamService(transactor, teamRepo, checkInRepo, blockStateRepo, locationRepo)
+	locationService := services.NewLocationService(transactor, clueRepo, hintRepo, locationRepo, markerRepo, blockRepo, auditRepo)
+	teamService := services.NewTeamService(transactor, events.NewBroker(), teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
 	userService := services.NewUserService(transactor, userRepo, instanceRepo)
+	memberRepo := repositories.NewInstanceMemberRepository(dbc)
+	orgRepo := repositories.NewOrganisationRepository(dbc)
+	collaboratorService := services.NewCollaboratorService(&mockEmailService{}, instanceRepo, locationRepo, memberRepo, orgRepo, userRepo)
 	instanceService := services.NewInstanceService(
 		transactor,
-		locationService, userService, teamService, instanceRepo, instanceSettingsRepo,
+		locationService, userService, teamService, collaboratorService, instanceRepo, instanceSettingsRepo, auditRepo,
+		memberRepo, orgRepo,
 	)
 
 	return instanceService, userService, cleanup
@@ -135,6 +145,13 @@ func TestInstanceService(t *testing.T) {
 	t.Run("DeleteInstance", func(t *testing.T) {
 		instance, _ := svc.CreateInstance(context.Background(), "GameToDelete", user)
 
+		// Only those who can manage the instance may delete it
+		other := &models.User{Email: "other@example.com", Password: "password"}
+		err := userService.CreateUser(context.Background(), other, "password")
+		require.NoError(t, err)
+		_, err = svc.DeleteInstance(context.Background(), other, instance.ID, "GameToDelete")
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
 		tests := []struct {
 			name         string
 			instanceID   string
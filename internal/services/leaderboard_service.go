@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+)
+
+var ErrLeaderboardHidden = errors.New("leaderboard is not shown for this game")
+
+type LeaderboardService interface {
+	// Rank returns every team that has started, best first
+	Rank(ctx context.Context, instanceID string) ([]LeaderboardEntry, error)
+	// ForTeam returns the leaderboard as a team should see it
+	ForTeam(ctx context.Context, team *models.Team) (*Leaderboard, error)
+	// ForToken returns the leaderboard for a shared link
+	ForToken(ctx context.Context, token string) (*Leaderboard, error)
+	// CreateShareToken creates a new link for the leaderboard, replacing any old link
+	CreateShareToken(ctx context.Context, settings *models.InstanceSettings) error
+}
+
+// Leaderboard is a ranked list of teams ready to show to players or the public.
+type Leaderboard struct {
+	Name       string             `json:"name"`
+	ShowPoints bool               `json:"show_points"`
+	Frozen     bool               `json:"frozen"`
+	Teams      []LeaderboardEntry `json:"teams"`
+}
+
+// LeaderboardEntry is a team's place on the leaderboard.
+// Hidden entries are in the top teams while the leaderboard is frozen.
+// Points and Locations are zero when the game does not show points.
+type LeaderboardEntry struct {
+	Rank        int       `json:"rank"`
+	Name        string    `json:"name"`
+	Points      int       `json:"points"`
+	Locations   int       `json:"locations"`
+	CompletedAt time.Time `json:"completed_at"`
+	Hidden      bool      `json:"hidden"`
+	Current     bool      `json:"current"`
+
+	teamCode string
+}
+
+type leaderboardService struct {
+	instanceRepo         repositories.InstanceRepository
+	instanceSettingsRepo repositories.InstanceSettingsRepository
+	teamRepo             repositories.TeamRepository
+}
+
+// NewLeaderboardService creates a new LeaderboardService.
+func NewLeaderboardService(
+	instanceRepo repositories.InstanceRepository,
+	instanceSettingsRepo repositories.InstanceSettingsRepository,
+	teamRepo repositories.TeamRepository,
+) LeaderboardService {
+	return &leaderboardService{
+		instanceRepo:         instanceRepo,
+		instanceSettingsRepo: instanceSettingsRepo,
+		teamRepo:             teamRepo,
+	}
+}
+
+// Rank returns every team that has started, best first.
+// Teams are ranked by points, then by the number of locations they have
+// finished, then by who finished their last location first.
+// Teams that cannot be separated share a rank.
+func (s *leaderboardService) Rank(ctx context.Context, instanceID string) ([]LeaderboardEntry, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+
+	teams, err := s.teamRepo.FindAllWithScans(ctx, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("finding teams: %w", err)
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(teams))
+	for _, team := range teams {
+		if !team.HasStarted {
+			continue
+		}
+		entry := LeaderboardEntry{
+			Name:     team.Name,
+			Points:   team.Points,
+			teamCode: team.Code,
+		}
+		for _, checkIn := range team.CheckIns {
+			if checkIn.MustCheckOut {
+				continue
+			}
+			entry.Locations++
+			completed := checkIn.TimeIn
+			if checkIn.TimeOut.After(completed) {
+				completed = checkIn.TimeOut
+			}
+			if completed.After(entry.CompletedAt) {
+				entry.CompletedAt = completed
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return rankedBefore(entries[i], entries[j])
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if i > 0 && !rankedBefore(entries[i-1], entries[i]) {
+			entries[i].Rank = entries[i-1].Rank
+		}
+	}
+	return entries, nil
+}
+
+// rankedBefore returns true if a ranks above b.
+func rankedBefore(a, b LeaderboardEntry) bool {
+	if a.Points != b.Points {
+		return a.Points > b.Points
+	}
+	if a.Locations != b.Locations {
+		return a.Locations > b.Locations
+	}
+	if a.CompletedAt.IsZero() || b.CompletedAt.IsZero() {
+		return !a.CompletedAt.IsZero() && b.CompletedAt.IsZero()
+	}
+	return a.CompletedAt.Before(b.CompletedAt)
+}
+
+// ForTeam returns the leaderboard as a team should see it.
+func (s *leaderboardService) ForTeam(ctx context.Context, team *models.Team) (*Leaderboard, error) {
+	instance, err := s.instanceRepo.GetByID(ctx, team.InstanceID)
+	if err != nil {
+		return nil, fmt.Errorf("finding instance: %w", err)
+	}
+	return s.leaderboard(ctx, instance, team.Code)
+}
+
+// ForToken returns the leaderboard for a shared link.
+// Every team is treated as another team, so names are hidden if anonymised.
+func (s *leaderboardService) ForToken(ctx context.Context, token string) (*Leaderboard, error) {
+	if token == "" {
+		return nil, NewValidationError("token")
+	}
+
+	settings, err := s.instanceSettingsRepo.GetByLeaderboardToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("finding leaderboard: %w", err)
+	}
+
+	instance, err := s.instanceRepo.GetByID(ctx, settings.InstanceID)
+	if err != nil {
+		return nil, fmt.Errorf("finding instance: %w", err)
+	}
+	return s.leaderboard(ctx, instance, "")
+}
+
+// leaderboard ranks the teams and applies the instance's visibility settings.
+func (s *leaderboardService) leaderboard(ctx context.Context, instance *models.Instance, teamCode string) (*Leaderboard, error) {
+	settings := instance.Settings
+	if !settings.ShowLeaderboard {
+		return nil, ErrLeaderboardHidden
+	}
+
+	entries, err := s.Rank(ctx, instance.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &Leaderboard{
+		Name:       instance.Name,
+		ShowPoints: settings.EnablePoints,
+		Frozen:     leaderboardFrozen(instance, time.Now()),
+		Teams:      entries,
+	}
+
+	for i := range board.Teams {
+		entry := &board.Teams[i]
+		entry.Current = teamCode != "" && entry.teamCode == teamCode
+		// Teams can always see their own place, even among the hidden top teams
+		if board.Frozen && entry.Rank <= settings.LeaderboardFreezeTop && !entry.Current {
+			*entry = LeaderboardEntry{Rank: entry.Rank, Hidden: true}
+			continue
+		}
+		if !board.ShowPoints {
+			entry.Points = 0
+			entry.Locations = 0
+		}
+		if settings.LeaderboardAnonymise && !entry.Current {
+			entry.Name = ""
+		}
+	}
+	return board, nil
+}
+
+// leaderboardFrozen returns true if the top teams should be hidden.
+// The leaderboard freezes for the last minutes of a game with an end time
+// and is revealed again when the game ends.
+func leaderboardFrozen(instance *models.Instance, now time.Time) bool {
+	settings := instance.Settings
+	if settings.LeaderboardFreeze <= 0 || settings.LeaderboardFreezeTop <= 0 {
+		return false
+	}
+	end := instance.EndTime.Time
+	if end.IsZero() || !now.Before(end) {
+		return false
+	}
+	return !now.Before(end.Add(-time.Duration(settings.LeaderboardFreeze) * time.Minute))
+}
+
+// CreateShareToken creates a new link for the leaderboard, replacing any old link.
+func (s *leaderboardService) CreateShareToken(ctx context.Context, settings *models.InstanceSettings) error {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return fmt.Errorf("generating token: %w", err)
+	}
+
+	settings.LeaderboardToken = base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(b)
+	err = s.instanceSettingsRepo.Update(ctx, settings)
+	if err != nil {
+		return fmt.Errorf("saving token: %w", err)
+	}
+	return nil
+}
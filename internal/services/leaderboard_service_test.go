@@ -0,0 +1,170 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func setupLeaderboardService(t *testing.T) (services.LeaderboardService, services.TeamService, *bun.DB, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	instanceRepo := repositories.NewInstanceRepository(dbc)
+	settingsRepo := repositories.NewInstanceSettingsRepository(dbc)
+	locationRepo := repositories.NewLocationRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	auditRepo := repositories.NewAuditRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+
+	teamService := services.NewTeamService(transactor, events.NewBroker(), teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	leaderboardService := services.NewLeaderboardService(instanceRepo, settingsRepo, teamRepo)
+
+	return leaderboardService, teamService, dbc, cleanup
+}
+
+// createLeaderboardGame creates an instance with a leaderboard and started teams
+// with the given points.
+func createLeaderboardGame(t *testing.T, dbc *bun.DB, teamService services.TeamService, settings models.InstanceSettings, points ...int) (*models.Instance, []models.Team) {
+	t.Helper()
+	ctx := context.Background()
+
+	instance := &models.Instance{Name: gofakeit.Name(), UserID: gofakeit.UUID()}
+	require.NoError(t, repositories.NewInstanceRepository(dbc).Create(ctx, instance))
+	settings.InstanceID = instance.ID
+	settings.ShowLeaderboard = true
+	settings.EnablePoints = true
+	require.NoError(t, repositories.NewInstanceSettingsRepository(dbc).Create(ctx, &settings))
+	instance.Settings = settings
+
+	teams, err := teamService.AddTeams(ctx, instance.ID, len(points))
+	require.NoError(t, err)
+	for i := range teams {
+		teams[i].Name = gofakeit.Name()
+		teams[i].HasStarted = true
+		require.NoError(t, teamService.Update(ctx, &teams[i]))
+		if points[i] != 0 {
+			require.NoError(t, teamService.AwardPoints(ctx, &teams[i], &models.PointsTransaction{Amount: points[i], Source: models.ManualSource}))
+		}
+	}
+	return instance, teams
+}
+
+func TestLeaderboardService_Rank(t *testing.T) {
+	svc, teamService, dbc, cleanup := setupLeaderboardService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instance, teams := createLeaderboardGame(t, dbc, teamService, models.InstanceSettings{}, 10, 20, 10, 10)
+
+	// Team 3 visits more locations than team 1, and team 4 matches team 1
+	// but finishes later
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	location := models.Location{ID: gofakeit.UUID(), InstanceID: instance.ID}
+	_, err := checkInRepo.LogCheckIn(ctx, teams[2], location, false, false)
+	require.NoError(t, err)
+	_, err = checkInRepo.LogCheckIn(ctx, teams[2], models.Location{ID: gofakeit.UUID(), InstanceID: instance.ID}, false, false)
+	require.NoError(t, err)
+	_, err = checkInRepo.LogCheckIn(ctx, teams[0], location, false, false)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	_, err = checkInRepo.LogCheckIn(ctx, teams[3], location, false, false)
+	require.NoError(t, err)
+
+	// Teams that have not started are left off
+	_, err = teamService.AddTeams(ctx, instance.ID, 1)
+	require.NoError(t, err)
+
+	entries, err := svc.Rank(ctx, instance.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	wantOrder := []int{1, 2, 0, 3}
+	for i, want := range wantOrder {
+		assert.Equal(t, teams[want].Name, entries[i].Name, "position %d", i+1)
+		assert.Equal(t, i+1, entries[i].Rank)
+	}
+}
+
+func TestLeaderboardService_Visibility(t *testing.T) {
+	svc, teamService, dbc, cleanup := setupLeaderboardService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instance, teams := createLeaderboardGame(t, dbc, teamService, models.InstanceSettings{
+		LeaderboardAnonymise: true,
+		LeaderboardFreeze:    10,
+		LeaderboardFreezeTop: 1,
+	}, 30, 20, 10)
+
+	// Not frozen without an end time
+	board, err := svc.ForTeam(ctx, &teams[1])
+	require.NoError(t, err)
+	assert.False(t, board.Frozen)
+	assert.Empty(t, board.Teams[0].Name, "other teams are anonymised")
+	assert.Equal(t, teams[1].Name, board.Teams[1].Name, "a team can see its own name")
+	assert.True(t, board.Teams[1].Current)
+
+	// Frozen in the last minutes of the game
+	instance.EndTime = bun.NullTime{Time: time.Now().Add(5 * time.Minute)}
+	require.NoError(t, repositories.NewInstanceRepository(dbc).Update(ctx, instance))
+	board, err = svc.ForTeam(ctx, &teams[1])
+	require.NoError(t, err)
+	assert.True(t, board.Frozen)
+	assert.True(t, board.Teams[0].Hidden)
+	assert.Equal(t, 0, board.Teams[0].Points)
+	assert.Equal(t, 20, board.Teams[1].Points)
+
+	// The top teams can still see their own place
+	board, err = svc.ForTeam(ctx, &teams[0])
+	require.NoError(t, err)
+	assert.False(t, board.Teams[0].Hidden)
+	assert.True(t, board.Teams[0].Current)
+	assert.Equal(t, teams[0].Name, board.Teams[0].Name)
+	assert.Equal(t, 30, board.Teams[0].Points)
+
+	// Shared links need a token and anonymise every team
+	settingsRepo := repositories.NewInstanceSettingsRepository(dbc)
+	settings := instance.Settings
+	require.NoError(t, svc.CreateShareToken(ctx, &settings))
+	require.NotEmpty(t, settings.LeaderboardToken)
+	board, err = svc.ForToken(ctx, settings.LeaderboardToken)
+	require.NoError(t, err)
+	for _, entry := range board.Teams {
+		assert.Empty(t, entry.Name)
+	}
+	_, err = svc.ForToken(ctx, gofakeit.Password(true, true, true, false, false, 22))
+	assert.Error(t, err)
+
+	// Points are left out when the game does not use them
+	settings.EnablePoints = false
+	require.NoError(t, settingsRepo.Update(ctx, &settings))
+	board, err = svc.ForToken(ctx, settings.LeaderboardToken)
+	require.NoError(t, err)
+	assert.False(t, board.ShowPoints)
+	for _, entry := range board.Teams {
+		assert.Equal(t, 0, entry.Points)
+		assert.Equal(t, 0, entry.Locations)
+	}
+
+	// Hidden leaderboards are not shown
+	settings.ShowLeaderboard = false
+	require.NoError(t, settingsRepo.Update(ctx, &settings))
+	_, err = svc.ForToken(ctx, settings.LeaderboardToken)
+	assert.ErrorIs(t, err, services.ErrLeaderboardHidden)
+}
@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/nathanhollows/Rapua/v3/blocks"
 	"github.com/nathanhollows/Rapua/v3/db"
 	"github.com/nathanhollows/Rapua/v3/models"
 	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/uptrace/bun"
 )
 
 type LocationService interface {
@@ -20,6 +22,10 @@ type LocationService interface {
 	CreateMarker(ctx context.Context, name string, lat, lng float64) (models.Marker, error)
 	// DuplicateLocation creates a new location given an existing location and the instance ID of the new location
 	DuplicateLocation(ctx context.Context, location models.Location, newInstanceID string) (models.Location, error)
+	// DuplicateLocations duplicates the given locations together into a new instance, keeping the unlock rules between them
+	DuplicateLocations(ctx context.Context, locations []models.Location, newInstanceID string) ([]models.Location, error)
+	// DuplicateLocationsWithTransaction duplicates the given locations into a new instance as part of a larger change
+	DuplicateLocationsWithTransaction(ctx context.Context, tx *bun.Tx, locations []models.Location, newInstanceID string) ([]models.Location, error)
 
 	// GetByID finds a location by its ID
 	GetByID(ctx context.Context, locationID string) (*models.Location, error)
@@ -56,24 +62,30 @@ type locationService struct {
 	transactor   db.Transactor
 	locationRepo repositories.LocationRepository
 	clueRepo     repositories.ClueRepository
+	hintRepo     repositories.HintRepository
 	markerRepo   repositories.MarkerRepository
 	blockRepo    repositories.BlockRepository
+	auditRepo    repositories.AuditRepository
 }
 
 // NewLocationService creates a new instance of LocationService.
 func NewLocationService(
 	transactor db.Transactor,
 	clueRepo repositories.ClueRepository,
+	hintRepo repositories.HintRepository,
 	locationRepo repositories.LocationRepository,
 	markerRepo repositories.MarkerRepository,
 	blockRepo repositories.BlockRepository,
+	auditRepo repositories.AuditRepository,
 ) LocationService {
 	return locationService{
 		transactor:   transactor,
 		clueRepo:     clueRepo,
+		hintRepo:     hintRepo,
 		locationRepo: locationRepo,
 		markerRepo:   markerRepo,
 		blockRepo:    blockRepo,
+		auditRepo:    auditRepo,
 	}
 }
 
@@ -96,6 +108,12 @@ func (s locationService) CreateLocation(ctx context.Context, instanceID, name st
 		return models.Location{}, fmt.Errorf("saving location: %v", err)
 	}
 
+	location.Marker = marker
+	err = s.audit(ctx, models.AuditLocationCreated, &location, nil, auditLocation(&location))
+	if err != nil {
+		return models.Location{}, err
+	}
+
 	return location, nil
 }
 
@@ -117,6 +135,12 @@ func (s locationService) CreateLocationFromMarker(ctx context.Context, instanceI
 		return models.Location{}, fmt.Errorf("saving location: %v", err)
 	}
 
+	location.Marker = *marker
+	err = s.audit(ctx, models.AuditLocationCreated, &location, nil, auditLocation(&location))
+	if err != nil {
+		return models.Location{}, err
+	}
+
 	return location, nil
 }
 
@@ -136,49 +160,192 @@ func (s locationService) CreateMarker(ctx context.Context, name string, lat, lng
 
 // DuplicateLocation duplicates a location.
 func (s locationService) DuplicateLocation(ctx context.Context, location models.Location, newInstanceID string) (models.Location, error) {
-	// Load relations
-	err := s.locationRepo.LoadRelations(ctx, &location)
+	newLocations, err := s.DuplicateLocations(ctx, []models.Location{location}, newInstanceID)
+	if err != nil {
+		return models.Location{}, err
+	}
+	return newLocations[0], nil
+}
+
+// DuplicateLocations duplicates the given locations together into a new instance.
+func (s locationService) DuplicateLocations(ctx context.Context, locations []models.Location, newInstanceID string) ([]models.Location, error) {
+	copies, err := s.loadCopies(ctx, locations)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	newLocations, err := s.saveCopies(ctx, tx, copies, newInstanceID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+	return newLocations, nil
+}
+
+// DuplicateLocationsWithTransaction duplicates the given locations as part of a larger change.
+// The locations are read before anything is written, so it must be called
+// before any other writes in the transaction.
+func (s locationService) DuplicateLocationsWithTransaction(ctx context.Context, tx *bun.Tx, locations []models.Location, newInstanceID string) ([]models.Location, error) {
+	copies, err := s.loadCopies(ctx, locations)
 	if err != nil {
-		return models.Location{}, fmt.Errorf("loading relations: %v", err)
+		return nil, err
+	}
+	return s.saveCopies(ctx, tx, copies, newInstanceID)
+}
+
+// locationCopy is a location with the blocks and hints copied along with it.
+type locationCopy struct {
+	location models.Location
+	blocks   []blocks.Block
+	hints    []models.Hint
+}
+
+// loadCopies reads everything that is copied with the locations.
+func (s locationService) loadCopies(ctx context.Context, locations []models.Location) ([]locationCopy, error) {
+	copies := make([]locationCopy, 0, len(locations))
+	for _, location := range locations {
+		err := s.locationRepo.LoadRelations(ctx, &location)
+		if err != nil {
+			return nil, fmt.Errorf("loading relations: %v", err)
+		}
+
+		c := locationCopy{location: location}
+		for _, block := range location.Blocks {
+			block, err := s.blockRepo.GetByID(ctx, block.ID)
+			if err != nil {
+				return nil, fmt.Errorf("finding block: %v", err)
+			}
+			c.blocks = append(c.blocks, block)
+		}
+
+		c.hints, err = s.hintRepo.FindByLocation(ctx, location.ID)
+		if err != nil {
+			return nil, fmt.Errorf("finding hints: %w", err)
+		}
+		copies = append(copies, c)
+	}
+	return copies, nil
+}
+
+// saveCopies saves copies of the locations to a new instance.
+// Unlock rules may require a block at any of the locations, so the rules are
+// only updated once every block has been copied.
+func (s locationService) saveCopies(ctx context.Context, tx *bun.Tx, copies []locationCopy, newInstanceID string) ([]models.Location, error) {
+	blockIDs := make(map[string]string)
+	newLocations := make([]models.Location, 0, len(copies))
+	for _, c := range copies {
+		newLocation, err := s.saveCopy(ctx, tx, c, newInstanceID, blockIDs)
+		if err != nil {
+			return nil, err
+		}
+		newLocations = append(newLocations, newLocation)
 	}
 
+	for i := range newLocations {
+		err := s.remapUnlockRules(ctx, tx, &newLocations[i], blockIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newLocations, nil
+}
+
+// saveCopy copies a location with its clues, blocks and hints.
+// The IDs of the copied blocks are added to blockIDs, keyed by the original ID.
+func (s locationService) saveCopy(ctx context.Context, tx *bun.Tx, c locationCopy, newInstanceID string, blockIDs map[string]string) (models.Location, error) {
 	// Copy the location
-	newLocation := location
+	newLocation := c.location
 	newLocation.ID = ""
 	newLocation.InstanceID = newInstanceID
-	err = s.locationRepo.Create(ctx, &newLocation)
+	err := s.locationRepo.CreateWithTransaction(ctx, tx, &newLocation)
 	if err != nil {
 		return models.Location{}, fmt.Errorf("saving location: %v", err)
 	}
 
 	// Copy the clues
-	for _, clue := range location.Clues {
+	for _, clue := range c.location.Clues {
 		newClue := clue
 		newClue.ID = ""
 		newClue.InstanceID = newInstanceID
 		newClue.LocationID = newLocation.ID
-		err = s.clueRepo.Save(ctx, &newClue)
+		err = s.clueRepo.SaveWithTransaction(ctx, tx, &newClue)
 		if err != nil {
 			return models.Location{}, fmt.Errorf("saving clue: %v", err)
 		}
 	}
 
 	// Copy the blocks
-	fmt.Println("Copying blocks: ", len(location.Blocks))
-	for _, block := range location.Blocks {
-		block, err := s.blockRepo.GetByID(ctx, block.ID)
+	for _, block := range c.blocks {
+		newBlock, err := s.blockRepo.CreateWithTransaction(ctx, tx, block, newLocation.ID)
 		if err != nil {
-			return models.Location{}, fmt.Errorf("finding block: %v", err)
+			return models.Location{}, fmt.Errorf("saving block: %v", err)
 		}
-		_, err = s.blockRepo.Create(ctx, block, newLocation.ID)
+		blockIDs[block.GetID()] = newBlock.GetID()
+	}
+
+	// Copy the hints, pointing block hints at the new blocks
+	for _, hint := range c.hints {
+		hint.ID = ""
+		hint.InstanceID = newInstanceID
+		hint.LocationID = newLocation.ID
+		if hint.BlockID != "" {
+			hint.BlockID = blockIDs[hint.BlockID]
+			if hint.BlockID == "" {
+				continue
+			}
+		}
+		err = s.hintRepo.Save(ctx, tx, &hint)
 		if err != nil {
-			return models.Location{}, fmt.Errorf("saving block: %v", err)
+			return models.Location{}, fmt.Errorf("saving hint: %w", err)
 		}
 	}
 
 	return newLocation, nil
 }
 
+// remapUnlockRules points the block rules of a copied location at the copied blocks.
+// Rules for blocks that were not copied are left without a block for the admin to fix.
+func (s locationService) remapUnlockRules(ctx context.Context, tx *bun.Tx, location *models.Location, blockIDs map[string]string) error {
+	changed := false
+	rules := make(models.UnlockRules, len(location.UnlockRules))
+	for i, rule := range location.UnlockRules {
+		if rule.BlockID != "" {
+			rule.BlockID = blockIDs[rule.BlockID]
+			changed = true
+		}
+		rules[i] = rule
+	}
+	if !changed {
+		return nil
+	}
+
+	location.UnlockRules = rules
+	err := s.locationRepo.UpdateWithTransaction(ctx, tx, location)
+	if err != nil {
+		return fmt.Errorf("updating unlock rules: %w", err)
+	}
+	return nil
+}
+
 // GetByID finds a location by ID.
 func (s locationService) GetByID(ctx context.Context, locationID string) (*models.Location, error) {
 	location, err := s.locationRepo.GetByID(ctx, locationID)
@@ -224,26 +391,45 @@ func (s locationService) IncrementVisitorStats(ctx context.Context, location *mo
 
 // UpdateCoords updates the coordinates for a location.
 func (s locationService) UpdateCoords(ctx context.Context, location *models.Location, lat, lng float64) error {
+	before := auditLocation(location)
 	location.Marker.Lat = lat
 	location.Marker.Lng = lng
-	return s.markerRepo.Update(ctx, &location.Marker)
+	err := s.markerRepo.Update(ctx, &location.Marker)
+	if err != nil {
+		return err
+	}
+	return s.audit(ctx, models.AuditLocationUpdated, location, before, auditLocation(location))
 }
 
 // UpdateName updates the name of a location.
 func (s locationService) UpdateName(ctx context.Context, location *models.Location, name string) error {
+	before := auditLocation(location)
 	location.Name = name
-	return s.locationRepo.Update(ctx, location)
+	err := s.locationRepo.Update(ctx, location)
+	if err != nil {
+		return err
+	}
+	return s.audit(ctx, models.AuditLocationUpdated, location, before, auditLocation(location))
 }
 
 func (s locationService) UpdateLocation(ctx context.Context, location *models.Location, data LocationUpdateData) error {
+	for _, rule := range data.UnlockRules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("%w: unlock rules: %w", ErrInvalidArgument, err)
+		}
+	}
+
 	if location.Marker.Code == "" {
 		s.locationRepo.LoadMarker(ctx, location)
 	}
 
+	before := auditLocation(location)
+
 	// Set up the marker data
 	update := false
 
-	if data.Name != "" && data.Name != location.Marker.Name {
+	// Library markers keep their own name, the location name is enough
+	if data.Name != "" && data.Name != location.Marker.Name && !location.Marker.InLibrary {
 		location.Marker.Name = data.Name
 		update = true
 	}
@@ -258,11 +444,13 @@ func (s locationService) UpdateLocation(ctx context.Context, location *models.Lo
 		update = true
 	}
 
-	// To avoid updating markers that other games are using, we need to check if the marker is shared
+	// To avoid updating markers that other games are using, we need to check if the marker is shared.
+	// Library markers are only moved from the library
 	shared, err := s.markerRepo.IsShared(ctx, location.Marker.Code)
 	if err != nil {
 		return fmt.Errorf("checking if marker is shared: %v", err)
 	}
+	shared = shared || location.Marker.InLibrary
 
 	if shared && update {
 		newMarker, err := s.CreateMarker(ctx, location.Marker.Name, location.Marker.Lat, location.Marker.Lng)
@@ -290,11 +478,27 @@ func (s locationService) UpdateLocation(ctx context.Context, location *models.Lo
 		update = true
 	}
 
+	if data.CheckInRadius >= 0 && data.CheckInRadius != location.CheckInRadius {
+		location.CheckInRadius = data.CheckInRadius
+		update = true
+	}
+
+	if data.Capacity >= 0 && data.Capacity != location.Capacity {
+		location.Capacity = data.Capacity
+		update = true
+	}
+
+	if data.UnlockRules != nil {
+		location.UnlockRules = data.UnlockRules
+		update = true
+	}
+
 	if update {
 		err := s.locationRepo.Update(ctx, location)
 		if err != nil {
 			return fmt.Errorf("updating location: %v", err)
 		}
+		return s.audit(ctx, models.AuditLocationUpdated, location, before, auditLocation(location))
 	}
 
 	return nil
@@ -322,6 +526,11 @@ func (s locationService) ReorderLocations(ctx context.Context, instanceID string
 		return errors.New("list length does not match number of locations")
 	}
 
+	before := make([]string, len(locations))
+	for i, location := range locations {
+		before[i] = location.ID
+	}
+
 	// Reorder the locations
 	for i, locationID := range locationIDs {
 		for j, location := range locations {
@@ -340,10 +549,16 @@ func (s locationService) ReorderLocations(ctx context.Context, instanceID string
 		}
 	}
 
-	return nil
+	return recordAudit(ctx, s.auditRepo, nil, models.AuditEvent{
+		InstanceID: instanceID,
+		Action:     models.AuditLocationOrdered,
+		EntityType: "instance",
+		EntityID:   instanceID,
+	}, before, locationIDs)
 }
 
-// If the marker is not used by any other locations, it is also deleted.
+// If the marker is not used by any other locations, it is also deleted
+// unless it is kept in the marker library.
 func (s locationService) DeleteLocation(ctx context.Context, locationID string) error {
 	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
@@ -363,6 +578,17 @@ func (s locationService) DeleteLocation(ctx context.Context, locationID string)
 		return fmt.Errorf("finding location: %v", err)
 	}
 
+	err = recordAudit(ctx, s.auditRepo, tx, models.AuditEvent{
+		InstanceID: location.InstanceID,
+		Action:     models.AuditLocationDeleted,
+		EntityType: "location",
+		EntityID:   location.ID,
+	}, auditLocation(location), nil)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+
 	// Delete all related clues
 	err = s.clueRepo.DeleteByLocationID(ctx, locationID)
 	if err != nil {
@@ -384,13 +610,17 @@ func (s locationService) DeleteLocation(ctx context.Context, locationID string)
 		return fmt.Errorf("deleting location: %v", err)
 	}
 
-	// Delete the marker if it is not used by any other locations
+	// Delete the marker if it is not used by any other locations or kept in the library
 	locations, err := s.locationRepo.FindLocationsByMarkerID(ctx, location.MarkerID)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("finding locations by marker: %v", err)
 	}
-	if len(locations) == 0 {
+	inLibrary := false
+	if marker, err := s.markerRepo.GetByCode(ctx, location.MarkerID); err == nil {
+		inLibrary = marker.InLibrary
+	}
+	if len(locations) == 0 && !inLibrary {
 		err = s.markerRepo.Delete(ctx, location.MarkerID)
 		if err != nil {
 			tx.Rollback()
@@ -432,3 +662,17 @@ func (s locationService) LoadRelations(ctx context.Context, location *models.Loc
 	}
 	return nil
 }
+
+// audit records a change to a location.
+func (s locationService) audit(ctx context.Context, action models.AuditAction, location *models.Location, before, after any) error {
+	err := recordAudit(ctx, s.auditRepo, nil, models.AuditEvent{
+		InstanceID: location.InstanceID,
+		Action:     action,
+		EntityType: "location",
+		EntityID:   location.ID,
+	}, before, after)
+	if err != nil {
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+	return nil
+}
@@ -7,8 +7,10 @@ import (
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/nathanhollows/Rapua/v3/db"
 	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
 	"github.com/nathanhollows/Rapua/v3/repositories"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setupLocationService(t *testing.T) (services.LocationService, func()) {
@@ -18,11 +20,13 @@ func setupLocationService(t *testing.T) (services.LocationService, func()) {
 	transactor := db.NewTransactor(dbc)
 
 	clueRepo := repositories.NewClueRepository(dbc)
+	hintRepo := repositories.NewHintRepository(dbc)
 	locationRepo := repositories.NewLocationRepository(dbc)
 	markerRepo := repositories.NewMarkerRepository(dbc)
 	blockStateRepo := repositories.NewBlockStateRepository(dbc)
 	blockRepo := repositories.NewBlockRepository(dbc, blockStateRepo)
-	locationService := services.NewLocationService(transactor, clueRepo, locationRepo, markerRepo, blockRepo)
+	auditRepo := repositories.NewAuditRepository(dbc)
+	locationService := services.NewLocationService(transactor, clueRepo, hintRepo, locationRepo, markerRepo, blockRepo, auditRepo)
 	return locationService, cleanup
 }
 
@@ -64,4 +68,87 @@ func TestLocationService_DuplicateLocation(t *testing.T) {
 		assert.Len(t, blocks, 1)
 
 	})
+
+	t.Run("Duplicate location with hints", func(t *testing.T) {
+		hintService, _, hintCleanup := setupHintService(t)
+		defer hintCleanup()
+		ctx := context.Background()
+
+		location, err := service.CreateLocation(ctx, gofakeit.UUID(), gofakeit.Name(), gofakeit.Latitude(), gofakeit.Longitude(), 10)
+		require.NoError(t, err)
+		block, err := blockService.NewBlock(ctx, location.ID, "image")
+		require.NoError(t, err)
+		require.NoError(t, hintService.UpdateHints(ctx, location.InstanceID, location.ID, "", []models.Hint{{Content: "By the gate", Penalty: 2}}))
+		require.NoError(t, hintService.UpdateHints(ctx, location.InstanceID, location.ID, block.GetID(), []models.Hint{{Content: "Look closer"}}))
+
+		newInstanceID := gofakeit.UUID()
+		newLocation, err := service.DuplicateLocation(ctx, location, newInstanceID)
+		require.NoError(t, err)
+
+		newBlocks, err := blockService.FindByLocationID(ctx, newLocation.ID)
+		require.NoError(t, err)
+		require.Len(t, newBlocks, 1)
+
+		hints, err := hintService.FindByLocation(ctx, newLocation.ID)
+		require.NoError(t, err)
+		require.Len(t, hints, 2)
+		locationHints := models.HintsFor(hints, "")
+		require.Len(t, locationHints, 1)
+		assert.Equal(t, "By the gate", locationHints[0].Content)
+		assert.Equal(t, 2, locationHints[0].Penalty)
+		assert.Equal(t, newInstanceID, locationHints[0].InstanceID)
+		assert.Len(t, models.HintsFor(hints, newBlocks[0].GetID()), 1, "block hints follow the copied block")
+
+		original, err := hintService.FindByLocation(ctx, location.ID)
+		require.NoError(t, err)
+		assert.Len(t, original, 2, "the original hints are untouched")
+	})
+	t.Run("Duplicate locations keep block unlock rules", func(t *testing.T) {
+		ctx := context.Background()
+		instanceID := gofakeit.UUID()
+
+		first, err := service.CreateLocation(ctx, instanceID, gofakeit.Name(), gofakeit.Latitude(), gofakeit.Longitude(), 10)
+		require.NoError(t, err)
+		second, err := service.CreateLocation(ctx, instanceID, gofakeit.Name(), gofakeit.Latitude(), gofakeit.Longitude(), 10)
+		require.NoError(t, err)
+		block, err := blockService.NewBlock(ctx, first.ID, "image")
+		require.NoError(t, err)
+
+		// Both locations require the block at the first location
+		for _, location := range []*models.Location{&first, &second} {
+			err = service.UpdateLocation(ctx, location, services.LocationUpdateData{
+				Name:        location.Name,
+				Latitude:    location.Marker.Lat,
+				Longitude:   location.Marker.Lng,
+				Points:      location.Points,
+				UnlockRules: models.UnlockRules{{Type: models.RequireBlock, BlockID: block.GetID()}},
+			})
+			require.NoError(t, err)
+		}
+
+		newLocations, err := service.DuplicateLocations(ctx, []models.Location{first, second}, gofakeit.UUID())
+		require.NoError(t, err)
+		require.Len(t, newLocations, 2)
+		newBlocks, err := blockService.FindByLocationID(ctx, newLocations[0].ID)
+		require.NoError(t, err)
+		require.Len(t, newBlocks, 1)
+		for _, newLocation := range newLocations {
+			saved, err := service.GetByID(ctx, newLocation.ID)
+			require.NoError(t, err)
+			require.Len(t, saved.UnlockRules, 1)
+			assert.Equal(t, newBlocks[0].GetID(), saved.UnlockRules[0].BlockID)
+		}
+
+		// A location copied on its own cannot follow a block elsewhere
+		newLocation, err := service.DuplicateLocation(ctx, second, gofakeit.UUID())
+		require.NoError(t, err)
+		saved, err := service.GetByID(ctx, newLocation.ID)
+		require.NoError(t, err)
+		require.Len(t, saved.UnlockRules, 1)
+		assert.Empty(t, saved.UnlockRules[0].BlockID)
+
+		original, err := service.GetByID(ctx, second.ID)
+		require.NoError(t, err)
+		assert.Equal(t, block.GetID(), original.UnlockRules[0].BlockID, "the original rules are untouched")
+	})
 }
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+)
+
+var (
+	ErrMarkerNotInLibrary = errors.New("marker is not in the library")
+	ErrMarkerInInstance   = errors.New("marker is already used in this instance")
+)
+
+type MarkerService interface {
+	// Search finds library markers in the user's current workspace
+	Search(ctx context.Context, user *models.User, query, tag string) ([]models.Marker, error)
+	// Get returns a library marker the user can see
+	Get(ctx context.Context, user *models.User, code string) (*models.Marker, error)
+
+	// Create adds a marker to the library of the user's current workspace
+	Create(ctx context.Context, user *models.User, data MarkerData) (*models.Marker, error)
+	// Update changes a library marker. Every instance using the marker
+	// sees the new coordinates
+	Update(ctx context.Context, user *models.User, code string, data MarkerData) (*models.Marker, error)
+	// Remove takes a marker out of the library. Markers still used by
+	// instances are kept for those instances
+	Remove(ctx context.Context, user *models.User, code string) error
+
+	// AttachToInstance adds a library marker to the user's current instance
+	AttachToInstance(ctx context.Context, user *models.User, code, name string, points int) (models.Location, error)
+}
+
+type markerService struct {
+	collaboratorService CollaboratorService
+	locationService     LocationService
+	locationRepo        repositories.LocationRepository
+	markerRepo          repositories.MarkerRepository
+	orgRepo             repositories.OrganisationRepository
+}
+
+// NewMarkerService creates a new MarkerService.
+func NewMarkerService(
+	collaboratorService CollaboratorService,
+	locationService LocationService,
+	locationRepo repositories.LocationRepository,
+	markerRepo repositories.MarkerRepository,
+	orgRepo repositories.OrganisationRepository,
+) MarkerService {
+	return &markerService{
+		collaboratorService: collaboratorService,
+		locationService:     locationService,
+		locationRepo:        locationRepo,
+		markerRepo:          markerRepo,
+		orgRepo:             orgRepo,
+	}
+}
+
+// Search finds library markers in the user's current workspace.
+func (s *markerService) Search(ctx context.Context, user *models.User, query, tag string) ([]models.Marker, error) {
+	if user == nil {
+		return nil, ErrUserNotAuthenticated
+	}
+	markers, err := s.markerRepo.FindLibrary(ctx, user.ID, user.CurrentOrganisationID, query, tag)
+	if err != nil {
+		return nil, fmt.Errorf("finding markers: %w", err)
+	}
+	return markers, nil
+}
+
+// Get returns a library marker the user can see.
+// Markers are visible to their creator, or to members of their organisation.
+func (s *markerService) Get(ctx context.Context, user *models.User, code string) (*models.Marker, error) {
+	if user == nil {
+		return nil, ErrUserNotAuthenticated
+	}
+	marker, err := s.markerRepo.GetByCode(ctx, code)
+	if err != nil || !marker.InLibrary {
+		return nil, ErrMarkerNotInLibrary
+	}
+	if marker.OrganisationID == "" {
+		if marker.UserID != user.ID {
+			return nil, ErrPermissionDenied
+		}
+		return marker, nil
+	}
+	if _, err := s.orgRepo.GetMember(ctx, marker.OrganisationID, user.ID); err != nil {
+		return nil, ErrPermissionDenied
+	}
+	return marker, nil
+}
+
+// Create adds a marker to the library of the user's current workspace.
+func (s *markerService) Create(ctx context.Context, user *models.User, data MarkerData) (*models.Marker, error) {
+	if user == nil {
+		return nil, ErrUserNotAuthenticated
+	}
+	if err := validateMarkerData(data); err != nil {
+		return nil, err
+	}
+
+	marker := &models.Marker{
+		InLibrary:      true,
+		UserID:         user.ID,
+		OrganisationID: user.CurrentOrganisationID,
+	}
+	applyMarkerData(marker, data)
+	err := s.markerRepo.Create(ctx, marker)
+	if err != nil {
+		return nil, fmt.Errorf("saving marker: %w", err)
+	}
+	return marker, nil
+}
+
+// Update changes a library marker.
+// Locations share the marker row, so moving it moves every instance using it.
+func (s *markerService) Update(ctx context.Context, user *models.User, code string, data MarkerData) (*models.Marker, error) {
+	marker, err := s.editable(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateMarkerData(data); err != nil {
+		return nil, err
+	}
+
+	applyMarkerData(marker, data)
+	err = s.markerRepo.Update(ctx, marker)
+	if err != nil {
+		return nil, fmt.Errorf("updating marker: %w", err)
+	}
+	return marker, nil
+}
+
+// Remove takes a marker out of the library.
+// Unused markers are deleted, otherwise the instances using them keep them.
+func (s *markerService) Remove(ctx context.Context, user *models.User, code string) error {
+	marker, err := s.editable(ctx, user, code)
+	if err != nil {
+		return err
+	}
+
+	locations, err := s.locationRepo.FindLocationsByMarkerID(ctx, marker.Code)
+	if err != nil {
+		return fmt.Errorf("finding locations by marker: %w", err)
+	}
+	if len(locations) == 0 {
+		err = s.markerRepo.Delete(ctx, marker.Code)
+		if err != nil {
+			return fmt.Errorf("deleting marker: %w", err)
+		}
+		return nil
+	}
+
+	marker.InLibrary = false
+	err = s.markerRepo.Update(ctx, marker)
+	if err != nil {
+		return fmt.Errorf("updating marker: %w", err)
+	}
+	return nil
+}
+
+// AttachToInstance adds a library marker to the user's current instance.
+// The marker's name is used when no name is given.
+func (s *markerService) AttachToInstance(ctx context.Context, user *models.User, code, name string, points int) (models.Location, error) {
+	marker, err := s.Get(ctx, user, code)
+	if err != nil {
+		return models.Location{}, err
+	}
+	err = s.collaboratorService.Authorize(ctx, user, user.CurrentInstanceID, models.PermissionEdit)
+	if err != nil {
+		return models.Location{}, err
+	}
+
+	if _, err := s.locationRepo.GetByInstanceAndCode(ctx, user.CurrentInstanceID, marker.Code); err == nil {
+		return models.Location{}, ErrMarkerInInstance
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = marker.Name
+	}
+	if points < 0 {
+		return models.Location{}, NewValidationError("points")
+	}
+
+	location, err := s.locationService.CreateLocationFromMarker(ctx, user.CurrentInstanceID, name, points, marker.Code)
+	if err != nil {
+		return models.Location{}, fmt.Errorf("creating location: %w", err)
+	}
+	return location, nil
+}
+
+// editable finds a library marker the user may change.
+// Personal markers belong to their creator. Organisation markers can be
+// changed by their creator or an organisation admin.
+func (s *markerService) editable(ctx context.Context, user *models.User, code string) (*models.Marker, error) {
+	marker, err := s.Get(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if marker.UserID == user.ID {
+		return marker, nil
+	}
+	member, err := s.orgRepo.GetMember(ctx, marker.OrganisationID, user.ID)
+	if err != nil || member.Role != models.OrgRoleAdmin {
+		return nil, ErrPermissionDenied
+	}
+	return marker, nil
+}
+
+// validateMarkerData checks a library marker has a name and a real place.
+func validateMarkerData(data MarkerData) error {
+	if strings.TrimSpace(data.Name) == "" {
+		return NewValidationError("name")
+	}
+	if data.Latitude < -90 || data.Latitude > 90 {
+		return NewValidationError("latitude")
+	}
+	if data.Longitude < -180 || data.Longitude > 180 {
+		return NewValidationError("longitude")
+	}
+	return nil
+}
+
+// applyMarkerData copies the data onto a marker.
+func applyMarkerData(marker *models.Marker, data MarkerData) {
+	marker.Name = strings.TrimSpace(data.Name)
+	marker.Lat = data.Latitude
+	marker.Lng = data.Longitude
+	marker.Notes = strings.TrimSpace(data.Notes)
+	if data.Photo != "" {
+		marker.Photo = data.Photo
+	}
+	marker.SetTags(data.Tags)
+}
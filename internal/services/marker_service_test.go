@@ -0,0 +1,169 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupMarkerService(t *testing.T) (services.MarkerService, services.OrganisationService, services.InstanceService, services.LocationService, services.UserService, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+
+	auditRepo := repositories.NewAuditRepository(dbc)
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	blockRepo := repositories.NewBlockRepository(dbc, blockStateRepo)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	clueRepo := repositories.NewClueRepository(dbc)
+	hintRepo := repositories.NewHintRepository(dbc)
+	instanceRepo := repositories.NewInstanceRepository(dbc)
+	instanceSettingsRepo := repositories.NewInstanceSettingsRepository(dbc)
+	locationRepo := repositories.NewLocationRepository(dbc)
+	markerRepo := repositories.NewMarkerRepository(dbc)
+	memberRepo := repositories.NewInstanceMemberRepository(dbc)
+	orgRepo := repositories.NewOrganisationRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+	userRepo := repositories.NewUserRepository(dbc)
+
+	locationService := services.NewLocationService(transactor, clueRepo, hintRepo, locationRepo, markerRepo, blockRepo, auditRepo)
+	teamService := services.NewTeamService(transactor, events.NewBroker(), teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	userService := services.NewUserService(transactor, userRepo, instanceRepo)
+	collaboratorService := services.NewCollaboratorService(&mockEmailService{}, instanceRepo, locationRepo, memberRepo, orgRepo, userRepo)
+	instanceService := services.NewInstanceService(
+		transactor,
+		locationService, userService, teamService, collaboratorService, instanceRepo, instanceSettingsRepo, auditRepo,
+		memberRepo, orgRepo,
+	)
+	organisationService := services.NewOrganisationService(auditRepo, instanceRepo, orgRepo, userRepo)
+	markerService := services.NewMarkerService(collaboratorService, locationService, locationRepo, markerRepo, orgRepo)
+
+	return markerService, organisationService, instanceService, locationService, userService, cleanup
+}
+
+func TestMarkerService(t *testing.T) {
+	svc, organisationService, instanceService, locationService, userService, cleanup := setupMarkerService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	admin := createTestUser(t, userService, "admin@example.com")
+	teacher := createTestUser(t, userService, "teacher@example.com")
+	stranger := createTestUser(t, userService, "stranger@example.com")
+
+	organisation, err := organisationService.Create(ctx, admin, "Example School")
+	require.NoError(t, err)
+	_, err = organisationService.AddMember(ctx, admin, organisation.ID, teacher.Email, models.OrgRoleMember)
+	require.NoError(t, err)
+	require.NoError(t, organisationService.SwitchWorkspace(ctx, admin, organisation.ID))
+	require.NoError(t, organisationService.SwitchWorkspace(ctx, teacher, organisation.ID))
+
+	plaque, err := svc.Create(ctx, teacher, services.MarkerData{
+		Name:      " Library plaque ",
+		Latitude:  -45.866,
+		Longitude: 170.514,
+		Notes:     "Left of the main doors",
+		Tags:      []string{"Campus", "plaque", " campus ", ""},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Library plaque", plaque.Name)
+	assert.Equal(t, organisation.ID, plaque.OrganisationID)
+	assert.Equal(t, []string{"campus", "plaque"}, plaque.TagList())
+
+	_, err = svc.Create(ctx, teacher, services.MarkerData{Name: "Nowhere", Latitude: 91})
+	assert.ErrorIs(t, err, services.ErrInvalidArgument)
+
+	t.Run("Members search the workspace library", func(t *testing.T) {
+		_, err := svc.Create(ctx, teacher, services.MarkerData{Name: "Clocktower", Latitude: -45.864, Longitude: 170.513, Tags: []string{"campus"}})
+		require.NoError(t, err)
+
+		markers, err := svc.Search(ctx, admin, "", "campus")
+		require.NoError(t, err)
+		assert.Len(t, markers, 2)
+
+		markers, err = svc.Search(ctx, admin, "DOORS", "")
+		require.NoError(t, err)
+		require.Len(t, markers, 1)
+		assert.Equal(t, plaque.Code, markers[0].Code)
+
+		markers, err = svc.Search(ctx, admin, "", "camp")
+		require.NoError(t, err)
+		assert.Empty(t, markers, "tags match exactly")
+
+		markers, err = svc.Search(ctx, stranger, "", "")
+		require.NoError(t, err)
+		assert.Empty(t, markers, "other workspaces are not searched")
+	})
+
+	game, err := instanceService.CreateInstance(ctx, "Orientation", teacher)
+	require.NoError(t, err)
+	other, err := instanceService.CreateInstance(ctx, "Open day", teacher)
+	require.NoError(t, err)
+
+	t.Run("Markers are attached to instances as locations", func(t *testing.T) {
+		_, err := svc.AttachToInstance(ctx, stranger, plaque.Code, "", 10)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		_, err = instanceService.SwitchInstance(ctx, teacher, game.ID)
+		require.NoError(t, err)
+		location, err := svc.AttachToInstance(ctx, teacher, plaque.Code, "", 10)
+		require.NoError(t, err)
+		assert.Equal(t, "Library plaque", location.Name)
+		assert.Equal(t, plaque.Code, location.MarkerID)
+
+		_, err = svc.AttachToInstance(ctx, teacher, plaque.Code, "", 10)
+		assert.ErrorIs(t, err, services.ErrMarkerInInstance)
+
+		_, err = instanceService.SwitchInstance(ctx, teacher, other.ID)
+		require.NoError(t, err)
+		_, err = svc.AttachToInstance(ctx, teacher, plaque.Code, "Plaque", 5)
+		require.NoError(t, err)
+	})
+
+	t.Run("Moving a library marker moves every instance using it", func(t *testing.T) {
+		_, err := svc.Update(ctx, stranger, plaque.Code, services.MarkerData{Name: "Moved", Latitude: -45.8, Longitude: 170.5})
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		_, err = svc.Update(ctx, admin, plaque.Code, services.MarkerData{Name: "Library plaque", Latitude: -45.867, Longitude: 170.515})
+		require.NoError(t, err, "organisation admins edit any marker")
+
+		for _, instanceID := range []string{game.ID, other.ID} {
+			location, err := locationService.GetByInstanceAndCode(ctx, instanceID, plaque.Code)
+			require.NoError(t, err)
+			require.NoError(t, locationService.LoadRelations(ctx, location))
+			assert.InDelta(t, -45.867, location.Marker.Lat, 0.0001)
+			assert.InDelta(t, 170.515, location.Marker.Lng, 0.0001)
+		}
+	})
+
+	t.Run("Renaming a location keeps the library marker", func(t *testing.T) {
+		location, err := locationService.GetByInstanceAndCode(ctx, other.ID, plaque.Code)
+		require.NoError(t, err)
+		err = locationService.UpdateLocation(ctx, location, services.LocationUpdateData{Name: "Open day plaque", Latitude: -100, Longitude: -200, Points: -1, CheckInRadius: -1, Capacity: -1})
+		require.NoError(t, err)
+		assert.Equal(t, plaque.Code, location.MarkerID)
+
+		marker, err := svc.Get(ctx, teacher, plaque.Code)
+		require.NoError(t, err)
+		assert.Equal(t, "Library plaque", marker.Name)
+	})
+
+	t.Run("Removed markers stay in the instances using them", func(t *testing.T) {
+		err := svc.Remove(ctx, teacher, plaque.Code)
+		require.NoError(t, err)
+
+		_, err = svc.Get(ctx, teacher, plaque.Code)
+		assert.ErrorIs(t, err, services.ErrMarkerNotInLibrary)
+		_, err = locationService.GetByInstanceAndCode(ctx, game.ID, plaque.Code)
+		require.NoError(t, err)
+	})
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
 	"strings"
 
 	"github.com/nathanhollows/Rapua/v3/models"
@@ -13,6 +16,7 @@ import (
 var (
 	ErrAllLocationsVisited = errors.New("all locations visited")
 	ErrInstanceNotFound    = errors.New("instance not found")
+	ErrLocationLocked      = errors.New("location is locked")
 )
 
 type NavigationService interface {
@@ -21,9 +25,13 @@ type NavigationService interface {
 	CheckValidLocation(ctx context.Context, team *models.Team, settings *models.InstanceSettings, markerID string) (bool, error)
 	// DetermineNextLocations returns the next locations for the team to visit
 	// Team.Instance.Settings must be loaded before calling this function
+	// Conditional mode also requires Team.Blocks and Team.Instance.Locations.Blocks
 	DetermineNextLocations(ctx context.Context, team *models.Team) ([]models.Location, error)
 	// HasVisited returns true if the team has visited the location
 	HasVisited(checkins []models.CheckIn, locationID string) bool
+	// ValidateUnlockRules checks the unlock rules of the given locations for
+	// cycles and locations that can never be unlocked
+	ValidateUnlockRules(locations []models.Location) []UnlockRuleProblem
 }
 
 type navigationService struct{}
@@ -48,6 +56,24 @@ func (s *navigationService) CheckValidLocation(ctx context.Context, team *models
 			return true, nil
 		}
 	}
+
+	// Balanced suggestions shift as other teams move, so teams may check in
+	// to any location they have not yet visited
+	if settings.NavigationMode == models.BalancedNav {
+		for _, loc := range s.getUnvisitedLocations(ctx, team) {
+			if loc.MarkerID == markerID {
+				return true, nil
+			}
+		}
+	}
+
+	if settings.NavigationMode == models.ConditionalNav {
+		for _, loc := range team.Instance.Locations {
+			if loc.MarkerID == markerID {
+				return false, fmt.Errorf("%w: %s", ErrLocationLocked, markerID)
+			}
+		}
+	}
 	return false, fmt.Errorf("code %s is not a valid next location", markerID)
 }
 
@@ -70,6 +96,10 @@ func (s *navigationService) DetermineNextLocations(ctx context.Context, team *mo
 		return s.getRandomLocations(ctx, team)
 	case models.FreeRoamNav:
 		return s.getFreeRoamLocations(ctx, team)
+	case models.ConditionalNav:
+		return s.getConditionalLocations(ctx, team)
+	case models.BalancedNav:
+		return s.getBalancedLocations(ctx, team)
 	}
 
 	return nil, errors.New("invalid navigation mode")
@@ -98,7 +128,9 @@ func (s *navigationService) getUnvisitedLocations(_ context.Context, team *model
 }
 
 // getOrderedLocations returns locations in the order defined by the admin. This
-// function returns the next location for the team to visit.
+// function returns the next location for the team to visit. Teams assigned a
+// route follow the route instead, with any locations missing from the route
+// visited last in the default order.
 func (s *navigationService) getOrderedLocations(ctx context.Context, team *models.Team) ([]models.Location, error) {
 	unvisited := s.getUnvisitedLocations(ctx, team)
 	if len(unvisited) == 0 {
@@ -114,6 +146,19 @@ func (s *navigationService) getOrderedLocations(ctx context.Context, team *model
 		}
 	}
 
+	// Follow the team's route, if they have one
+	if team.RouteID != "" && team.Route.ID == team.RouteID {
+		position := func(location models.Location) int {
+			if i := team.Route.Position(location.MarkerID); i != -1 {
+				return i
+			}
+			return len(team.Route.Locations)
+		}
+		slices.SortStableFunc(unvisited, func(a, b models.Location) int {
+			return position(a) - position(b)
+		})
+	}
+
 	return unvisited[:1], nil
 }
 
@@ -136,17 +181,13 @@ func (s *navigationService) getRandomLocations(ctx context.Context, team *models
 	}
 
 	// Seed the random number generator with the team code to ensure deterministic shuffling
-	seed := uint64(0)
-	for _, char := range team.Code {
-		seed += uint64(char)
-	}
-	rand.Seed(seed)
+	rng := rand.New(rand.NewSource(teamSeed(team.Code)))
 
 	// We shuffle the list of all locations to ensure randomness
 	// even when the team has visited some locations
 	shuffledLocations := make([]models.Location, len(allLocations))
 	copy(shuffledLocations, allLocations)
-	rand.Shuffle(len(shuffledLocations), func(i, j int) {
+	rng.Shuffle(len(shuffledLocations), func(i, j int) {
 		shuffledLocations[i], shuffledLocations[j] = shuffledLocations[j], shuffledLocations[i]
 	})
 
@@ -169,6 +210,91 @@ func (s *navigationService) getRandomLocations(ctx context.Context, team *models
 	return selectedLocations, nil
 }
 
+// teamSeed returns a stable seed for a team code. The whole code is hashed so
+// codes sharing the same characters, such as anagrams, get different seeds.
+func teamSeed(code string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(code))
+	return h.Sum64()
+}
+
+// balanceWeight returns how strongly a location should be suggested given how
+// busy it is. Empty locations have a weight of 1 and full locations 0.
+// Occupancy is only known when teams check out, otherwise the number of teams
+// that have already visited is used to spread visits out and capacity is ignored.
+func balanceWeight(location models.Location, tracksOccupancy bool) float64 {
+	count := max(location.CurrentCount, 0)
+	if tracksOccupancy && location.Capacity > 0 {
+		free := location.Capacity - count
+		if free <= 0 {
+			return 0
+		}
+		return float64(free) / float64(location.Capacity)
+	}
+	return 1 / float64(1+count)
+}
+
+// getBalancedLocations returns random locations for the team to visit,
+// favouring locations with fewer teams and more free capacity. Locations at
+// capacity are only suggested when there is nowhere else to go.
+// The same team, progress and occupancy always give the same suggestions.
+func (s *navigationService) getBalancedLocations(ctx context.Context, team *models.Team) ([]models.Location, error) {
+	unvisited := s.getUnvisitedLocations(ctx, team)
+	if len(unvisited) == 0 {
+		return nil, ErrAllLocationsVisited
+	}
+	slices.SortFunc(unvisited, func(a, b models.Location) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	// Seeding with the team's progress gives a fresh draw after each check-in
+	rng := rand.New(rand.NewSource(teamSeed(team.Code) + uint64(len(team.CheckIns))))
+	tracksOccupancy := team.Instance.Settings.CompletionMethod == models.CheckInAndOut
+
+	// Weighted sampling without replacement: each location gets a key of
+	// u^(1/weight) and the highest keys win. Full locations sort last.
+	type candidate struct {
+		location models.Location
+		full     bool
+		key      float64
+	}
+	candidates := make([]candidate, len(unvisited))
+	for i, location := range unvisited {
+		u := rng.Float64()
+		weight := balanceWeight(location, tracksOccupancy)
+		if weight == 0 {
+			candidates[i] = candidate{location: location, full: true, key: u}
+			continue
+		}
+		candidates[i] = candidate{location: location, key: math.Pow(u, 1/weight)}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		if a.full != b.full {
+			if a.full {
+				return 1
+			}
+			return -1
+		}
+		switch {
+		case a.key > b.key:
+			return -1
+		case a.key < b.key:
+			return 1
+		}
+		return 0
+	})
+
+	n := team.Instance.Settings.MaxNextLocations
+	if n <= 0 || n > len(candidates) {
+		n = len(candidates)
+	}
+	selected := make([]models.Location, n)
+	for i := range selected {
+		selected[i] = candidates[i].location
+	}
+	return selected, nil
+}
+
 // getFreeRoamLocations returns a list of locations for free roam mode. This
 // function returns all locations in the instance for the team to visit.
 func (s *navigationService) getFreeRoamLocations(ctx context.Context, team *models.Team) ([]models.Location, error) {
@@ -190,3 +316,262 @@ func (s *navigationService) HasVisited(checkins []models.CheckIn, locationID str
 	}
 	return false
 }
+
+// getConditionalLocations returns the unvisited locations whose unlock rules
+// the team has met. If nothing is unlocked and nothing can be unlocked, the
+// team has finished.
+func (s *navigationService) getConditionalLocations(ctx context.Context, team *models.Team) ([]models.Location, error) {
+	unvisited := s.getUnvisitedLocations(ctx, team)
+	if len(unvisited) == 0 {
+		return nil, ErrAllLocationsVisited
+	}
+
+	state := newUnlockState(team)
+	unlocked := []models.Location{}
+	for _, location := range unvisited {
+		if state.meets(location.UnlockRules) {
+			unlocked = append(unlocked, location)
+		}
+	}
+	if len(unlocked) > 0 {
+		return unlocked, nil
+	}
+
+	// Nothing is unlocked yet, but completing blocks may unlock more
+	reachable := state.reachable(team.Instance.Locations)
+	for _, location := range unvisited {
+		if reachable[location.MarkerID] {
+			return unlocked, nil
+		}
+	}
+
+	return nil, ErrAllLocationsVisited
+}
+
+// unlockState tracks what a team has done towards unlocking locations.
+type unlockState struct {
+	// visited is keyed by marker code
+	visited map[string]bool
+	// completed is keyed by block ID
+	completed map[string]bool
+}
+
+// newUnlockState returns the unlock state for a team.
+func newUnlockState(team *models.Team) unlockState {
+	state := unlockState{
+		visited:   make(map[string]bool),
+		completed: make(map[string]bool),
+	}
+	for _, location := range team.Instance.Locations {
+		for _, checkIn := range team.CheckIns {
+			if checkIn.LocationID == location.ID {
+				state.visited[location.MarkerID] = true
+			}
+		}
+	}
+	for _, block := range team.Blocks {
+		if block.IsComplete {
+			state.completed[block.BlockID] = true
+		}
+	}
+	return state
+}
+
+// meets returns true if the state satisfies all of the rules.
+func (st unlockState) meets(rules models.UnlockRules) bool {
+	for _, rule := range rules {
+		switch rule.Type {
+		case models.RequireAllLocations:
+			for _, code := range rule.Locations {
+				if !st.visited[code] {
+					return false
+				}
+			}
+		case models.RequireAnyLocations:
+			count := 0
+			for _, code := range rule.Locations {
+				if st.visited[code] {
+					count++
+				}
+			}
+			if count < max(rule.Count, 1) {
+				return false
+			}
+		case models.RequireBlock:
+			if !st.completed[rule.BlockID] {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// reachable returns the marker codes of the locations that can be unlocked
+// from this state, assuming the team visits every location as it unlocks
+// and completes every block along the way.
+func (st unlockState) reachable(locations []models.Location) map[string]bool {
+	reached := unlockState{
+		visited:   make(map[string]bool, len(locations)),
+		completed: make(map[string]bool, len(st.completed)),
+	}
+	for code := range st.visited {
+		reached.visited[code] = true
+	}
+	for id := range st.completed {
+		reached.completed[id] = true
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, location := range locations {
+			if !reached.visited[location.MarkerID] {
+				if !reached.meets(location.UnlockRules) {
+					continue
+				}
+				reached.visited[location.MarkerID] = true
+				changed = true
+			}
+			for _, block := range location.Blocks {
+				if !reached.completed[block.ID] {
+					reached.completed[block.ID] = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	return reached.visited
+}
+
+// UnlockRuleProblem describes a location whose unlock rules need attention.
+type UnlockRuleProblem struct {
+	Location models.Location
+	Problem  string
+}
+
+// ValidateUnlockRules checks the unlock rules of the given locations. It
+// reports invalid rules, references to missing locations or blocks, and
+// locations that can never be unlocked, including those locked in a cycle.
+// Locations.Blocks must be loaded.
+func (s *navigationService) ValidateUnlockRules(locations []models.Location) []UnlockRuleProblem {
+	problems := []UnlockRuleProblem{}
+
+	byCode := make(map[string]models.Location, len(locations))
+	blockLocation := make(map[string]string)
+	for _, location := range locations {
+		byCode[location.MarkerID] = location
+		for _, block := range location.Blocks {
+			blockLocation[block.ID] = location.MarkerID
+		}
+	}
+
+	// Rules that are incomplete or refer to something that does not exist
+	reported := make(map[string]bool)
+	for _, location := range locations {
+		for i, rule := range location.UnlockRules {
+			problem := ""
+			if err := rule.Validate(); err != nil {
+				problem = fmt.Sprintf("Rule %d is invalid: %s.", i+1, err)
+			}
+			for _, code := range rule.Locations {
+				if _, ok := byCode[code]; !ok {
+					problem = fmt.Sprintf("Rule %d requires a location that no longer exists.", i+1)
+				}
+			}
+			if rule.Type == models.RequireBlock && rule.BlockID != "" && blockLocation[rule.BlockID] == "" {
+				problem = fmt.Sprintf("Rule %d requires a block that no longer exists.", i+1)
+			}
+			if problem != "" {
+				problems = append(problems, UnlockRuleProblem{Location: location, Problem: problem})
+				reported[location.MarkerID] = true
+			}
+		}
+	}
+
+	// Locations that can never be unlocked, either because they depend on
+	// each other or on a location that can never be unlocked
+	reachable := unlockState{}.reachable(locations)
+	locked := make(map[string]bool)
+	for _, location := range locations {
+		if !reachable[location.MarkerID] {
+			locked[location.MarkerID] = true
+		}
+	}
+
+	dependencies := func(location models.Location) []string {
+		deps := []string{}
+		for _, rule := range location.UnlockRules {
+			for _, code := range rule.Locations {
+				if locked[code] {
+					deps = append(deps, code)
+				}
+			}
+			if code := blockLocation[rule.BlockID]; rule.Type == models.RequireBlock && locked[code] {
+				deps = append(deps, code)
+			}
+		}
+		return deps
+	}
+
+	cycles := make(map[string]string)
+	state := make(map[string]int) // 0 unseen, 1 on the current path, 2 done
+	path := []string{}
+	var visit func(code string)
+	visit = func(code string) {
+		state[code] = 1
+		path = append(path, code)
+		for _, dep := range dependencies(byCode[code]) {
+			switch state[dep] {
+			case 0:
+				visit(dep)
+			case 1:
+				// Found a cycle from dep back to dep
+				start := 0
+				for i, c := range path {
+					if c == dep {
+						start = i
+					}
+				}
+				names := []string{}
+				for _, c := range path[start:] {
+					names = append(names, byCode[c].Name)
+				}
+				names = append(names, byCode[dep].Name)
+				description := strings.Join(names, " → ")
+				for _, c := range path[start:] {
+					if cycles[c] == "" {
+						cycles[c] = description
+					}
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[code] = 2
+	}
+	for _, location := range locations {
+		if locked[location.MarkerID] && state[location.MarkerID] == 0 {
+			visit(location.MarkerID)
+		}
+	}
+
+	for _, location := range locations {
+		if !locked[location.MarkerID] {
+			continue
+		}
+		if cycle, ok := cycles[location.MarkerID]; ok {
+			problems = append(problems, UnlockRuleProblem{
+				Location: location,
+				Problem:  "Its requirements form a cycle: " + cycle + ".",
+			})
+		} else if !reported[location.MarkerID] {
+			problems = append(problems, UnlockRuleProblem{
+				Location: location,
+				Problem:  "It can never be unlocked because it requires locations or blocks that are never unlocked.",
+			})
+		}
+	}
+
+	return problems
+}
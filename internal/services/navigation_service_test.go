@@ -0,0 +1,425 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conditionalTeam returns a team playing a Conditional game with the given locations.
+func conditionalTeam(locations []models.Location) *models.Team {
+	return &models.Team{
+		Code:       "TEAM",
+		InstanceID: "instance",
+		Instance: models.Instance{
+			ID:        "instance",
+			Locations: locations,
+			Settings: models.InstanceSettings{
+				InstanceID:     "instance",
+				NavigationMode: models.ConditionalNav,
+			},
+		},
+	}
+}
+
+func conditionalLocations() []models.Location {
+	return []models.Location{
+		{ID: "a", MarkerID: "A", Name: "Start"},
+		{ID: "b", MarkerID: "B", Name: "Left", UnlockRules: models.UnlockRules{
+			{Type: models.RequireAllLocations, Locations: []string{"A"}},
+		}},
+		{ID: "c", MarkerID: "C", Name: "Right", UnlockRules: models.UnlockRules{
+			{Type: models.RequireAllLocations, Locations: []string{"A"}},
+		}, Blocks: []models.Block{{ID: "block-c", LocationID: "c"}}},
+		{ID: "d", MarkerID: "D", Name: "Either", UnlockRules: models.UnlockRules{
+			{Type: models.RequireAnyLocations, Locations: []string{"B", "C"}, Count: 1},
+		}},
+		{ID: "e", MarkerID: "E", Name: "Puzzle", UnlockRules: models.UnlockRules{
+			{Type: models.RequireBlock, BlockID: "block-c"},
+		}},
+	}
+}
+
+func markerIDs(locations []models.Location) []string {
+	ids := make([]string, len(locations))
+	for i, location := range locations {
+		ids[i] = location.MarkerID
+	}
+	return ids
+}
+
+func TestNavigationService_ConditionalNextLocations(t *testing.T) {
+	svc := services.NewNavigationService()
+
+	tests := []struct {
+		name      string
+		checkIns  []string
+		completed []string
+		expected  []string
+	}{
+		{"Only locations without rules at the start", nil, nil, []string{"A"}},
+		{"All rule", []string{"a"}, nil, []string{"B", "C"}},
+		{"Any rule", []string{"a", "b"}, nil, []string{"C", "D"}},
+		{"Block rule", []string{"a", "c"}, []string{"block-c"}, []string{"B", "D", "E"}},
+		{"Incomplete block", []string{"a", "c"}, nil, []string{"B", "D"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			team := conditionalTeam(conditionalLocations())
+			for _, id := range tt.checkIns {
+				team.CheckIns = append(team.CheckIns, models.CheckIn{LocationID: id})
+			}
+			for _, id := range tt.completed {
+				team.Blocks = append(team.Blocks, models.TeamBlockState{BlockID: id, IsComplete: true})
+			}
+
+			locations, err := svc.DetermineNextLocations(context.Background(), team)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, markerIDs(locations))
+		})
+	}
+}
+
+func TestNavigationService_ConditionalWaitingOnBlock(t *testing.T) {
+	svc := services.NewNavigationService()
+
+	locations := []models.Location{
+		{ID: "a", MarkerID: "A", Blocks: []models.Block{{ID: "block-a", LocationID: "a"}}},
+		{ID: "b", MarkerID: "B", UnlockRules: models.UnlockRules{
+			{Type: models.RequireBlock, BlockID: "block-a"},
+		}},
+	}
+	team := conditionalTeam(locations)
+	team.CheckIns = []models.CheckIn{{LocationID: "a"}}
+
+	// Nothing is unlocked, but completing the block will unlock B
+	next, err := svc.DetermineNextLocations(context.Background(), team)
+	require.NoError(t, err)
+	assert.Empty(t, next)
+
+	team.Blocks = []models.TeamBlockState{{BlockID: "block-a", IsComplete: true}}
+	next, err = svc.DetermineNextLocations(context.Background(), team)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"B"}, markerIDs(next))
+}
+
+func TestNavigationService_ConditionalFinished(t *testing.T) {
+	svc := services.NewNavigationService()
+
+	// B can never be unlocked, so visiting A finishes the game
+	locations := []models.Location{
+		{ID: "a", MarkerID: "A"},
+		{ID: "b", MarkerID: "B", UnlockRules: models.UnlockRules{
+			{Type: models.RequireAllLocations, Locations: []string{"MISSING"}},
+		}},
+	}
+	team := conditionalTeam(locations)
+	team.CheckIns = []models.CheckIn{{LocationID: "a"}}
+
+	_, err := svc.DetermineNextLocations(context.Background(), team)
+	assert.ErrorIs(t, err, services.ErrAllLocationsVisited)
+}
+
+func TestNavigationService_ConditionalCheckValidLocation(t *testing.T) {
+	svc := services.NewNavigationService()
+
+	team := conditionalTeam(conditionalLocations())
+
+	valid, err := svc.CheckValidLocation(context.Background(), team, &team.Instance.Settings, "a")
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = svc.CheckValidLocation(context.Background(), team, &team.Instance.Settings, "B")
+	assert.False(t, valid)
+	assert.ErrorIs(t, err, services.ErrLocationLocked)
+
+	valid, err = svc.CheckValidLocation(context.Background(), team, &team.Instance.Settings, "NOPE")
+	assert.False(t, valid)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, services.ErrLocationLocked)
+}
+
+func TestNavigationService_ValidateUnlockRules(t *testing.T) {
+	svc := services.NewNavigationService()
+
+	t.Run("Valid graph", func(t *testing.T) {
+		problems := svc.ValidateUnlockRules(conditionalLocations())
+		assert.Empty(t, problems)
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		locations := []models.Location{
+			{ID: "a", MarkerID: "A", Name: "Start"},
+			{ID: "b", MarkerID: "B", Name: "Chicken", UnlockRules: models.UnlockRules{
+				{Type: models.RequireAllLocations, Locations: []string{"C"}},
+			}},
+			{ID: "c", MarkerID: "C", Name: "Egg", UnlockRules: models.UnlockRules{
+				{Type: models.RequireAllLocations, Locations: []string{"B"}},
+			}},
+			{ID: "d", MarkerID: "D", Name: "After", UnlockRules: models.UnlockRules{
+				{Type: models.RequireAllLocations, Locations: []string{"C"}},
+			}},
+		}
+		problems := svc.ValidateUnlockRules(locations)
+		require.Len(t, problems, 3)
+		assert.Equal(t, "B", problems[0].Location.MarkerID)
+		assert.Contains(t, problems[0].Problem, "Chicken → Egg → Chicken")
+		assert.Equal(t, "C", problems[1].Location.MarkerID)
+		assert.Contains(t, problems[1].Problem, "cycle")
+		assert.Equal(t, "D", problems[2].Location.MarkerID)
+		assert.Contains(t, problems[2].Problem, "never be unlocked")
+	})
+
+	t.Run("Cycle with a way out", func(t *testing.T) {
+		locations := []models.Location{
+			{ID: "a", MarkerID: "A"},
+			{ID: "b", MarkerID: "B", UnlockRules: models.UnlockRules{
+				{Type: models.RequireAnyLocations, Locations: []string{"A", "C"}, Count: 1},
+			}},
+			{ID: "c", MarkerID: "C", UnlockRules: models.UnlockRules{
+				{Type: models.RequireAllLocations, Locations: []string{"B"}},
+			}},
+		}
+		assert.Empty(t, svc.ValidateUnlockRules(locations))
+	})
+
+	t.Run("Cycle through a block", func(t *testing.T) {
+		locations := []models.Location{
+			{ID: "a", MarkerID: "A", Name: "Lock", UnlockRules: models.UnlockRules{
+				{Type: models.RequireBlock, BlockID: "block-b"},
+			}},
+			{ID: "b", MarkerID: "B", Name: "Key", UnlockRules: models.UnlockRules{
+				{Type: models.RequireAllLocations, Locations: []string{"A"}},
+			}, Blocks: []models.Block{{ID: "block-b", LocationID: "b"}}},
+		}
+		problems := svc.ValidateUnlockRules(locations)
+		require.Len(t, problems, 2)
+		assert.Contains(t, problems[0].Problem, "Lock → Key → Lock")
+	})
+
+	t.Run("Missing references", func(t *testing.T) {
+		locations := []models.Location{
+			{ID: "a", MarkerID: "A"},
+			{ID: "b", MarkerID: "B", UnlockRules: models.UnlockRules{
+				{Type: models.RequireAllLocations, Locations: []string{"GONE"}},
+			}},
+			{ID: "c", MarkerID: "C", UnlockRules: models.UnlockRules{
+				{Type: models.RequireBlock, BlockID: "gone"},
+			}},
+			{ID: "d", MarkerID: "D", UnlockRules: models.UnlockRules{
+				{Type: models.RequireAnyLocations, Locations: []string{"A"}, Count: 2},
+			}},
+		}
+		problems := svc.ValidateUnlockRules(locations)
+		require.Len(t, problems, 3)
+		assert.Contains(t, problems[0].Problem, "location that no longer exists")
+		assert.Contains(t, problems[1].Problem, "block that no longer exists")
+		assert.Contains(t, problems[2].Problem, "invalid")
+	})
+}
+
+func TestNavigationService_OrderedRoute(t *testing.T) {
+	svc := services.NewNavigationService()
+
+	team := &models.Team{
+		Code:       "TEAM",
+		InstanceID: "instance",
+		Instance: models.Instance{
+			ID: "instance",
+			Locations: []models.Location{
+				{ID: "a", MarkerID: "A", Order: 0},
+				{ID: "b", MarkerID: "B", Order: 1},
+				{ID: "c", MarkerID: "C", Order: 2},
+				{ID: "d", MarkerID: "D", Order: 3},
+			},
+			Settings: models.InstanceSettings{
+				InstanceID:     "instance",
+				NavigationMode: models.OrderedNav,
+			},
+		},
+	}
+
+	// Without a route teams follow the default order
+	next, err := svc.DetermineNextLocations(context.Background(), team)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A"}, markerIDs(next))
+
+	// Teams follow their route, with locations missing from it visited last
+	team.RouteID = "route"
+	team.Route = models.Route{ID: "route", Locations: models.StrArray{"C", "A", "GONE"}}
+	expected := []string{"C", "A", "B", "D"}
+	for _, marker := range expected {
+		next, err = svc.DetermineNextLocations(context.Background(), team)
+		require.NoError(t, err)
+		require.Equal(t, []string{marker}, markerIDs(next))
+		team.CheckIns = append(team.CheckIns, models.CheckIn{LocationID: next[0].ID})
+	}
+
+	_, err = svc.DetermineNextLocations(context.Background(), team)
+	assert.ErrorIs(t, err, services.ErrAllLocationsVisited)
+}
+
+// balancedInstance returns an instance playing a Balanced game.
+func balancedInstance(locations []models.Location, completion models.CompletionMethod) models.Instance {
+	return models.Instance{
+		ID:        "instance",
+		Locations: locations,
+		Settings: models.InstanceSettings{
+			InstanceID:       "instance",
+			NavigationMode:   models.BalancedNav,
+			CompletionMethod: completion,
+			MaxNextLocations: 3,
+		},
+	}
+}
+
+func TestNavigationService_RandomSeedsAnagrams(t *testing.T) {
+	svc := services.NewNavigationService()
+
+	locations := []models.Location{}
+	for i := 0; i < 8; i++ {
+		locations = append(locations, models.Location{ID: fmt.Sprint(i), MarkerID: fmt.Sprint("M", i)})
+	}
+	instance := models.Instance{
+		ID:        "instance",
+		Locations: locations,
+		Settings: models.InstanceSettings{
+			InstanceID:       "instance",
+			NavigationMode:   models.RandomNav,
+			MaxNextLocations: 8,
+		},
+	}
+
+	// Anagram codes used to share a seed and so the same route
+	first, err := svc.DetermineNextLocations(context.Background(), &models.Team{Code: "ABCD", Instance: instance})
+	require.NoError(t, err)
+	second, err := svc.DetermineNextLocations(context.Background(), &models.Team{Code: "DCBA", Instance: instance})
+	require.NoError(t, err)
+	assert.NotEqual(t, markerIDs(first), markerIDs(second))
+
+	// The same team always gets the same locations
+	again, err := svc.DetermineNextLocations(context.Background(), &models.Team{Code: "ABCD", Instance: instance})
+	require.NoError(t, err)
+	assert.Equal(t, markerIDs(first), markerIDs(again))
+}
+
+func TestNavigationService_BalancedIsDeterministic(t *testing.T) {
+	svc := services.NewNavigationService()
+
+	locations := []models.Location{
+		{ID: "a", MarkerID: "A", CurrentCount: 2, Capacity: 5},
+		{ID: "b", MarkerID: "B", CurrentCount: 0},
+		{ID: "c", MarkerID: "C", CurrentCount: 4},
+		{ID: "d", MarkerID: "D", CurrentCount: 1, Capacity: 2},
+	}
+	team := &models.Team{Code: "TEAM", Instance: balancedInstance(locations, models.CheckInAndOut)}
+
+	first, err := svc.DetermineNextLocations(context.Background(), team)
+	require.NoError(t, err)
+	assert.Len(t, first, 3)
+	for i := 0; i < 10; i++ {
+		next, err := svc.DetermineNextLocations(context.Background(), team)
+		require.NoError(t, err)
+		assert.Equal(t, markerIDs(first), markerIDs(next))
+	}
+}
+
+func TestNavigationService_BalancedFullLocations(t *testing.T) {
+	svc := services.NewNavigationService()
+
+	locations := []models.Location{
+		{ID: "a", MarkerID: "A", CurrentCount: 3, Capacity: 3},
+		{ID: "b", MarkerID: "B", CurrentCount: 10},
+		{ID: "c", MarkerID: "C", CurrentCount: 5, Capacity: 4},
+	}
+	instance := balancedInstance(locations, models.CheckInAndOut)
+	instance.Settings.MaxNextLocations = 1
+
+	// Full locations are only suggested when nothing else is available
+	for _, code := range []string{"AAAA", "BBBB", "CCCC", "DDDD", "EEEE"} {
+		next, err := svc.DetermineNextLocations(context.Background(), &models.Team{Code: code, Instance: instance})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"B"}, markerIDs(next))
+	}
+
+	// Teams may still check in to full locations they walk past
+	team := &models.Team{Code: "AAAA", Instance: instance}
+	valid, err := svc.CheckValidLocation(context.Background(), team, &team.Instance.Settings, "a")
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// Capacity is ignored when teams don't check out
+	instance.Settings.CompletionMethod = models.CheckInOnly
+	instance.Settings.MaxNextLocations = 0
+	next, err := svc.DetermineNextLocations(context.Background(), &models.Team{Code: "AAAA", Instance: instance})
+	require.NoError(t, err)
+	assert.Len(t, next, 3)
+}
+
+func TestNavigationService_BalancedSimulation(t *testing.T) {
+	svc := services.NewNavigationService()
+
+	simulate := func(t *testing.T, mode models.NavigationMode, teamCount int, capacity int) map[string]int {
+		t.Helper()
+		locations := []models.Location{}
+		for i := 0; i < 6; i++ {
+			locations = append(locations, models.Location{ID: fmt.Sprint(i), MarkerID: fmt.Sprint("M", i), Capacity: capacity})
+		}
+		instance := balancedInstance(locations, models.CheckInAndOut)
+		instance.Settings.NavigationMode = mode
+
+		// Teams arrive one after another and go to their first suggestion
+		for i := 0; i < teamCount; i++ {
+			team := &models.Team{Code: fmt.Sprintf("T%03d", i), Instance: instance}
+			next, err := svc.DetermineNextLocations(context.Background(), team)
+			require.NoError(t, err)
+			require.NotEmpty(t, next)
+			for j := range instance.Locations {
+				if instance.Locations[j].ID == next[0].ID {
+					instance.Locations[j].CurrentCount++
+				}
+			}
+		}
+
+		occupancy := map[string]int{}
+		for _, location := range instance.Locations {
+			occupancy[location.MarkerID] = location.CurrentCount
+		}
+		return occupancy
+	}
+
+	t.Run("Capacity is never exceeded while there is room", func(t *testing.T) {
+		occupancy := simulate(t, models.BalancedNav, 60, 10)
+		for marker, count := range occupancy {
+			assert.Equal(t, 10, count, marker)
+		}
+	})
+
+	t.Run("Teams spread out without capacities", func(t *testing.T) {
+		occupancy := simulate(t, models.BalancedNav, 120, 0)
+		for marker, count := range occupancy {
+			assert.InDelta(t, 20, count, 6, marker)
+		}
+	})
+
+	t.Run("Balanced spreads teams more evenly than Random", func(t *testing.T) {
+		spread := func(occupancy map[string]int) int {
+			least, most := math.MaxInt, 0
+			for _, count := range occupancy {
+				least = min(least, count)
+				most = max(most, count)
+			}
+			return most - least
+		}
+		random := simulate(t, models.RandomNav, 120, 0)
+		balanced := simulate(t, models.BalancedNav, 120, 0)
+		assert.Less(t, spread(balanced), spread(random))
+	})
+}
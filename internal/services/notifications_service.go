@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/nathanhollows/Rapua/v3/internal/events"
 	"github.com/nathanhollows/Rapua/v3/models"
 	"github.com/nathanhollows/Rapua/v3/repositories"
 )
@@ -17,15 +18,18 @@ type NotificationService interface {
 }
 
 type notificationService struct {
+	broker                 *events.Broker
 	notificationRepository repositories.NotificationRepository
 	teamRepository         repositories.TeamRepository
 }
 
 func NewNotificationService(
+	broker *events.Broker,
 	notificationRepository repositories.NotificationRepository,
 	teamRepository repositories.TeamRepository,
 ) NotificationService {
 	return &notificationService{
+		broker:                 broker,
 		notificationRepository: notificationRepository,
 		teamRepository:         teamRepository,
 	}
@@ -33,13 +37,32 @@ func NewNotificationService(
 
 // SendNotification sends a notification to a team.
 func (s *notificationService) SendNotification(ctx context.Context, teamCode string, content string) (models.Notification, error) {
+	team, err := s.teamRepository.GetByCode(ctx, teamCode)
+	if err != nil {
+		return models.Notification{}, fmt.Errorf("finding team: %w", err)
+	}
+	return s.send(ctx, *team, content)
+}
+
+// send saves the notification and pushes it to the team's open pages.
+func (s *notificationService) send(ctx context.Context, team models.Team, content string) (models.Notification, error) {
 	notification := models.Notification{
-		TeamCode: teamCode,
+		TeamCode: team.Code,
 		Content:  content,
 	}
 
 	err := s.notificationRepository.Create(ctx, &notification)
-	return notification, err
+	if err != nil {
+		return notification, err
+	}
+
+	s.broker.Publish(events.Event{
+		Type:       events.NotificationSent,
+		InstanceID: team.InstanceID,
+		TeamCode:   team.Code,
+		Payload:    notification,
+	})
+	return notification, nil
 }
 
 // SendNotificationToAllTeams sends a notification to all teams.
@@ -59,7 +82,7 @@ func (s *notificationService) SendNotificationToAllTeams(ctx context.Context, in
 
 	for _, team := range teams {
 		if team.HasStarted {
-			_, err := s.SendNotification(ctx, team.Code, content)
+			_, err := s.send(ctx, team, content)
 			if err != nil {
 				return err
 			}
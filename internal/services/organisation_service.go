@@ -0,0 +1,434 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+)
+
+var (
+	ErrAlreadyOrganisationMember = errors.New("user is already a member of this organisation")
+	ErrLastOrganisationAdmin     = errors.New("an organisation must keep at least one admin")
+	ErrOrganisationUserNotFound  = errors.New("no account uses that email address")
+)
+
+type OrganisationService interface {
+	// Create creates an organisation with the user as its first admin
+	Create(ctx context.Context, user *models.User, name string) (*models.Organisation, error)
+	// FindForUser returns the organisations a user belongs to
+	FindForUser(ctx context.Context, userID string) ([]models.Organisation, error)
+	// Role returns the user's role in an organisation
+	Role(ctx context.Context, user *models.User, organisationID string) (models.OrganisationRole, error)
+	// SwitchWorkspace switches the user to an organisation's workspace, or to
+	// their own workspace when the organisation ID is empty
+	SwitchWorkspace(ctx context.Context, user *models.User, organisationID string) error
+
+	// FindMembers returns the members of an organisation
+	FindMembers(ctx context.Context, organisationID string) ([]models.OrganisationMember, error)
+	// AddMember adds an existing user to an organisation by their email
+	AddMember(ctx context.Context, user *models.User, organisationID, email string, role models.OrganisationRole) (*models.OrganisationMember, error)
+	// UpdateMemberRole changes a member's role
+	UpdateMemberRole(ctx context.Context, user *models.User, organisationID, memberID string, role models.OrganisationRole) error
+	// RemoveMember removes a member from an organisation.
+	// Members may remove themselves to leave
+	RemoveMember(ctx context.Context, user *models.User, organisationID, memberID string) error
+
+	// FindInstances returns the instances in an organisation
+	FindInstances(ctx context.Context, organisationID string) ([]models.Instance, error)
+	// MoveInstance moves one of the user's instances into an organisation, or
+	// back to their own workspace when the organisation ID is empty
+	MoveInstance(ctx context.Context, user *models.User, instanceID, organisationID string) error
+	// TransferInstance gives an organisation instance to another member
+	TransferInstance(ctx context.Context, user *models.User, instanceID, newOwnerID string) error
+	// Usage totals what an organisation's instances use
+	Usage(ctx context.Context, organisationID string) (*models.OrganisationUsage, error)
+}
+
+type organisationService struct {
+	auditRepo    repositories.AuditRepository
+	instanceRepo repositories.InstanceRepository
+	orgRepo      repositories.OrganisationRepository
+	userRepo     repositories.UserRepository
+}
+
+// NewOrganisationService creates a new OrganisationService.
+func NewOrganisationService(
+	auditRepo repositories.AuditRepository,
+	instanceRepo repositories.InstanceRepository,
+	orgRepo repositories.OrganisationRepository,
+	userRepo repositories.UserRepository,
+) OrganisationService {
+	return &organisationService{
+		auditRepo:    auditRepo,
+		instanceRepo: instanceRepo,
+		orgRepo:      orgRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// Create creates an organisation with the user as its first admin.
+func (s *organisationService) Create(ctx context.Context, user *models.User, name string) (*models.Organisation, error) {
+	if user == nil {
+		return nil, ErrUserNotAuthenticated
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, NewValidationError("name")
+	}
+
+	organisation := &models.Organisation{Name: name}
+	err := s.orgRepo.Create(ctx, organisation)
+	if err != nil {
+		return nil, fmt.Errorf("creating organisation: %w", err)
+	}
+
+	err = s.orgRepo.CreateMember(ctx, &models.OrganisationMember{
+		OrganisationID: organisation.ID,
+		UserID:         user.ID,
+		Role:           models.OrgRoleAdmin,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("adding admin: %w", err)
+	}
+	return organisation, nil
+}
+
+// FindForUser returns the organisations a user belongs to.
+func (s *organisationService) FindForUser(ctx context.Context, userID string) ([]models.Organisation, error) {
+	organisations, err := s.orgRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("finding organisations: %w", err)
+	}
+	return organisations, nil
+}
+
+// Role returns the user's role in an organisation.
+// Users who are not members get ErrPermissionDenied.
+func (s *organisationService) Role(ctx context.Context, user *models.User, organisationID string) (models.OrganisationRole, error) {
+	if user == nil {
+		return "", ErrUserNotAuthenticated
+	}
+	member, err := s.orgRepo.GetMember(ctx, organisationID, user.ID)
+	if err != nil {
+		return "", ErrPermissionDenied
+	}
+	return member.Role, nil
+}
+
+// SwitchWorkspace switches the user to an organisation's workspace.
+// The current instance is cleared if it belongs to another workspace.
+func (s *organisationService) SwitchWorkspace(ctx context.Context, user *models.User, organisationID string) error {
+	if user == nil {
+		return ErrUserNotAuthenticated
+	}
+	if organisationID != "" {
+		_, err := s.Role(ctx, user, organisationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	user.CurrentOrganisationID = organisationID
+	if user.CurrentInstance.OrganisationID != organisationID {
+		user.CurrentInstanceID = ""
+	}
+	err := s.userRepo.Update(ctx, user)
+	if err != nil {
+		return fmt.Errorf("updating user: %w", err)
+	}
+	return nil
+}
+
+// FindMembers returns the members of an organisation.
+func (s *organisationService) FindMembers(ctx context.Context, organisationID string) ([]models.OrganisationMember, error) {
+	members, err := s.orgRepo.FindMembers(ctx, organisationID)
+	if err != nil {
+		return nil, fmt.Errorf("finding members: %w", err)
+	}
+	return members, nil
+}
+
+// AddMember adds an existing user to an organisation by their email.
+// Only admins may add members.
+func (s *organisationService) AddMember(ctx context.Context, user *models.User, organisationID, email string, role models.OrganisationRole) (*models.OrganisationMember, error) {
+	err := s.requireAdmin(ctx, user, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, NewValidationError("email")
+	}
+	if _, ok := models.ParseOrganisationRole(string(role)); !ok {
+		return nil, NewValidationError("role")
+	}
+
+	newMember, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, ErrOrganisationUserNotFound
+	}
+	if _, err := s.orgRepo.GetMember(ctx, organisationID, newMember.ID); err == nil {
+		return nil, ErrAlreadyOrganisationMember
+	}
+
+	member := &models.OrganisationMember{
+		OrganisationID: organisationID,
+		UserID:         newMember.ID,
+		Role:           role,
+	}
+	err = s.orgRepo.CreateMember(ctx, member)
+	if err != nil {
+		return nil, fmt.Errorf("adding member: %w", err)
+	}
+	return member, nil
+}
+
+// UpdateMemberRole changes a member's role.
+func (s *organisationService) UpdateMemberRole(ctx context.Context, user *models.User, organisationID, memberID string, role models.OrganisationRole) error {
+	err := s.requireAdmin(ctx, user, organisationID)
+	if err != nil {
+		return err
+	}
+	if _, ok := models.ParseOrganisationRole(string(role)); !ok {
+		return NewValidationError("role")
+	}
+
+	member, err := s.member(ctx, organisationID, memberID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.OrgRoleAdmin && role != models.OrgRoleAdmin {
+		err = s.keepAdmin(ctx, organisationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	member.Role = role
+	err = s.orgRepo.UpdateMember(ctx, member)
+	if err != nil {
+		return fmt.Errorf("updating member: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember removes a member from an organisation.
+// Their instances stay in the organisation so an admin can transfer them.
+func (s *organisationService) RemoveMember(ctx context.Context, user *models.User, organisationID, memberID string) error {
+	if user == nil {
+		return ErrUserNotAuthenticated
+	}
+
+	member, err := s.member(ctx, organisationID, memberID)
+	if err != nil {
+		return err
+	}
+
+	// Members can always leave
+	if member.UserID != user.ID {
+		err = s.requireAdmin(ctx, user, organisationID)
+		if err != nil {
+			return err
+		}
+	}
+	if member.Role == models.OrgRoleAdmin {
+		err = s.keepAdmin(ctx, organisationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.orgRepo.DeleteMember(ctx, member.ID)
+	if err != nil {
+		return fmt.Errorf("removing member: %w", err)
+	}
+
+	// Move the member back to their own workspace so they are not locked out
+	removed, err := s.userRepo.GetByID(ctx, member.UserID)
+	if err != nil {
+		return fmt.Errorf("finding member: %w", err)
+	}
+	if removed.CurrentOrganisationID == organisationID {
+		err = s.SwitchWorkspace(ctx, removed, "")
+		if err != nil {
+			return err
+		}
+	}
+	if removed.ID == user.ID {
+		user.CurrentOrganisationID = removed.CurrentOrganisationID
+		user.CurrentInstanceID = removed.CurrentInstanceID
+	}
+	return nil
+}
+
+// FindInstances returns the instances in an organisation.
+func (s *organisationService) FindInstances(ctx context.Context, organisationID string) ([]models.Instance, error) {
+	instances, err := s.instanceRepo.FindByOrganisationID(ctx, organisationID)
+	if err != nil {
+		return nil, fmt.Errorf("finding instances: %w", err)
+	}
+	return instances, nil
+}
+
+// MoveInstance moves one of the user's instances between workspaces.
+// Only the owner may move an instance, and only into an organisation they
+// belong to.
+func (s *organisationService) MoveInstance(ctx context.Context, user *models.User, instanceID, organisationID string) error {
+	if user == nil {
+		return ErrUserNotAuthenticated
+	}
+
+	instance, err := s.instanceRepo.GetByID(ctx, instanceID)
+	if err != nil {
+		return fmt.Errorf("finding instance: %w", err)
+	}
+	if instance.UserID != user.ID || instance.IsTemplate {
+		return ErrPermissionDenied
+	}
+	if organisationID != "" {
+		_, err = s.Role(ctx, user, organisationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	before := s.auditWorkspace(ctx, instance)
+	instance.OrganisationID = organisationID
+	err = s.instanceRepo.Update(ctx, instance)
+	if err != nil {
+		return fmt.Errorf("moving instance: %w", err)
+	}
+
+	return s.audit(ctx, models.AuditInstanceMoved, instance, before, s.auditWorkspace(ctx, instance))
+}
+
+// TransferInstance gives an organisation instance to another member.
+// Only organisation admins may transfer instances.
+func (s *organisationService) TransferInstance(ctx context.Context, user *models.User, instanceID, newOwnerID string) error {
+	if newOwnerID == "" {
+		return NewValidationError("newOwnerID")
+	}
+
+	instance, err := s.instanceRepo.GetByID(ctx, instanceID)
+	if err != nil {
+		return fmt.Errorf("finding instance: %w", err)
+	}
+	if instance.OrganisationID == "" {
+		return ErrPermissionDenied
+	}
+	err = s.requireAdmin(ctx, user, instance.OrganisationID)
+	if err != nil {
+		return err
+	}
+	_, err = s.orgRepo.GetMember(ctx, instance.OrganisationID, newOwnerID)
+	if err != nil {
+		return fmt.Errorf("%w: new owner must be a member of the organisation", ErrInvalidArgument)
+	}
+
+	before := s.auditOwner(ctx, instance)
+	instance.UserID = newOwnerID
+	err = s.instanceRepo.Update(ctx, instance)
+	if err != nil {
+		return fmt.Errorf("transferring instance: %w", err)
+	}
+
+	return s.audit(ctx, models.AuditInstanceTransfer, instance, before, s.auditOwner(ctx, instance))
+}
+
+// Usage totals what an organisation's instances use.
+func (s *organisationService) Usage(ctx context.Context, organisationID string) (*models.OrganisationUsage, error) {
+	usage, err := s.orgRepo.Usage(ctx, organisationID)
+	if err != nil {
+		return nil, fmt.Errorf("finding usage: %w", err)
+	}
+	return usage, nil
+}
+
+// requireAdmin checks the user is an admin of the organisation.
+func (s *organisationService) requireAdmin(ctx context.Context, user *models.User, organisationID string) error {
+	role, err := s.Role(ctx, user, organisationID)
+	if err != nil {
+		return err
+	}
+	if role != models.OrgRoleAdmin {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// keepAdmin checks the organisation has another admin besides the one being
+// removed or demoted.
+func (s *organisationService) keepAdmin(ctx context.Context, organisationID string) error {
+	members, err := s.orgRepo.FindMembers(ctx, organisationID)
+	if err != nil {
+		return fmt.Errorf("finding members: %w", err)
+	}
+	admins := 0
+	for _, member := range members {
+		if member.Role == models.OrgRoleAdmin {
+			admins++
+		}
+	}
+	if admins < 2 {
+		return ErrLastOrganisationAdmin
+	}
+	return nil
+}
+
+// member finds a member of the given organisation.
+func (s *organisationService) member(ctx context.Context, organisationID, memberID string) (*models.OrganisationMember, error) {
+	if memberID == "" {
+		return nil, NewValidationError("memberID")
+	}
+	member, err := s.orgRepo.GetMemberByID(ctx, memberID)
+	if err != nil || member.OrganisationID != organisationID {
+		return nil, ErrPermissionDenied
+	}
+	return member, nil
+}
+
+// auditWorkspace returns the workspace of an instance for the audit log.
+func (s *organisationService) auditWorkspace(ctx context.Context, instance *models.Instance) map[string]any {
+	workspace := "Personal"
+	if instance.OrganisationID != "" {
+		workspace = instance.OrganisationID
+		if organisation, err := s.orgRepo.GetByID(ctx, instance.OrganisationID); err == nil {
+			workspace = organisation.Name
+		}
+	}
+	return map[string]any{
+		"Name":      instance.Name,
+		"Workspace": workspace,
+	}
+}
+
+// auditOwner returns the owner of an instance for the audit log.
+// The owner's email is shown when their account still exists.
+func (s *organisationService) auditOwner(ctx context.Context, instance *models.Instance) map[string]any {
+	owner := instance.UserID
+	if user, err := s.userRepo.GetByID(ctx, instance.UserID); err == nil {
+		owner = user.Email
+	}
+	return map[string]any{
+		"Name":  instance.Name,
+		"Owner": owner,
+	}
+}
+
+// audit records a change to an instance.
+func (s *organisationService) audit(ctx context.Context, action models.AuditAction, instance *models.Instance, before, after any) error {
+	err := recordAudit(ctx, s.auditRepo, nil, models.AuditEvent{
+		InstanceID: instance.ID,
+		Action:     action,
+		EntityType: "instance",
+		EntityID:   instance.ID,
+	}, before, after)
+	if err != nil {
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+	return nil
+}
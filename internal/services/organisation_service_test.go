@@ -0,0 +1,221 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupOrganisationService(t *testing.T) (services.OrganisationService, services.CollaboratorService, services.InstanceService, services.UserService, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+
+	auditRepo := repositories.NewAuditRepository(dbc)
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	blockRepo := repositories.NewBlockRepository(dbc, blockStateRepo)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	clueRepo := repositories.NewClueRepository(dbc)
+	hintRepo := repositories.NewHintRepository(dbc)
+	instanceRepo := repositories.NewInstanceRepository(dbc)
+	instanceSettingsRepo := repositories.NewInstanceSettingsRepository(dbc)
+	locationRepo := repositories.NewLocationRepository(dbc)
+	markerRepo := repositories.NewMarkerRepository(dbc)
+	memberRepo := repositories.NewInstanceMemberRepository(dbc)
+	orgRepo := repositories.NewOrganisationRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+	userRepo := repositories.NewUserRepository(dbc)
+
+	locationService := services.NewLocationService(transactor, clueRepo, hintRepo, locationRepo, markerRepo, blockRepo, auditRepo)
+	teamService := services.NewTeamService(transactor, events.NewBroker(), teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	userService := services.NewUserService(transactor, userRepo, instanceRepo)
+	collaboratorService := services.NewCollaboratorService(&mockEmailService{}, instanceRepo, locationRepo, memberRepo, orgRepo, userRepo)
+	instanceService := services.NewInstanceService(
+		transactor,
+		locationService, userService, teamService, collaboratorService, instanceRepo, instanceSettingsRepo, auditRepo,
+		memberRepo, orgRepo,
+	)
+	organisationService := services.NewOrganisationService(auditRepo, instanceRepo, orgRepo, userRepo)
+
+	return organisationService, collaboratorService, instanceService, userService, cleanup
+}
+
+func TestOrganisationService(t *testing.T) {
+	svc, collaboratorService, instanceService, userService, cleanup := setupOrganisationService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	admin := createTestUser(t, userService, "admin@example.com")
+	teacher := createTestUser(t, userService, "teacher@example.com")
+	colleague := createTestUser(t, userService, "colleague@example.com")
+	stranger := createTestUser(t, userService, "stranger@example.com")
+
+	organisation, err := svc.Create(ctx, admin, " Example School ")
+	require.NoError(t, err)
+	assert.Equal(t, "Example School", organisation.Name)
+
+	_, err = svc.Create(ctx, admin, " ")
+	assert.ErrorIs(t, err, services.ErrInvalidArgument)
+
+	t.Run("Admins add existing users", func(t *testing.T) {
+		_, err := svc.AddMember(ctx, stranger, organisation.ID, teacher.Email, models.OrgRoleMember)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		_, err = svc.AddMember(ctx, admin, organisation.ID, "nobody@example.com", models.OrgRoleMember)
+		assert.ErrorIs(t, err, services.ErrOrganisationUserNotFound)
+
+		_, err = svc.AddMember(ctx, admin, organisation.ID, teacher.Email, models.OrganisationRole("owner"))
+		assert.ErrorIs(t, err, services.ErrInvalidArgument)
+
+		_, err = svc.AddMember(ctx, admin, organisation.ID, teacher.Email, models.OrgRoleMember)
+		require.NoError(t, err)
+		_, err = svc.AddMember(ctx, admin, organisation.ID, colleague.Email, models.OrgRoleMember)
+		require.NoError(t, err)
+
+		_, err = svc.AddMember(ctx, admin, organisation.ID, teacher.Email, models.OrgRoleAdmin)
+		assert.ErrorIs(t, err, services.ErrAlreadyOrganisationMember)
+
+		organisations, err := svc.FindForUser(ctx, teacher.ID)
+		require.NoError(t, err)
+		require.Len(t, organisations, 1)
+		assert.Equal(t, organisation.ID, organisations[0].ID)
+	})
+
+	t.Run("Only members can switch to the workspace", func(t *testing.T) {
+		err := svc.SwitchWorkspace(ctx, stranger, organisation.ID)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		err = svc.SwitchWorkspace(ctx, teacher, organisation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, organisation.ID, teacher.CurrentOrganisationID)
+	})
+
+	// Instances created in the workspace belong to the organisation
+	game, err := instanceService.CreateInstance(ctx, "Orientation", teacher)
+	require.NoError(t, err)
+	assert.Equal(t, organisation.ID, game.OrganisationID)
+
+	t.Run("Organisation roles apply to its instances", func(t *testing.T) {
+		role, err := collaboratorService.Role(ctx, admin, game.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleOwner, role)
+
+		role, err = collaboratorService.Role(ctx, colleague, game.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleViewer, role)
+
+		_, err = collaboratorService.Role(ctx, stranger, game.ID)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		// Switching to an organisation instance moves members into its workspace
+		_, err = instanceService.SwitchInstance(ctx, admin, game.ID)
+		require.NoError(t, err)
+		assert.Equal(t, organisation.ID, admin.CurrentOrganisationID)
+	})
+
+	t.Run("Only admins transfer instances to members", func(t *testing.T) {
+		err := svc.TransferInstance(ctx, colleague, game.ID, colleague.ID)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		err = svc.TransferInstance(ctx, admin, game.ID, stranger.ID)
+		assert.ErrorIs(t, err, services.ErrInvalidArgument)
+
+		err = svc.TransferInstance(ctx, admin, game.ID, colleague.ID)
+		require.NoError(t, err)
+
+		role, err := collaboratorService.Role(ctx, colleague, game.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleOwner, role)
+		role, err = collaboratorService.Role(ctx, teacher, game.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleViewer, role)
+	})
+
+	t.Run("Organisations keep an admin", func(t *testing.T) {
+		members, err := svc.FindMembers(ctx, organisation.ID)
+		require.NoError(t, err)
+		var adminMember, teacherMember models.OrganisationMember
+		for _, member := range members {
+			switch member.UserID {
+			case admin.ID:
+				adminMember = member
+			case teacher.ID:
+				teacherMember = member
+			}
+		}
+
+		err = svc.RemoveMember(ctx, admin, organisation.ID, adminMember.ID)
+		assert.ErrorIs(t, err, services.ErrLastOrganisationAdmin)
+		err = svc.UpdateMemberRole(ctx, admin, organisation.ID, adminMember.ID, models.OrgRoleMember)
+		assert.ErrorIs(t, err, services.ErrLastOrganisationAdmin)
+
+		err = svc.UpdateMemberRole(ctx, teacher, organisation.ID, teacherMember.ID, models.OrgRoleAdmin)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+		err = svc.UpdateMemberRole(ctx, admin, organisation.ID, teacherMember.ID, models.OrgRoleAdmin)
+		require.NoError(t, err)
+		err = svc.UpdateMemberRole(ctx, admin, organisation.ID, adminMember.ID, models.OrgRoleMember)
+		require.NoError(t, err)
+	})
+
+	t.Run("Removed members go back to their own workspace", func(t *testing.T) {
+		members, err := svc.FindMembers(ctx, organisation.ID)
+		require.NoError(t, err)
+		var colleagueMember models.OrganisationMember
+		for _, member := range members {
+			if member.UserID == colleague.ID {
+				colleagueMember = member
+			}
+		}
+		require.NoError(t, svc.SwitchWorkspace(ctx, colleague, organisation.ID))
+
+		err = svc.RemoveMember(ctx, admin, organisation.ID, colleagueMember.ID)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied, "only admins remove other members")
+		err = svc.RemoveMember(ctx, teacher, organisation.ID, colleagueMember.ID)
+		require.NoError(t, err)
+
+		removed, err := userService.GetUserByEmail(ctx, colleague.Email)
+		require.NoError(t, err)
+		assert.Empty(t, removed.CurrentOrganisationID)
+
+		// Their instances stay with the organisation to be handed over
+		instances, err := svc.FindInstances(ctx, organisation.ID)
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+		assert.Equal(t, colleague.ID, instances[0].UserID)
+		err = svc.TransferInstance(ctx, teacher, game.ID, teacher.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Owners move instances between workspaces", func(t *testing.T) {
+		require.NoError(t, svc.SwitchWorkspace(ctx, stranger, ""))
+		personal, err := instanceService.CreateInstance(ctx, "Personal game", stranger)
+		require.NoError(t, err)
+		assert.Empty(t, personal.OrganisationID)
+
+		err = svc.MoveInstance(ctx, stranger, personal.ID, organisation.ID)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied, "only members can move instances in")
+		err = svc.MoveInstance(ctx, teacher, personal.ID, organisation.ID)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied, "only owners can move instances")
+
+		err = svc.MoveInstance(ctx, teacher, game.ID, "")
+		require.NoError(t, err)
+		instances, err := svc.FindInstances(ctx, organisation.ID)
+		require.NoError(t, err)
+		assert.Empty(t, instances)
+
+		usage, err := svc.Usage(ctx, organisation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, usage.Members)
+		assert.Equal(t, 0, usage.Instances)
+	})
+}
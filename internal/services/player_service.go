@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/nathanhollows/Rapua/v3/internal/contextkeys"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+)
+
+type PlayerService interface {
+	// Join adds a named player to a team, or returns the player if they have joined before
+	Join(ctx context.Context, team *models.Team, name, email string) (*models.Player, error)
+
+	// GetByID returns a player by their ID
+	GetByID(ctx context.Context, playerID string) (*models.Player, error)
+	// FindByTeamCode returns the players in a team, in the order they joined
+	FindByTeamCode(ctx context.Context, teamCode string) ([]models.Player, error)
+	// Contributions returns what each player in a team has done
+	Contributions(ctx context.Context, teamCode string) ([]models.PlayerContribution, error)
+}
+
+type playerService struct {
+	playerRepo repositories.PlayerRepository
+}
+
+// NewPlayerService creates a new PlayerService.
+func NewPlayerService(playerRepo repositories.PlayerRepository) PlayerService {
+	return &playerService{
+		playerRepo: playerRepo,
+	}
+}
+
+// WithPlayer returns a context that records changes as made by the player.
+func WithPlayer(ctx context.Context, playerID string) context.Context {
+	return context.WithValue(ctx, contextkeys.PlayerKey, playerID)
+}
+
+// PlayerIDFromContext returns the ID of the player making changes.
+// Anonymous players have no ID.
+func PlayerIDFromContext(ctx context.Context) string {
+	playerID, _ := ctx.Value(contextkeys.PlayerKey).(string)
+	return playerID
+}
+
+// Join adds a named player to a team.
+// Players are optional, so a blank name and email returns no player.
+// A player who has joined the team before, matched by email or else by name,
+// rejoins as the same player so their contributions are kept.
+func (s *playerService) Join(ctx context.Context, team *models.Team, name, email string) (*models.Player, error) {
+	if team == nil {
+		return nil, NewValidationError("team")
+	}
+	name = strings.TrimSpace(name)
+	email = strings.ToLower(strings.TrimSpace(email))
+	if name == "" && email == "" {
+		return nil, nil
+	}
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return nil, fmt.Errorf("%w: email is not valid", ErrInvalidArgument)
+		}
+	}
+
+	players, err := s.playerRepo.FindByTeamCode(ctx, team.Code)
+	if err != nil {
+		return nil, fmt.Errorf("finding players: %w", err)
+	}
+
+	var existing *models.Player
+	for i := range players {
+		if email != "" && strings.EqualFold(players[i].Email, email) {
+			existing = &players[i]
+			break
+		}
+	}
+	if existing == nil && name != "" {
+		for i := range players {
+			if strings.EqualFold(players[i].Name, name) {
+				existing = &players[i]
+				break
+			}
+		}
+	}
+
+	if existing != nil {
+		if name != "" {
+			existing.Name = name
+		}
+		if existing.Email == "" {
+			existing.Email = email
+		}
+		existing.LastSeenAt = time.Now().UTC()
+		err = s.playerRepo.Update(ctx, existing)
+		if err != nil {
+			return nil, fmt.Errorf("updating player: %w", err)
+		}
+		return existing, nil
+	}
+
+	if name == "" {
+		return nil, NewValidationError("name")
+	}
+	player := &models.Player{
+		InstanceID: team.InstanceID,
+		TeamCode:   team.Code,
+		Name:       name,
+		Email:      email,
+	}
+	err = s.playerRepo.Create(ctx, player)
+	if err != nil {
+		return nil, fmt.Errorf("creating player: %w", err)
+	}
+	return player, nil
+}
+
+// GetByID returns a player by their ID.
+func (s *playerService) GetByID(ctx context.Context, playerID string) (*models.Player, error) {
+	if playerID == "" {
+		return nil, NewValidationError("playerID")
+	}
+	player, err := s.playerRepo.GetByID(ctx, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("finding player: %w", err)
+	}
+	return player, nil
+}
+
+// FindByTeamCode returns the players in a team, in the order they joined.
+func (s *playerService) FindByTeamCode(ctx context.Context, teamCode string) ([]models.Player, error) {
+	if teamCode == "" {
+		return nil, NewValidationError("teamCode")
+	}
+	return s.playerRepo.FindByTeamCode(ctx, teamCode)
+}
+
+// Contributions returns the check-ins, submissions and points made by each
+// player in a team.
+func (s *playerService) Contributions(ctx context.Context, teamCode string) ([]models.PlayerContribution, error) {
+	if teamCode == "" {
+		return nil, NewValidationError("teamCode")
+	}
+	contributions, err := s.playerRepo.FindContributions(ctx, teamCode)
+	if err != nil {
+		return nil, fmt.Errorf("finding contributions: %w", err)
+	}
+	return contributions, nil
+}
@@ -0,0 +1,155 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func setupPlayerService(t *testing.T) (services.PlayerService, services.CheckInService, services.TeamService, *bun.DB, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+
+	auditRepo := repositories.NewAuditRepository(dbc)
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	locationRepo := repositories.NewLocationRepository(dbc)
+	playerRepo := repositories.NewPlayerRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+
+	teamService := services.NewTeamService(transactor, events.NewBroker(), teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	checkInService := services.NewCheckInService(checkInRepo, locationRepo, teamRepo)
+	playerService := services.NewPlayerService(playerRepo)
+
+	return playerService, checkInService, teamService, dbc, cleanup
+}
+
+func TestPlayerService_Join(t *testing.T) {
+	svc, _, teamService, _, cleanup := setupPlayerService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	teams, err := teamService.AddTeams(ctx, gofakeit.UUID(), 2)
+	require.NoError(t, err)
+	team := &teams[0]
+
+	t.Run("Anonymous players have no identity", func(t *testing.T) {
+		player, err := svc.Join(ctx, team, " ", "")
+		require.NoError(t, err)
+		assert.Nil(t, player)
+	})
+
+	t.Run("Invalid details are rejected", func(t *testing.T) {
+		_, err := svc.Join(ctx, team, "Ana", "not an email")
+		assert.ErrorIs(t, err, services.ErrInvalidArgument)
+
+		_, err = svc.Join(ctx, team, "", "new@example.com")
+		assert.ErrorIs(t, err, services.ErrInvalidArgument, "new players need a name")
+
+		_, err = svc.Join(ctx, nil, "Ana", "")
+		assert.ErrorIs(t, err, services.ErrInvalidArgument)
+	})
+
+	ana, err := svc.Join(ctx, team, "Ana", "Ana@Example.com")
+	require.NoError(t, err)
+	require.NotNil(t, ana)
+	assert.Equal(t, "ana@example.com", ana.Email)
+	assert.Equal(t, team.InstanceID, ana.InstanceID)
+
+	tests := []struct {
+		name  string
+		pName string
+		email string
+	}{
+		{"Rejoin by email", "", "ANA@example.com"},
+		{"Rejoin by name", "ana", ""},
+		{"Rejoin by email with a new name", "Ana B", "ana@example.com"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			player, err := svc.Join(ctx, team, tc.pName, tc.email)
+			require.NoError(t, err)
+			assert.Equal(t, ana.ID, player.ID)
+		})
+	}
+
+	t.Run("Players are kept per team", func(t *testing.T) {
+		other, err := svc.Join(ctx, &teams[1], "Ana", "ana@example.com")
+		require.NoError(t, err)
+		assert.NotEqual(t, ana.ID, other.ID)
+
+		players, err := svc.FindByTeamCode(ctx, team.Code)
+		require.NoError(t, err)
+		require.Len(t, players, 1)
+		assert.Equal(t, "Ana B", players[0].Name)
+	})
+
+	t.Run("Deleting the team removes its players", func(t *testing.T) {
+		require.NoError(t, teamService.Delete(ctx, team.InstanceID, team.Code))
+		players, err := svc.FindByTeamCode(ctx, team.Code)
+		require.NoError(t, err)
+		assert.Empty(t, players)
+	})
+}
+
+func TestPlayerService_Contributions(t *testing.T) {
+	svc, checkInService, teamService, dbc, cleanup := setupPlayerService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	teams, err := teamService.AddTeams(ctx, gofakeit.UUID(), 1)
+	require.NoError(t, err)
+	team := teams[0]
+
+	ana, err := svc.Join(ctx, &team, "Ana", "")
+	require.NoError(t, err)
+	ben, err := svc.Join(ctx, &team, "Ben", "")
+	require.NoError(t, err)
+
+	checkIn := func(ctx context.Context, points int) {
+		location := models.Location{ID: gofakeit.UUID(), InstanceID: team.InstanceID, Points: points}
+		_, err := checkInService.CheckIn(ctx, team, location, false, false)
+		require.NoError(t, err)
+	}
+	checkIn(services.WithPlayer(ctx, ana.ID), 10)
+	checkIn(services.WithPlayer(ctx, ana.ID), 5)
+	checkIn(services.WithPlayer(ctx, ben.ID), 20)
+	checkIn(ctx, 50)
+
+	blockID := gofakeit.UUID()
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	state, err := blockStateRepo.NewBlockState(ctx, blockID, team.Code)
+	require.NoError(t, err)
+	state.SetComplete(true)
+	state.SetPointsAwarded(3)
+	_, err = blockStateRepo.Create(ctx, state)
+	require.NoError(t, err)
+	require.NoError(t, blockStateRepo.SetPlayer(ctx, blockID, team.Code, ben.ID))
+
+	contributions, err := svc.Contributions(ctx, team.Code)
+	require.NoError(t, err)
+	require.Len(t, contributions, 2)
+
+	assert.Equal(t, "Ana", contributions[0].Player.Name)
+	assert.Equal(t, 2, contributions[0].CheckIns)
+	assert.Equal(t, 0, contributions[0].Submissions)
+	assert.Equal(t, 15, contributions[0].Points)
+
+	assert.Equal(t, "Ben", contributions[1].Player.Name)
+	assert.Equal(t, 1, contributions[1].CheckIns)
+	assert.Equal(t, 1, contributions[1].Submissions)
+	assert.Equal(t, 23, contributions[1].Points)
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nathanhollows/Rapua/v3/blocks"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrSubmissionNotPending = errors.New("submission is not awaiting review")
+	ErrRejectionComment     = errors.New("a comment is required when rejecting a submission")
+)
+
+type ReviewService interface {
+	// FindPending returns all submissions awaiting review for an instance
+	FindPending(ctx context.Context, instanceID string) ([]ReviewSubmission, error)
+	// Approve completes a submission and awards the points it earned to the team
+	Approve(ctx context.Context, instanceID, blockID, teamCode, comment string) error
+	// Reject returns a submission to the team so they can try again
+	Reject(ctx context.Context, instanceID, blockID, teamCode, comment string) error
+}
+
+// ReviewSubmission is a block submission awaiting review.
+type ReviewSubmission struct {
+	Block blocks.Block
+	State blocks.PlayerState
+}
+
+type reviewService struct {
+	transactor          db.Transactor
+	broker              *events.Broker
+	auditRepo           repositories.AuditRepository
+	blockStateRepo      repositories.BlockStateRepository
+	checkInRepo         repositories.CheckInRepository
+	pointsRepo          repositories.PointsRepository
+	teamRepo            repositories.TeamRepository
+	blockService        BlockService
+	bonusService        BonusService
+	notificationService NotificationService
+}
+
+func NewReviewService(
+	transactor db.Transactor,
+	broker *events.Broker,
+	auditRepo repositories.AuditRepository,
+	blockStateRepo repositories.BlockStateRepository,
+	checkInRepo repositories.CheckInRepository,
+	pointsRepo repositories.PointsRepository,
+	teamRepo repositories.TeamRepository,
+	blockService BlockService,
+	bonusService BonusService,
+	notificationService NotificationService,
+) ReviewService {
+	return &reviewService{
+		transactor:          transactor,
+		broker:              broker,
+		auditRepo:           auditRepo,
+		blockStateRepo:      blockStateRepo,
+		checkInRepo:         checkInRepo,
+		pointsRepo:          pointsRepo,
+		teamRepo:            teamRepo,
+		blockService:        blockService,
+		bonusService:        bonusService,
+		notificationService: notificationService,
+	}
+}
+
+// FindPending returns all submissions awaiting review for an instance.
+func (s *reviewService) FindPending(ctx context.Context, instanceID string) ([]ReviewSubmission, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+
+	states, err := s.blockStateRepo.FindPendingReviewByInstanceID(ctx, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("finding pending reviews: %w", err)
+	}
+
+	submissions := make([]ReviewSubmission, 0, len(states))
+	for _, state := range states {
+		block, err := s.blockService.GetByBlockID(ctx, state.GetBlockID())
+		if err != nil {
+			return nil, fmt.Errorf("getting block %s: %w", state.GetBlockID(), err)
+		}
+		submissions = append(submissions, ReviewSubmission{Block: block, State: state})
+	}
+	return submissions, nil
+}
+
+// Approve completes a submission and awards the points it earned to the team.
+// The state, points and check in are saved together, and only once if
+// several facilitators approve the same submission.
+func (s *reviewService) Approve(ctx context.Context, instanceID, blockID, teamCode, comment string) error {
+	team, block, state, err := s.getPending(ctx, instanceID, blockID, teamCode)
+	if err != nil {
+		return err
+	}
+
+	// The check in is complete if this was the last block holding it up
+	found, states, err := s.blockService.FindByLocationIDAndTeamCodeWithState(ctx, block.GetLocationID(), team.Code)
+	if err != nil {
+		return fmt.Errorf("finding blocks for location: %w", err)
+	}
+	unfinishedCheckIn := false
+	for _, other := range found {
+		if other.GetID() == blockID || !other.RequiresValidation() {
+			continue
+		}
+		if states[other.GetID()] == nil || !states[other.GetID()].IsComplete() {
+			unfinishedCheckIn = true
+			break
+		}
+	}
+
+	// The points were worked out when the team submitted, so partial
+	// credit and attempt decay carry through the review
+	state.SetComplete(true)
+	state.SetReviewStatus(blocks.ReviewStatusApproved)
+	state.SetReviewComment(comment)
+	transaction := models.PointsTransaction{
+		InstanceID: team.InstanceID,
+		TeamCode:   team.Code,
+		Amount:     state.GetPointsAwarded(),
+		Reason:     fmt.Sprint("Completed block ", block.GetName()),
+		Source:     models.BlockSource,
+		SourceID:   block.GetID(),
+	}
+	err = s.resolve(ctx, team, state, func(tx *bun.Tx) error {
+		if transaction.Amount != 0 {
+			err := s.pointsRepo.Create(ctx, tx, &transaction)
+			if err != nil {
+				return fmt.Errorf("recording points: %w", err)
+			}
+			err = s.teamRepo.ReconcilePoints(ctx, tx, team.InstanceID, []string{team.Code})
+			if err != nil {
+				return fmt.Errorf("reconciling points: %w", err)
+			}
+		}
+		if unfinishedCheckIn {
+			return nil
+		}
+		return s.checkInRepo.CompleteBlocks(ctx, tx, team.Code, block.GetLocationID())
+	})
+	if err != nil {
+		return err
+	}
+	if transaction.Amount != 0 {
+		publishPoints(s.broker, transaction)
+	}
+
+	message := fmt.Sprintf("Your %s submission was approved!", strings.ToLower(block.GetName()))
+	if comment != "" {
+		message += " " + comment
+	}
+	_, err = s.notificationService.SendNotification(ctx, team.Code, message)
+	if err != nil {
+		return fmt.Errorf("sending notification: %w", err)
+	}
+
+	if unfinishedCheckIn {
+		return nil
+	}
+	err = s.bonusService.BlocksCompleted(ctx, team, block.GetLocationID())
+	if err != nil {
+		return fmt.Errorf("awarding bonus points: %w", err)
+	}
+	return nil
+}
+
+// Reject returns a submission to the team so they can try again.
+// The comment is sent to the team as a notification.
+func (s *reviewService) Reject(ctx context.Context, instanceID, blockID, teamCode, comment string) error {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return ErrRejectionComment
+	}
+
+	team, block, state, err := s.getPending(ctx, instanceID, blockID, teamCode)
+	if err != nil {
+		return err
+	}
+
+	state.SetPointsAwarded(0)
+	state.SetReviewStatus(blocks.ReviewStatusRejected)
+	state.SetReviewComment(comment)
+	err = s.resolve(ctx, team, state, nil)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Your %s submission needs another try: %s", strings.ToLower(block.GetName()), comment)
+	_, err = s.notificationService.SendNotification(ctx, team.Code, message)
+	if err != nil {
+		return fmt.Errorf("sending notification: %w", err)
+	}
+	return nil
+}
+
+// resolve saves a review and any changes that come with it in a single
+// transaction. ErrSubmissionNotPending is returned if another reviewer
+// resolved the submission first.
+func (s *reviewService) resolve(ctx context.Context, team *models.Team, state blocks.PlayerState, change func(tx *bun.Tx) error) error {
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	saved, err := s.blockStateRepo.SaveReview(ctx, tx, state)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("saving block state: %w", err)
+	}
+	if !saved {
+		tx.Rollback()
+		return ErrSubmissionNotPending
+	}
+
+	if change != nil {
+		if err := change(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	err = recordAudit(ctx, s.auditRepo, tx, models.AuditEvent{
+		InstanceID: team.InstanceID,
+		Action:     models.AuditBlockReviewed,
+		EntityType: "block",
+		EntityID:   state.GetBlockID(),
+		TeamCode:   team.Code,
+		Reason:     state.GetReviewComment(),
+	}, nil, map[string]any{
+		"Complete":      state.IsComplete(),
+		"PointsAwarded": state.GetPointsAwarded(),
+		"ReviewStatus":  state.GetReviewStatus(),
+	})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	s.broker.Publish(events.Event{
+		Type:       events.BlockStateUpdated,
+		InstanceID: team.InstanceID,
+		TeamCode:   team.Code,
+		Payload:    state,
+	})
+	return nil
+}
+
+// getPending fetches a pending submission and checks it belongs to the instance.
+func (s *reviewService) getPending(ctx context.Context, instanceID, blockID, teamCode string) (*models.Team, blocks.Block, blocks.PlayerState, error) {
+	if instanceID == "" {
+		return nil, nil, nil, NewValidationError("instanceID")
+	}
+
+	team, err := s.teamRepo.GetByCode(ctx, teamCode)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %w", ErrTeamNotFound, err)
+	}
+	if team.InstanceID != instanceID {
+		return nil, nil, nil, ErrPermissionDenied
+	}
+
+	block, state, err := s.blockService.GetBlockWithStateByBlockIDAndTeamCode(ctx, blockID, team.Code)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("getting block with state: %w", err)
+	}
+	if state.GetReviewStatus() != blocks.ReviewStatusPending {
+		return nil, nil, nil, ErrSubmissionNotPending
+	}
+	return team, block, state, nil
+}
@@ -0,0 +1,209 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/blocks"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func setupReviewService(t *testing.T) (services.ReviewService, services.BlockService, services.TeamService, *events.Broker, *bun.DB, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	blockRepo := repositories.NewBlockRepository(dbc, blockStateRepo)
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	locationRepo := repositories.NewLocationRepository(dbc)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	auditRepo := repositories.NewAuditRepository(dbc)
+	notificationRepo := repositories.NewNotificationRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+	hintRepo := repositories.NewHintRepository(dbc)
+
+	broker := events.NewBroker()
+	notificationService := services.NewNotificationService(broker, notificationRepo, teamRepo)
+	teamService := services.NewTeamService(transactor, broker, teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	bonusService := services.NewBonusService(transactor, broker, bonusRepo, checkInRepo, hintRepo, locationRepo, pointsRepo, teamRepo, teamService)
+	blockService := services.NewBlockService(transactor, broker, auditRepo, blockRepo, blockStateRepo, checkInRepo, pointsRepo, teamRepo, bonusService)
+	reviewService := services.NewReviewService(transactor, broker, auditRepo, blockStateRepo, checkInRepo, pointsRepo, teamRepo, blockService, bonusService, notificationService)
+
+	return reviewService, blockService, teamService, broker, dbc, cleanup
+}
+
+// createPendingSubmission creates a team with a submission awaiting review.
+func createPendingSubmission(t *testing.T, dbc *bun.DB, blockService services.BlockService, teamService services.TeamService, instanceID string) (models.Team, blocks.Block) {
+	t.Helper()
+	ctx := context.Background()
+
+	teams, err := teamService.AddTeams(ctx, instanceID, 1)
+	require.NoError(t, err)
+	team := teams[0]
+
+	location := models.Location{ID: gofakeit.UUID(), InstanceID: instanceID}
+	_, err = repositories.NewCheckInRepository(dbc).LogCheckIn(ctx, team, location, false, true)
+	require.NoError(t, err)
+
+	block, err := blockService.NewBlock(ctx, location.ID, "answer")
+	require.NoError(t, err)
+	block, err = blockService.UpdateBlock(ctx, block, map[string][]string{
+		"points": {"10"},
+		"prompt": {"Describe the statue"},
+		"answer": {"statue"},
+	})
+	require.NoError(t, err)
+	block, err = blockService.SetRequiresReview(ctx, block, true)
+	require.NoError(t, err)
+
+	state, err := blockService.NewBlockState(ctx, block.GetID(), team.Code)
+	require.NoError(t, err)
+	state.SetPointsAwarded(block.GetPoints())
+	state.SetReviewStatus(blocks.ReviewStatusPending)
+	_, err = blockService.UpdateState(ctx, state)
+	require.NoError(t, err)
+
+	return team, block
+}
+
+func TestReviewService_FindPending(t *testing.T) {
+	svc, blockService, teamService, _, dbc, cleanup := setupReviewService(t)
+	defer cleanup()
+
+	instanceID := gofakeit.UUID()
+	team, block := createPendingSubmission(t, dbc, blockService, teamService, instanceID)
+
+	submissions, err := svc.FindPending(context.Background(), instanceID)
+	require.NoError(t, err)
+	require.Len(t, submissions, 1)
+	assert.Equal(t, block.GetID(), submissions[0].Block.GetID())
+	assert.Equal(t, team.Code, submissions[0].State.GetPlayerID())
+
+	// Submissions from other instances are not included
+	submissions, err = svc.FindPending(context.Background(), gofakeit.UUID())
+	require.NoError(t, err)
+	assert.Empty(t, submissions)
+
+	_, err = svc.FindPending(context.Background(), "")
+	assert.Error(t, err)
+}
+
+func TestReviewService_Approve(t *testing.T) {
+	svc, blockService, teamService, broker, dbc, cleanup := setupReviewService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	team, block := createPendingSubmission(t, dbc, blockService, teamService, instanceID)
+
+	// A different instance cannot approve the submission
+	err := svc.Approve(ctx, gofakeit.UUID(), block.GetID(), team.Code, "")
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+	sub := broker.Subscribe(instanceID, team.Code)
+	defer broker.Unsubscribe(sub)
+
+	err = svc.Approve(ctx, instanceID, block.GetID(), team.Code, "Great work")
+	require.NoError(t, err)
+
+	// The team's open pages are told about the points and the leaderboard
+	var published []events.EventType
+	for len(sub.Events()) > 0 {
+		published = append(published, (<-sub.Events()).Type)
+	}
+	assert.Contains(t, published, events.PointsChanged)
+	assert.Contains(t, published, events.LeaderboardUpdated)
+
+	_, state, err := blockService.GetBlockWithStateByBlockIDAndTeamCode(ctx, block.GetID(), team.Code)
+	require.NoError(t, err)
+	assert.True(t, state.IsComplete())
+	assert.Equal(t, 10, state.GetPointsAwarded())
+	assert.Equal(t, blocks.ReviewStatusApproved, state.GetReviewStatus())
+
+	updated, err := teamService.FindTeamByCode(ctx, team.Code)
+	require.NoError(t, err)
+	assert.Equal(t, 10, updated.Points)
+
+	notifications, err := repositories.NewNotificationRepository(dbc).FindByTeamCode(ctx, team.Code)
+	require.NoError(t, err)
+	assert.Len(t, notifications, 1)
+
+	// The submission can only be approved once
+	err = svc.Approve(ctx, instanceID, block.GetID(), team.Code, "")
+	assert.ErrorIs(t, err, services.ErrSubmissionNotPending)
+}
+
+func TestReviewService_Approve_KeepsEarnedPoints(t *testing.T) {
+	svc, blockService, teamService, _, dbc, cleanup := setupReviewService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	team, block := createPendingSubmission(t, dbc, blockService, teamService, instanceID)
+
+	// Partial credit and attempt decay are worked out on submission
+	_, state, err := blockService.GetBlockWithStateByBlockIDAndTeamCode(ctx, block.GetID(), team.Code)
+	require.NoError(t, err)
+	state.SetPointsAwarded(4)
+	_, err = blockService.UpdateState(ctx, state)
+	require.NoError(t, err)
+
+	err = svc.Approve(ctx, instanceID, block.GetID(), team.Code, "")
+	require.NoError(t, err)
+
+	updated, err := teamService.FindTeamByCode(ctx, team.Code)
+	require.NoError(t, err)
+	assert.Equal(t, 4, updated.Points)
+}
+
+func TestReviewService_Reject(t *testing.T) {
+	svc, blockService, teamService, broker, dbc, cleanup := setupReviewService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	team, block := createPendingSubmission(t, dbc, blockService, teamService, instanceID)
+
+	// A comment is required
+	err := svc.Reject(ctx, instanceID, block.GetID(), team.Code, " ")
+	assert.ErrorIs(t, err, services.ErrRejectionComment)
+
+	sub := broker.Subscribe(instanceID, team.Code)
+	defer broker.Unsubscribe(sub)
+
+	err = svc.Reject(ctx, instanceID, block.GetID(), team.Code, "We can't see the statue")
+	require.NoError(t, err)
+
+	// The team's open pages are told about the state change and the comment
+	var published []events.EventType
+	for len(sub.Events()) > 0 {
+		published = append(published, (<-sub.Events()).Type)
+	}
+	assert.Equal(t, []events.EventType{events.BlockStateUpdated, events.NotificationSent}, published)
+
+	_, state, err := blockService.GetBlockWithStateByBlockIDAndTeamCode(ctx, block.GetID(), team.Code)
+	require.NoError(t, err)
+	assert.False(t, state.IsComplete())
+	assert.Equal(t, blocks.ReviewStatusRejected, state.GetReviewStatus())
+	assert.Equal(t, "We can't see the statue", state.GetReviewComment())
+
+	notifications, err := repositories.NewNotificationRepository(dbc).FindByTeamCode(ctx, team.Code)
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	assert.Contains(t, notifications[0].Content, "We can't see the statue")
+
+	updated, err := teamService.FindTeamByCode(ctx, team.Code)
+	require.NoError(t, err)
+	assert.Equal(t, 0, updated.Points)
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+)
+
+type RouteService interface {
+	// FindByInstance returns all routes for an instance
+	FindByInstance(ctx context.Context, instanceID string) ([]models.Route, error)
+
+	// CreateRoute creates a route following the default location order
+	CreateRoute(ctx context.Context, instanceID string, name string) (*models.Route, error)
+	// UpdateRoute renames and reorders a route
+	UpdateRoute(ctx context.Context, instanceID string, routeID string, name string, markerIDs []string) (*models.Route, error)
+	// GenerateRoutes replaces the routes for an instance with rotated copies
+	// of the default order and spreads the teams evenly between them
+	GenerateRoutes(ctx context.Context, instanceID string, count int) ([]models.Route, error)
+
+	// AssignRoute assigns a team to a route, or to the default order when routeID is empty
+	AssignRoute(ctx context.Context, instanceID string, teamCode string, routeID string) error
+	// BalanceTeams assigns the given teams without a route to the least used routes
+	BalanceTeams(ctx context.Context, instanceID string, teams []models.Team) error
+
+	// DeleteRoute removes a route and returns its teams to the default order
+	DeleteRoute(ctx context.Context, instanceID string, routeID string) error
+}
+
+type routeService struct {
+	transactor   db.Transactor
+	routeRepo    repositories.RouteRepository
+	locationRepo repositories.LocationRepository
+	teamRepo     repositories.TeamRepository
+}
+
+// NewRouteService creates a new RouteService.
+func NewRouteService(transactor db.Transactor,
+	routeRepo repositories.RouteRepository,
+	locationRepo repositories.LocationRepository,
+	teamRepo repositories.TeamRepository,
+) RouteService {
+	return &routeService{
+		transactor:   transactor,
+		routeRepo:    routeRepo,
+		locationRepo: locationRepo,
+		teamRepo:     teamRepo,
+	}
+}
+
+// FindByInstance returns all routes for an instance.
+func (s *routeService) FindByInstance(ctx context.Context, instanceID string) ([]models.Route, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+	return s.routeRepo.FindByInstance(ctx, instanceID)
+}
+
+// defaultOrder returns the marker codes for an instance in the default order.
+func (s *routeService) defaultOrder(ctx context.Context, instanceID string) ([]string, error) {
+	locations, err := s.locationRepo.FindByInstance(ctx, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("finding locations: %w", err)
+	}
+	slices.SortStableFunc(locations, func(a, b models.Location) int {
+		return a.Order - b.Order
+	})
+
+	markerIDs := make([]string, len(locations))
+	for i, location := range locations {
+		markerIDs[i] = location.MarkerID
+	}
+	return markerIDs, nil
+}
+
+// getRoute returns a route, ensuring it belongs to the instance.
+func (s *routeService) getRoute(ctx context.Context, instanceID string, routeID string) (*models.Route, error) {
+	route, err := s.routeRepo.GetByID(ctx, routeID)
+	if err != nil {
+		return nil, err
+	}
+	if route.InstanceID != instanceID {
+		return nil, ErrPermissionDenied
+	}
+	return route, nil
+}
+
+// CreateRoute creates a route following the default location order.
+func (s *routeService) CreateRoute(ctx context.Context, instanceID string, name string) (*models.Route, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, NewValidationError("name")
+	}
+
+	markerIDs, err := s.defaultOrder(ctx, instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	route := &models.Route{
+		InstanceID: instanceID,
+		Name:       name,
+		Locations:  markerIDs,
+	}
+	err = s.routeRepo.Save(ctx, route)
+	if err != nil {
+		return nil, fmt.Errorf("saving route: %w", err)
+	}
+	return route, nil
+}
+
+// UpdateRoute renames and reorders a route.
+// Marker codes that do not belong to the instance are dropped.
+func (s *routeService) UpdateRoute(ctx context.Context, instanceID string, routeID string, name string, markerIDs []string) (*models.Route, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+	if routeID == "" {
+		return nil, NewValidationError("routeID")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, NewValidationError("name")
+	}
+
+	route, err := s.getRoute(ctx, instanceID, routeID)
+	if err != nil {
+		return nil, fmt.Errorf("finding route: %w", err)
+	}
+
+	known, err := s.defaultOrder(ctx, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	order := models.StrArray{}
+	for _, markerID := range markerIDs {
+		if slices.Contains(known, markerID) && !slices.Contains(order, markerID) {
+			order = append(order, markerID)
+		}
+	}
+
+	route.Name = name
+	route.Locations = order
+	err = s.routeRepo.Update(ctx, route)
+	if err != nil {
+		return nil, fmt.Errorf("updating route: %w", err)
+	}
+	return route, nil
+}
+
+// GenerateRoutes replaces the routes for an instance with rotated copies of
+// the default order and spreads the teams evenly between them.
+// Each route starts an equal distance apart in the default order, so teams
+// moving at the same pace are never at the same location as a team on
+// another route. The number of routes is capped at the number of locations.
+func (s *routeService) GenerateRoutes(ctx context.Context, instanceID string, count int) ([]models.Route, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("%w: count must be at least 1", ErrInvalidArgument)
+	}
+
+	markerIDs, err := s.defaultOrder(ctx, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	if len(markerIDs) == 0 {
+		return nil, fmt.Errorf("%w: routes require at least one location", ErrInvalidArgument)
+	}
+	count = min(count, len(markerIDs))
+
+	teams, err := s.teamRepo.FindAll(ctx, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("finding teams: %w", err)
+	}
+
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	err = s.routeRepo.DeleteByInstanceID(ctx, tx, instanceID)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("deleting routes: %w", err)
+	}
+
+	routes := make([]models.Route, count)
+	for i := range routes {
+		offset := i * len(markerIDs) / count
+		routes[i] = models.Route{
+			InstanceID: instanceID,
+			Name:       fmt.Sprintf("Route %d", i+1),
+			Locations:  append(slices.Clone(markerIDs[offset:]), markerIDs[:offset]...),
+		}
+	}
+	err = s.routeRepo.InsertBatch(ctx, tx, routes)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("saving routes: %w", err)
+	}
+
+	// Deal the teams out like cards so each route has an equal share
+	assignments := make([][]string, count)
+	for i, team := range teams {
+		assignments[i%count] = append(assignments[i%count], team.Code)
+	}
+	for i, teamCodes := range assignments {
+		err = s.routeRepo.AssignTeams(ctx, tx, instanceID, routes[i].ID, teamCodes)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("assigning teams: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+	return routes, nil
+}
+
+// AssignRoute assigns a team to a route, or to the default order when routeID is empty.
+func (s *routeService) AssignRoute(ctx context.Context, instanceID string, teamCode string, routeID string) error {
+	if instanceID == "" {
+		return NewValidationError("instanceID")
+	}
+	if teamCode == "" {
+		return NewValidationError("teamCode")
+	}
+	if routeID != "" {
+		_, err := s.getRoute(ctx, instanceID, routeID)
+		if err != nil {
+			return fmt.Errorf("finding route: %w", err)
+		}
+	}
+
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	err = s.routeRepo.AssignTeams(ctx, tx, instanceID, routeID, []string{teamCode})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("assigning team: %w", err)
+	}
+	return tx.Commit()
+}
+
+// BalanceTeams assigns the given teams without a route to the least used
+// routes, updating the teams in place. Teams are left on the default order if
+// the instance has no routes.
+func (s *routeService) BalanceTeams(ctx context.Context, instanceID string, teams []models.Team) error {
+	if instanceID == "" {
+		return NewValidationError("instanceID")
+	}
+
+	routes, err := s.routeRepo.FindByInstance(ctx, instanceID)
+	if err != nil {
+		return fmt.Errorf("finding routes: %w", err)
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+
+	existing, err := s.teamRepo.FindAll(ctx, instanceID)
+	if err != nil {
+		return fmt.Errorf("finding teams: %w", err)
+	}
+	usage := make(map[string]int, len(routes))
+	for _, team := range existing {
+		if team.RouteID != "" {
+			usage[team.RouteID]++
+		}
+	}
+
+	assignments := make(map[string][]string, len(routes))
+	for i := range teams {
+		if teams[i].RouteID != "" {
+			continue
+		}
+		least := routes[0].ID
+		for _, route := range routes[1:] {
+			if usage[route.ID] < usage[least] {
+				least = route.ID
+			}
+		}
+		usage[least]++
+		assignments[least] = append(assignments[least], teams[i].Code)
+		teams[i].RouteID = least
+	}
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	for routeID, teamCodes := range assignments {
+		err = s.routeRepo.AssignTeams(ctx, tx, instanceID, routeID, teamCodes)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("assigning teams: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteRoute removes a route and returns its teams to the default order.
+func (s *routeService) DeleteRoute(ctx context.Context, instanceID string, routeID string) error {
+	if instanceID == "" {
+		return NewValidationError("instanceID")
+	}
+	if routeID == "" {
+		return NewValidationError("routeID")
+	}
+
+	_, err := s.getRoute(ctx, instanceID, routeID)
+	if err != nil {
+		return fmt.Errorf("finding route: %w", err)
+	}
+
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	err = s.routeRepo.Delete(ctx, tx, routeID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("deleting route: %w", err)
+	}
+	return tx.Commit()
+}
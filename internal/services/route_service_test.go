@@ -0,0 +1,185 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRouteService(t *testing.T) (services.RouteService, services.TeamService, repositories.LocationRepository, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	locationRepo := repositories.NewLocationRepository(dbc)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	auditRepo := repositories.NewAuditRepository(dbc)
+	routeRepo := repositories.NewRouteRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+
+	teamService := services.NewTeamService(transactor, events.NewBroker(), teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	routeService := services.NewRouteService(transactor, routeRepo, locationRepo, teamRepo)
+
+	return routeService, teamService, locationRepo, cleanup
+}
+
+// createRouteLocations creates locations in order and returns their marker codes.
+func createRouteLocations(t *testing.T, repo repositories.LocationRepository, instanceID string, count int) []string {
+	t.Helper()
+	markerIDs := make([]string, count)
+	// Create in reverse to check routes follow the location order
+	for i := count - 1; i >= 0; i-- {
+		markerIDs[i] = fmt.Sprintf("M%d%s", i, gofakeit.LetterN(4))
+		err := repo.Create(context.Background(), &models.Location{
+			InstanceID: instanceID,
+			MarkerID:   markerIDs[i],
+			ContentID:  gofakeit.UUID(),
+			Name:       gofakeit.Name(),
+			Order:      i,
+		})
+		require.NoError(t, err)
+	}
+	return markerIDs
+}
+
+func TestRouteService_GenerateRoutes(t *testing.T) {
+	svc, teamService, locationRepo, cleanup := setupRouteService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	markerIDs := createRouteLocations(t, locationRepo, instanceID, 6)
+	_, err := teamService.AddTeams(ctx, instanceID, 9)
+	require.NoError(t, err)
+
+	routes, err := svc.GenerateRoutes(ctx, instanceID, 3)
+	require.NoError(t, err)
+	require.Len(t, routes, 3)
+
+	// Routes are evenly spaced rotations of the default order
+	assert.Equal(t, models.StrArray(markerIDs), routes[0].Locations)
+	assert.Equal(t, markerIDs[2], routes[1].Locations[0])
+	assert.Equal(t, markerIDs[4], routes[2].Locations[0])
+	for _, route := range routes {
+		assert.ElementsMatch(t, markerIDs, route.Locations)
+	}
+
+	// Teams are spread evenly
+	teams, err := teamService.FindAll(ctx, instanceID)
+	require.NoError(t, err)
+	counts := map[string]int{}
+	for _, team := range teams {
+		counts[team.RouteID]++
+	}
+	for _, route := range routes {
+		assert.Equal(t, 3, counts[route.ID])
+	}
+
+	// Generating again replaces the routes and caps them at one per location
+	routes, err = svc.GenerateRoutes(ctx, instanceID, 10)
+	require.NoError(t, err)
+	assert.Len(t, routes, 6)
+	found, err := svc.FindByInstance(ctx, instanceID)
+	require.NoError(t, err)
+	assert.Len(t, found, 6)
+
+	// Validation
+	_, err = svc.GenerateRoutes(ctx, instanceID, 0)
+	assert.ErrorIs(t, err, services.ErrInvalidArgument)
+	_, err = svc.GenerateRoutes(ctx, gofakeit.UUID(), 2)
+	assert.ErrorIs(t, err, services.ErrInvalidArgument, "routes need locations")
+}
+
+func TestRouteService_BalanceTeams(t *testing.T) {
+	svc, teamService, locationRepo, cleanup := setupRouteService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	createRouteLocations(t, locationRepo, instanceID, 3)
+
+	// Without routes teams follow the default order
+	teams, err := teamService.AddTeams(ctx, instanceID, 2)
+	require.NoError(t, err)
+	require.NoError(t, svc.BalanceTeams(ctx, instanceID, teams))
+	for _, team := range teams {
+		assert.Empty(t, team.RouteID)
+	}
+
+	routes, err := svc.GenerateRoutes(ctx, instanceID, 3)
+	require.NoError(t, err)
+
+	// The two existing teams are on the first two routes, so new teams fill the third first
+	teams, err = teamService.AddTeams(ctx, instanceID, 4)
+	require.NoError(t, err)
+	require.NoError(t, svc.BalanceTeams(ctx, instanceID, teams))
+	assert.Equal(t, routes[2].ID, teams[0].RouteID)
+
+	all, err := teamService.FindAll(ctx, instanceID)
+	require.NoError(t, err)
+	counts := map[string]int{}
+	for _, team := range all {
+		require.NotEmpty(t, team.RouteID)
+		counts[team.RouteID]++
+	}
+	for _, route := range routes {
+		assert.Equal(t, 2, counts[route.ID])
+	}
+}
+
+func TestRouteService_CreateUpdateDelete(t *testing.T) {
+	svc, teamService, locationRepo, cleanup := setupRouteService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	markerIDs := createRouteLocations(t, locationRepo, instanceID, 3)
+
+	route, err := svc.CreateRoute(ctx, instanceID, " Scenic ")
+	require.NoError(t, err)
+	assert.Equal(t, "Scenic", route.Name)
+	assert.Equal(t, models.StrArray(markerIDs), route.Locations)
+
+	_, err = svc.CreateRoute(ctx, instanceID, "")
+	assert.ErrorIs(t, err, services.ErrInvalidArgument)
+
+	// Unknown and repeated locations are dropped
+	route, err = svc.UpdateRoute(ctx, instanceID, route.ID, "Reversed", []string{markerIDs[2], "NOPE", markerIDs[1], markerIDs[2], markerIDs[0]})
+	require.NoError(t, err)
+	assert.Equal(t, models.StrArray{markerIDs[2], markerIDs[1], markerIDs[0]}, route.Locations)
+
+	// Routes belong to their instance
+	_, err = svc.UpdateRoute(ctx, gofakeit.UUID(), route.ID, "Stolen", nil)
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+	teams, err := teamService.AddTeams(ctx, instanceID, 1)
+	require.NoError(t, err)
+	err = svc.AssignRoute(ctx, gofakeit.UUID(), teams[0].Code, route.ID)
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	err = svc.AssignRoute(ctx, instanceID, teams[0].Code, route.ID)
+	require.NoError(t, err)
+
+	team, err := teamService.FindTeamByCode(ctx, teams[0].Code)
+	require.NoError(t, err)
+	assert.Equal(t, route.ID, team.RouteID)
+
+	// Deleting the route returns the team to the default order
+	err = svc.DeleteRoute(ctx, instanceID, route.ID)
+	require.NoError(t, err)
+	team, err = teamService.FindTeamByCode(ctx, teams[0].Code)
+	require.NoError(t, err)
+	assert.Empty(t, team.RouteID)
+}
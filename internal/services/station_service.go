@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nathanhollows/Rapua/v3/blocks"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+)
+
+type StationService interface {
+	// Queue returns the teams at a location and the teams heading there
+	Queue(ctx context.Context, instanceID, locationID string) (*StationQueue, error)
+
+	// CheckIn checks a team in at a location on their behalf
+	CheckIn(ctx context.Context, instanceID, teamCode, locationID string) error
+	// CheckOut checks a team out of a location on their behalf
+	CheckOut(ctx context.Context, instanceID, teamCode, locationID string) error
+	// CompleteBlocks marks a team's unfinished activities at a location complete
+	CompleteBlocks(ctx context.Context, instanceID, teamCode, locationID, actorID string) error
+}
+
+// StationQueue is the view of a location for the staff running it.
+// Present teams are checked in and have not left; incoming teams have
+// started playing and may visit the location next.
+type StationQueue struct {
+	Location models.Location
+	Present  []StationTeam
+	Incoming []models.Team
+}
+
+// StationTeam is a team checked in at a location.
+// Unfinished counts the activities the team must still complete there.
+type StationTeam struct {
+	Team       models.Team
+	CheckIn    models.CheckIn
+	Unfinished int
+}
+
+type stationService struct {
+	blockService    BlockService
+	gameplayService GameplayService
+	teamService     TeamService
+	auditRepo       repositories.AuditRepository
+	checkInRepo     repositories.CheckInRepository
+	locationRepo    repositories.LocationRepository
+}
+
+// NewStationService creates a new StationService.
+func NewStationService(
+	blockService BlockService,
+	gameplayService GameplayService,
+	teamService TeamService,
+	auditRepo repositories.AuditRepository,
+	checkInRepo repositories.CheckInRepository,
+	locationRepo repositories.LocationRepository,
+) StationService {
+	return &stationService{
+		blockService:    blockService,
+		gameplayService: gameplayService,
+		teamService:     teamService,
+		auditRepo:       auditRepo,
+		checkInRepo:     checkInRepo,
+		locationRepo:    locationRepo,
+	}
+}
+
+// Queue returns the teams at a location and the teams heading there.
+// A team is at the location until they check out or, in games without
+// check-outs, until they finish the location's activities.
+func (s *stationService) Queue(ctx context.Context, instanceID, locationID string) (*StationQueue, error) {
+	location, err := s.location(ctx, instanceID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	checkIns, err := s.checkInRepo.FindByLocation(ctx, location.ID)
+	if err != nil {
+		return nil, fmt.Errorf("finding check-ins: %w", err)
+	}
+	teams, err := s.teamService.FindAll(ctx, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("finding teams: %w", err)
+	}
+
+	visited := make(map[string]models.CheckIn, len(checkIns))
+	for _, checkIn := range checkIns {
+		visited[checkIn.TeamID] = checkIn
+	}
+
+	queue := &StationQueue{
+		Location: *location,
+		Present:  []StationTeam{},
+		Incoming: []models.Team{},
+	}
+	for _, team := range teams {
+		if checkIn, ok := visited[team.Code]; ok {
+			if !checkIn.TimeOut.IsZero() || (!checkIn.MustCheckOut && checkIn.BlocksCompleted) {
+				continue
+			}
+			unfinished, err := s.unfinished(ctx, &team, location.ID)
+			if err != nil {
+				return nil, err
+			}
+			queue.Present = append(queue.Present, StationTeam{
+				Team:       team,
+				CheckIn:    checkIn,
+				Unfinished: len(unfinished),
+			})
+			continue
+		}
+
+		if !team.HasStarted {
+			continue
+		}
+		// Suggestions depend on the team's instance and history
+		player, err := s.teamService.FindTeamByCode(ctx, team.Code)
+		if err != nil {
+			return nil, fmt.Errorf("finding team %s: %w", team.Code, err)
+		}
+		next, err := s.gameplayService.SuggestNextLocations(ctx, player)
+		if err != nil {
+			if errors.Is(err, ErrAllLocationsVisited) {
+				continue
+			}
+			return nil, fmt.Errorf("finding next locations for team %s: %w", team.Code, err)
+		}
+		for _, suggestion := range next {
+			if suggestion.ID == location.ID {
+				queue.Incoming = append(queue.Incoming, team)
+				break
+			}
+		}
+	}
+
+	return queue, nil
+}
+
+// CheckIn checks a team in at a location on their behalf.
+// The usual game rules apply, except the team's position is not checked.
+func (s *stationService) CheckIn(ctx context.Context, instanceID, teamCode, locationID string) error {
+	location, team, err := s.locationAndTeam(ctx, instanceID, teamCode, locationID)
+	if err != nil {
+		return err
+	}
+
+	err = s.gameplayService.CheckInOnBehalf(ctx, team, location.MarkerID)
+	if err != nil {
+		return fmt.Errorf("checking in: %w", err)
+	}
+
+	return s.audit(ctx, models.AuditCheckInAdded, instanceID, team.Code, location.ID)
+}
+
+// CheckOut checks a team out of a location on their behalf.
+// The team must have finished the location's activities first.
+func (s *stationService) CheckOut(ctx context.Context, instanceID, teamCode, locationID string) error {
+	location, team, err := s.locationAndTeam(ctx, instanceID, teamCode, locationID)
+	if err != nil {
+		return err
+	}
+
+	err = s.gameplayService.CheckOut(ctx, team, location.MarkerID)
+	if err != nil {
+		return fmt.Errorf("checking out: %w", err)
+	}
+
+	return s.audit(ctx, models.AuditCheckOutAdded, instanceID, team.Code, location.ID)
+}
+
+// CompleteBlocks marks a team's unfinished activities at a location complete.
+// The team is awarded each activity's points.
+func (s *stationService) CompleteBlocks(ctx context.Context, instanceID, teamCode, locationID, actorID string) error {
+	location, team, err := s.locationAndTeam(ctx, instanceID, teamCode, locationID)
+	if err != nil {
+		return err
+	}
+
+	unfinished, err := s.unfinished(ctx, team, location.ID)
+	if err != nil {
+		return err
+	}
+	for _, block := range unfinished {
+		err = s.blockService.CompleteBlock(ctx, instanceID, block.GetID(), team.Code, actorID)
+		if err != nil {
+			return fmt.Errorf("completing block %s: %w", block.GetID(), err)
+		}
+	}
+	return nil
+}
+
+// location finds a location and checks it belongs to the instance.
+func (s *stationService) location(ctx context.Context, instanceID, locationID string) (*models.Location, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+	if locationID == "" {
+		return nil, NewValidationError("locationID")
+	}
+	location, err := s.locationRepo.GetByID(ctx, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("finding location: %w", err)
+	}
+	if location.InstanceID != instanceID {
+		return nil, ErrPermissionDenied
+	}
+	return location, nil
+}
+
+// locationAndTeam finds a location and team and checks both belong to the instance.
+func (s *stationService) locationAndTeam(ctx context.Context, instanceID, teamCode, locationID string) (*models.Location, *models.Team, error) {
+	location, err := s.location(ctx, instanceID, locationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if teamCode == "" {
+		return nil, nil, NewValidationError("teamCode")
+	}
+	team, err := s.teamService.FindTeamByCode(ctx, teamCode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrTeamNotFound, err)
+	}
+	if team.InstanceID != instanceID {
+		return nil, nil, ErrPermissionDenied
+	}
+	return location, team, nil
+}
+
+// unfinished returns the activities a team must still complete at a location.
+func (s *stationService) unfinished(ctx context.Context, team *models.Team, locationID string) ([]blocks.Block, error) {
+	found, states, err := s.blockService.FindByLocationIDAndTeamCodeWithState(ctx, locationID, team.Code)
+	if err != nil {
+		return nil, fmt.Errorf("finding unfinished activities: %w", err)
+	}
+	unfinished := []blocks.Block{}
+	for _, block := range found {
+		if !block.RequiresValidation() {
+			continue
+		}
+		if state := states[block.GetID()]; state != nil && state.IsComplete() {
+			continue
+		}
+		unfinished = append(unfinished, block)
+	}
+	return unfinished, nil
+}
+
+// audit records a check-in or check-out made on a team's behalf.
+func (s *stationService) audit(ctx context.Context, action models.AuditAction, instanceID, teamCode, locationID string) error {
+	err := recordAudit(ctx, s.auditRepo, nil, models.AuditEvent{
+		InstanceID: instanceID,
+		Action:     action,
+		EntityType: "location",
+		EntityID:   locationID,
+		TeamCode:   teamCode,
+	}, nil, nil)
+	if err != nil {
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+	return nil
+}
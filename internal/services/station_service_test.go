@@ -0,0 +1,174 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func setupStationService(t *testing.T) (services.StationService, services.GameplayService, services.BlockService, services.LocationService, services.TeamService, *bun.DB, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+	broker := events.NewBroker()
+
+	auditRepo := repositories.NewAuditRepository(dbc)
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	blockRepo := repositories.NewBlockRepository(dbc, blockStateRepo)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	clueRepo := repositories.NewClueRepository(dbc)
+	hintRepo := repositories.NewHintRepository(dbc)
+	locationRepo := repositories.NewLocationRepository(dbc)
+	markerRepo := repositories.NewMarkerRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+
+	checkInService := services.NewCheckInService(checkInRepo, locationRepo, teamRepo)
+	locationService := services.NewLocationService(transactor, clueRepo, hintRepo, locationRepo, markerRepo, blockRepo, auditRepo)
+	teamService := services.NewTeamService(transactor, broker, teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	bonusService := services.NewBonusService(transactor, broker, bonusRepo, checkInRepo, hintRepo, locationRepo, pointsRepo, teamRepo, teamService)
+	blockService := services.NewBlockService(transactor, broker, auditRepo, blockRepo, blockStateRepo, checkInRepo, pointsRepo, teamRepo, bonusService)
+	gameplayService := services.NewGameplayService(
+		broker,
+		checkInService, services.NewGeofenceService(), locationService, teamService, blockService, bonusService, services.NewNavigationService(), markerRepo,
+	)
+	stationService := services.NewStationService(blockService, gameplayService, teamService, auditRepo, checkInRepo, locationRepo)
+
+	return stationService, gameplayService, blockService, locationService, teamService, dbc, cleanup
+}
+
+func TestStationService(t *testing.T) {
+	svc, gameplayService, blockService, locationService, teamService, dbc, cleanup := setupStationService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instance := &models.Instance{Name: "Station game", UserID: gofakeit.UUID()}
+	require.NoError(t, repositories.NewInstanceRepository(dbc).Create(ctx, instance))
+
+	// Teams must check out, and check-ins are geofenced so only staff can
+	// check a team in without a position
+	require.NoError(t, repositories.NewInstanceSettingsRepository(dbc).Create(ctx, &models.InstanceSettings{
+		InstanceID:       instance.ID,
+		NavigationMode:   models.FreeRoamNav,
+		CompletionMethod: models.CheckInAndOut,
+		CheckInRadius:    50,
+		EnablePoints:     true,
+	}))
+
+	station, err := locationService.CreateLocation(ctx, instance.ID, "Station", -45.86, 170.51, 10)
+	require.NoError(t, err)
+	_, err = locationService.CreateLocation(ctx, instance.ID, "Elsewhere", -45.87, 170.52, 10)
+	require.NoError(t, err)
+
+	block, err := blockService.NewBlock(ctx, station.ID, "answer")
+	require.NoError(t, err)
+	_, err = blockService.UpdateBlock(ctx, block, map[string][]string{
+		"points": {"5"},
+		"prompt": {"What colour is the door?"},
+		"answer": {"red"},
+	})
+	require.NoError(t, err)
+
+	teams, err := teamService.AddTeams(ctx, instance.ID, 3)
+	require.NoError(t, err)
+	for _, team := range teams[:2] {
+		team.HasStarted = true
+		require.NoError(t, teamService.Update(ctx, &team))
+	}
+	arriving, waiting := teams[0], teams[1]
+
+	actor := models.Actor{Type: models.ActorFacilitator, ID: "facilitator", InstanceID: instance.ID}
+	staffCtx := services.WithActor(ctx, actor)
+
+	queueCodes := func(t *testing.T) (present []string, incoming []string) {
+		t.Helper()
+		queue, err := svc.Queue(ctx, instance.ID, station.ID)
+		require.NoError(t, err)
+		for _, team := range queue.Present {
+			present = append(present, team.Team.Code)
+		}
+		for _, team := range queue.Incoming {
+			incoming = append(incoming, team.Code)
+		}
+		return present, incoming
+	}
+
+	t.Run("Started teams are on their way", func(t *testing.T) {
+		present, incoming := queueCodes(t)
+		assert.Empty(t, present)
+		assert.ElementsMatch(t, []string{arriving.Code, waiting.Code}, incoming)
+	})
+
+	t.Run("Locations are scoped to the instance", func(t *testing.T) {
+		_, err := svc.Queue(ctx, "another-instance", station.ID)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+		err = svc.CheckIn(staffCtx, "another-instance", arriving.Code, station.ID)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	})
+
+	t.Run("Players need a position to check in", func(t *testing.T) {
+		team, err := teamService.FindTeamByCode(ctx, arriving.Code)
+		require.NoError(t, err)
+		err = gameplayService.CheckIn(ctx, team, station.MarkerID, nil)
+		assert.ErrorIs(t, err, services.ErrPositionRequired)
+	})
+
+	t.Run("Staff can check a team in", func(t *testing.T) {
+		require.NoError(t, svc.CheckIn(staffCtx, instance.ID, arriving.Code, station.ID))
+
+		queue, err := svc.Queue(ctx, instance.ID, station.ID)
+		require.NoError(t, err)
+		require.Len(t, queue.Present, 1)
+		assert.Equal(t, arriving.Code, queue.Present[0].Team.Code)
+		assert.Equal(t, 1, queue.Present[0].Unfinished)
+		require.Len(t, queue.Incoming, 1)
+		assert.Equal(t, waiting.Code, queue.Incoming[0].Code)
+
+		err = svc.CheckIn(staffCtx, instance.ID, arriving.Code, station.ID)
+		assert.ErrorIs(t, err, services.ErrAlreadyCheckedIn)
+	})
+
+	t.Run("Teams finish the activities before checking out", func(t *testing.T) {
+		err := svc.CheckOut(staffCtx, instance.ID, arriving.Code, station.ID)
+		assert.ErrorIs(t, err, services.ErrUnfinishedCheckIn)
+
+		err = svc.CompleteBlocks(staffCtx, instance.ID, waiting.Code, station.ID, actor.ID)
+		assert.ErrorIs(t, err, services.ErrNotCheckedIn)
+
+		require.NoError(t, svc.CompleteBlocks(staffCtx, instance.ID, arriving.Code, station.ID, actor.ID))
+		require.NoError(t, svc.CheckOut(staffCtx, instance.ID, arriving.Code, station.ID))
+
+		present, incoming := queueCodes(t)
+		assert.Empty(t, present)
+		assert.Equal(t, []string{waiting.Code}, incoming)
+
+		team, err := teamService.FindTeamByCode(ctx, arriving.Code)
+		require.NoError(t, err)
+		assert.Equal(t, 5, team.Points, "activity points are awarded")
+	})
+
+	t.Run("Changes are audited", func(t *testing.T) {
+		audit, err := repositories.NewAuditRepository(dbc).FindByInstanceID(ctx, instance.ID)
+		require.NoError(t, err)
+		actions := []models.AuditAction{}
+		for _, event := range audit {
+			if event.ActorID == actor.ID {
+				actions = append(actions, event.Action)
+			}
+		}
+		assert.Contains(t, actions, models.AuditCheckInAdded)
+		assert.Contains(t, actions, models.AuditBlockCompleted)
+		assert.Contains(t, actions, models.AuditCheckOutAdded)
+	})
+}
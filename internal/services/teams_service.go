@@ -9,6 +9,7 @@ import (
 
 	"github.com/nathanhollows/Rapua/v3/db"
 	"github.com/nathanhollows/Rapua/v3/helpers"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
 	"github.com/nathanhollows/Rapua/v3/models"
 	"github.com/nathanhollows/Rapua/v3/repositories"
 	"github.com/uptrace/bun"
@@ -27,8 +28,20 @@ type TeamService interface {
 
 	// Update updates a team in the database
 	Update(ctx context.Context, team *models.Team) error
-	// AwardPoints awards points to a team
-	AwardPoints(ctx context.Context, team *models.Team, points int, reason string) error
+	// AwardPoints records a points transaction for a team and updates their total
+	AwardPoints(ctx context.Context, team *models.Team, transaction *models.PointsTransaction) error
+	// AdjustPoints records a manual change to a team's points made by an admin
+	AdjustPoints(ctx context.Context, instanceID string, teamCode string, actorID string, amount int, reason string) (*models.PointsTransaction, error)
+	// UndoPoints reverses a manual change to a team's points
+	UndoPoints(ctx context.Context, instanceID string, transactionID string, actorID string) error
+	// FindPoints returns the points transactions for a team, oldest first
+	FindPoints(ctx context.Context, teamCode string) ([]models.PointsTransaction, error)
+	// RevokeCheckIn removes a team's check-in at a location and the points it earned
+	RevokeCheckIn(ctx context.Context, instanceID string, teamCode string, locationID string, actorID string) error
+	// ClearCheckOut lets a team continue without checking out of their current location
+	ClearCheckOut(ctx context.Context, instanceID string, teamCode string, actorID string) error
+	// GrantExtraTime adds minutes to a team's time limit
+	GrantExtraTime(ctx context.Context, instanceID string, teamCode string, minutes int) (*models.Team, error)
 	// Reset wipes a team's progress for re-use
 	Reset(ctx context.Context, instanceID string, teamCodes []string) error
 
@@ -45,26 +58,38 @@ type TeamService interface {
 
 type teamService struct {
 	transactor     db.Transactor
+	broker         *events.Broker
+	auditRepo      repositories.AuditRepository
 	teamRepo       repositories.TeamRepository
 	checkInRepo    repositories.CheckInRepository
 	blockStateRepo repositories.BlockStateRepository
 	locationRepo   repositories.LocationRepository
+	bonusRepo      repositories.BonusRepository
+	pointsRepo     repositories.PointsRepository
 	batchSize      int
 }
 
 // NewTeamService creates a new TeamService.
 func NewTeamService(transactor db.Transactor,
+	broker *events.Broker,
 	tr repositories.TeamRepository,
 	cr repositories.CheckInRepository,
 	bsr repositories.BlockStateRepository,
 	lr repositories.LocationRepository,
+	br repositories.BonusRepository,
+	pr repositories.PointsRepository,
+	ar repositories.AuditRepository,
 ) TeamService {
 	return &teamService{
 		transactor:     transactor,
+		broker:         broker,
+		auditRepo:      ar,
 		teamRepo:       tr,
 		checkInRepo:    cr,
 		blockStateRepo: bsr,
 		locationRepo:   lr,
+		bonusRepo:      br,
+		pointsRepo:     pr,
 		batchSize:      100,
 	}
 }
@@ -130,6 +155,21 @@ func (s *teamService) AddTeams(ctx context.Context, instanceID string, count int
 		newTeams = append(newTeams, teams...)
 	}
 
+	codes := make([]string, len(newTeams))
+	for i, team := range newTeams {
+		codes[i] = team.Code
+	}
+	err := recordAudit(ctx, s.auditRepo, nil, models.AuditEvent{
+		InstanceID: instanceID,
+		Action:     models.AuditTeamsAdded,
+		EntityType: "instance",
+		EntityID:   instanceID,
+		Reason:     fmt.Sprintf("Added %d teams", len(newTeams)),
+	}, nil, codes)
+	if err != nil {
+		return nil, fmt.Errorf("recording audit event: %w", err)
+	}
+
 	return newTeams, nil
 }
 
@@ -199,13 +239,443 @@ func (s *teamService) GetTeamActivityOverview(ctx context.Context, instanceID st
 
 // Update updates a team in the database.
 func (s *teamService) Update(ctx context.Context, team *models.Team) error {
-	return s.teamRepo.Update(ctx, team)
+	err := s.teamRepo.Update(ctx, team)
+	if err != nil {
+		return err
+	}
+	return s.auditTeam(ctx, nil, models.AuditTeamUpdated, team.InstanceID, team.Code, nil, auditTeam(team))
+}
+
+// AwardPoints records a points transaction for a team and updates their total.
+// The stored total is recalculated from the ledger so concurrent awards are not lost.
+func (s *teamService) AwardPoints(ctx context.Context, team *models.Team, transaction *models.PointsTransaction) error {
+	transaction.InstanceID = team.InstanceID
+	transaction.TeamCode = team.Code
+
+	err := s.recordPoints(ctx, team.InstanceID, team.Code, func(tx *bun.Tx) error {
+		return s.pointsRepo.Create(ctx, tx, transaction)
+	})
+	if err != nil {
+		return err
+	}
+
+	team.Points += transaction.Amount
+	publishPoints(s.broker, *transaction)
+	return nil
+}
+
+// AdjustPoints records a manual change to a team's points made by an admin.
+func (s *teamService) AdjustPoints(ctx context.Context, instanceID string, teamCode string, actorID string, amount int, reason string) (*models.PointsTransaction, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+	if actorID == "" {
+		return nil, NewValidationError("actorID")
+	}
+	if amount == 0 {
+		return nil, NewValidationError("amount")
+	}
+	if reason == "" {
+		return nil, NewValidationError("reason")
+	}
+
+	team, err := s.teamRepo.GetByCode(ctx, teamCode)
+	if err != nil {
+		return nil, fmt.Errorf("finding team: %w", err)
+	}
+	if team.InstanceID != instanceID {
+		return nil, ErrPermissionDenied
+	}
+
+	transaction := &models.PointsTransaction{
+		InstanceID: instanceID,
+		TeamCode:   team.Code,
+		Amount:     amount,
+		Reason:     reason,
+		Source:     models.ManualSource,
+		ActorID:    actorID,
+	}
+	err = s.recordPoints(ctx, instanceID, team.Code, func(tx *bun.Tx) error {
+		err := s.pointsRepo.Create(ctx, tx, transaction)
+		if err != nil {
+			return err
+		}
+		return s.auditRepo.Create(ctx, tx, describeActor(ctx, &models.AuditEvent{
+			InstanceID: instanceID,
+			ActorID:    actorID,
+			Action:     models.AuditPointsAdjusted,
+			EntityType: "points_transaction",
+			EntityID:   transaction.ID,
+			TeamCode:   team.Code,
+			Reason:     reason,
+		}))
+	})
+	if err != nil {
+		return nil, err
+	}
+	publishPoints(s.broker, *transaction)
+	return transaction, nil
+}
+
+// UndoPoints reverses a manual change to a team's points.
+// The original transaction is kept and marked as undone.
+func (s *teamService) UndoPoints(ctx context.Context, instanceID string, transactionID string, actorID string) error {
+	if instanceID == "" {
+		return NewValidationError("instanceID")
+	}
+	if actorID == "" {
+		return NewValidationError("actorID")
+	}
+
+	original, err := s.pointsRepo.GetByID(ctx, transactionID)
+	if err != nil {
+		return fmt.Errorf("finding points transaction: %w", err)
+	}
+	if original.InstanceID != instanceID {
+		return ErrPermissionDenied
+	}
+	if !original.Undoable() {
+		return NewValidationError("transactionID")
+	}
+
+	reversal := &models.PointsTransaction{
+		InstanceID: instanceID,
+		TeamCode:   original.TeamCode,
+		Amount:     -original.Amount,
+		Reason:     fmt.Sprint("Undo: ", original.Reason),
+		Source:     models.ManualSource,
+		SourceID:   original.ID,
+		ActorID:    actorID,
+	}
+	err = s.recordPoints(ctx, instanceID, original.TeamCode, func(tx *bun.Tx) error {
+		undone, err := s.pointsRepo.Undo(ctx, tx, original.ID, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("undoing points transaction: %w", err)
+		}
+		if !undone {
+			// Someone else undid the transaction first
+			return NewValidationError("transactionID")
+		}
+		err = s.pointsRepo.Create(ctx, tx, reversal)
+		if err != nil {
+			return err
+		}
+		return s.auditRepo.Create(ctx, tx, describeActor(ctx, &models.AuditEvent{
+			InstanceID: instanceID,
+			ActorID:    actorID,
+			Action:     models.AuditPointsUndone,
+			EntityType: "points_transaction",
+			EntityID:   original.ID,
+			TeamCode:   original.TeamCode,
+			Reason:     original.Reason,
+		}))
+	})
+	if err != nil {
+		return err
+	}
+	publishPoints(s.broker, *reversal)
+	return nil
+}
+
+// FindPoints returns the points transactions for a team, oldest first.
+func (s *teamService) FindPoints(ctx context.Context, teamCode string) ([]models.PointsTransaction, error) {
+	return s.pointsRepo.FindByTeam(ctx, teamCode)
+}
+
+// recordPoints changes the ledger and reconciles the team's total in a single transaction.
+func (s *teamService) recordPoints(ctx context.Context, instanceID string, teamCode string, change func(tx *bun.Tx) error) error {
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	err = change(tx)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("recording points: %w", err)
+	}
+
+	err = s.teamRepo.ReconcilePoints(ctx, tx, instanceID, []string{teamCode})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("reconciling points: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// publishPoints tells teams about changes to their points once they are saved.
+// Any change may reorder the leaderboard, so the instance is told to refresh it too.
+func publishPoints(broker *events.Broker, transactions ...models.PointsTransaction) {
+	if len(transactions) == 0 {
+		return
+	}
+	for _, transaction := range transactions {
+		broker.Publish(events.Event{
+			Type:       events.PointsChanged,
+			InstanceID: transaction.InstanceID,
+			TeamCode:   transaction.TeamCode,
+			Payload:    transaction,
+		})
+	}
+	broker.Publish(events.Event{
+		Type:       events.LeaderboardUpdated,
+		InstanceID: transactions[0].InstanceID,
+	})
+}
+
+// RevokeCheckIn removes a team's check-in at a location and the points it earned.
+// The team's progress on the location's blocks is cleared and the points
+// for those blocks are reversed too, so the team may check in there again
+// afterwards and start afresh.
+func (s *teamService) RevokeCheckIn(ctx context.Context, instanceID string, teamCode string, locationID string, actorID string) error {
+	if instanceID == "" {
+		return NewValidationError("instanceID")
+	}
+	if actorID == "" {
+		return NewValidationError("actorID")
+	}
+
+	team, err := s.teamRepo.GetByCode(ctx, teamCode)
+	if err != nil {
+		return fmt.Errorf("finding team: %w", err)
+	}
+	if team.InstanceID != instanceID {
+		return ErrPermissionDenied
+	}
+
+	checkedIn := false
+	for _, checkIn := range team.CheckIns {
+		if checkIn.LocationID == locationID {
+			checkedIn = true
+			break
+		}
+	}
+	if !checkedIn {
+		return NewValidationError("locationID")
+	}
+
+	// Reverse the points awarded for checking in and for the location's
+	// blocks, net of any earlier revocation
+	states, err := s.blockStateRepo.FindByLocationAndTeam(ctx, locationID, team.Code)
+	if err != nil {
+		return fmt.Errorf("finding block states: %w", err)
+	}
+	sources := map[string]models.PointsSource{locationID: models.LocationSource}
+	for _, state := range states {
+		sources[state.GetBlockID()] = models.BlockSource
+	}
+
+	ledger, err := s.pointsRepo.FindByTeam(ctx, team.Code)
+	if err != nil {
+		return fmt.Errorf("finding points: %w", err)
+	}
+	var reversals []*models.PointsTransaction
+	bySource := map[string]*models.PointsTransaction{}
+	for _, transaction := range ledger {
+		source, ok := sources[transaction.SourceID]
+		if !ok || transaction.Source != source {
+			continue
+		}
+		reversal, ok := bySource[transaction.SourceID]
+		if !ok {
+			reversal = &models.PointsTransaction{
+				InstanceID: instanceID,
+				TeamCode:   team.Code,
+				Reason:     fmt.Sprint("Revoked: ", transaction.Reason),
+				Source:     source,
+				SourceID:   transaction.SourceID,
+				ActorID:    actorID,
+			}
+			bySource[transaction.SourceID] = reversal
+			reversals = append(reversals, reversal)
+		}
+		reversal.Amount -= transaction.Amount
+	}
+
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	err = s.checkInRepo.Delete(ctx, tx, team.Code, locationID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("deleting check in: %w", err)
+	}
+
+	if team.MustCheckOut == locationID {
+		err = s.teamRepo.ClearMustCheckOut(ctx, tx, instanceID, team.Code)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("clearing check out: %w", err)
+		}
+	}
+
+	err = s.blockStateRepo.DeleteByLocationAndTeam(ctx, tx, locationID, team.Code)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("deleting block states: %w", err)
+	}
+
+	var reversed []models.PointsTransaction
+	for _, reversal := range reversals {
+		if reversal.Amount == 0 {
+			continue
+		}
+		err = s.pointsRepo.Create(ctx, tx, reversal)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("recording points: %w", err)
+		}
+		reversed = append(reversed, *reversal)
+	}
+	if len(reversed) > 0 {
+		err = s.teamRepo.ReconcilePoints(ctx, tx, instanceID, []string{team.Code})
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("reconciling points: %w", err)
+		}
+	}
+
+	err = s.locationRepo.UpdateStatistics(ctx, tx, instanceID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("updating location statistics: %w", err)
+	}
+
+	err = s.auditRepo.Create(ctx, tx, describeActor(ctx, &models.AuditEvent{
+		InstanceID: instanceID,
+		ActorID:    actorID,
+		Action:     models.AuditCheckInRevoked,
+		EntityType: "location",
+		EntityID:   locationID,
+		TeamCode:   team.Code,
+	}))
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	publishPoints(s.broker, reversed...)
+	return nil
+}
+
+// ClearCheckOut lets a team continue without checking out of their current location.
+// The check-in is closed as if the team had checked out.
+func (s *teamService) ClearCheckOut(ctx context.Context, instanceID string, teamCode string, actorID string) error {
+	if instanceID == "" {
+		return NewValidationError("instanceID")
+	}
+	if actorID == "" {
+		return NewValidationError("actorID")
+	}
+
+	team, err := s.teamRepo.GetByCode(ctx, teamCode)
+	if err != nil {
+		return fmt.Errorf("finding team: %w", err)
+	}
+	if team.InstanceID != instanceID {
+		return ErrPermissionDenied
+	}
+	if team.MustCheckOut == "" {
+		return NewValidationError("mustCheckOut")
+	}
+
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	err = s.checkInRepo.Close(ctx, tx, team.Code, team.MustCheckOut)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("closing check in: %w", err)
+	}
+
+	err = s.teamRepo.ClearMustCheckOut(ctx, tx, instanceID, team.Code)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("clearing check out: %w", err)
+	}
+
+	err = s.locationRepo.UpdateStatistics(ctx, tx, instanceID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("updating location statistics: %w", err)
+	}
+
+	err = s.auditRepo.Create(ctx, tx, describeActor(ctx, &models.AuditEvent{
+		InstanceID: instanceID,
+		ActorID:    actorID,
+		Action:     models.AuditCheckOutCleared,
+		EntityType: "location",
+		EntityID:   team.MustCheckOut,
+		TeamCode:   team.Code,
+	}))
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+
+	return tx.Commit()
 }
 
-// AwardPoints awards points to a team.
-func (s *teamService) AwardPoints(ctx context.Context, team *models.Team, points int, _ string) error {
-	team.Points += points
-	return s.teamRepo.Update(ctx, team)
+// GrantExtraTime adds minutes to a team's time limit.
+func (s *teamService) GrantExtraTime(ctx context.Context, instanceID string, teamCode string, minutes int) (*models.Team, error) {
+	if instanceID == "" {
+		return nil, NewValidationError("instanceID")
+	}
+	if minutes <= 0 {
+		return nil, NewValidationError("minutes")
+	}
+
+	team, err := s.teamRepo.GetByCode(ctx, teamCode)
+	if err != nil {
+		return nil, fmt.Errorf("finding team: %w", err)
+	}
+	if team.InstanceID != instanceID {
+		return nil, ErrPermissionDenied
+	}
+
+	before := auditTeam(team)
+	team.ExtraTime += minutes
+	err = s.teamRepo.Update(ctx, team)
+	if err != nil {
+		return nil, fmt.Errorf("updating team: %w", err)
+	}
+	err = s.auditTeam(ctx, nil, models.AuditTeamTimeAdded, instanceID, team.Code, before, auditTeam(team))
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
 }
 
 // Reset wipes a team's progress for re-use.
@@ -241,6 +711,29 @@ func (s *teamService) Reset(ctx context.Context, instanceID string, teamCodes []
 		return fmt.Errorf("deleting block states: %w", err)
 	}
 
+	err = s.bonusRepo.DeleteAwardsByTeamCodes(ctx, tx, instanceID, teamCodes)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("deleting bonus awards: %w", err)
+	}
+
+	err = s.pointsRepo.DeleteByTeamCodes(ctx, tx, instanceID, teamCodes)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("deleting points transactions: %w", err)
+	}
+
+	err = recordAudit(ctx, s.auditRepo, tx, models.AuditEvent{
+		InstanceID: instanceID,
+		Action:     models.AuditTeamsReset,
+		EntityType: "instance",
+		EntityID:   instanceID,
+	}, teamCodes, nil)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+
 	err = s.locationRepo.UpdateStatistics(ctx, tx, instanceID)
 	if err != nil {
 		tx.Rollback()
@@ -283,6 +776,18 @@ func (s *teamService) Delete(ctx context.Context, instanceID string, teamCode st
 		return fmt.Errorf("deleting block states: %w", err)
 	}
 
+	err = s.pointsRepo.DeleteByTeamCodes(ctx, tx, instanceID, []string{teamCode})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("deleting points transactions: %w", err)
+	}
+
+	err = s.auditTeam(ctx, tx, models.AuditTeamDeleted, instanceID, teamCode, map[string]any{"Code": teamCode}, nil)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	err = s.locationRepo.UpdateStatistics(ctx, tx, instanceID)
 	if err != nil {
 		tx.Rollback()
@@ -292,6 +797,21 @@ func (s *teamService) Delete(ctx context.Context, instanceID string, teamCode st
 	return tx.Commit()
 }
 
+// auditTeam records a change to a team.
+func (s *teamService) auditTeam(ctx context.Context, tx *bun.Tx, action models.AuditAction, instanceID, teamCode string, before, after any) error {
+	err := recordAudit(ctx, s.auditRepo, tx, models.AuditEvent{
+		InstanceID: instanceID,
+		Action:     action,
+		EntityType: "team",
+		EntityID:   teamCode,
+		TeamCode:   teamCode,
+	}, before, after)
+	if err != nil {
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+	return nil
+}
+
 // DeleteByInstanceID removes all teams for a specific instance.
 func (s *teamService) DeleteByInstanceID(ctx context.Context, tx *bun.Tx, instanceID string) error {
 	err := s.teamRepo.DeleteByInstanceID(ctx, tx, instanceID)
@@ -299,6 +819,11 @@ func (s *teamService) DeleteByInstanceID(ctx context.Context, tx *bun.Tx, instan
 		return fmt.Errorf("deleting teams by instance ID: %w", err)
 	}
 
+	err = s.pointsRepo.DeleteByInstanceID(ctx, tx, instanceID)
+	if err != nil {
+		return fmt.Errorf("deleting points transactions by instance ID: %w", err)
+	}
+
 	return nil
 }
 
@@ -313,6 +838,10 @@ func (s *teamService) LoadRelation(ctx context.Context, team *models.Team, relat
 		return s.teamRepo.LoadBlockingLocation(ctx, team)
 	case "Messages":
 		return s.teamRepo.LoadMessages(ctx, team)
+	case "Blocks":
+		return s.teamRepo.LoadBlockStates(ctx, team)
+	case "Route":
+		return s.teamRepo.LoadRoute(ctx, team)
 	default:
 		return errors.New("unknown relation")
 	}
@@ -6,10 +6,12 @@ import (
 
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
 	"github.com/nathanhollows/Rapua/v3/internal/services"
 	"github.com/nathanhollows/Rapua/v3/models"
 	"github.com/nathanhollows/Rapua/v3/repositories"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setupTeamsService(t *testing.T) (services.TeamService, func()) {
@@ -22,7 +24,10 @@ func setupTeamsService(t *testing.T) (services.TeamService, func()) {
 	blockStateRepo := repositories.NewBlockStateRepository(dbc)
 	teamRepo := repositories.NewTeamRepository(dbc)
 	locationRepo := repositories.NewLocationRepository(dbc)
-	teamService := services.NewTeamService(transactor, teamRepo, checkinRepo, blockStateRepo, locationRepo)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	auditRepo := repositories.NewAuditRepository(dbc)
+	teamService := services.NewTeamService(transactor, events.NewBroker(), teamRepo, checkinRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
 
 	return teamService, cleanup
 }
@@ -136,3 +141,193 @@ func TestTeamService_Reset(t *testing.T) {
 		})
 	}
 }
+
+func TestTeamService_GrantExtraTime(t *testing.T) {
+	teamService, cleanup := setupTeamsService(t)
+	defer cleanup()
+
+	instanceID := gofakeit.UUID()
+	teams, err := teamService.AddTeams(context.Background(), instanceID, 1)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		instanceID string
+		minutes    int
+		wantErr    error
+		wantExtra  int
+	}{
+		{"Grants extra time", instanceID, 10, nil, 10},
+		{"Adds to existing extra time", instanceID, 5, nil, 15},
+		{"Rejects zero minutes", instanceID, 0, services.ErrInvalidArgument, 15},
+		{"Rejects negative minutes", instanceID, -5, services.ErrInvalidArgument, 15},
+		{"Rejects other instances", gofakeit.UUID(), 10, services.ErrPermissionDenied, 15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := teamService.GrantExtraTime(context.Background(), tt.instanceID, teams[0].Code, tt.minutes)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			team, err := teamService.FindTeamByCode(context.Background(), teams[0].Code)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantExtra, team.ExtraTime)
+		})
+	}
+}
+
+func TestTeamService_PointsLedger(t *testing.T) {
+	teamService, cleanup := setupTeamsService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	userID := gofakeit.UUID()
+	teams, err := teamService.AddTeams(ctx, instanceID, 1)
+	assert.NoError(t, err)
+	team := teams[0]
+
+	err = teamService.AwardPoints(ctx, &team, &models.PointsTransaction{Amount: 10, Reason: "Checked in", Source: models.LocationSource})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, team.Points)
+
+	// A stale copy of the team does not overwrite the total
+	team.Points = 0
+	assert.NoError(t, teamService.Update(ctx, &team))
+
+	manual, err := teamService.AdjustPoints(ctx, instanceID, team.Code, userID, 5, "Helped another team")
+	assert.NoError(t, err)
+
+	found, err := teamService.FindTeamByCode(ctx, team.Code)
+	assert.NoError(t, err)
+	assert.Equal(t, 15, found.Points)
+
+	_, err = teamService.AdjustPoints(ctx, instanceID, team.Code, userID, 5, "")
+	assert.ErrorIs(t, err, services.ErrInvalidArgument, "manual changes need a reason")
+	_, err = teamService.AdjustPoints(ctx, gofakeit.UUID(), team.Code, userID, 5, "Wrong game")
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+	err = teamService.UndoPoints(ctx, gofakeit.UUID(), manual.ID, userID)
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	err = teamService.UndoPoints(ctx, instanceID, manual.ID, userID)
+	assert.NoError(t, err)
+	err = teamService.UndoPoints(ctx, instanceID, manual.ID, userID)
+	assert.ErrorIs(t, err, services.ErrInvalidArgument, "changes can only be undone once")
+
+	found, err = teamService.FindTeamByCode(ctx, team.Code)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, found.Points)
+
+	ledger, err := teamService.FindPoints(ctx, team.Code)
+	assert.NoError(t, err)
+	assert.Len(t, ledger, 3)
+	assert.False(t, ledger[0].Undoable(), "only manual changes can be undone")
+
+	err = teamService.UndoPoints(ctx, instanceID, ledger[0].ID, userID)
+	assert.ErrorIs(t, err, services.ErrInvalidArgument)
+
+	// Resetting the team clears the ledger
+	assert.NoError(t, teamService.Reset(ctx, instanceID, []string{team.Code}))
+	ledger, err = teamService.FindPoints(ctx, team.Code)
+	assert.NoError(t, err)
+	assert.Empty(t, ledger)
+}
+
+func TestTeamService_RevokeCheckIn(t *testing.T) {
+	_, blockService, teamService, _, dbc, cleanup := setupReviewService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	userID := gofakeit.UUID()
+	teams, err := teamService.AddTeams(ctx, instanceID, 1)
+	require.NoError(t, err)
+	team := teams[0]
+
+	location := models.Location{ID: gofakeit.UUID(), InstanceID: instanceID, Name: "Library"}
+	_, err = repositories.NewCheckInRepository(dbc).LogCheckIn(ctx, team, location, false, false)
+	require.NoError(t, err)
+	err = teamService.AwardPoints(ctx, &team, &models.PointsTransaction{
+		Amount:   10,
+		Reason:   "Checked in at Library",
+		Source:   models.LocationSource,
+		SourceID: location.ID,
+	})
+	require.NoError(t, err)
+
+	block, err := blockService.NewBlock(ctx, location.ID, "answer")
+	require.NoError(t, err)
+	block, err = blockService.UpdateBlock(ctx, block, map[string][]string{
+		"points": {"5"},
+		"prompt": {"Find the oldest book"},
+		"answer": {"atlas"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, blockService.CompleteBlock(ctx, instanceID, block.GetID(), team.Code, userID))
+
+	err = teamService.RevokeCheckIn(ctx, gofakeit.UUID(), team.Code, location.ID, userID)
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	err = teamService.RevokeCheckIn(ctx, instanceID, team.Code, gofakeit.UUID(), userID)
+	assert.ErrorIs(t, err, services.ErrInvalidArgument, "the team must have checked in")
+
+	err = teamService.RevokeCheckIn(ctx, instanceID, team.Code, location.ID, userID)
+	require.NoError(t, err)
+
+	found, err := teamService.FindTeamByCode(ctx, team.Code)
+	require.NoError(t, err)
+	assert.Empty(t, found.CheckIns)
+	assert.Equal(t, 0, found.Points, "points for checking in and for blocks are removed")
+
+	ledger, err := teamService.FindPoints(ctx, team.Code)
+	require.NoError(t, err)
+	require.Len(t, ledger, 4)
+	assert.Equal(t, "Revoked: Checked in at Library", ledger[2].Reason)
+	assert.Equal(t, -10, ledger[2].Amount)
+	assert.Equal(t, userID, ledger[2].ActorID)
+	assert.Equal(t, models.BlockSource, ledger[3].Source)
+	assert.Equal(t, -5, ledger[3].Amount)
+
+	states, err := repositories.NewBlockStateRepository(dbc).FindByLocationAndTeam(ctx, location.ID, team.Code)
+	require.NoError(t, err)
+	assert.Empty(t, states, "progress on the location's blocks is cleared")
+}
+
+func TestTeamService_ClearCheckOut(t *testing.T) {
+	_, _, teamService, _, dbc, cleanup := setupReviewService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	userID := gofakeit.UUID()
+	teams, err := teamService.AddTeams(ctx, instanceID, 1)
+	require.NoError(t, err)
+	team := teams[0]
+
+	err = teamService.ClearCheckOut(ctx, instanceID, team.Code, userID)
+	assert.ErrorIs(t, err, services.ErrInvalidArgument, "the team must be waiting to check out")
+
+	location := models.Location{ID: gofakeit.UUID(), InstanceID: instanceID}
+	_, err = repositories.NewCheckInRepository(dbc).LogCheckIn(ctx, team, location, true, false)
+	require.NoError(t, err)
+	found, err := teamService.FindTeamByCode(ctx, team.Code)
+	require.NoError(t, err)
+	found.MustCheckOut = location.ID
+	require.NoError(t, teamService.Update(ctx, found))
+
+	err = teamService.ClearCheckOut(ctx, gofakeit.UUID(), team.Code, userID)
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+	err = teamService.ClearCheckOut(ctx, instanceID, team.Code, userID)
+	require.NoError(t, err)
+
+	found, err = teamService.FindTeamByCode(ctx, team.Code)
+	require.NoError(t, err)
+	assert.Empty(t, found.MustCheckOut)
+	require.Len(t, found.CheckIns, 1)
+	assert.False(t, found.CheckIns[0].MustCheckOut)
+	assert.False(t, found.CheckIns[0].TimeOut.IsZero())
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+)
+
+type TemplateService interface {
+	// Publish creates a read-only template from one of the user's instances
+	Publish(ctx context.Context, user *models.User, instanceID string, data TemplateData) (*models.Instance, error)
+	// Update changes the details of one of the user's templates
+	Update(ctx context.Context, user *models.User, templateID string, data TemplateData) (*models.Instance, error)
+	// Delete removes one of the user's templates
+	Delete(ctx context.Context, user *models.User, templateID string) error
+
+	// FindByUser returns the templates the user has published
+	FindByUser(ctx context.Context, userID string) ([]models.Instance, error)
+	// GetByShareToken returns the template for a share link
+	GetByShareToken(ctx context.Context, token string) (*models.Instance, error)
+	// Gallery returns the public templates that match the filter
+	Gallery(ctx context.Context, filter TemplateFilter) ([]models.Instance, error)
+
+	// Clone copies a shared template into the user's account as a new instance
+	Clone(ctx context.Context, user *models.User, token, name string) (*models.Instance, error)
+}
+
+// TemplateData is the information shown about a template.
+// Public templates are listed in the gallery; others can only be found by
+// their share link.
+type TemplateData struct {
+	Name        string
+	Description string
+	Tags        []string
+	IsPublic    bool
+}
+
+// TemplateFilter narrows down the templates in the gallery.
+// Zero values match every template.
+type TemplateFilter struct {
+	Search       string
+	Tag          string
+	MinLocations int
+	MaxLocations int
+}
+
+type templateService struct {
+	transactor      db.Transactor
+	instanceService InstanceService
+	auditRepo       repositories.AuditRepository
+	instanceRepo    repositories.InstanceRepository
+}
+
+// NewTemplateService creates a new TemplateService.
+func NewTemplateService(
+	transactor db.Transactor,
+	instanceService InstanceService,
+	auditRepo repositories.AuditRepository,
+	instanceRepo repositories.InstanceRepository,
+) TemplateService {
+	return &templateService{
+		transactor:      transactor,
+		instanceService: instanceService,
+		auditRepo:       auditRepo,
+		instanceRepo:    instanceRepo,
+	}
+}
+
+// Publish creates a read-only template from one of the user's instances.
+// The template is a copy, so later changes to the instance are not shared.
+// It is saved in one transaction so a failed copy leaves nothing behind.
+func (s *templateService) Publish(ctx context.Context, user *models.User, instanceID string, data TemplateData) (*models.Instance, error) {
+	if user == nil {
+		return nil, ErrUserNotAuthenticated
+	}
+	if data.Name == "" {
+		return nil, NewValidationError("name")
+	}
+
+	instance, err := s.instanceRepo.GetByID(ctx, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("finding instance: %w", err)
+	}
+	if instance.UserID != user.ID || instance.IsTemplate {
+		return nil, ErrPermissionDenied
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+	template := &models.Instance{
+		Name:           data.Name,
+		UserID:         user.ID,
+		OrganisationID: user.CurrentOrganisationID,
+		IsTemplate:     true,
+		ShareToken:     token,
+		Description:    data.Description,
+		IsPublic:       data.IsPublic,
+	}
+	template.SetTags(data.Tags)
+
+	tx, err := s.transactor.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	err = s.instanceService.DuplicateInstanceWithTransaction(ctx, tx, instance, template)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("copying instance: %w", err)
+	}
+
+	err = recordAudit(ctx, s.auditRepo, tx, models.AuditEvent{
+		InstanceID: instance.ID,
+		Action:     models.AuditInstancePublished,
+		EntityType: "instance",
+		EntityID:   template.ID,
+	}, nil, auditInstance(template))
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("recording audit event: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+	return template, nil
+}
+
+// Update changes the details of one of the user's templates.
+func (s *templateService) Update(ctx context.Context, user *models.User, templateID string, data TemplateData) (*models.Instance, error) {
+	template, err := s.ownTemplate(ctx, user, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if data.Name == "" {
+		return nil, NewValidationError("name")
+	}
+
+	template.Name = data.Name
+	template.Description = data.Description
+	template.IsPublic = data.IsPublic
+	template.SetTags(data.Tags)
+	if err := s.instanceRepo.Update(ctx, template); err != nil {
+		return nil, fmt.Errorf("saving template: %w", err)
+	}
+	return template, nil
+}
+
+// Delete removes one of the user's templates.
+// Instances already cloned from the template are not affected.
+func (s *templateService) Delete(ctx context.Context, user *models.User, templateID string) error {
+	template, err := s.ownTemplate(ctx, user, templateID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.instanceService.DeleteInstance(ctx, user, template.ID, template.Name)
+	if err != nil {
+		return fmt.Errorf("deleting template: %w", err)
+	}
+	return nil
+}
+
+// FindByUser returns the templates the user has published.
+func (s *templateService) FindByUser(ctx context.Context, userID string) ([]models.Instance, error) {
+	templates, err := s.instanceRepo.FindTemplatesByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("finding templates: %w", err)
+	}
+	return templates, nil
+}
+
+// GetByShareToken returns the template for a share link.
+func (s *templateService) GetByShareToken(ctx context.Context, token string) (*models.Instance, error) {
+	if token == "" {
+		return nil, NewValidationError("token")
+	}
+	template, err := s.instanceRepo.GetTemplateByShareToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("finding template: %w", err)
+	}
+	return template, nil
+}
+
+// Gallery returns the public templates that match the filter, newest first.
+func (s *templateService) Gallery(ctx context.Context, filter TemplateFilter) ([]models.Instance, error) {
+	templates, err := s.instanceRepo.FindPublicTemplates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding templates: %w", err)
+	}
+
+	search := strings.ToLower(strings.TrimSpace(filter.Search))
+	matches := []models.Instance{}
+	for _, template := range templates {
+		if filter.Tag != "" && !template.HasTag(filter.Tag) {
+			continue
+		}
+		if filter.MinLocations > 0 && len(template.Locations) < filter.MinLocations {
+			continue
+		}
+		if filter.MaxLocations > 0 && len(template.Locations) > filter.MaxLocations {
+			continue
+		}
+		if search != "" &&
+			!strings.Contains(strings.ToLower(template.Name), search) &&
+			!strings.Contains(strings.ToLower(template.Description), search) &&
+			!template.HasTag(search) {
+			continue
+		}
+		matches = append(matches, template)
+	}
+	return matches, nil
+}
+
+// Clone copies a shared template into the user's account as a new instance.
+func (s *templateService) Clone(ctx context.Context, user *models.User, token, name string) (*models.Instance, error) {
+	if user == nil {
+		return nil, ErrUserNotAuthenticated
+	}
+
+	template, err := s.GetByShareToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = template.Name
+	}
+
+	instance, err := s.instanceService.CopyTemplate(ctx, user, template, name)
+	if err != nil {
+		return nil, fmt.Errorf("copying template: %w", err)
+	}
+	return instance, nil
+}
+
+// ownTemplate finds a template and checks the user published it.
+func (s *templateService) ownTemplate(ctx context.Context, user *models.User, templateID string) (*models.Instance, error) {
+	if user == nil {
+		return nil, ErrUserNotAuthenticated
+	}
+	template, err := s.instanceRepo.GetByID(ctx, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("finding template: %w", err)
+	}
+	if template.UserID != user.ID || !template.IsTemplate {
+		return nil, ErrPermissionDenied
+	}
+	return template, nil
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("generating token: %w", err)
+	}
+	return base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(b), nil
+}
@@ -0,0 +1,219 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/internal/events"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTemplateService(t *testing.T) (services.TemplateService, services.InstanceService, services.LocationService, services.UserService, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+
+	auditRepo := repositories.NewAuditRepository(dbc)
+	blockStateRepo := repositories.NewBlockStateRepository(dbc)
+	blockRepo := repositories.NewBlockRepository(dbc, blockStateRepo)
+	bonusRepo := repositories.NewBonusRepository(dbc)
+	checkInRepo := repositories.NewCheckInRepository(dbc)
+	clueRepo := repositories.NewClueRepository(dbc)
+	hintRepo := repositories.NewHintRepository(dbc)
+	instanceRepo := repositories.NewInstanceRepository(dbc)
+	instanceSettingsRepo := repositories.NewInstanceSettingsRepository(dbc)
+	locationRepo := repositories.NewLocationRepository(dbc)
+	markerRepo := repositories.NewMarkerRepository(dbc)
+	pointsRepo := repositories.NewPointsRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+	userRepo := repositories.NewUserRepository(dbc)
+
+	locationService := services.NewLocationService(transactor, clueRepo, hintRepo, locationRepo, markerRepo, blockRepo, auditRepo)
+	teamService := services.NewTeamService(transactor, events.NewBroker(), teamRepo, checkInRepo, blockStateRepo, locationRepo, bonusRepo, pointsRepo, auditRepo)
+	userService := services.NewUserService(transactor, userRepo, instanceRepo)
+	memberRepo := repositories.NewInstanceMemberRepository(dbc)
+	orgRepo := repositories.NewOrganisationRepository(dbc)
+	collaboratorService := services.NewCollaboratorService(&mockEmailService{}, instanceRepo, locationRepo, memberRepo, orgRepo, userRepo)
+	instanceService := services.NewInstanceService(
+		transactor,
+		locationService, userService, teamService, collaboratorService, instanceRepo, instanceSettingsRepo, auditRepo,
+		memberRepo, orgRepo,
+	)
+	templateService := services.NewTemplateService(transactor, instanceService, auditRepo, instanceRepo)
+
+	return templateService, instanceService, locationService, userService, cleanup
+}
+
+// createTemplateUser creates a user who can publish and clone templates.
+func createTemplateUser(t *testing.T, userService services.UserService, email string) *models.User {
+	t.Helper()
+	user := &models.User{Email: email, Password: "password"}
+	require.NoError(t, userService.CreateUser(context.Background(), user, "password"))
+	return user
+}
+
+// createTemplateGame creates an instance for the user with the given number of locations.
+func createTemplateGame(t *testing.T, instanceService services.InstanceService, locationService services.LocationService, user *models.User, name string, locations int) *models.Instance {
+	t.Helper()
+	ctx := context.Background()
+	instance, err := instanceService.CreateInstance(ctx, name, user)
+	require.NoError(t, err)
+	for i := 0; i < locations; i++ {
+		_, err := locationService.CreateLocation(ctx, instance.ID, "Location", -45.86, 170.51, 10)
+		require.NoError(t, err)
+	}
+	return instance
+}
+
+func TestTemplateService_PublishAndClone(t *testing.T) {
+	svc, instanceService, locationService, userService, cleanup := setupTemplateService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	owner := createTemplateUser(t, userService, "owner@example.com")
+	other := createTemplateUser(t, userService, "other@example.com")
+	game := createTemplateGame(t, instanceService, locationService, owner, "Campus hunt", 2)
+
+	t.Run("Only the owner can publish", func(t *testing.T) {
+		_, err := svc.Publish(ctx, other, game.ID, services.TemplateData{Name: "Stolen"})
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		_, err = svc.Publish(ctx, owner, game.ID, services.TemplateData{})
+		assert.Error(t, err, "a name is required")
+	})
+
+	template, err := svc.Publish(ctx, owner, game.ID, services.TemplateData{
+		Name:        "Campus hunt template",
+		Description: "Orientation week",
+		Tags:        []string{"Campus", " orientation "},
+		IsPublic:    true,
+	})
+	require.NoError(t, err)
+	assert.True(t, template.IsTemplate)
+	assert.NotEmpty(t, template.ShareToken)
+	assert.Equal(t, "campus,orientation", template.Tags)
+
+	t.Run("Templates are read-only", func(t *testing.T) {
+		_, err := instanceService.SwitchInstance(ctx, owner, template.ID)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		_, err = svc.Publish(ctx, owner, template.ID, services.TemplateData{Name: "Copy"})
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	})
+
+	t.Run("Templates are listed separately", func(t *testing.T) {
+		ids, err := instanceService.FindInstanceIDsForUser(ctx, owner.ID)
+		require.NoError(t, err)
+		assert.Contains(t, ids, template.ID)
+		assert.Len(t, ids, 2, "publishing leaves no other copy behind")
+
+		published, err := svc.FindByUser(ctx, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, published, 1)
+		assert.Len(t, published[0].Locations, 2)
+	})
+
+	t.Run("Share link shows the template", func(t *testing.T) {
+		found, err := svc.GetByShareToken(ctx, template.ShareToken)
+		require.NoError(t, err)
+		assert.Equal(t, template.ID, found.ID)
+		assert.Len(t, found.Locations, 2)
+
+		_, err = svc.GetByShareToken(ctx, "missing")
+		assert.Error(t, err)
+	})
+
+	t.Run("Templates are only copied by share link", func(t *testing.T) {
+		_, err := instanceService.DuplicateInstance(ctx, other, template.ID, "Copy")
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+
+		_, err = instanceService.CopyTemplate(ctx, other, game, "Copy")
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	})
+
+	t.Run("Clone copies into another account", func(t *testing.T) {
+		clone, err := svc.Clone(ctx, other, template.ShareToken, "")
+		require.NoError(t, err)
+		assert.Equal(t, other.ID, clone.UserID)
+		assert.Equal(t, "Campus hunt template", clone.Name)
+		assert.False(t, clone.IsTemplate)
+
+		locations, err := locationService.FindByInstance(ctx, clone.ID)
+		require.NoError(t, err)
+		assert.Len(t, locations, 2)
+
+		_, err = instanceService.SwitchInstance(ctx, other, clone.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Only the owner can change a template", func(t *testing.T) {
+		_, err := svc.Update(ctx, other, template.ID, services.TemplateData{Name: "Mine"})
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+		assert.ErrorIs(t, svc.Delete(ctx, other, template.ID), services.ErrPermissionDenied)
+		assert.ErrorIs(t, svc.Delete(ctx, owner, game.ID), services.ErrPermissionDenied)
+
+		updated, err := svc.Update(ctx, owner, template.ID, services.TemplateData{Name: "Renamed"})
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", updated.Name)
+		assert.False(t, updated.IsPublic)
+	})
+
+	t.Run("Delete removes the share link", func(t *testing.T) {
+		require.NoError(t, svc.Delete(ctx, owner, template.ID))
+		_, err := svc.GetByShareToken(ctx, template.ShareToken)
+		assert.Error(t, err)
+	})
+}
+
+func TestTemplateService_Gallery(t *testing.T) {
+	svc, instanceService, locationService, userService, cleanup := setupTemplateService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	owner := createTemplateUser(t, userService, "gallery@example.com")
+	publish := func(name string, locations int, tags []string, public bool) {
+		game := createTemplateGame(t, instanceService, locationService, owner, name, locations)
+		_, err := svc.Publish(ctx, owner, game.ID, services.TemplateData{
+			Name:        name,
+			Description: "A game about " + name,
+			Tags:        tags,
+			IsPublic:    public,
+		})
+		require.NoError(t, err)
+	}
+	publish("Museum", 1, []string{"history"}, true)
+	publish("Botanic garden", 3, []string{"nature", "history"}, true)
+	publish("Private trail", 2, []string{"history"}, false)
+
+	tests := []struct {
+		name   string
+		filter services.TemplateFilter
+		want   []string
+	}{
+		{"Everything public", services.TemplateFilter{}, []string{"Museum", "Botanic garden"}},
+		{"By tag", services.TemplateFilter{Tag: "Nature"}, []string{"Botanic garden"}},
+		{"By search", services.TemplateFilter{Search: "museum"}, []string{"Museum"}},
+		{"By description", services.TemplateFilter{Search: "about botanic"}, []string{"Botanic garden"}},
+		{"Minimum locations", services.TemplateFilter{MinLocations: 2}, []string{"Botanic garden"}},
+		{"Maximum locations", services.TemplateFilter{MaxLocations: 2}, []string{"Museum"}},
+		{"No match", services.TemplateFilter{Tag: "sport"}, []string{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			templates, err := svc.Gallery(ctx, tc.filter)
+			require.NoError(t, err)
+			names := []string{}
+			for _, template := range templates {
+				names = append(names, template.Name)
+			}
+			assert.ElementsMatch(t, tc.want, names)
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/nathanhollows/Rapua/v3/models"
@@ -36,38 +41,94 @@ type UploadMetadata struct {
 // UploadStorage is an interface for storing files.
 type UploadStorage interface {
 	Upload(ctx context.Context, file multipart.File, filename string) (map[string]string, string, error)
+	Open(ctx context.Context, url string) (io.ReadCloser, error)
 	Type() string
 }
 
+// allowedMediaType is a file type that may be uploaded.
+type allowedMediaType struct {
+	mediaType models.MediaType
+	extension string
+}
+
+// allowedMediaTypes are the file types that may be uploaded, by the content
+// type http.DetectContentType reports for them.
+var allowedMediaTypes = map[string]allowedMediaType{
+	// Images
+	"image/jpeg": {models.MediaTypeImage, ".jpg"},
+	"image/png":  {models.MediaTypeImage, ".png"},
+	"image/gif":  {models.MediaTypeImage, ".gif"},
+	"image/webp": {models.MediaTypeImage, ".webp"},
+	// Videos
+	"video/mp4":  {models.MediaTypeVideo, ".mp4"},
+	"video/webm": {models.MediaTypeVideo, ".webm"},
+}
+
 // UploadFile uploads a file and saves metadata to the database.
+// The file type is detected from its contents rather than trusted from the client.
 func (s *UploadService) UploadFile(ctx context.Context, file multipart.File, fileHeader *multipart.FileHeader, data UploadMetadata) (*models.Upload, error) {
 	if fileHeader == nil {
 		return nil, errors.New("file header is nil")
 	}
+	if file == nil {
+		return nil, errors.New("file is nil")
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	fileType, filename, err := detectMediaType(head[:n], fileHeader.Filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("rewinding file: %w", err)
+	}
+
+	return s.store(ctx, file, filename, fileType, data)
+}
+
+// detectMediaType checks the type of a file from its first bytes.
+// It returns the media type and the filename with the extension for that type,
+// so a file can never be stored as something the browser would run.
+func detectMediaType(head []byte, filename string) (models.MediaType, string, error) {
+	contentType := http.DetectContentType(head)
+	allowed, ok := allowedMediaTypes[contentType]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported file type: %s", contentType)
+	}
+	name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		name = "upload"
+	}
+	return allowed.mediaType, name + allowed.extension, nil
+}
 
-	// Validate file type
-	var fileType models.MediaType
-	allowedMimeTypes := map[string]models.MediaType{
-		// Images
-		"image/jpeg": models.MediaTypeImage,
-		"image/png":  models.MediaTypeImage,
-		"image/gif":  models.MediaTypeImage,
-		"image/webp": models.MediaTypeImage,
-		// Videos
-		"video/mp4":       models.MediaTypeVideo,
-		"video/quicktime": models.MediaTypeVideo,
-		"video/webm":      models.MediaTypeVideo,
+// StoreFile saves a file that did not come from a form, such as one from an
+// imported bundle, and saves its metadata to the database.
+// As with UploadFile, the file type is detected from its contents.
+func (s *UploadService) StoreFile(ctx context.Context, content []byte, filename string, data UploadMetadata) (*models.Upload, error) {
+	fileType, filename, err := detectMediaType(content[:min(len(content), 512)], filename)
+	if err != nil {
+		return nil, err
 	}
+	return s.store(ctx, memoryFile{bytes.NewReader(content)}, filename, fileType, data)
+}
 
-	contentType := fileHeader.Header.Get("Content-Type")
-	if val, ok := allowedMimeTypes[contentType]; ok {
-		fileType = val
-	} else {
-		return nil, fmt.Errorf("unsupported file type: %s", contentType)
+// Open returns the contents of an uploaded file.
+func (s *UploadService) Open(ctx context.Context, upload *models.Upload) (io.ReadCloser, error) {
+	if upload.Storage != s.storage.Type() {
+		return nil, fmt.Errorf("upload is stored in %s, not %s", upload.Storage, s.storage.Type())
 	}
+	return s.storage.Open(ctx, upload.OriginalURL)
+}
 
+// store uploads a file and saves its metadata to the database.
+func (s *UploadService) store(ctx context.Context, file multipart.File, filename string, fileType models.MediaType, data UploadMetadata) (*models.Upload, error) {
 	// Upload file to storage (local or S3)
-	links, deleteData, err := s.storage.Upload(ctx, file, fileHeader.Filename)
+	links, deleteData, err := s.storage.Upload(ctx, file, filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to store file: %w", err)
 	}
@@ -104,3 +165,12 @@ func (s *UploadService) Search(ctx context.Context, filters map[string]string) (
 	}
 	return s.repo.SearchByCriteria(ctx, filters)
 }
+
+// memoryFile lets file contents held in memory be stored like a form upload.
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
@@ -3,10 +3,13 @@ package services_test
 import (
 	"context"
 	"errors"
+	"io"
 	"mime/multipart"
+	"strings"
 	"testing"
 
 	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
 	"github.com/nathanhollows/Rapua/v3/repositories"
 	"github.com/stretchr/testify/assert"
 )
@@ -20,10 +23,23 @@ func (m *mockUploadStorage) Upload(ctx context.Context, file multipart.File, fil
 	return map[string]string{"original": "https://cdn.example.com/" + filename}, "delete-token", nil
 }
 
+func (m *mockUploadStorage) Open(ctx context.Context, url string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("\xff\xd8\xff\xe0 contents of " + url)), nil
+}
+
 func (m *mockUploadStorage) Type() string {
 	return "mock"
 }
 
+// testFile is an in-memory file for uploading.
+type testFile struct {
+	*strings.Reader
+}
+
+func (testFile) Close() error {
+	return nil
+}
+
 func setupUploadService(t *testing.T) (services.UploadService, func()) {
 	t.Helper()
 	dbc, cleanup := setupDB(t)
@@ -38,28 +54,48 @@ func TestUploadService_UploadFile(t *testing.T) {
 	svc, cleanup := setupUploadService(t)
 	defer cleanup()
 
+	jpeg := "\xff\xd8\xff\xe0\x00\x10JFIF\x00"
 	tests := []struct {
 		name      string
 		filename  string
 		fileType  string
+		content   string
+		stored    string
 		expectErr bool
 	}{
 		{
-			name:      "Valid image upload",
-			filename:  "test.jpg",
-			fileType:  "image/jpeg",
-			expectErr: false,
+			name:     "Valid image upload",
+			filename: "test.jpg",
+			fileType: "image/jpeg",
+			content:  jpeg,
+			stored:   "test.jpg",
 		},
 		{
 			name:      "Invalid file type",
 			filename:  "test.exe",
 			fileType:  "application/x-msdownload",
+			content:   "MZ\x90\x00",
+			expectErr: true,
+		},
+		{
+			name:      "HTML posing as an image",
+			filename:  "test.jpg",
+			fileType:  "image/jpeg",
+			content:   "<html><script>alert(1)</script></html>",
 			expectErr: true,
 		},
+		{
+			name:     "Extension follows the contents",
+			filename: "photo.html",
+			fileType: "text/html",
+			content:  jpeg,
+			stored:   "photo.jpg",
+		},
 		{
 			name:      "Storage error",
 			filename:  "error.jpg",
 			fileType:  "image/jpeg",
+			content:   jpeg,
 			expectErr: true,
 		},
 	}
@@ -67,7 +103,8 @@ func TestUploadService_UploadFile(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			fileHeader := &multipart.FileHeader{Filename: tt.filename, Header: map[string][]string{"Content-Type": {tt.fileType}}}
-			result, err := svc.UploadFile(context.Background(), nil, fileHeader, services.UploadMetadata{})
+			file := testFile{strings.NewReader(tt.content)}
+			result, err := svc.UploadFile(context.Background(), file, fileHeader, services.UploadMetadata{})
 
 			if tt.expectErr {
 				assert.Error(t, err)
@@ -75,7 +112,8 @@ func TestUploadService_UploadFile(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
-				assert.Equal(t, "https://cdn.example.com/"+tt.filename, result.OriginalURL)
+				assert.Equal(t, "https://cdn.example.com/"+tt.stored, result.OriginalURL)
+				assert.Equal(t, models.MediaTypeImage, result.Type)
 			}
 		})
 	}
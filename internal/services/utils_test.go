@@ -7,6 +7,9 @@ import (
 
 	"github.com/nathanhollows/Rapua/v3/db"
 	"github.com/nathanhollows/Rapua/v3/internal/migrations"
+	"github.com/nathanhollows/Rapua/v3/internal/services"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/stretchr/testify/require"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/migrate"
 )
@@ -42,3 +45,11 @@ func setupDB(t *testing.T) (*bun.DB, func()) {
 		db.Close()
 	}
 }
+
+// createTestUser creates a user named after their email address.
+func createTestUser(t *testing.T, userService services.UserService, email string) *models.User {
+	t.Helper()
+	user := &models.User{Name: email, Email: email, Password: "password"}
+	require.NoError(t, userService.CreateUser(context.Background(), user, "password"))
+	return user
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -72,6 +74,32 @@ func (s *LocalStorage) Upload(ctx context.Context, file multipart.File, filename
 	return map[string]string{"original": filePath}, "", nil
 }
 
+// Open opens a file saved by Upload, given its URL.
+// Only files within the storage's base path can be opened.
+func (s *LocalStorage) Open(ctx context.Context, fileURL string) (io.ReadCloser, error) {
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing URL: %w", err)
+	}
+
+	// The URL path mirrors where the file was saved, after any site prefix
+	base := filepath.ToSlash(filepath.Clean(s.basePath))
+	i := strings.Index(u.Path, "/"+base+"/")
+	if i == -1 {
+		return nil, fmt.Errorf("file is not in local storage: %s", fileURL)
+	}
+	filePath := filepath.Clean(filepath.FromSlash(u.Path[i+1:]))
+	if !strings.HasPrefix(filePath, filepath.Clean(s.basePath)+string(filepath.Separator)) {
+		return nil, fmt.Errorf("file is not in local storage: %s", fileURL)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	return file, nil
+}
+
 // Type returns the storage type.
 func (s *LocalStorage) Type() string {
 	return "local"
@@ -2,6 +2,7 @@ package storage_test
 
 import (
 	"context"
+	"io"
 	"mime/multipart"
 	"os"
 	"path/filepath"
@@ -136,3 +137,28 @@ func TestLocalStorage_Type(t *testing.T) {
 	storage := storage.NewLocalStorage("./test_uploads")
 	assert.Equal(t, "local", storage.Type())
 }
+
+func TestLocalStorage_Open(t *testing.T) {
+	basePath := "./test_uploads"
+	_ = os.RemoveAll(basePath)
+	storage := storage.NewLocalStorage(basePath)
+
+	paths, _, err := storage.Upload(context.Background(), mockMultipartFile("open me"), "open.txt")
+	assert.NoError(t, err)
+
+	file, err := storage.Open(context.Background(), paths["original"])
+	if assert.NoError(t, err) {
+		content, err := io.ReadAll(file)
+		file.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, "open me", string(content))
+	}
+
+	_, err = storage.Open(context.Background(), "https://example.com/test_uploads/../go.mod")
+	assert.Error(t, err, "files outside the base path should not be opened")
+
+	_, err = storage.Open(context.Background(), "https://example.com/elsewhere/open.txt")
+	assert.Error(t, err)
+
+	_ = os.RemoveAll(basePath)
+}
@@ -1,6 +1,12 @@
 package templates
 
-import "strconv"
+import (
+	"strconv"
+	"time"
+
+	"github.com/nathanhollows/Rapua/v3/helpers"
+	"github.com/nathanhollows/Rapua/v3/models"
+)
 
 func intToString(i int) string {
 	return strconv.Itoa(i)
@@ -19,3 +25,110 @@ func filter[T any](items []T, fn func(T) bool) []T {
 	}
 	return filtered
 }
+
+// actorLabel names who made a change in the audit log.
+func actorLabel(actorType models.ActorType, name, id string) string {
+	if name != "" {
+		return name
+	}
+	if actorType == models.ActorFacilitator {
+		return "Facilitator"
+	}
+	return id
+}
+
+// templateURL is the share link for a template.
+func templateURL(token string) string {
+	return helpers.URL("/admin/templates/" + token)
+}
+
+// playerName finds the name of the player with the given ID.
+// Anonymous check-ins and submissions have no player.
+func playerName(players []models.PlayerContribution, playerID string) string {
+	if playerID == "" {
+		return ""
+	}
+	for _, contribution := range players {
+		if contribution.Player.ID == playerID {
+			return contribution.Player.Name
+		}
+	}
+	return ""
+}
+
+// tokenLocationNames names the locations a facilitator link grants access to.
+func tokenLocationNames(token models.FacilitatorToken, locations []models.Location) []string {
+	var names []string
+	for _, location := range locations {
+		for _, id := range token.Locations {
+			if location.ID == id {
+				names = append(names, location.Name)
+			}
+		}
+	}
+	return names
+}
+
+// formatTokenTime formats a facilitator link time, or "Never" if it is unset.
+func formatTokenTime(t time.Time) string {
+	if t.IsZero() {
+		return "Never"
+	}
+	return t.UTC().Format("2006-01-02 15:04")
+}
+
+// switchableInstances lists the user's own instances followed by those shared
+// with them and the rest of their organisation's.
+func switchableInstances(user models.User) []models.Instance {
+	instances := make([]models.Instance, 0, len(user.Instances)+len(user.SharedInstances)+len(user.OrganisationInstances))
+	instances = append(instances, user.Instances...)
+	instances = append(instances, user.SharedInstances...)
+	return append(instances, user.OrganisationInstances...)
+}
+
+// memberEmail returns an organisation member's email.
+func memberEmail(member models.OrganisationMember) string {
+	if member.User == nil {
+		return "Unknown user"
+	}
+	return member.User.Email
+}
+
+// hasMember reports whether a user is one of the organisation's members.
+func hasMember(members []models.OrganisationMember, userID string) bool {
+	for _, member := range members {
+		if member.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// instanceOwner returns the email of an organisation instance's owner.
+func instanceOwner(members []models.OrganisationMember, instance models.Instance) string {
+	for _, member := range members {
+		if member.UserID == instance.UserID {
+			return memberEmail(member)
+		}
+	}
+	return "Former member"
+}
+
+// inCurrentInstance reports whether a marker is used by the user's current
+// instance.
+func inCurrentInstance(user models.User, code string) bool {
+	for _, location := range user.CurrentInstance.Locations {
+		if location.MarkerID == code {
+			return true
+		}
+	}
+	return false
+}
+
+// markerCoord formats a coordinate for a form, leaving unmapped markers blank.
+func markerCoord(coord float64, mapped bool) string {
+	if !mapped {
+		return ""
+	}
+	return strconv.FormatFloat(coord, 'f', -1, 64)
+}
@@ -18,7 +18,7 @@ func TestBlocks_MethodsExist(t *testing.T) {
 			t.Errorf("Block %s is missing a RenderAdminEdit view", block.GetName())
 		}
 
-		template = templates.RenderAdminBlock(instanceSettings, block, true)
+		template = templates.RenderAdminBlock(instanceSettings, block, nil, true)
 		if template == nil {
 			t.Errorf("Block %s is missing a RenderAdminBlock view", block.GetName())
 		}
@@ -0,0 +1,264 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AuditAction describes a change recorded in the audit log.
+type AuditAction string
+
+const (
+	AuditBlockCompleted    AuditAction = "block.completed"
+	AuditBlockCreated      AuditAction = "block.created"
+	AuditBlockUpdated      AuditAction = "block.updated"
+	AuditBlockReordered    AuditAction = "block.reordered"
+	AuditBlockDeleted      AuditAction = "block.deleted"
+	AuditBlockReviewed     AuditAction = "block.reviewed"
+	AuditCheckInAdded      AuditAction = "check_in.added"
+	AuditCheckInRevoked    AuditAction = "check_in.revoked"
+	AuditCheckOutAdded     AuditAction = "check_out.added"
+	AuditCheckOutCleared   AuditAction = "check_out.cleared"
+	AuditGameStarted       AuditAction = "game.started"
+	AuditGameStopped       AuditAction = "game.stopped"
+	AuditGameScheduled     AuditAction = "game.scheduled"
+	AuditInstanceCreated   AuditAction = "instance.created"
+	AuditInstanceCopied    AuditAction = "instance.duplicated"
+	AuditInstanceDeleted   AuditAction = "instance.deleted"
+	AuditInstanceImported  AuditAction = "instance.imported"
+	AuditInstancePublished AuditAction = "instance.published"
+	AuditInstanceMoved     AuditAction = "instance.moved"
+	AuditInstanceTransfer  AuditAction = "instance.transferred"
+	AuditLocationCreated   AuditAction = "location.created"
+	AuditLocationUpdated   AuditAction = "location.updated"
+	AuditLocationOrdered   AuditAction = "location.reordered"
+	AuditLocationDeleted   AuditAction = "location.deleted"
+	AuditPointsAdjusted    AuditAction = "points.adjusted"
+	AuditPointsUndone      AuditAction = "points.undone"
+	AuditSettingsUpdated   AuditAction = "settings.updated"
+	AuditTeamsAdded        AuditAction = "team.added"
+	AuditTeamUpdated       AuditAction = "team.updated"
+	AuditTeamTimeAdded     AuditAction = "team.time_extended"
+	AuditTeamsReset        AuditAction = "team.reset"
+	AuditTeamDeleted       AuditAction = "team.deleted"
+)
+
+// ActorType identifies who made a change.
+type ActorType string
+
+const (
+	ActorUser        ActorType = "user"
+	ActorFacilitator ActorType = "facilitator"
+)
+
+// Actor is the person making a change.
+// Facilitators are identified by their login link rather than an account.
+type Actor struct {
+	Type       ActorType
+	ID         string
+	Name       string
+	InstanceID string
+}
+
+// AuditEvent records a change made to a game by an admin or facilitator.
+// EntityType and EntityID identify what was changed, and TeamCode is set when
+// the change affected a team. Before and After hold JSON snapshots of the
+// entity so the change can be shown as a diff.
+type AuditEvent struct {
+	baseModel
+
+	ID         string          `bun:"id,pk,type:varchar(36)"`
+	InstanceID string          `bun:"instance_id,notnull"`
+	ActorID    string          `bun:"actor_id,notnull"`
+	ActorType  ActorType       `bun:"actor_type,notnull,default:'user'"`
+	ActorName  string          `bun:"actor_name,nullzero"`
+	Action     AuditAction     `bun:"action,notnull"`
+	EntityType string          `bun:"entity_type,notnull"`
+	EntityID   string          `bun:"entity_id,notnull"`
+	TeamCode   string          `bun:"team_code,nullzero"`
+	Reason     string          `bun:"reason,type:text"`
+	Before     json.RawMessage `bun:"snapshot_before,type:text,nullzero"`
+	After      json.RawMessage `bun:"snapshot_after,type:text,nullzero"`
+}
+
+// AuditFilter narrows down the events returned from the audit log.
+// Zero values are ignored.
+type AuditFilter struct {
+	ActorID string
+	Since   time.Time
+	Until   time.Time
+}
+
+// AuditChange is a single field that differs between the before and after
+// snapshots of an event.
+type AuditChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// String returns a readable description of the action.
+func (a AuditAction) String() string {
+	switch a {
+	case AuditBlockCompleted:
+		return "Marked block complete"
+	case AuditBlockCreated:
+		return "Created block"
+	case AuditBlockUpdated:
+		return "Updated block"
+	case AuditBlockReordered:
+		return "Reordered blocks"
+	case AuditBlockDeleted:
+		return "Deleted block"
+	case AuditBlockReviewed:
+		return "Reviewed submission"
+	case AuditCheckInAdded:
+		return "Checked team in"
+	case AuditCheckInRevoked:
+		return "Revoked check-in"
+	case AuditCheckOutAdded:
+		return "Checked team out"
+	case AuditCheckOutCleared:
+		return "Cleared check-out"
+	case AuditGameStarted:
+		return "Started game"
+	case AuditGameStopped:
+		return "Stopped game"
+	case AuditGameScheduled:
+		return "Scheduled game"
+	case AuditInstanceCreated:
+		return "Created game"
+	case AuditInstanceCopied:
+		return "Duplicated game"
+	case AuditInstanceDeleted:
+		return "Deleted game"
+	case AuditInstanceImported:
+		return "Imported game"
+	case AuditInstancePublished:
+		return "Published template"
+	case AuditInstanceMoved:
+		return "Moved game"
+	case AuditInstanceTransfer:
+		return "Transferred game"
+	case AuditLocationCreated:
+		return "Created location"
+	case AuditLocationUpdated:
+		return "Updated location"
+	case AuditLocationOrdered:
+		return "Reordered locations"
+	case AuditLocationDeleted:
+		return "Deleted location"
+	case AuditPointsAdjusted:
+		return "Adjusted points"
+	case AuditPointsUndone:
+		return "Undid points change"
+	case AuditSettingsUpdated:
+		return "Updated settings"
+	case AuditTeamsAdded:
+		return "Added teams"
+	case AuditTeamUpdated:
+		return "Updated team"
+	case AuditTeamTimeAdded:
+		return "Granted extra time"
+	case AuditTeamsReset:
+		return "Reset teams"
+	case AuditTeamDeleted:
+		return "Deleted team"
+	}
+	return string(a)
+}
+
+// SetChange stores snapshots of the entity before and after the change.
+// Either side may be nil, such as when an entity is created or deleted.
+func (e *AuditEvent) SetChange(before, after any) error {
+	var err error
+	e.Before, err = marshalSnapshot(before)
+	if err != nil {
+		return fmt.Errorf("marshalling before: %w", err)
+	}
+	e.After, err = marshalSnapshot(after)
+	if err != nil {
+		return fmt.Errorf("marshalling after: %w", err)
+	}
+	return nil
+}
+
+// Changes returns the top-level fields that differ between the before and
+// after snapshots, sorted by field name.
+// When only one snapshot is set, empty values, nested objects and lists are
+// left out to keep the summary short.
+func (e *AuditEvent) Changes() []AuditChange {
+	before := unmarshalSnapshot(e.Before)
+	after := unmarshalSnapshot(e.After)
+	oneSided := before == nil || after == nil
+
+	fields := map[string]bool{}
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+
+	changes := []AuditChange{}
+	for field := range fields {
+		if field == "CreatedAt" || field == "UpdatedAt" {
+			continue
+		}
+		b, a := before[field], after[field]
+		if bytes.Equal(b, a) {
+			continue
+		}
+		if oneSided && (isNested(b) || isNested(a) || snapshotValue(b)+snapshotValue(a) == "") {
+			continue
+		}
+		changes = append(changes, AuditChange{
+			Field:  field,
+			Before: snapshotValue(b),
+			After:  snapshotValue(a),
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+func marshalSnapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// unmarshalSnapshot splits a snapshot into its top-level fields.
+// Snapshots that are not objects are stored under the "value" field.
+func unmarshalSnapshot(data json.RawMessage) map[string]json.RawMessage {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return map[string]json.RawMessage{"value": data}
+	}
+	return fields
+}
+
+func isNested(v json.RawMessage) bool {
+	return len(v) > 0 && (v[0] == '{' || v[0] == '[')
+}
+
+// snapshotValue formats a JSON value for display.
+func snapshotValue(v json.RawMessage) string {
+	if len(v) == 0 || string(v) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(v, &s); err == nil {
+		return s
+	}
+	return string(v)
+}
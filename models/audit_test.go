@@ -0,0 +1,69 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAuditEvent_Changes(t *testing.T) {
+	type snapshot struct {
+		Name   string
+		Points int
+		Tags   []string
+	}
+	tests := []struct {
+		name   string
+		before any
+		after  any
+		want   []AuditChange
+	}{
+		{
+			name:   "Updated fields",
+			before: snapshot{Name: "Library", Points: 10},
+			after:  snapshot{Name: "Old Library", Points: 10},
+			want:   []AuditChange{{Field: "Name", Before: "Library", After: "Old Library"}},
+		},
+		{
+			name:   "Created",
+			before: nil,
+			after:  snapshot{Name: "Library", Points: 10, Tags: []string{"a"}},
+			want: []AuditChange{
+				{Field: "Name", After: "Library"},
+				{Field: "Points", After: "10"},
+			},
+		},
+		{
+			name:   "Deleted",
+			before: snapshot{Name: "Library"},
+			after:  nil,
+			want: []AuditChange{
+				{Field: "Name", Before: "Library"},
+				{Field: "Points", Before: "0"},
+			},
+		},
+		{
+			name:   "Plain values",
+			before: 10,
+			after:  15,
+			want:   []AuditChange{{Field: "value", Before: "10", After: "15"}},
+		},
+		{
+			name:   "No change",
+			before: snapshot{Name: "Library"},
+			after:  snapshot{Name: "Library"},
+			want:   []AuditChange{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &AuditEvent{}
+			if err := event.SetChange(tt.before, tt.after); err != nil {
+				t.Fatalf("AuditEvent.SetChange() error = %v", err)
+			}
+			if got := event.Changes(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AuditEvent.Changes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
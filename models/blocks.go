@@ -12,6 +12,7 @@ type Block struct {
 	Ordering           int             `bun:"ordering,type:int"`
 	Points             int             `bun:"points,type:int"`
 	ValidationRequired bool            `bun:"validation_required,type:bool"`
+	ReviewRequired     bool            `bun:"review_required,type:bool"`
 }
 
 type TeamBlockState struct {
@@ -21,4 +22,7 @@ type TeamBlockState struct {
 	IsComplete    bool            `bun:"is_complete,type:bool"`
 	PointsAwarded int             `bun:"points_awarded,type:int"`
 	PlayerData    json.RawMessage `bun:"player_data,type:jsonb"`
+	ReviewStatus  string          `bun:"review_status,nullzero"`
+	ReviewComment string          `bun:"review_comment,nullzero"`
+	PlayerID      string          `bun:"player_id,nullzero"`
 }
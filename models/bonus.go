@@ -0,0 +1,86 @@
+package models
+
+import "errors"
+
+type BonusRuleType int
+
+type BonusRuleTypes []BonusRuleType
+
+const (
+	FirstToArriveBonus BonusRuleType = iota
+	QuickFinishBonus
+	AllBlocksBonus
+	StreakBonus
+)
+
+// BonusRule awards extra points to teams that meet a condition.
+// Threshold configures the condition and its meaning depends on the type:
+//   - FirstToArriveBonus: how many teams to reward at each location
+//   - QuickFinishBonus: the time limit in minutes to visit every location
+//   - AllBlocksBonus: unused
+//   - StreakBonus: how many locations in a row without a hint
+type BonusRule struct {
+	baseModel
+
+	ID         string        `bun:"id,pk,type:varchar(36)"`
+	InstanceID string        `bun:"instance_id,notnull"`
+	Type       BonusRuleType `bun:"type,type:int"`
+	Points     int           `bun:"points,type:int"`
+	Threshold  int           `bun:"threshold,type:int"`
+}
+
+// BonusAward records bonus points awarded to a team by a rule.
+// LocationID is set for rules that can be earned once per location.
+type BonusAward struct {
+	baseModel
+
+	ID         string `bun:"id,pk,type:varchar(36)"`
+	InstanceID string `bun:"instance_id,notnull"`
+	TeamCode   string `bun:"team_code,notnull"`
+	RuleID     string `bun:"rule_id,notnull"`
+	LocationID string `bun:"location_id,nullzero"`
+	Points     int    `bun:"points,type:int"`
+	Reason     string `bun:"reason,type:text"`
+}
+
+// GetBonusRuleTypes returns a list of bonus rule types.
+func GetBonusRuleTypes() BonusRuleTypes {
+	return []BonusRuleType{FirstToArriveBonus, QuickFinishBonus, AllBlocksBonus, StreakBonus}
+}
+
+// String returns the string representation of the BonusRuleType.
+func (b BonusRuleType) String() string {
+	return [...]string{"First to Arrive", "Quick Finish", "All Activities", "Streak"}[b]
+}
+
+// Description returns the description of the BonusRuleType.
+func (b BonusRuleType) Description() string {
+	return [...]string{
+		"Awarded to the first teams to check in at each location.",
+		"Awarded to teams that visit every location within a time limit.",
+		"Awarded each time a team completes every activity at a location.",
+		"Awarded each time a team visits a number of locations in a row without revealing a hint.",
+	}[b]
+}
+
+// ThresholdLabel describes the threshold for the BonusRuleType.
+// An empty label means the rule has no threshold.
+func (b BonusRuleType) ThresholdLabel() string {
+	return [...]string{"Teams rewarded", "Minutes", "", "Locations in a row"}[b]
+}
+
+// Parse BonusRuleType.
+func ParseBonusRuleType(s string) (BonusRuleType, error) {
+	switch s {
+	case "First to Arrive":
+		return FirstToArriveBonus, nil
+	case "Quick Finish":
+		return QuickFinishBonus, nil
+	case "All Activities":
+		return AllBlocksBonus, nil
+	case "Streak":
+		return StreakBonus, nil
+	default:
+		return 0, errors.New("invalid BonusRuleType")
+	}
+}
@@ -15,6 +15,8 @@ type CheckIn struct {
 	MustCheckOut    bool      `bun:"must_check_out"`
 	Points          int       `bun:"points,"`
 	BlocksCompleted bool      `bun:"blocks_completed,type:int"`
+	PlayerID        string    `bun:"player_id,nullzero"`
 
 	Location Location `bun:"rel:has-one,join:location_id=id"`
+	Player   Player   `bun:"rel:has-one,join:player_id=id"`
 }
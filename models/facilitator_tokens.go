@@ -7,6 +7,9 @@ import (
 type FacilitatorToken struct {
 	Token      string    `bun:"token,pk"`
 	InstanceID string    `bun:"instance_id,notnull"`
+	Name       string    `bun:"name,type:varchar(255)"`
 	Locations  StrArray  `bun:"locations,type:text"`
 	ExpiresAt  time.Time `bun:"expires_at,type:datetime"`
+	CreatedAt  time.Time `bun:"created_at,type:datetime,nullzero"`
+	LastUsedAt time.Time `bun:"last_used_at,type:datetime,nullzero"`
 }
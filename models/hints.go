@@ -0,0 +1,41 @@
+package models
+
+// Hint is an optional tip that players may reveal for a cost.
+// Hints belong to a location, or to one of its blocks when BlockID is set.
+type Hint struct {
+	baseModel
+
+	ID         string `bun:"id,pk,type:varchar(36)"`
+	InstanceID string `bun:"instance_id,notnull"`
+	LocationID string `bun:"location_id,notnull"`
+	BlockID    string `bun:"block_id,nullzero"`
+	Order      int    `bun:"ordering,type:int"`
+	Content    string `bun:"content,type:text"`
+	Penalty    int    `bun:"penalty,type:int"`
+}
+
+// TeamHint records a hint revealed by a team.
+type TeamHint struct {
+	baseModel
+
+	TeamCode   string `bun:"team_code,pk,notnull"`
+	HintID     string `bun:"hint_id,pk,notnull"`
+	InstanceID string `bun:"instance_id,notnull"`
+	LocationID string `bun:"location_id,notnull"`
+	BlockID    string `bun:"block_id,nullzero"`
+	Penalty    int    `bun:"penalty,type:int"`
+
+	Hint     Hint     `bun:"rel:has-one,join:hint_id=id"`
+	Location Location `bun:"rel:has-one,join:location_id=id"`
+}
+
+// HintsFor returns the hints for a location, or for a block when blockID is set.
+func HintsFor(hints []Hint, blockID string) []Hint {
+	var filtered []Hint
+	for _, hint := range hints {
+		if hint.BlockID == blockID {
+			filtered = append(filtered, hint)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+)
+
+// InstanceRole is what a user may do in an instance.
+type InstanceRole string
+
+const (
+	// RoleOwner can do everything, including deleting the instance and
+	// managing collaborators
+	RoleOwner InstanceRole = "owner"
+	// RoleEditor can change the game content and run the game
+	RoleEditor InstanceRole = "editor"
+	// RoleViewer can only watch the game's activity
+	RoleViewer InstanceRole = "viewer"
+)
+
+// Permission is an action that is limited to some roles.
+type Permission int
+
+const (
+	// PermissionView allows watching the game's activity
+	PermissionView Permission = iota
+	// PermissionEdit allows changing the game content and running the game
+	PermissionEdit
+	// PermissionManage allows deleting the instance and managing collaborators
+	PermissionManage
+)
+
+// ParseInstanceRole returns the role with the given name.
+// Owners cannot be invited, so only editor and viewer are accepted.
+func ParseInstanceRole(role string) (InstanceRole, bool) {
+	switch InstanceRole(role) {
+	case RoleEditor, RoleViewer:
+		return InstanceRole(role), true
+	}
+	return "", false
+}
+
+// Can reports whether the role has the given permission.
+func (r InstanceRole) Can(permission Permission) bool {
+	switch r {
+	case RoleOwner:
+		return true
+	case RoleEditor:
+		return permission <= PermissionEdit
+	case RoleViewer:
+		return permission == PermissionView
+	}
+	return false
+}
+
+// String returns the role's display name.
+func (r InstanceRole) String() string {
+	switch r {
+	case RoleOwner:
+		return "Owner"
+	case RoleEditor:
+		return "Editor"
+	case RoleViewer:
+		return "Viewer"
+	}
+	return "None"
+}
+
+// InstanceMember gives another user access to an instance.
+// Invitations are members that have not been accepted yet.
+type InstanceMember struct {
+	baseModel
+
+	ID          string       `bun:"id,pk,type:varchar(36)"`
+	InstanceID  string       `bun:"instance_id,notnull"`
+	UserID      string       `bun:"user_id,nullzero,type:varchar(36)"`
+	Email       string       `bun:"email,notnull"`
+	Role        InstanceRole `bun:"role,type:varchar(16),notnull"`
+	InvitedBy   string       `bun:"invited_by,type:varchar(36)"`
+	InviteToken string       `bun:"invite_token,nullzero"`
+	AcceptedAt  time.Time    `bun:"accepted_at,nullzero"`
+
+	User     *User     `bun:"rel:belongs-to,join:user_id=id"`
+	Instance *Instance `bun:"rel:belongs-to,join:instance_id=id"`
+}
+
+// IsPending reports whether the invitation has not been accepted yet.
+func (m *InstanceMember) IsPending() bool {
+	return m.AcceptedAt.IsZero()
+}
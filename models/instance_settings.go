@@ -12,4 +12,11 @@ type InstanceSettings struct {
 	EnablePoints      bool             `bun:"enable_points,type:bool"`
 	EnableBonusPoints bool             `bun:"enable_bonus_points,type:bool"`
 	ShowLeaderboard   bool             `bun:"show_leaderboard,type:bool"`
+	CheckInRadius     int              `bun:"check_in_radius,type:int"`
+	TeamDuration      int              `bun:"team_duration,type:int"`
+
+	LeaderboardAnonymise bool   `bun:"leaderboard_anonymise,type:bool"`
+	LeaderboardFreeze    int    `bun:"leaderboard_freeze,type:int"`
+	LeaderboardFreezeTop int    `bun:"leaderboard_freeze_top,type:int"`
+	LeaderboardToken     string `bun:"leaderboard_token,nullzero"`
 }
@@ -13,11 +13,19 @@ type Instance struct {
 	ID                    string       `bun:"id,pk,type:varchar(36)"`
 	Name                  string       `bun:"name,type:varchar(255)"`
 	UserID                string       `bun:"user_id,type:varchar(36)"`
+	OrganisationID        string       `bun:"organisation_id,type:varchar(36)"`
 	StartTime             bun.NullTime `bun:"start_time,nullzero"`
 	EndTime               bun.NullTime `bun:"end_time,nullzero"`
 	Status                GameStatus   `bun:"-"`
 	IsQuickStartDismissed bool         `bun:"is_quick_start_dismissed,type:bool"`
 
+	// Templates are read-only copies of an instance that others can clone
+	IsTemplate  bool   `bun:"is_template,type:bool"`
+	IsPublic    bool   `bun:"is_public,type:bool"`
+	ShareToken  string `bun:"share_token,nullzero"`
+	Description string `bun:"description,type:text"`
+	Tags        string `bun:"tags,type:text"`
+
 	Teams     []Team           `bun:"rel:has-many,join:id=instance_id"`
 	Locations []Location       `bun:"rel:has-many,join:id=instance_id"`
 	Settings  InstanceSettings `bun:"rel:has-one,join:id=instance_id"`
@@ -44,3 +52,18 @@ func (i *Instance) GetStatus() GameStatus {
 	return Active
 
 }
+
+// TagList returns the template's tags.
+func (i *Instance) TagList() []string {
+	return splitTags(i.Tags)
+}
+
+// SetTags stores the given tags in lowercase, without blanks or duplicates.
+func (i *Instance) SetTags(tags []string) {
+	i.Tags = joinTags(tags)
+}
+
+// HasTag reports whether the template has the given tag.
+func (i *Instance) HasTag(tag string) bool {
+	return hasTag(i.Tags, tag)
+}
@@ -81,3 +81,38 @@ func TestInstance_GetStatus(t *testing.T) {
 	}
 
 }
+
+func TestInstance_SetTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{"No tags", nil, []string{}},
+		{"Blank tags", []string{"", "  "}, []string{}},
+		{"Normalised", []string{" Museum ", "history"}, []string{"museum", "history"}},
+		{"Duplicates", []string{"museum", "MUSEUM", "walk"}, []string{"museum", "walk"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := &Instance{}
+			i.SetTags(tt.tags)
+			got := i.TagList()
+			if len(got) != len(tt.want) {
+				t.Fatalf("TagList() = %v, want %v", got, tt.want)
+			}
+			for j := range got {
+				if got[j] != tt.want[j] {
+					t.Errorf("TagList() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	i := &Instance{}
+	i.SetTags([]string{"Museum"})
+	if !i.HasTag("museum") || !i.HasTag(" MUSEUM") || i.HasTag("walk") {
+		t.Errorf("HasTag() did not match tags %q", i.Tags)
+	}
+}
@@ -11,9 +11,33 @@ type Marker struct {
 	CurrentCount int     `bun:"current_count,type:int"`
 	AvgDuration  float64 `bun:"avg_duration,type:float"`
 
+	// Library markers are kept for reuse, such as permanent plaques.
+	// They belong to a user's personal workspace or to an organisation
+	InLibrary      bool   `bun:"in_library,type:bool"`
+	UserID         string `bun:"user_id,type:varchar(36)"`
+	OrganisationID string `bun:"organisation_id,type:varchar(36)"`
+	Notes          string `bun:"notes,type:text"`
+	Photo          string `bun:"photo,type:varchar(255)"`
+	Tags           string `bun:"tags,type:text"`
+
 	Locations []Location `bun:"rel:has-many,join:code=marker_id"`
 }
 
 func (m Marker) IsMapped() bool {
 	return m.Lat != 0 && m.Lng != 0
 }
+
+// TagList returns the marker's tags.
+func (m *Marker) TagList() []string {
+	return splitTags(m.Tags)
+}
+
+// SetTags stores the given tags in lowercase, without blanks or duplicates.
+func (m *Marker) SetTags(tags []string) {
+	m.Tags = joinTags(tags)
+}
+
+// HasTag reports whether the marker has the given tag.
+func (m *Marker) HasTag(tag string) bool {
+	return hasTag(m.Tags, tag)
+}
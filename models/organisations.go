@@ -0,0 +1,61 @@
+package models
+
+// OrganisationRole is what a member may do in an organisation.
+type OrganisationRole string
+
+const (
+	// OrgRoleAdmin manages members and can take over any instance in the
+	// organisation
+	OrgRoleAdmin OrganisationRole = "admin"
+	// OrgRoleMember can create instances and view everyone else's
+	OrgRoleMember OrganisationRole = "member"
+)
+
+// ParseOrganisationRole returns the role with the given name.
+func ParseOrganisationRole(role string) (OrganisationRole, bool) {
+	switch OrganisationRole(role) {
+	case OrgRoleAdmin, OrgRoleMember:
+		return OrganisationRole(role), true
+	}
+	return "", false
+}
+
+// String returns the role's display name.
+func (r OrganisationRole) String() string {
+	switch r {
+	case OrgRoleAdmin:
+		return "Admin"
+	case OrgRoleMember:
+		return "Member"
+	}
+	return string(r)
+}
+
+// Organisation is a shared workspace, such as a school, that groups users
+// and their instances.
+type Organisation struct {
+	baseModel
+
+	ID   string `bun:"id,pk,type:varchar(36)"`
+	Name string `bun:"name,type:varchar(255)"`
+}
+
+// OrganisationMember gives a user a role in an organisation.
+type OrganisationMember struct {
+	baseModel
+
+	ID             string           `bun:"id,pk,type:varchar(36)"`
+	OrganisationID string           `bun:"organisation_id,notnull"`
+	UserID         string           `bun:"user_id,notnull"`
+	Role           OrganisationRole `bun:"role,type:varchar(16)"`
+
+	User *User `bun:"rel:belongs-to,join:user_id=id"`
+}
+
+// OrganisationUsage totals what an organisation's instances use.
+type OrganisationUsage struct {
+	Members   int
+	Instances int
+	Locations int
+	Teams     int
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Player is a named member of a team.
+// Players are optional; teams without players play anonymously using the
+// team code. The email lets a player rejoin their team on another device.
+type Player struct {
+	baseModel
+
+	ID         string    `bun:"id,pk,type:varchar(36)"`
+	InstanceID string    `bun:"instance_id,notnull"`
+	TeamCode   string    `bun:"team_code,notnull"`
+	Name       string    `bun:"name,type:varchar(255)"`
+	Email      string    `bun:"email,nullzero"`
+	LastSeenAt time.Time `bun:"last_seen_at,nullzero"`
+}
+
+// PlayerContribution summarises what a player has done for their team.
+type PlayerContribution struct {
+	Player      Player
+	CheckIns    int
+	Submissions int
+	Points      int
+}
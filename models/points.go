@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+type PointsSource int
+
+const (
+	LocationSource PointsSource = iota
+	BlockSource
+	BonusSource
+	HintSource
+	ManualSource
+)
+
+// PointsTransaction records a change to a team's points.
+// A team's points are the sum of their transactions.
+// SourceID refers to the location, block, bonus rule, or hint that awarded
+// the points. ActorID is the user who made a manual change.
+// Undoing a manual change adds a reversing transaction whose SourceID is the
+// transaction it undoes.
+type PointsTransaction struct {
+	baseModel
+
+	ID         string       `bun:"id,pk,type:varchar(36)"`
+	InstanceID string       `bun:"instance_id,notnull"`
+	TeamCode   string       `bun:"team_code,notnull"`
+	Amount     int          `bun:"amount,type:int"`
+	Reason     string       `bun:"reason,type:text"`
+	Source     PointsSource `bun:"source,type:int"`
+	SourceID   string       `bun:"source_id,nullzero"`
+	ActorID    string       `bun:"actor_id,nullzero"`
+	UndoneAt   time.Time    `bun:"undone_at,nullzero"`
+}
+
+// GetPointsSources returns a list of points sources.
+func GetPointsSources() []PointsSource {
+	return []PointsSource{LocationSource, BlockSource, BonusSource, HintSource, ManualSource}
+}
+
+// String returns the string representation of the PointsSource.
+func (p PointsSource) String() string {
+	return [...]string{"Locations", "Activities", "Bonuses", "Hints", "Manual"}[p]
+}
+
+// Undoable returns true if the transaction is a manual change made by an admin
+// that has not already been undone.
+func (p *PointsTransaction) Undoable() bool {
+	return p.Source == ManualSource && p.ActorID != "" && p.SourceID == "" && p.UndoneAt.IsZero()
+}
@@ -0,0 +1,24 @@
+package models
+
+// Route is an ordering of locations for teams to follow in Ordered
+// navigation mode. Locations are referenced by marker code so routes
+// survive duplicating an instance.
+type Route struct {
+	baseModel
+
+	ID         string   `bun:"id,pk,type:varchar(36)"`
+	InstanceID string   `bun:"instance_id,notnull"`
+	Name       string   `bun:"name,type:varchar(255)"`
+	Locations  StrArray `bun:"locations,type:text"`
+}
+
+// Position returns the index of a marker in the route, or -1 if the route
+// does not include it.
+func (r Route) Position(markerID string) int {
+	for i, code := range r.Locations {
+		if code == markerID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,37 @@
+package models
+
+import "strings"
+
+// splitTags returns the tags stored in a comma separated list.
+func splitTags(tags string) []string {
+	if tags == "" {
+		return []string{}
+	}
+	return strings.Split(tags, ",")
+}
+
+// joinTags stores the given tags in lowercase, without blanks or duplicates.
+func joinTags(tags []string) string {
+	seen := map[string]bool{}
+	list := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		list = append(list, tag)
+	}
+	return strings.Join(list, ",")
+}
+
+// hasTag reports whether a comma separated list contains the given tag.
+func hasTag(tags, tag string) bool {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	for _, t := range splitTags(tags) {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -1,19 +1,47 @@
 package models
 
+import "time"
+
 type Team struct {
 	baseModel
 
-	ID           string `bun:"id,pk"`
-	Code         string `bun:"code,unique"`
-	Name         string `bun:"name,"`
-	InstanceID   string `bun:"instance_id,notnull"`
-	HasStarted   bool   `bun:"has_started,default:false"`
-	MustCheckOut string `bun:"must_scan_out"`
-	Points       int    `bun:"points,"`
+	ID           string    `bun:"id,pk"`
+	Code         string    `bun:"code,unique"`
+	Name         string    `bun:"name,"`
+	InstanceID   string    `bun:"instance_id,notnull"`
+	HasStarted   bool      `bun:"has_started,default:false"`
+	MustCheckOut string    `bun:"must_scan_out"`
+	Points       int       `bun:"points,"`
+	RouteID      string    `bun:"route_id,nullzero"`
+	StartedAt    time.Time `bun:"started_at,nullzero"`
+	ExtraTime    int       `bun:"extra_time,type:int"`
 
 	Instance         Instance         `bun:"rel:has-one,join:instance_id=id"`
 	CheckIns         []CheckIn        `bun:"rel:has-many,join:code=team_code"`
 	BlockingLocation Location         `bun:"rel:has-one,join:must_scan_out=marker_id,join:instance_id=instance_id"`
 	Messages         []Notification   `bun:"rel:has-many,join:code=team_code"`
 	Blocks           []TeamBlockState `bun:"rel:has-many,join:code=team_code"`
+	Route            Route            `bun:"rel:has-one,join:route_id=id"`
+	Players          []Player         `bun:"rel:has-many,join:code=team_code"`
+}
+
+// Deadline returns the time the team runs out of time.
+// The clock starts when the team starts playing, or when the game starts if
+// the team joined early, and includes any extra time granted by an admin.
+// A zero time means the team has no time limit.
+func (t *Team) Deadline() time.Time {
+	if t.Instance.Settings.TeamDuration <= 0 || t.StartedAt.IsZero() {
+		return time.Time{}
+	}
+	start := t.StartedAt
+	if t.Instance.StartTime.Time.After(start) {
+		start = t.Instance.StartTime.Time
+	}
+	return start.Add(time.Duration(t.Instance.Settings.TeamDuration+t.ExtraTime) * time.Minute)
+}
+
+// TimeExpired returns true if the team has run out of time.
+func (t *Team) TimeExpired() bool {
+	deadline := t.Deadline()
+	return !deadline.IsZero() && time.Now().After(deadline)
 }
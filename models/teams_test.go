@@ -0,0 +1,92 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+func TestTeam_Deadline(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		duration    int
+		extraTime   int
+		startedAt   time.Time
+		startTime   time.Time
+		wantZero    bool
+		wantExpired bool
+		want        time.Time
+	}{
+		{
+			name:      "No time limit",
+			duration:  0,
+			startedAt: now.Add(-time.Hour),
+			wantZero:  true,
+		},
+		{
+			name:     "Not started",
+			duration: 90,
+			wantZero: true,
+		},
+		{
+			name:      "Started after the game",
+			duration:  90,
+			startedAt: now.Add(-30 * time.Minute),
+			startTime: now.Add(-time.Hour),
+			want:      now.Add(60 * time.Minute),
+		},
+		{
+			name:      "Started before the game",
+			duration:  90,
+			startedAt: now.Add(-2 * time.Hour),
+			startTime: now.Add(-time.Hour),
+			want:      now.Add(30 * time.Minute),
+		},
+		{
+			name:        "Out of time",
+			duration:    90,
+			startedAt:   now.Add(-2 * time.Hour),
+			want:        now.Add(-30 * time.Minute),
+			wantExpired: true,
+		},
+		{
+			name:      "Extra time",
+			duration:  90,
+			extraTime: 45,
+			startedAt: now.Add(-2 * time.Hour),
+			want:      now.Add(15 * time.Minute),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			team := &Team{
+				StartedAt: tt.startedAt,
+				ExtraTime: tt.extraTime,
+				Instance: Instance{
+					StartTime: bun.NullTime{Time: tt.startTime},
+					Settings:  InstanceSettings{TeamDuration: tt.duration},
+				},
+			}
+
+			got := team.Deadline()
+			if tt.wantZero {
+				if !got.IsZero() {
+					t.Errorf("Team.Deadline() = %v, want zero", got)
+				}
+				if team.TimeExpired() {
+					t.Errorf("Team.TimeExpired() = true, want false")
+				}
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Team.Deadline() = %v, want %v", got, tt.want)
+			}
+			if team.TimeExpired() != tt.wantExpired {
+				t.Errorf("Team.TimeExpired() = %v, want %v", team.TimeExpired(), tt.wantExpired)
+			}
+		})
+	}
+}
@@ -23,6 +23,8 @@ const (
 	RandomNav NavigationMode = iota
 	FreeRoamNav
 	OrderedNav
+	ConditionalNav
+	BalancedNav
 )
 
 const (
@@ -73,7 +75,7 @@ func (s *StrArray) Scan(value interface{}) error {
 
 // GetNavigationModes returns a list of navigation modes.
 func GetNavigationModes() NavigationModes {
-	return []NavigationMode{RandomNav, FreeRoamNav, OrderedNav}
+	return []NavigationMode{RandomNav, FreeRoamNav, OrderedNav, ConditionalNav, BalancedNav}
 }
 
 // GetNavigationMethods returns a list of navigation methods.
@@ -93,7 +95,7 @@ func GetGameStatuses() GameStatuses {
 
 // String returns the string representation of the NavigationMode.
 func (n NavigationMode) String() string {
-	return [...]string{"Random", "Free Roam", "Ordered"}[n]
+	return [...]string{"Random", "Free Roam", "Ordered", "Conditional", "Balanced"}[n]
 }
 
 // String returns the string representation of the NavigationMethod.
@@ -117,6 +119,8 @@ func (n NavigationMode) Description() string {
 		"The game will randomly select locations for players to visit. Good for large groups as it disperses players.",
 		"Players can visit locations in any order. This mode shows all locations and is good for exploration.",
 		"Players must visit locations in a specific order. Good for narrative experiences.",
+		"Locations unlock as players meet their requirements. Good for branching stories and puzzles.",
+		"Like Random, but steers players away from busy and full locations. Good for large groups at small locations.",
 	}[n]
 }
 
@@ -159,6 +163,10 @@ func ParseNavigationMode(s string) (NavigationMode, error) {
 		return FreeRoamNav, nil
 	case "Ordered":
 		return OrderedNav, nil
+	case "Conditional":
+		return ConditionalNav, nil
+	case "Balanced":
+		return BalancedNav, nil
 	default:
 		return 0, errors.New("invalid NavigationMode")
 	}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type UnlockRuleType string
+
+const (
+	// RequireAllLocations unlocks once every listed location has been visited
+	RequireAllLocations UnlockRuleType = "all"
+	// RequireAnyLocations unlocks once Count of the listed locations have been visited
+	RequireAnyLocations UnlockRuleType = "any"
+	// RequireBlock unlocks once the block has been completed
+	RequireBlock UnlockRuleType = "block"
+)
+
+// UnlockRule is a condition a team must meet before a location is unlocked
+// in Conditional navigation mode. Locations are referenced by marker code so
+// rules survive duplicating an instance.
+type UnlockRule struct {
+	Type      UnlockRuleType `json:"type"`
+	Locations []string       `json:"locations,omitempty"`
+	Count     int            `json:"count,omitempty"`
+	BlockID   string         `json:"block_id,omitempty"`
+}
+
+// UnlockRules are the conditions for unlocking a location.
+// A location is unlocked once all of its rules are met.
+type UnlockRules []UnlockRule
+
+// String returns the string representation of the UnlockRuleType.
+func (t UnlockRuleType) String() string {
+	switch t {
+	case RequireAllLocations:
+		return "Requires all of"
+	case RequireAnyLocations:
+		return "Requires any of"
+	case RequireBlock:
+		return "Requires block"
+	}
+	return string(t)
+}
+
+// GetUnlockRuleTypes returns a list of unlock rule types.
+func GetUnlockRuleTypes() []UnlockRuleType {
+	return []UnlockRuleType{RequireAllLocations, RequireAnyLocations, RequireBlock}
+}
+
+// Validate checks the rule is complete.
+func (r UnlockRule) Validate() error {
+	switch r.Type {
+	case RequireAllLocations:
+		if len(r.Locations) == 0 {
+			return errors.New("rule requires at least one location")
+		}
+	case RequireAnyLocations:
+		if len(r.Locations) == 0 {
+			return errors.New("rule requires at least one location")
+		}
+		if r.Count < 1 || r.Count > len(r.Locations) {
+			return fmt.Errorf("count must be between 1 and %d", len(r.Locations))
+		}
+	case RequireBlock:
+		if r.BlockID == "" {
+			return errors.New("rule requires a block")
+		}
+	default:
+		return fmt.Errorf("invalid unlock rule type %q", r.Type)
+	}
+	return nil
+}
+
+// Value converts UnlockRules to a JSON string for database storage.
+func (r UnlockRules) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return "[]", nil
+	}
+	bytes, err := json.Marshal(r)
+	return string(bytes), err
+}
+
+// Scan converts a database JSON string back into UnlockRules.
+func (r *UnlockRules) Scan(value interface{}) error {
+	if value == nil {
+		*r = UnlockRules{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("failed to scan UnlockRules: expected string, got %T", value)
+	}
+	if len(data) == 0 {
+		*r = UnlockRules{}
+		return nil
+	}
+
+	return json.Unmarshal(data, r)
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnlockRules_JSONEncoding(t *testing.T) {
+	rules := UnlockRules{
+		{Type: RequireAnyLocations, Locations: []string{"ABCDE", "FGHIJ"}, Count: 1},
+		{Type: RequireBlock, BlockID: "block"},
+	}
+	value, err := rules.Value()
+	assert.NoError(t, err)
+
+	var decoded UnlockRules
+	err = decoded.Scan(value)
+	assert.NoError(t, err)
+	assert.Equal(t, rules, decoded)
+
+	// Handles empty rules
+	value, err = UnlockRules{}.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", value)
+
+	// Handles nil and empty columns
+	err = decoded.Scan(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, UnlockRules{}, decoded)
+	err = decoded.Scan("")
+	assert.NoError(t, err)
+	assert.Equal(t, UnlockRules{}, decoded)
+
+	// Invalid JSON case
+	err = decoded.Scan(`{bad json}`)
+	assert.Error(t, err)
+}
+
+func TestUnlockRule_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  UnlockRule
+		valid bool
+	}{
+		{"All", UnlockRule{Type: RequireAllLocations, Locations: []string{"A"}}, true},
+		{"All without locations", UnlockRule{Type: RequireAllLocations}, false},
+		{"Any", UnlockRule{Type: RequireAnyLocations, Locations: []string{"A", "B"}, Count: 2}, true},
+		{"Any with too high a count", UnlockRule{Type: RequireAnyLocations, Locations: []string{"A"}, Count: 2}, false},
+		{"Any with no count", UnlockRule{Type: RequireAnyLocations, Locations: []string{"A"}}, false},
+		{"Block", UnlockRule{Type: RequireBlock, BlockID: "block"}, true},
+		{"Block without block", UnlockRule{Type: RequireBlock}, false},
+		{"Unknown type", UnlockRule{Type: "none"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -19,4 +19,28 @@ type User struct {
 	Instances         []Instance `bun:"rel:has-many,join:id=user_id"`
 	CurrentInstanceID string     `bun:"current_instance_id,type:varchar(36)"`
 	CurrentInstance   Instance   `bun:"rel:has-one,join:current_instance_id=id"`
+
+	// CurrentOrganisationID is the user's workspace, or empty for their own
+	CurrentOrganisationID string `bun:"current_organisation_id,type:varchar(36)"`
+	// Organisations are the organisations the user belongs to
+	Organisations []Organisation `bun:"-"`
+
+	// SharedInstances are the instances other users have shared with this user
+	SharedInstances []Instance `bun:"-"`
+	// OrganisationInstances are the other members' instances in the user's
+	// current organisation
+	OrganisationInstances []Instance `bun:"-"`
+	// CurrentRole is the user's role in their current instance
+	CurrentRole InstanceRole `bun:"-"`
+}
+
+// CurrentOrganisation returns the organisation the user is working in, or nil
+// when they are in their own workspace.
+func (u User) CurrentOrganisation() *Organisation {
+	for i := range u.Organisations {
+		if u.Organisations[i].ID == u.CurrentOrganisationID {
+			return &u.Organisations[i]
+		}
+	}
+	return nil
 }
@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/uptrace/bun"
+)
+
+type AuditRepository interface {
+	// Create adds an event to the audit log
+	// Requires a transaction so the event is only kept if the change is
+	Create(ctx context.Context, tx *bun.Tx, event *models.AuditEvent) error
+
+	// Log adds an event to the audit log outside of a transaction
+	Log(ctx context.Context, event *models.AuditEvent) error
+
+	// FindByInstanceID returns the events for an instance, newest first
+	FindByInstanceID(ctx context.Context, instanceID string) ([]models.AuditEvent, error)
+	// Find returns the events for an instance matching the filter, newest first
+	Find(ctx context.Context, instanceID string, filter models.AuditFilter) ([]models.AuditEvent, error)
+	// FindActors returns everyone who has made a change to an instance
+	FindActors(ctx context.Context, instanceID string) ([]models.Actor, error)
+}
+
+type auditRepository struct {
+	db *bun.DB
+}
+
+// NewAuditRepository creates a new AuditRepository.
+func NewAuditRepository(db *bun.DB) AuditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+// Create adds an event to the audit log.
+func (r *auditRepository) Create(ctx context.Context, tx *bun.Tx, event *models.AuditEvent) error {
+	err := r.prepare(event)
+	if err != nil {
+		return err
+	}
+	_, err = tx.NewInsert().Model(event).Exec(ctx)
+	return err
+}
+
+// Log adds an event to the audit log outside of a transaction.
+func (r *auditRepository) Log(ctx context.Context, event *models.AuditEvent) error {
+	err := r.prepare(event)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.NewInsert().Model(event).Exec(ctx)
+	return err
+}
+
+// prepare validates an event and fills in its defaults.
+func (r *auditRepository) prepare(event *models.AuditEvent) error {
+	if event.InstanceID == "" || event.ActorID == "" {
+		return errors.New("instance ID and actor ID must be set")
+	}
+	if event.ActorType == "" {
+		event.ActorType = models.ActorUser
+	}
+	// Set the time here rather than in the database so events made in the
+	// same second keep their order
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	if event.ID == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return fmt.Errorf("generating UUID: %w", err)
+		}
+		event.ID = id.String()
+	}
+	return nil
+}
+
+// FindByInstanceID returns the events for an instance, newest first.
+func (r *auditRepository) FindByInstanceID(ctx context.Context, instanceID string) ([]models.AuditEvent, error) {
+	events := []models.AuditEvent{}
+	err := r.db.
+		NewSelect().
+		Model(&events).
+		Where("instance_id = ?", instanceID).
+		Order("created_at DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding audit events by instance: %w", err)
+	}
+	return events, nil
+}
+
+// Find returns the events for an instance matching the filter, newest first.
+func (r *auditRepository) Find(ctx context.Context, instanceID string, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	events := []models.AuditEvent{}
+	query := r.db.
+		NewSelect().
+		Model(&events).
+		Where("instance_id = ?", instanceID)
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until.UTC())
+	}
+	err := query.Order("created_at DESC").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding audit events: %w", err)
+	}
+	return events, nil
+}
+
+// FindActors returns everyone who has made a change to an instance.
+func (r *auditRepository) FindActors(ctx context.Context, instanceID string) ([]models.Actor, error) {
+	events := []models.AuditEvent{}
+	err := r.db.
+		NewSelect().
+		Model(&events).
+		Column("actor_id", "actor_type", "actor_name").
+		Where("instance_id = ?", instanceID).
+		Order("created_at DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding audit actors: %w", err)
+	}
+
+	seen := map[string]bool{}
+	actors := []models.Actor{}
+	for _, event := range events {
+		if seen[event.ActorID] {
+			continue
+		}
+		seen[event.ActorID] = true
+		actors = append(actors, models.Actor{
+			Type:       event.ActorType,
+			ID:         event.ActorID,
+			Name:       event.ActorName,
+			InstanceID: instanceID,
+		})
+	}
+	return actors, nil
+}
@@ -0,0 +1,99 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRepository(t *testing.T) {
+	dbc, cleanup := setupDB(t)
+	defer cleanup()
+	repo := repositories.NewAuditRepository(dbc)
+	transactor := db.NewTransactor(dbc)
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	tx, err := transactor.BeginTx(ctx, &sql.TxOptions{})
+	require.NoError(t, err)
+	event := &models.AuditEvent{
+		InstanceID: instanceID,
+		ActorID:    gofakeit.UUID(),
+		Action:     models.AuditCheckInRevoked,
+		EntityType: "location",
+		EntityID:   gofakeit.UUID(),
+		TeamCode:   "ABCD",
+	}
+	require.NoError(t, repo.Create(ctx, tx, event))
+	assert.NotEmpty(t, event.ID)
+	err = repo.Create(ctx, tx, &models.AuditEvent{InstanceID: instanceID})
+	assert.Error(t, err, "events need an actor")
+	require.NoError(t, tx.Commit())
+
+	// Rolled back events are not kept
+	tx, err = transactor.BeginTx(ctx, &sql.TxOptions{})
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, tx, &models.AuditEvent{
+		InstanceID: instanceID,
+		ActorID:    gofakeit.UUID(),
+		Action:     models.AuditPointsAdjusted,
+	}))
+	require.NoError(t, tx.Rollback())
+
+	events, err := repo.FindByInstanceID(ctx, instanceID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.AuditCheckInRevoked, events[0].Action)
+	assert.Equal(t, "ABCD", events[0].TeamCode)
+}
+
+func TestAuditRepository_Find(t *testing.T) {
+	dbc, cleanup := setupDB(t)
+	defer cleanup()
+	repo := repositories.NewAuditRepository(dbc)
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	user := models.Actor{Type: models.ActorUser, ID: gofakeit.UUID(), Name: "Jo"}
+	facilitator := models.Actor{Type: models.ActorFacilitator, ID: "abc123"}
+	for _, actor := range []models.Actor{user, facilitator, user} {
+		require.NoError(t, repo.Log(ctx, &models.AuditEvent{
+			InstanceID: instanceID,
+			ActorID:    actor.ID,
+			ActorType:  actor.Type,
+			ActorName:  actor.Name,
+			Action:     models.AuditLocationUpdated,
+			EntityType: "location",
+			EntityID:   gofakeit.UUID(),
+		}))
+	}
+
+	events, err := repo.Find(ctx, instanceID, models.AuditFilter{})
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+
+	events, err = repo.Find(ctx, instanceID, models.AuditFilter{ActorID: facilitator.ID})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.ActorFacilitator, events[0].ActorType)
+
+	events, err = repo.Find(ctx, instanceID, models.AuditFilter{Since: time.Now().Add(-time.Hour), Until: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+
+	events, err = repo.Find(ctx, instanceID, models.AuditFilter{Until: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	actors, err := repo.FindActors(ctx, instanceID)
+	require.NoError(t, err)
+	assert.Len(t, actors, 2)
+}
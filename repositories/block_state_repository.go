@@ -19,9 +19,23 @@ type BlockStateRepository interface {
 
 	// GetByBlockAndTeam gets a player state by block ID and team code
 	GetByBlockAndTeam(ctx context.Context, blockID string, teamCode string) (blocks.PlayerState, error)
+	// FindPendingReviewByInstanceID finds all player states awaiting review for an instance
+	FindPendingReviewByInstanceID(ctx context.Context, instanceID string) ([]blocks.PlayerState, error)
+	// FindByLocationAndTeam finds a team's player states for the blocks at a location
+	FindByLocationAndTeam(ctx context.Context, locationID string, teamCode string) ([]blocks.PlayerState, error)
 
 	// Update updates an existing player state
 	Update(ctx context.Context, block blocks.PlayerState) (blocks.PlayerState, error)
+	// Save creates or updates a player state as part of a larger change
+	Save(ctx context.Context, tx *bun.Tx, state blocks.PlayerState) error
+	// SaveReview saves a reviewed player state as part of a larger change,
+	// as long as it is still awaiting review. It reports whether it was saved
+	SaveReview(ctx context.Context, tx *bun.Tx, state blocks.PlayerState) (bool, error)
+	// SaveCompletion saves a completed player state as part of a larger change,
+	// as long as it was not already complete. It reports whether it was saved
+	SaveCompletion(ctx context.Context, tx *bun.Tx, state blocks.PlayerState) (bool, error)
+	// SetPlayer records which player in the team last submitted the block
+	SetPlayer(ctx context.Context, blockID, teamCode, playerID string) error
 
 	// Delete deletes a player state by block ID and team code
 	Delete(ctx context.Context, block_id string, team_code string) error
@@ -30,6 +44,8 @@ type BlockStateRepository interface {
 	DeleteByBlockID(ctx context.Context, tx *bun.Tx, blockID string) error
 	// DeleteByTeamCodes deletes all player states for a team
 	DeleteByTeamCodes(ctx context.Context, tx *bun.Tx, teamCodes []string) error
+	// DeleteByLocationAndTeam deletes a team's player states for the blocks at a location
+	DeleteByLocationAndTeam(ctx context.Context, tx *bun.Tx, locationID string, teamCode string) error
 }
 
 type blockStateRepository struct {
@@ -49,6 +65,8 @@ type PlayerStateData struct {
 	playerData    json.RawMessage
 	isComplete    bool
 	pointsAwarded int
+	reviewStatus  string
+	reviewComment string
 }
 
 func (p *PlayerStateData) GetBlockID() string {
@@ -83,6 +101,22 @@ func (p *PlayerStateData) SetPointsAwarded(points int) {
 	p.pointsAwarded = points
 }
 
+func (p *PlayerStateData) GetReviewStatus() string {
+	return p.reviewStatus
+}
+
+func (p *PlayerStateData) SetReviewStatus(status string) {
+	p.reviewStatus = status
+}
+
+func (p *PlayerStateData) GetReviewComment() string {
+	return p.reviewComment
+}
+
+func (p *PlayerStateData) SetReviewComment(comment string) {
+	p.reviewComment = comment
+}
+
 // Convert model state to PlayerState.
 func convertModelToPlayerStateData(state models.TeamBlockState) blocks.PlayerState {
 	return &PlayerStateData{
@@ -91,6 +125,8 @@ func convertModelToPlayerStateData(state models.TeamBlockState) blocks.PlayerSta
 		playerData:    state.PlayerData,
 		isComplete:    state.IsComplete,
 		pointsAwarded: state.PointsAwarded,
+		reviewStatus:  state.ReviewStatus,
+		reviewComment: state.ReviewComment,
 	}
 }
 
@@ -102,6 +138,8 @@ func convertPlayerStateToModelData(state blocks.PlayerState) models.TeamBlockSta
 		PlayerData:    state.GetPlayerData(),
 		IsComplete:    state.IsComplete(),
 		PointsAwarded: state.GetPointsAwarded(),
+		ReviewStatus:  state.GetReviewStatus(),
+		ReviewComment: state.GetReviewComment(),
 	}
 }
 
@@ -120,6 +158,46 @@ func (r *blockStateRepository) GetByBlockAndTeam(ctx context.Context, blockID st
 	return convertModelToPlayerStateData(modelState), nil
 }
 
+// FindPendingReviewByInstanceID fetches all team block states awaiting review for an instance.
+// The oldest submissions are returned first.
+func (r *blockStateRepository) FindPendingReviewByInstanceID(ctx context.Context, instanceID string) ([]blocks.PlayerState, error) {
+	var modelStates []models.TeamBlockState
+	err := r.db.NewSelect().
+		Model(&modelStates).
+		Where("review_status = ?", blocks.ReviewStatusPending).
+		Where("team_code IN (?)", r.db.NewSelect().Model((*models.Team)(nil)).Column("code").Where("instance_id = ?", instanceID)).
+		Order("updated_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]blocks.PlayerState, len(modelStates))
+	for i, state := range modelStates {
+		states[i] = convertModelToPlayerStateData(state)
+	}
+	return states, nil
+}
+
+// FindByLocationAndTeam fetches a team's block states for the blocks at a location.
+func (r *blockStateRepository) FindByLocationAndTeam(ctx context.Context, locationID string, teamCode string) ([]blocks.PlayerState, error) {
+	var modelStates []models.TeamBlockState
+	err := r.db.NewSelect().
+		Model(&modelStates).
+		Where("team_code = ?", teamCode).
+		Where("block_id IN (?)", r.db.NewSelect().Model((*models.Block)(nil)).Column("id").Where("location_id = ?", locationID)).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]blocks.PlayerState, len(modelStates))
+	for i, state := range modelStates {
+		states[i] = convertModelToPlayerStateData(state)
+	}
+	return states, nil
+}
+
 // Create inserts a new team block state into the database.
 func (r *blockStateRepository) Create(ctx context.Context, state blocks.PlayerState) (blocks.PlayerState, error) {
 	modelState := convertPlayerStateToModelData(state)
@@ -146,6 +224,8 @@ func (r *blockStateRepository) Update(ctx context.Context, state blocks.PlayerSt
 		Set("player_data = ?", modelState.PlayerData).
 		Set("is_complete = ?", modelState.IsComplete).
 		Set("points_awarded = ?", modelState.PointsAwarded).
+		Set("review_status = ?", modelState.ReviewStatus).
+		Set("review_comment = ?", modelState.ReviewComment).
 		Set("updated_at = ?", time.Now()).
 		Where("block_id = ?", state.GetBlockID()).
 		Where("team_code = ?", state.GetPlayerID()).
@@ -154,6 +234,116 @@ func (r *blockStateRepository) Update(ctx context.Context, state blocks.PlayerSt
 	return state, err
 }
 
+// Save creates or updates a player state as part of a larger change.
+func (r *blockStateRepository) Save(ctx context.Context, tx *bun.Tx, state blocks.PlayerState) error {
+	if state.GetBlockID() == "" || state.GetPlayerID() == "" {
+		return errors.New("block_id and team_code must be set")
+	}
+	modelState := convertPlayerStateToModelData(state)
+
+	exists, err := tx.NewSelect().
+		Model((*models.TeamBlockState)(nil)).
+		Where("block_id = ?", modelState.BlockID).
+		Where("team_code = ?", modelState.TeamCode).
+		Exists(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = tx.NewInsert().Model(&modelState).Exec(ctx)
+		return err
+	}
+
+	modelState.UpdatedAt = time.Now()
+	_, err = tx.NewUpdate().
+		Model(&modelState).
+		ExcludeColumn("created_at", "player_id").
+		Where("block_id = ?", modelState.BlockID).
+		Where("team_code = ?", modelState.TeamCode).
+		Exec(ctx)
+	return err
+}
+
+// SaveReview saves a reviewed player state as part of a larger change.
+// Nothing is saved if the state is no longer awaiting review, so two
+// reviewers can never resolve the same submission.
+func (r *blockStateRepository) SaveReview(ctx context.Context, tx *bun.Tx, state blocks.PlayerState) (bool, error) {
+	modelState := convertPlayerStateToModelData(state)
+	res, err := tx.NewUpdate().
+		Model(&modelState).
+		Set("is_complete = ?", modelState.IsComplete).
+		Set("points_awarded = ?", modelState.PointsAwarded).
+		Set("review_status = ?", modelState.ReviewStatus).
+		Set("review_comment = ?", modelState.ReviewComment).
+		Set("updated_at = ?", time.Now()).
+		Where("block_id = ?", modelState.BlockID).
+		Where("team_code = ?", modelState.TeamCode).
+		Where("review_status = ?", blocks.ReviewStatusPending).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// SaveCompletion saves a completed player state as part of a larger change.
+// Nothing is saved if the state is already complete, so a block can never be
+// completed, and its points paid, twice.
+func (r *blockStateRepository) SaveCompletion(ctx context.Context, tx *bun.Tx, state blocks.PlayerState) (bool, error) {
+	if state.GetBlockID() == "" || state.GetPlayerID() == "" {
+		return false, errors.New("block_id and team_code must be set")
+	}
+	modelState := convertPlayerStateToModelData(state)
+	modelState.UpdatedAt = time.Now()
+
+	res, err := tx.NewUpdate().
+		Model(&modelState).
+		ExcludeColumn("created_at", "player_id").
+		Where("block_id = ?", modelState.BlockID).
+		Where("team_code = ?", modelState.TeamCode).
+		Where("is_complete = ?", false).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows > 0 {
+		return true, nil
+	}
+
+	// The team may not have a state for the block yet
+	res, err = tx.NewInsert().
+		Model(&modelState).
+		Ignore().
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	rows, err = res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// SetPlayer records which player in the team last submitted the block.
+func (r *blockStateRepository) SetPlayer(ctx context.Context, blockID, teamCode, playerID string) error {
+	_, err := r.db.NewUpdate().
+		Model((*models.TeamBlockState)(nil)).
+		Set("player_id = ?", playerID).
+		Where("block_id = ?", blockID).
+		Where("team_code = ?", teamCode).
+		Exec(ctx)
+	return err
+}
+
 // NewBlockState creates a new block state.
 func (r *blockStateRepository) NewBlockState(ctx context.Context, blockID, teamCode string) (blocks.PlayerState, error) {
 	state := &PlayerStateData{
@@ -196,3 +386,13 @@ func (r *blockStateRepository) DeleteByTeamCodes(ctx context.Context, tx *bun.Tx
 		Exec(ctx)
 	return err
 }
+
+// DeleteByLocationAndTeam removes a team's block states for the blocks at a location.
+func (r *blockStateRepository) DeleteByLocationAndTeam(ctx context.Context, tx *bun.Tx, locationID string, teamCode string) error {
+	_, err := tx.NewDelete().
+		Model(&models.TeamBlockState{}).
+		Where("team_code = ?", teamCode).
+		Where("block_id IN (?)", tx.NewSelect().Model((*models.Block)(nil)).Column("id").Where("location_id = ?", locationID)).
+		Exec(ctx)
+	return err
+}
@@ -264,3 +264,80 @@ func TestBlockStateRepository_DeleteByTeamCodes(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockStateRepository_SaveReview(t *testing.T) {
+	repo, transactor, cleanup := setupBlockStateRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	state, err := repo.NewBlockState(ctx, gofakeit.UUID(), gofakeit.UUID())
+	assert.NoError(t, err)
+	state.SetReviewStatus(blocks.ReviewStatusPending)
+	state, err = repo.Create(ctx, state)
+	assert.NoError(t, err)
+
+	// save approves the state in its own transaction
+	save := func() bool {
+		tx, err := transactor.BeginTx(ctx, &sql.TxOptions{})
+		assert.NoError(t, err)
+		state.SetComplete(true)
+		state.SetReviewStatus(blocks.ReviewStatusApproved)
+		saved, err := repo.SaveReview(ctx, tx, state)
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+		return saved
+	}
+
+	assert.True(t, save())
+	assert.False(t, save(), "a state that is no longer pending is not saved again")
+
+	saved, err := repo.GetByBlockAndTeam(ctx, state.GetBlockID(), state.GetPlayerID())
+	assert.NoError(t, err)
+	assert.True(t, saved.IsComplete())
+	assert.Equal(t, blocks.ReviewStatusApproved, saved.GetReviewStatus())
+}
+
+func TestBlockStateRepository_SaveCompletion(t *testing.T) {
+	repo, transactor, cleanup := setupBlockStateRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// complete saves the state as complete in its own transaction
+	complete := func(state blocks.PlayerState) bool {
+		tx, err := transactor.BeginTx(ctx, &sql.TxOptions{})
+		assert.NoError(t, err)
+		state.SetComplete(true)
+		state.SetPointsAwarded(10)
+		saved, err := repo.SaveCompletion(ctx, tx, state)
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+		return saved
+	}
+
+	t.Run("Existing state", func(t *testing.T) {
+		state, err := repo.NewBlockState(ctx, gofakeit.UUID(), gofakeit.UUID())
+		assert.NoError(t, err)
+		state, err = repo.Create(ctx, state)
+		assert.NoError(t, err)
+
+		assert.True(t, complete(state))
+		assert.False(t, complete(state), "a complete state is not saved again")
+
+		saved, err := repo.GetByBlockAndTeam(ctx, state.GetBlockID(), state.GetPlayerID())
+		assert.NoError(t, err)
+		assert.True(t, saved.IsComplete())
+		assert.Equal(t, 10, saved.GetPointsAwarded())
+	})
+
+	t.Run("New state", func(t *testing.T) {
+		state, err := repo.NewBlockState(ctx, gofakeit.UUID(), gofakeit.UUID())
+		assert.NoError(t, err)
+
+		assert.True(t, complete(state))
+		assert.False(t, complete(state), "a complete state is not saved again")
+
+		saved, err := repo.GetByBlockAndTeam(ctx, state.GetBlockID(), state.GetPlayerID())
+		assert.NoError(t, err)
+		assert.True(t, saved.IsComplete())
+	})
+}
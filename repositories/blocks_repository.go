@@ -13,6 +13,8 @@ import (
 type BlockRepository interface {
 	// Create creates a new block for a location
 	Create(ctx context.Context, block blocks.Block, locationID string) (blocks.Block, error)
+	// CreateWithTransaction creates a new block for a location as part of a larger change
+	CreateWithTransaction(ctx context.Context, tx *bun.Tx, block blocks.Block, locationID string) (blocks.Block, error)
 
 	// GetByID fetches a block by its ID
 	GetByID(ctx context.Context, blockID string) (blocks.Block, error)
@@ -35,6 +37,8 @@ type BlockRepository interface {
 
 	// Reorder reorders the blocks for a specific location
 	Reorder(ctx context.Context, locationID string, blockIDs []string) error
+	// ReorderWithTransaction reorders the blocks for a location as part of a larger change
+	ReorderWithTransaction(ctx context.Context, tx *bun.Tx, locationID string, blockIDs []string) error
 }
 
 type blockRepository struct {
@@ -78,6 +82,15 @@ func (r *blockRepository) GetByID(ctx context.Context, blockID string) (blocks.B
 
 // Create saves a new block to the database.
 func (r *blockRepository) Create(ctx context.Context, block blocks.Block, locationID string) (blocks.Block, error) {
+	return r.create(ctx, r.db, block, locationID)
+}
+
+// CreateWithTransaction saves a new block as part of a larger change.
+func (r *blockRepository) CreateWithTransaction(ctx context.Context, tx *bun.Tx, block blocks.Block, locationID string) (blocks.Block, error) {
+	return r.create(ctx, tx, block, locationID)
+}
+
+func (r *blockRepository) create(ctx context.Context, db bun.IDB, block blocks.Block, locationID string) (blocks.Block, error) {
 	modelBlock := models.Block{
 		ID:                 uuid.New().String(),
 		LocationID:         locationID,
@@ -86,8 +99,9 @@ func (r *blockRepository) Create(ctx context.Context, block blocks.Block, locati
 		Ordering:           1e4,
 		Points:             block.GetPoints(),
 		ValidationRequired: block.RequiresValidation(),
+		ReviewRequired:     block.RequiresReview(),
 	}
-	_, err := r.db.NewInsert().Model(&modelBlock).Exec(ctx)
+	_, err := db.NewInsert().Model(&modelBlock).Exec(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -124,6 +138,7 @@ func convertBlockToModel(block blocks.Block) models.Block {
 		Data:               block.GetData(),
 		Points:             block.GetPoints(),
 		ValidationRequired: block.RequiresValidation(),
+		ReviewRequired:     block.RequiresReview(),
 	}
 }
 
@@ -142,12 +157,13 @@ func convertModelsToBlocks(modelBlocks []models.Block) (blocks.Blocks, error) {
 func convertModelToBlock(model *models.Block) (blocks.Block, error) {
 	// Convert model to block
 	newBlock, err := blocks.CreateFromBaseBlock(blocks.BaseBlock{
-		ID:         model.ID,
-		LocationID: model.LocationID,
-		Type:       model.Type,
-		Data:       model.Data,
-		Order:      model.Ordering,
-		Points:     model.Points,
+		ID:             model.ID,
+		LocationID:     model.LocationID,
+		Type:           model.Type,
+		Data:           model.Data,
+		Order:          model.Ordering,
+		Points:         model.Points,
+		ReviewRequired: model.ReviewRequired,
 	})
 	if err != nil {
 		return nil, err
@@ -173,8 +189,17 @@ func (r *blockRepository) DeleteByLocationID(ctx context.Context, tx *bun.Tx, lo
 
 // Reorder reorders the blocks.
 func (r *blockRepository) Reorder(ctx context.Context, locationID string, blockIDs []string) error {
+	return r.reorder(ctx, r.db, blockIDs)
+}
+
+// ReorderWithTransaction reorders the blocks as part of a larger change.
+func (r *blockRepository) ReorderWithTransaction(ctx context.Context, tx *bun.Tx, locationID string, blockIDs []string) error {
+	return r.reorder(ctx, tx, blockIDs)
+}
+
+func (r *blockRepository) reorder(ctx context.Context, db bun.IDB, blockIDs []string) error {
 	for i, blockID := range blockIDs {
-		_, err := r.db.NewUpdate().
+		_, err := db.NewUpdate().
 			Model(&models.Block{}).
 			Set("ordering = ?", i).
 			Where("id = ?", blockID).
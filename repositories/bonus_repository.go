@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/uptrace/bun"
+)
+
+type BonusRepository interface {
+	// SaveRule saves a bonus rule to the database
+	SaveRule(ctx context.Context, rule *models.BonusRule) error
+	// SaveRuleWithTransaction saves a bonus rule as part of a larger change
+	SaveRuleWithTransaction(ctx context.Context, tx *bun.Tx, rule *models.BonusRule) error
+	// UpdateRule updates a bonus rule in the database
+	UpdateRule(ctx context.Context, rule *models.BonusRule) error
+
+	// GetRuleByID returns a bonus rule by its ID
+	GetRuleByID(ctx context.Context, ruleID string) (*models.BonusRule, error)
+	// FindRulesByInstance returns all bonus rules for an instance, oldest first
+	FindRulesByInstance(ctx context.Context, instanceID string) ([]models.BonusRule, error)
+
+	// DeleteRule removes a bonus rule
+	// Points already awarded by the rule are kept
+	DeleteRule(ctx context.Context, ruleID string) error
+
+	// SaveAward records bonus points awarded to a team as part of a larger change
+	// It reports whether the award was saved, as each rule rewards a team once per location
+	SaveAward(ctx context.Context, tx *bun.Tx, award *models.BonusAward) (bool, error)
+	// HasAward returns true if a rule has already rewarded a team at a location
+	HasAward(ctx context.Context, teamCode string, ruleID string, locationID string) (bool, error)
+	// FindAwardsByTeam returns the bonus points awarded to a team, oldest first
+	FindAwardsByTeam(ctx context.Context, teamCode string) ([]models.BonusAward, error)
+
+	// DeleteAwardsByTeamCodes removes the awards for the given teams
+	DeleteAwardsByTeamCodes(ctx context.Context, tx *bun.Tx, instanceID string, teamCodes []string) error
+}
+
+type bonusRepository struct {
+	db *bun.DB
+}
+
+// NewBonusRepository creates a new BonusRepository.
+func NewBonusRepository(db *bun.DB) BonusRepository {
+	return &bonusRepository{
+		db: db,
+	}
+}
+
+// SaveRule saves a bonus rule to the database.
+func (r *bonusRepository) SaveRule(ctx context.Context, rule *models.BonusRule) error {
+	return r.saveRule(ctx, r.db, rule)
+}
+
+// SaveRuleWithTransaction saves a bonus rule as part of a larger change.
+func (r *bonusRepository) SaveRuleWithTransaction(ctx context.Context, tx *bun.Tx, rule *models.BonusRule) error {
+	return r.saveRule(ctx, tx, rule)
+}
+
+func (r *bonusRepository) saveRule(ctx context.Context, db bun.IDB, rule *models.BonusRule) error {
+	if rule.InstanceID == "" {
+		return errors.New("instance ID must be set")
+	}
+	if rule.ID == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return fmt.Errorf("generating UUID: %w", err)
+		}
+		rule.ID = id.String()
+	}
+	_, err := db.NewInsert().Model(rule).Exec(ctx)
+	return err
+}
+
+// UpdateRule updates a bonus rule in the database.
+func (r *bonusRepository) UpdateRule(ctx context.Context, rule *models.BonusRule) error {
+	if rule.ID == "" {
+		return errors.New("rule ID must be set")
+	}
+	_, err := r.db.NewUpdate().Model(rule).WherePK().Exec(ctx)
+	return err
+}
+
+// GetRuleByID returns a bonus rule by its ID.
+func (r *bonusRepository) GetRuleByID(ctx context.Context, ruleID string) (*models.BonusRule, error) {
+	rule := &models.BonusRule{}
+	err := r.db.
+		NewSelect().
+		Model(rule).
+		Where("id = ?", ruleID).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding bonus rule: %w", err)
+	}
+	return rule, nil
+}
+
+// FindRulesByInstance returns all bonus rules for an instance, oldest first.
+func (r *bonusRepository) FindRulesByInstance(ctx context.Context, instanceID string) ([]models.BonusRule, error) {
+	rules := []models.BonusRule{}
+	err := r.db.
+		NewSelect().
+		Model(&rules).
+		Where("instance_id = ?", instanceID).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding bonus rules by instance: %w", err)
+	}
+	return rules, nil
+}
+
+// DeleteRule removes a bonus rule.
+// Points already awarded by the rule are kept.
+func (r *bonusRepository) DeleteRule(ctx context.Context, ruleID string) error {
+	_, err := r.db.
+		NewDelete().
+		Model(&models.BonusRule{}).
+		Where("id = ?", ruleID).
+		Exec(ctx)
+	return err
+}
+
+// SaveAward records bonus points awarded to a team as part of a larger change.
+// Nothing is saved if the rule has already rewarded the team at the location,
+// so the same bonus can never be paid twice.
+func (r *bonusRepository) SaveAward(ctx context.Context, tx *bun.Tx, award *models.BonusAward) (bool, error) {
+	if award.TeamCode == "" || award.RuleID == "" {
+		return false, errors.New("team code and rule ID must be set")
+	}
+	if award.ID == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return false, fmt.Errorf("generating UUID: %w", err)
+		}
+		award.ID = id.String()
+	}
+	res, err := tx.NewInsert().
+		Model(award).
+		Ignore().
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// HasAward returns true if a rule has already rewarded a team at a location.
+// An empty locationID matches awards that are not tied to a location.
+func (r *bonusRepository) HasAward(ctx context.Context, teamCode string, ruleID string, locationID string) (bool, error) {
+	query := r.db.
+		NewSelect().
+		Model((*models.BonusAward)(nil)).
+		Where("team_code = ?", teamCode).
+		Where("rule_id = ?", ruleID)
+	if locationID == "" {
+		query = query.Where("location_id IS NULL")
+	} else {
+		query = query.Where("location_id = ?", locationID)
+	}
+	exists, err := query.Exists(ctx)
+	if err != nil {
+		return false, fmt.Errorf("checking bonus award: %w", err)
+	}
+	return exists, nil
+}
+
+// FindAwardsByTeam returns the bonus points awarded to a team, oldest first.
+func (r *bonusRepository) FindAwardsByTeam(ctx context.Context, teamCode string) ([]models.BonusAward, error) {
+	awards := []models.BonusAward{}
+	err := r.db.
+		NewSelect().
+		Model(&awards).
+		Where("team_code = ?", teamCode).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding bonus awards by team: %w", err)
+	}
+	return awards, nil
+}
+
+// DeleteAwardsByTeamCodes removes the awards for the given teams.
+func (r *bonusRepository) DeleteAwardsByTeamCodes(ctx context.Context, tx *bun.Tx, instanceID string, teamCodes []string) error {
+	_, err := tx.NewDelete().
+		Model(&models.BonusAward{}).
+		Where("instance_id = ? AND team_code IN (?)", instanceID, bun.In(teamCodes)).
+		Exec(ctx)
+	return err
+}
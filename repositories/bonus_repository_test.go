@@ -0,0 +1,125 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBonusRepository_Rules(t *testing.T) {
+	db, cleanup := setupDB(t)
+	defer cleanup()
+	repo := repositories.NewBonusRepository(db)
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	rule := &models.BonusRule{
+		InstanceID: instanceID,
+		Type:       models.StreakBonus,
+		Points:     5,
+		Threshold:  3,
+	}
+	require.NoError(t, repo.SaveRule(ctx, rule))
+	assert.NotEmpty(t, rule.ID)
+	require.NoError(t, repo.SaveRule(ctx, &models.BonusRule{InstanceID: gofakeit.UUID()}))
+
+	err := repo.SaveRule(ctx, &models.BonusRule{Points: 5})
+	assert.Error(t, err, "rules need an instance")
+
+	rule.Points = 10
+	require.NoError(t, repo.UpdateRule(ctx, rule))
+	found, err := repo.GetRuleByID(ctx, rule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 10, found.Points)
+	assert.Equal(t, models.StreakBonus, found.Type)
+
+	rules, err := repo.FindRulesByInstance(ctx, instanceID)
+	require.NoError(t, err)
+	assert.Len(t, rules, 1)
+
+	require.NoError(t, repo.DeleteRule(ctx, rule.ID))
+	_, err = repo.GetRuleByID(ctx, rule.ID)
+	assert.Error(t, err)
+}
+
+func TestBonusRepository_Awards(t *testing.T) {
+	dbc, cleanup := setupDB(t)
+	defer cleanup()
+	repo := repositories.NewBonusRepository(dbc)
+	transactor := db.NewTransactor(dbc)
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	teamCode := gofakeit.LetterN(4)
+	ruleID := gofakeit.UUID()
+	locationID := gofakeit.UUID()
+
+	// save records an award in its own transaction
+	save := func(award models.BonusAward) bool {
+		tx, err := transactor.BeginTx(ctx, &sql.TxOptions{})
+		require.NoError(t, err)
+		saved, err := repo.SaveAward(ctx, tx, &award)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		return saved
+	}
+
+	arrival := models.BonusAward{
+		InstanceID: instanceID,
+		TeamCode:   teamCode,
+		RuleID:     ruleID,
+		LocationID: locationID,
+		Points:     10,
+		Reason:     "1st to arrive",
+	}
+	finish := models.BonusAward{
+		InstanceID: instanceID,
+		TeamCode:   teamCode,
+		RuleID:     gofakeit.UUID(),
+		Points:     20,
+		Reason:     "Quick finish",
+	}
+	assert.True(t, save(arrival))
+	assert.True(t, save(finish))
+	assert.False(t, save(arrival), "a rule rewards a team once at each location")
+	assert.False(t, save(finish), "a rule rewards a team once without a location")
+
+	tests := []struct {
+		name       string
+		ruleID     string
+		locationID string
+		want       bool
+	}{
+		{"Same rule and location", ruleID, locationID, true},
+		{"Same rule, other location", ruleID, gofakeit.UUID(), false},
+		{"Same rule, no location", ruleID, "", false},
+		{"Other rule", gofakeit.UUID(), locationID, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.HasAward(ctx, teamCode, tt.ruleID, tt.locationID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	awards, err := repo.FindAwardsByTeam(ctx, teamCode)
+	require.NoError(t, err)
+	require.Len(t, awards, 2)
+
+	tx, err := transactor.BeginTx(ctx, &sql.TxOptions{})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteAwardsByTeamCodes(ctx, tx, instanceID, []string{teamCode}))
+	require.NoError(t, tx.Commit())
+
+	awards, err = repo.FindAwardsByTeam(ctx, teamCode)
+	require.NoError(t, err)
+	assert.Empty(t, awards)
+}
@@ -13,6 +13,8 @@ import (
 type CheckInRepository interface {
 	// FindCheckInByTeamAndLocation finds a check-in by team and location
 	FindCheckInByTeamAndLocation(ctx context.Context, teamCode string, locationID string) (*models.CheckIn, error)
+	// FindByLocation returns all check-ins at a location, earliest first
+	FindByLocation(ctx context.Context, locationID string) ([]models.CheckIn, error)
 
 	// LogCheckIn logs a new check-in for a team at a location
 	LogCheckIn(ctx context.Context, team models.Team, location models.Location, mustCheckOut bool, validationRequired bool) (models.CheckIn, error)
@@ -21,6 +23,13 @@ type CheckInRepository interface {
 
 	// Update updates an existing check-in
 	Update(ctx context.Context, checkIn *models.CheckIn) error
+	// CompleteBlocks marks the blocks for a check-in as complete
+	CompleteBlocks(ctx context.Context, tx *bun.Tx, teamCode string, locationID string) error
+	// Close ends a check-in that is waiting for the team to check out
+	Close(ctx context.Context, tx *bun.Tx, teamCode string, locationID string) error
+
+	// Delete removes a team's check-in at a location
+	Delete(ctx context.Context, tx *bun.Tx, teamCode string, locationID string) error
 
 	// DeleteByTeamCodes deletes all check-ins for the given teams
 	DeleteByTeamCodes(ctx context.Context, tx *bun.Tx, instanceID string, teamCodes []string) error
@@ -45,6 +54,20 @@ func (r *checkInRepository) FindCheckInByTeamAndLocation(ctx context.Context, te
 	return &checkIn, nil
 }
 
+// FindByLocation returns all check-ins at a location, earliest first.
+func (r *checkInRepository) FindByLocation(ctx context.Context, locationID string) ([]models.CheckIn, error) {
+	checkIns := []models.CheckIn{}
+	err := r.db.NewSelect().
+		Model(&checkIns).
+		Where("location_id = ?", locationID).
+		Order("time_in ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding check ins by location: %w", err)
+	}
+	return checkIns, nil
+}
+
 func (r *checkInRepository) Update(ctx context.Context, checkIn *models.CheckIn) error {
 	_, err := r.db.NewUpdate().Model(checkIn).WherePK().Exec(ctx)
 	return err
@@ -110,6 +133,39 @@ func (r *checkInRepository) LogCheckOut(ctx context.Context, team *models.Team,
 	return *checkIn, nil
 }
 
+// CompleteBlocks marks the blocks for a check-in as complete.
+func (r *checkInRepository) CompleteBlocks(ctx context.Context, tx *bun.Tx, teamCode string, locationID string) error {
+	_, err := tx.NewUpdate().
+		Model((*models.CheckIn)(nil)).
+		Set("blocks_completed = ?", true).
+		Set("updated_at = ?", time.Now()).
+		Where("team_code = ? AND location_id = ?", teamCode, locationID).
+		Exec(ctx)
+	return err
+}
+
+// Close ends a check-in that is waiting for the team to check out.
+func (r *checkInRepository) Close(ctx context.Context, tx *bun.Tx, teamCode string, locationID string) error {
+	_, err := tx.NewUpdate().
+		Model((*models.CheckIn)(nil)).
+		Set("time_out = ?", time.Now().UTC()).
+		Set("must_check_out = ?", false).
+		Set("updated_at = ?", time.Now()).
+		Where("team_code = ? AND location_id = ?", teamCode, locationID).
+		Where("must_check_out = ?", true).
+		Exec(ctx)
+	return err
+}
+
+// Delete removes a team's check-in at a location.
+func (r *checkInRepository) Delete(ctx context.Context, tx *bun.Tx, teamCode string, locationID string) error {
+	_, err := tx.NewDelete().
+		Model((*models.CheckIn)(nil)).
+		Where("team_code = ? AND location_id = ?", teamCode, locationID).
+		Exec(ctx)
+	return err
+}
+
 // DeleteByTeamCodes deletes all check-ins for the given teams.
 func (r *checkInRepository) DeleteByTeamCodes(ctx context.Context, tx *bun.Tx, instanceID string, teamCodes []string) error {
 	_, err := tx.NewDelete().
@@ -13,6 +13,8 @@ import (
 type ClueRepository interface {
 	// Save saves or updates a clue in the database
 	Save(ctx context.Context, c *models.Clue) error
+	// SaveWithTransaction saves a clue as part of a larger change
+	SaveWithTransaction(ctx context.Context, tx *bun.Tx, c *models.Clue) error
 
 	// FindCluesByLocation returns all clues for a given location
 	FindCluesByLocation(ctx context.Context, locationID string) ([]models.Clue, error)
@@ -39,6 +41,15 @@ func NewClueRepository(db *bun.DB) ClueRepository {
 
 // Save saves or updates a clue in the database.
 func (r *clueRepository) Save(ctx context.Context, c *models.Clue) error {
+	return r.save(ctx, r.db, c)
+}
+
+// SaveWithTransaction saves a clue as part of a larger change.
+func (r *clueRepository) SaveWithTransaction(ctx context.Context, tx *bun.Tx, c *models.Clue) error {
+	return r.save(ctx, tx, c)
+}
+
+func (r *clueRepository) save(ctx context.Context, db bun.IDB, c *models.Clue) error {
 	if c.InstanceID == "" || c.LocationID == "" {
 		return errors.New("instance ID and location ID must be set")
 	}
@@ -50,7 +61,7 @@ func (r *clueRepository) Save(ctx context.Context, c *models.Clue) error {
 		}
 		c.ID = id.String()
 	}
-	_, err = r.db.NewInsert().Model(c).Exec(ctx)
+	_, err = db.NewInsert().Model(c).Exec(ctx)
 	return err
 }
 
@@ -32,6 +32,38 @@ func (r *FacilitatorTokenRepo) GetToken(ctx context.Context, token string) (*mod
 	return &facToken, nil
 }
 
+// FindByInstanceID returns the unexpired tokens for an instance, newest first.
+func (r *FacilitatorTokenRepo) FindByInstanceID(ctx context.Context, instanceID string) ([]models.FacilitatorToken, error) {
+	var tokens []models.FacilitatorToken
+	err := r.db.NewSelect().
+		Model(&tokens).
+		Where("instance_id = ?", instanceID).
+		Where("expires_at >= ?", time.Now().UTC().Format("2006-01-02 15:04:05")).
+		Order("created_at DESC").
+		Scan(ctx)
+	return tokens, err
+}
+
+// UpdateLastUsed records when a token was last used.
+func (r *FacilitatorTokenRepo) UpdateLastUsed(ctx context.Context, token string, usedAt time.Time) error {
+	_, err := r.db.NewUpdate().
+		Model(&models.FacilitatorToken{}).
+		Set("last_used_at = ?", usedAt).
+		Where("token = ?", token).
+		Exec(ctx)
+	return err
+}
+
+// DeleteToken removes a token so it can no longer be used.
+func (r *FacilitatorTokenRepo) DeleteToken(ctx context.Context, token string) error {
+	_, err := r.db.NewDelete().
+		Model(&models.FacilitatorToken{}).
+		Where("token = ?", token).
+		Exec(ctx)
+	return err
+}
+
+// CleanUpExpiredTokens removes every expired token.
 func (r *FacilitatorTokenRepo) CleanUpExpiredTokens(ctx context.Context) error {
 	currentTime := time.Now().UTC().Format("2006-01-02 15:04:05")
 	_, err := r.db.NewDelete().
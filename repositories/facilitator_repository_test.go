@@ -46,3 +46,53 @@ func TestFacilitatorRepo_SaveAndRetrieveToken(t *testing.T) {
 	assert.ElementsMatch(t, token.Locations, retrieved.Locations) // JSON-safe comparison
 
 }
+
+func TestFacilitatorRepo_FindUpdateAndDelete(t *testing.T) {
+	repo, cleanup := setupFacilitatorTokenRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	instanceID := gofakeit.UUID()
+
+	older := models.FacilitatorToken{
+		Token:      gofakeit.UUID(),
+		InstanceID: instanceID,
+		Name:       "Older",
+		ExpiresAt:  time.Now().Add(time.Hour),
+		CreatedAt:  time.Now().Add(-time.Hour),
+	}
+	newer := models.FacilitatorToken{
+		Token:      gofakeit.UUID(),
+		InstanceID: instanceID,
+		Name:       "Newer",
+		ExpiresAt:  time.Now().Add(time.Hour),
+		CreatedAt:  time.Now(),
+	}
+	expired := models.FacilitatorToken{
+		Token:      gofakeit.UUID(),
+		InstanceID: instanceID,
+		ExpiresAt:  time.Now().Add(-time.Hour),
+		CreatedAt:  time.Now(),
+	}
+	for _, token := range []models.FacilitatorToken{older, newer, expired} {
+		assert.NoError(t, repo.SaveToken(ctx, token))
+	}
+
+	// Expired tokens are not listed and the newest comes first
+	tokens, err := repo.FindByInstanceID(ctx, instanceID)
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 2) {
+		assert.Equal(t, "Newer", tokens[0].Name)
+		assert.Equal(t, "Older", tokens[1].Name)
+	}
+
+	usedAt := time.Now().UTC().Truncate(time.Second)
+	assert.NoError(t, repo.UpdateLastUsed(ctx, older.Token, usedAt))
+	retrieved, err := repo.GetToken(ctx, older.Token)
+	assert.NoError(t, err)
+	assert.True(t, usedAt.Equal(retrieved.LastUsedAt))
+
+	assert.NoError(t, repo.DeleteToken(ctx, older.Token))
+	_, err = repo.GetToken(ctx, older.Token)
+	assert.Error(t, err)
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/uptrace/bun"
+)
+
+type HintRepository interface {
+	// Save creates or updates a hint as part of a larger change
+	Save(ctx context.Context, tx *bun.Tx, h *models.Hint) error
+
+	// GetByID returns a hint by its ID
+	GetByID(ctx context.Context, hintID string) (*models.Hint, error)
+	// FindByLocation returns all hints for a location and its blocks, in order
+	FindByLocation(ctx context.Context, locationID string) ([]models.Hint, error)
+
+	// Delete removes a hint as part of a larger change
+	Delete(ctx context.Context, tx *bun.Tx, hintID string) error
+
+	// SaveTeamHint records a hint revealed by a team
+	SaveTeamHint(ctx context.Context, th *models.TeamHint) error
+	// FindTeamHints returns the hints a team has revealed, oldest first
+	FindTeamHints(ctx context.Context, teamCode string) ([]models.TeamHint, error)
+}
+
+type hintRepository struct {
+	db *bun.DB
+}
+
+// NewHintRepository creates a new HintRepository.
+func NewHintRepository(db *bun.DB) HintRepository {
+	return &hintRepository{
+		db: db,
+	}
+}
+
+// Save creates or updates a hint as part of a larger change.
+// Hints without an ID are created, otherwise the existing hint is updated.
+func (r *hintRepository) Save(ctx context.Context, tx *bun.Tx, h *models.Hint) error {
+	if h.InstanceID == "" || h.LocationID == "" {
+		return errors.New("instance ID and location ID must be set")
+	}
+	if h.ID != "" {
+		h.UpdatedAt = time.Now()
+		_, err := tx.NewUpdate().
+			Model(h).
+			Column("ordering", "content", "penalty", "updated_at").
+			WherePK().
+			Exec(ctx)
+		return err
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Errorf("generating UUID: %w", err)
+	}
+	h.ID = id.String()
+	_, err = tx.NewInsert().Model(h).Exec(ctx)
+	return err
+}
+
+// GetByID returns a hint by its ID.
+func (r *hintRepository) GetByID(ctx context.Context, hintID string) (*models.Hint, error) {
+	hint := &models.Hint{}
+	err := r.db.
+		NewSelect().
+		Model(hint).
+		Where("id = ?", hintID).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding hint: %w", err)
+	}
+	return hint, nil
+}
+
+// FindByLocation returns all hints for a location and its blocks, in order.
+func (r *hintRepository) FindByLocation(ctx context.Context, locationID string) ([]models.Hint, error) {
+	hints := []models.Hint{}
+	err := r.db.
+		NewSelect().
+		Model(&hints).
+		Where("location_id = ?", locationID).
+		Order("ordering ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding hints by location: %w", err)
+	}
+	return hints, nil
+}
+
+// Delete removes a hint as part of a larger change.
+func (r *hintRepository) Delete(ctx context.Context, tx *bun.Tx, hintID string) error {
+	_, err := tx.NewDelete().
+		Model(&models.Hint{}).
+		Where("id = ?", hintID).
+		Exec(ctx)
+	return err
+}
+
+// SaveTeamHint records a hint revealed by a team.
+func (r *hintRepository) SaveTeamHint(ctx context.Context, th *models.TeamHint) error {
+	if th.TeamCode == "" || th.HintID == "" {
+		return errors.New("team code and hint ID must be set")
+	}
+	_, err := r.db.NewInsert().Model(th).Exec(ctx)
+	return err
+}
+
+// FindTeamHints returns the hints a team has revealed, oldest first.
+func (r *hintRepository) FindTeamHints(ctx context.Context, teamCode string) ([]models.TeamHint, error) {
+	teamHints := []models.TeamHint{}
+	err := r.db.
+		NewSelect().
+		Model(&teamHints).
+		Where("team_hint.team_code = ?", teamCode).
+		Relation("Hint").
+		Relation("Location").
+		Order("team_hint.created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding team hints: %w", err)
+	}
+	return teamHints, nil
+}
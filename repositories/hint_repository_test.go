@@ -0,0 +1,141 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupHintRepo(t *testing.T) (repositories.HintRepository, db.Transactor, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+
+	hintRepo := repositories.NewHintRepository(dbc)
+	return hintRepo, transactor, cleanup
+}
+
+// saveHint saves a hint in its own transaction.
+func saveHint(t *testing.T, repo repositories.HintRepository, transactor db.Transactor, hint *models.Hint) error {
+	t.Helper()
+	tx, err := transactor.BeginTx(context.Background(), &sql.TxOptions{})
+	require.NoError(t, err)
+	err = repo.Save(context.Background(), tx, hint)
+	if err != nil {
+		require.NoError(t, tx.Rollback())
+		return err
+	}
+	require.NoError(t, tx.Commit())
+	return nil
+}
+
+func TestHintRepository_Save(t *testing.T) {
+	repo, transactor, cleanup := setupHintRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	hint := &models.Hint{
+		InstanceID: gofakeit.UUID(),
+		LocationID: gofakeit.UUID(),
+		Content:    "Look behind the fountain",
+		Penalty:    5,
+	}
+	err := saveHint(t, repo, transactor, hint)
+	require.NoError(t, err)
+	assert.NotEmpty(t, hint.ID)
+
+	found, err := repo.GetByID(ctx, hint.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hint.Content, found.Content)
+	assert.Equal(t, 5, found.Penalty)
+
+	// Saving a hint with an ID updates it in place
+	hint.Content = "Look behind the statue"
+	hint.Penalty = 3
+	err = saveHint(t, repo, transactor, hint)
+	require.NoError(t, err)
+	found, err = repo.GetByID(ctx, hint.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Look behind the statue", found.Content)
+	assert.Equal(t, 3, found.Penalty)
+
+	err = saveHint(t, repo, transactor, &models.Hint{Content: "Missing location"})
+	assert.Error(t, err)
+}
+
+func TestHintRepository_FindAndDelete(t *testing.T) {
+	repo, transactor, cleanup := setupHintRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	locationID := gofakeit.UUID()
+	blockID := gofakeit.UUID()
+	hints := []*models.Hint{
+		{InstanceID: instanceID, LocationID: locationID, Order: 1, Content: "Second"},
+		{InstanceID: instanceID, LocationID: locationID, Order: 0, Content: "First"},
+		{InstanceID: instanceID, LocationID: locationID, BlockID: blockID, Content: "Block hint"},
+	}
+	for _, hint := range hints {
+		require.NoError(t, saveHint(t, repo, transactor, hint))
+	}
+
+	found, err := repo.FindByLocation(ctx, locationID)
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+	locationHints := models.HintsFor(found, "")
+	require.Len(t, locationHints, 2)
+	assert.Equal(t, "First", locationHints[0].Content, "location hints are ordered")
+	assert.Equal(t, "Second", locationHints[1].Content)
+	assert.Len(t, models.HintsFor(found, blockID), 1)
+
+	tx, err := transactor.BeginTx(ctx, &sql.TxOptions{})
+	require.NoError(t, err)
+	err = repo.Delete(ctx, tx, hints[1].ID)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	found, err = repo.FindByLocation(ctx, locationID)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, "Second", models.HintsFor(found, "")[0].Content)
+}
+
+func TestHintRepository_TeamHints(t *testing.T) {
+	repo, transactor, cleanup := setupHintRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	hint := &models.Hint{
+		InstanceID: gofakeit.UUID(),
+		LocationID: gofakeit.UUID(),
+		Content:    "Look up",
+		Penalty:    2,
+	}
+	require.NoError(t, saveHint(t, repo, transactor, hint))
+
+	teamCode := gofakeit.Password(false, true, false, false, false, 5)
+	err := repo.SaveTeamHint(ctx, &models.TeamHint{
+		TeamCode:   teamCode,
+		HintID:     hint.ID,
+		InstanceID: hint.InstanceID,
+		LocationID: hint.LocationID,
+		Penalty:    hint.Penalty,
+	})
+	require.NoError(t, err)
+
+	teamHints, err := repo.FindTeamHints(ctx, teamCode)
+	require.NoError(t, err)
+	require.Len(t, teamHints, 1)
+	assert.Equal(t, "Look up", teamHints[0].Hint.Content)
+
+	err = repo.SaveTeamHint(ctx, &models.TeamHint{TeamCode: teamCode})
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/uptrace/bun"
+)
+
+type InstanceMemberRepository interface {
+	// Create saves a new member or invitation to the database
+	Create(ctx context.Context, member *models.InstanceMember) error
+	// Update saves changes to a member
+	Update(ctx context.Context, member *models.InstanceMember) error
+	// Delete removes a member from an instance
+	Delete(ctx context.Context, memberID string) error
+
+	// GetByID returns a member by their ID
+	GetByID(ctx context.Context, memberID string) (*models.InstanceMember, error)
+	// GetByInviteToken returns the invitation with the given token
+	GetByInviteToken(ctx context.Context, token string) (*models.InstanceMember, error)
+	// GetByInstanceAndUser returns a user's accepted membership of an instance
+	GetByInstanceAndUser(ctx context.Context, instanceID, userID string) (*models.InstanceMember, error)
+	// FindByInstanceID returns the members and invitations for an instance,
+	// oldest first
+	FindByInstanceID(ctx context.Context, instanceID string) ([]models.InstanceMember, error)
+	// FindInstancesByUserID returns the instances a user has accepted
+	// invitations to
+	FindInstancesByUserID(ctx context.Context, userID string) ([]models.Instance, error)
+}
+
+type instanceMemberRepository struct {
+	db *bun.DB
+}
+
+// NewInstanceMemberRepository creates a new InstanceMemberRepository.
+func NewInstanceMemberRepository(db *bun.DB) InstanceMemberRepository {
+	return &instanceMemberRepository{
+		db: db,
+	}
+}
+
+// Create saves a new member or invitation to the database.
+func (r *instanceMemberRepository) Create(ctx context.Context, member *models.InstanceMember) error {
+	if member.InstanceID == "" || member.Email == "" {
+		return errors.New("instance ID and email must be set")
+	}
+	if member.ID == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return fmt.Errorf("generating UUID: %w", err)
+		}
+		member.ID = id.String()
+	}
+	member.Email = strings.ToLower(strings.TrimSpace(member.Email))
+	_, err := r.db.NewInsert().Model(member).Exec(ctx)
+	return err
+}
+
+// Update saves changes to a member.
+func (r *instanceMemberRepository) Update(ctx context.Context, member *models.InstanceMember) error {
+	member.UpdatedAt = time.Now().UTC()
+	_, err := r.db.NewUpdate().
+		Model(member).
+		Column("user_id", "role", "invite_token", "accepted_at", "updated_at").
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+// Delete removes a member from an instance.
+func (r *instanceMemberRepository) Delete(ctx context.Context, memberID string) error {
+	_, err := r.db.NewDelete().
+		Model(&models.InstanceMember{}).
+		Where("id = ?", memberID).
+		Exec(ctx)
+	return err
+}
+
+// GetByID returns a member by their ID.
+func (r *instanceMemberRepository) GetByID(ctx context.Context, memberID string) (*models.InstanceMember, error) {
+	var member models.InstanceMember
+	err := r.db.NewSelect().
+		Model(&member).
+		Where("instance_member.id = ?", memberID).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetByInviteToken returns the invitation with the given token.
+func (r *instanceMemberRepository) GetByInviteToken(ctx context.Context, token string) (*models.InstanceMember, error) {
+	var member models.InstanceMember
+	err := r.db.NewSelect().
+		Model(&member).
+		Where("invite_token = ?", token).
+		Relation("Instance").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetByInstanceAndUser returns a user's accepted membership of an instance.
+func (r *instanceMemberRepository) GetByInstanceAndUser(ctx context.Context, instanceID, userID string) (*models.InstanceMember, error) {
+	var member models.InstanceMember
+	err := r.db.NewSelect().
+		Model(&member).
+		Where("instance_id = ?", instanceID).
+		Where("user_id = ?", userID).
+		Where("accepted_at IS NOT NULL").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// FindByInstanceID returns the members and invitations for an instance.
+func (r *instanceMemberRepository) FindByInstanceID(ctx context.Context, instanceID string) ([]models.InstanceMember, error) {
+	var members []models.InstanceMember
+	err := r.db.NewSelect().
+		Model(&members).
+		Where("instance_member.instance_id = ?", instanceID).
+		Relation("User").
+		Order("instance_member.created_at ASC").
+		Scan(ctx)
+	return members, err
+}
+
+// FindInstancesByUserID returns the instances a user has accepted invitations to.
+func (r *instanceMemberRepository) FindInstancesByUserID(ctx context.Context, userID string) ([]models.Instance, error) {
+	var instances []models.Instance
+	err := r.db.NewSelect().
+		Model(&instances).
+		Join("JOIN instance_members AS im ON im.instance_id = instance.id").
+		Where("im.user_id = ?", userID).
+		Where("im.accepted_at IS NOT NULL").
+		Where("instance.is_template = ?", false).
+		Order("instance.name ASC").
+		Scan(ctx)
+	return instances, err
+}
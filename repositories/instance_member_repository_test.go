@@ -0,0 +1,69 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceMemberRepository(t *testing.T) {
+	db, cleanup := setupDB(t)
+	defer cleanup()
+	repo := repositories.NewInstanceMemberRepository(db)
+	instanceRepo := repositories.NewInstanceRepository(db)
+	ctx := context.Background()
+
+	instance := &models.Instance{Name: "Shared", UserID: gofakeit.UUID()}
+	require.NoError(t, instanceRepo.Create(ctx, instance))
+	userID := gofakeit.UUID()
+
+	err := repo.Create(ctx, &models.InstanceMember{Email: "nobody@example.com"})
+	assert.Error(t, err, "members need an instance")
+
+	invite := &models.InstanceMember{
+		InstanceID:  instance.ID,
+		Email:       " Friend@Example.com ",
+		Role:        models.RoleEditor,
+		InviteToken: gofakeit.UUID(),
+	}
+	require.NoError(t, repo.Create(ctx, invite))
+	assert.NotEmpty(t, invite.ID)
+	assert.Equal(t, "friend@example.com", invite.Email)
+
+	// Pending invitations do not grant access
+	_, err = repo.GetByInstanceAndUser(ctx, instance.ID, userID)
+	assert.Error(t, err)
+
+	found, err := repo.GetByInviteToken(ctx, invite.InviteToken)
+	require.NoError(t, err)
+	require.NotNil(t, found.Instance)
+	assert.Equal(t, "Shared", found.Instance.Name)
+
+	found.UserID = userID
+	found.InviteToken = ""
+	found.AcceptedAt = time.Now().UTC()
+	require.NoError(t, repo.Update(ctx, found))
+
+	member, err := repo.GetByInstanceAndUser(ctx, instance.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleEditor, member.Role)
+
+	instances, err := repo.FindInstancesByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, instance.ID, instances[0].ID)
+
+	members, err := repo.FindByInstanceID(ctx, instance.ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 1)
+
+	require.NoError(t, repo.Delete(ctx, member.ID))
+	_, err = repo.GetByID(ctx, member.ID)
+	assert.Error(t, err)
+}
@@ -13,11 +13,21 @@ import (
 type InstanceRepository interface {
 	// Create saves an instance to the database
 	Create(ctx context.Context, instance *models.Instance) error
+	// CreateWithTransaction saves an instance to the database as part of a larger change
+	CreateWithTransaction(ctx context.Context, tx *bun.Tx, instance *models.Instance) error
 
 	// GetByID finds an instance by ID
 	GetByID(ctx context.Context, id string) (*models.Instance, error)
 	// FindByUserID finds all instances associated with a user ID
 	FindByUserID(ctx context.Context, userID string) ([]models.Instance, error)
+	// FindByOrganisationID finds the instances in an organisation, by name
+	FindByOrganisationID(ctx context.Context, organisationID string) ([]models.Instance, error)
+	// FindTemplatesByUserID finds the templates a user has published
+	FindTemplatesByUserID(ctx context.Context, userID string) ([]models.Instance, error)
+	// FindPublicTemplates finds all templates listed in the gallery
+	FindPublicTemplates(ctx context.Context) ([]models.Instance, error)
+	// GetTemplateByShareToken finds a template by its share link token
+	GetTemplateByShareToken(ctx context.Context, token string) (*models.Instance, error)
 
 	// Update updates an instance in the database
 	Update(ctx context.Context, instance *models.Instance) error
@@ -25,6 +35,7 @@ type InstanceRepository interface {
 	// Delete deletes an instance from the database.
 	// Deleting an instance cascades to all related data.
 	Delete(ctx context.Context, tx *bun.Tx, id string) error
+
 	// DeleteByUserID removes all instances associated with a user ID
 	DeleteByUser(ctx context.Context, tx *bun.Tx, userID string) error
 
@@ -43,13 +54,22 @@ func NewInstanceRepository(db *bun.DB) InstanceRepository {
 }
 
 func (r *instanceRepository) Create(ctx context.Context, instance *models.Instance) error {
+	return r.create(ctx, r.db, instance)
+}
+
+// CreateWithTransaction saves an instance to the database as part of a larger change.
+func (r *instanceRepository) CreateWithTransaction(ctx context.Context, tx *bun.Tx, instance *models.Instance) error {
+	return r.create(ctx, tx, instance)
+}
+
+func (r *instanceRepository) create(ctx context.Context, db bun.IDB, instance *models.Instance) error {
 	if instance.ID == "" {
 		instance.ID = uuid.New().String()
 	}
 	if instance.UserID == "" {
 		return errors.New("UserID is required")
 	}
-	_, err := r.db.NewInsert().Model(instance).Exec(ctx)
+	_, err := db.NewInsert().Model(instance).Exec(ctx)
 	if err != nil {
 		return err
 	}
@@ -97,6 +117,75 @@ func (r *instanceRepository) FindByUserID(ctx context.Context, userID string) ([
 	return instances, nil
 }
 
+// FindByOrganisationID finds the instances in an organisation.
+func (r *instanceRepository) FindByOrganisationID(ctx context.Context, organisationID string) ([]models.Instance, error) {
+	instances := []models.Instance{}
+	err := r.db.NewSelect().
+		Model(&instances).
+		Where("organisation_id = ?", organisationID).
+		Where("is_template = ?", false).
+		Order("name ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// FindTemplatesByUserID finds the templates a user has published.
+func (r *instanceRepository) FindTemplatesByUserID(ctx context.Context, userID string) ([]models.Instance, error) {
+	instances := []models.Instance{}
+	err := r.db.NewSelect().
+		Model(&instances).
+		Where("user_id = ?", userID).
+		Where("is_template = ?", true).
+		Relation("Locations").
+		Order("name ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// FindPublicTemplates finds all templates listed in the gallery.
+func (r *instanceRepository) FindPublicTemplates(ctx context.Context) ([]models.Instance, error) {
+	instances := []models.Instance{}
+	err := r.db.NewSelect().
+		Model(&instances).
+		Where("is_template = ?", true).
+		Where("is_public = ?", true).
+		Relation("Locations").
+		Order("created_at DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// GetTemplateByShareToken finds a template by its share link token.
+// Locations are loaded in order with their markers, clues and blocks.
+func (r *instanceRepository) GetTemplateByShareToken(ctx context.Context, token string) (*models.Instance, error) {
+	instance := &models.Instance{}
+	err := r.db.NewSelect().
+		Model(instance).
+		Where("share_token = ?", token).
+		Where("is_template = ?", true).
+		Relation("Locations", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("order ASC")
+		}).
+		Relation("Locations.Marker").
+		Relation("Locations.Clues").
+		Relation("Locations.Blocks").
+		Relation("Settings").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
 func (r *instanceRepository) Delete(ctx context.Context, tx *bun.Tx, id string) error {
 	// Delete instance
 	_, err := tx.NewDelete().Model(&models.Instance{}).Where("id = ?", id).Exec(ctx)
@@ -122,6 +211,12 @@ func (r *instanceRepository) Delete(ctx context.Context, tx *bun.Tx, id string)
 		return err
 	}
 
+	// Delete collaborators
+	_, err = tx.NewDelete().Model(&models.InstanceMember{}).Where("instance_id = ?", id).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -132,10 +227,24 @@ func (r *instanceRepository) DeleteByUser(ctx context.Context, tx *bun.Tx, userI
 		return fmt.Errorf("finding instances by user ID: %w", err)
 	}
 	for _, instance := range instances {
+		// Organisation instances are kept so an admin can hand them over
+		if instance.OrganisationID != "" {
+			continue
+		}
 		if err := r.Delete(ctx, tx, instance.ID); err != nil {
 			return fmt.Errorf("deleting instance: %w", err)
 		}
 	}
+
+	// Remove the user from instances shared with them
+	_, err = tx.NewDelete().Model(&models.InstanceMember{}).Where("user_id = ?", userID).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("deleting memberships: %w", err)
+	}
+	_, err = tx.NewDelete().Model(&models.OrganisationMember{}).Where("user_id = ?", userID).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("deleting organisation memberships: %w", err)
+	}
 	return nil
 }
 
@@ -12,10 +12,15 @@ import (
 type InstanceSettingsRepository interface {
 	// Create new instance settings to the database
 	Create(ctx context.Context, settings *models.InstanceSettings) error
+	// CreateWithTransaction saves new instance settings as part of a larger change
+	CreateWithTransaction(ctx context.Context, tx *bun.Tx, settings *models.InstanceSettings) error
 
 	// Update updates an instance in the database
 	Update(ctx context.Context, settings *models.InstanceSettings) error
 
+	// GetByLeaderboardToken returns the settings with the given leaderboard token
+	GetByLeaderboardToken(ctx context.Context, token string) (*models.InstanceSettings, error)
+
 	// Delete removes and instance from the database given the instanceID
 	Delete(ctx context.Context, tx *bun.Tx, instanceID string) error
 }
@@ -31,12 +36,21 @@ func NewInstanceSettingsRepository(db *bun.DB) InstanceSettingsRepository {
 }
 
 func (r *instanceSettingsRepository) Create(ctx context.Context, settings *models.InstanceSettings) error {
+	return r.create(ctx, r.db, settings)
+}
+
+// CreateWithTransaction saves new instance settings as part of a larger change.
+func (r *instanceSettingsRepository) CreateWithTransaction(ctx context.Context, tx *bun.Tx, settings *models.InstanceSettings) error {
+	return r.create(ctx, tx, settings)
+}
+
+func (r *instanceSettingsRepository) create(ctx context.Context, db bun.IDB, settings *models.InstanceSettings) error {
 	if settings.InstanceID == "" {
 		return errors.New("instance ID is required")
 	}
 	settings.CreatedAt = time.Now().UTC()
 	settings.UpdatedAt = time.Now().UTC()
-	_, err := r.db.NewInsert().Model(settings).Exec(ctx)
+	_, err := db.NewInsert().Model(settings).Exec(ctx)
 	if err != nil {
 		return err
 	}
@@ -55,6 +69,21 @@ func (r *instanceSettingsRepository) Update(ctx context.Context, settings *model
 	return nil
 }
 
+func (r *instanceSettingsRepository) GetByLeaderboardToken(ctx context.Context, token string) (*models.InstanceSettings, error) {
+	if token == "" {
+		return nil, errors.New("token is required")
+	}
+	settings := &models.InstanceSettings{}
+	err := r.db.NewSelect().
+		Model(settings).
+		Where("leaderboard_token = ?", token).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
 func (r *instanceSettingsRepository) Delete(ctx context.Context, tx *bun.Tx, instanceID string) error {
 	_, err := tx.NewDelete().
 		Model(&models.InstanceSettings{}).
@@ -12,8 +12,12 @@ import (
 type LocationRepository interface {
 	// Create saves or updates a location
 	Create(ctx context.Context, location *models.Location) error
+	// CreateWithTransaction saves or updates a location as part of a larger change
+	CreateWithTransaction(ctx context.Context, tx *bun.Tx, location *models.Location) error
 	// Update updates a location in the database
 	Update(ctx context.Context, location *models.Location) error
+	// UpdateWithTransaction updates a location as part of a larger change
+	UpdateWithTransaction(ctx context.Context, tx *bun.Tx, location *models.Location) error
 
 	// GetByID finds a location by ID
 	GetByID(ctx context.Context, locationID string) (*models.Location, error)
@@ -59,18 +63,36 @@ func NewLocationRepository(db *bun.DB) LocationRepository {
 
 // Create saves or updates a location.
 func (r *locationRepository) Create(ctx context.Context, location *models.Location) error {
+	return r.create(ctx, r.db, location)
+}
+
+// CreateWithTransaction saves or updates a location as part of a larger change.
+func (r *locationRepository) CreateWithTransaction(ctx context.Context, tx *bun.Tx, location *models.Location) error {
+	return r.create(ctx, tx, location)
+}
+
+func (r *locationRepository) create(ctx context.Context, db bun.IDB, location *models.Location) error {
 	var err error
 	if location.ID == "" {
 		location.ID = uuid.New().String()
-		_, err = r.db.NewInsert().Model(location).Exec(ctx)
+		_, err = db.NewInsert().Model(location).Exec(ctx)
 		return err
 	}
-	return r.Update(ctx, location)
+	return r.update(ctx, db, location)
 }
 
 // Update updates a location in the database.
 func (r *locationRepository) Update(ctx context.Context, location *models.Location) error {
-	_, err := r.db.NewUpdate().Model(location).WherePK().Exec(ctx)
+	return r.update(ctx, r.db, location)
+}
+
+// UpdateWithTransaction updates a location as part of a larger change.
+func (r *locationRepository) UpdateWithTransaction(ctx context.Context, tx *bun.Tx, location *models.Location) error {
+	return r.update(ctx, tx, location)
+}
+
+func (r *locationRepository) update(ctx context.Context, db bun.IDB, location *models.Location) error {
+	_, err := db.NewUpdate().Model(location).WherePK().Exec(ctx)
 	return err
 }
 
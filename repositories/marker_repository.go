@@ -12,11 +12,16 @@ import (
 type MarkerRepository interface {
 	// Create a new marker in the database
 	Create(ctx context.Context, marker *models.Marker) error
+	// CreateWithTransaction creates a new marker as part of a larger change
+	CreateWithTransaction(ctx context.Context, tx *bun.Tx, marker *models.Marker) error
 
 	// GetByCode finds a marker by its code
 	GetByCode(ctx context.Context, code string) (*models.Marker, error)
 	// FindNotInInstance finds markers that are not in an instance
 	FindNotInInstance(ctx context.Context, instanceID string, otherInstances []string) ([]models.Marker, error)
+	// FindLibrary finds the library markers in a workspace matching a search
+	// and tag. The personal workspace is used when the organisation ID is empty
+	FindLibrary(ctx context.Context, userID, organisationID, query, tag string) ([]models.Marker, error)
 
 	// Update updates a marker in the database
 	Update(ctx context.Context, marker *models.Marker) error
@@ -44,13 +49,22 @@ func NewMarkerRepository(db *bun.DB) MarkerRepository {
 
 // Create saves or updates a marker in the database.
 func (r *markerRepository) Create(ctx context.Context, marker *models.Marker) error {
+	return r.create(ctx, r.db, marker)
+}
+
+// CreateWithTransaction saves or updates a marker as part of a larger change.
+func (r *markerRepository) CreateWithTransaction(ctx context.Context, tx *bun.Tx, marker *models.Marker) error {
+	return r.create(ctx, tx, marker)
+}
+
+func (r *markerRepository) create(ctx context.Context, db bun.IDB, marker *models.Marker) error {
 	if marker.Code == "" {
 		// TODO: Remove magic number
 		marker.Code = helpers.NewCode(5)
-		_, err := r.db.NewInsert().Model(marker).Exec(ctx)
+		_, err := db.NewInsert().Model(marker).Exec(ctx)
 		return err
 	}
-	_, err := r.db.NewUpdate().Model(marker).WherePK("code").Exec(ctx)
+	_, err := db.NewUpdate().Model(marker).WherePK("code").Exec(ctx)
 	return err
 }
 
@@ -59,7 +73,8 @@ func (r *markerRepository) Update(ctx context.Context, marker *models.Marker) er
 	_, err := r.db.
 		NewUpdate().
 		Model(marker).
-		Column("name", "lat", "lng", "total_visits", "current_count", "avg_duration").
+		Column("name", "lat", "lng", "total_visits", "current_count", "avg_duration",
+			"in_library", "user_id", "organisation_id", "notes", "photo", "tags").
 		WherePK("code").
 		Exec(ctx)
 
@@ -94,6 +109,38 @@ func (r *markerRepository) FindNotInInstance(ctx context.Context, instanceID str
 	return markers, err
 }
 
+// FindLibrary finds the library markers in a workspace.
+// The query matches names, notes and tags, and the tag must match exactly.
+func (r *markerRepository) FindLibrary(ctx context.Context, userID, organisationID, query, tag string) ([]models.Marker, error) {
+	var markers []models.Marker
+	q := r.db.NewSelect().
+		Model(&markers).
+		Where("in_library = ?", true).
+		Where("organisation_id = ?", organisationID)
+	if organisationID == "" {
+		q = q.Where("user_id = ?", userID)
+	}
+	if query = strings.ToLower(strings.TrimSpace(query)); query != "" {
+		like := "%" + query + "%"
+		q = q.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("LOWER(name) LIKE ?", like).
+				WhereOr("LOWER(notes) LIKE ?", like).
+				WhereOr("tags LIKE ?", like)
+		})
+	}
+	if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+		// Tags are stored as a comma separated list
+		q = q.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("tags = ?", tag).
+				WhereOr("tags LIKE ?", tag+",%").
+				WhereOr("tags LIKE ?", "%,"+tag).
+				WhereOr("tags LIKE ?", "%,"+tag+",%")
+		})
+	}
+	err := q.Order("name ASC").Scan(ctx)
+	return markers, err
+}
+
 // UpdateCoords updates the latitude and longitude of a marker in the database.
 func (r *markerRepository) UpdateCoords(ctx context.Context, marker *models.Marker, lat, lng float64) error {
 	marker.Lat = lat
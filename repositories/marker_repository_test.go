@@ -1,9 +1,13 @@
 package repositories_test
 
 import (
+	"context"
 	"testing"
 
+	"github.com/nathanhollows/Rapua/v3/models"
 	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setupMarkerRepo(t *testing.T) (repositories.MarkerRepository, func()) {
@@ -13,3 +17,45 @@ func setupMarkerRepo(t *testing.T) (repositories.MarkerRepository, func()) {
 	markerRepo := repositories.NewMarkerRepository(db)
 	return markerRepo, cleanup
 }
+
+func TestMarkerRepository_FindLibrary(t *testing.T) {
+	repo, cleanup := setupMarkerRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	markers := []models.Marker{
+		{Name: "Library plaque", InLibrary: true, UserID: "user-1", Tags: "campus,plaque"},
+		{Name: "Clocktower", InLibrary: true, UserID: "user-1", Notes: "Under the clock", Tags: "campus"},
+		{Name: "School gate", InLibrary: true, UserID: "user-1", OrganisationID: "org-1", Tags: "campus-gate"},
+		{Name: "Game marker", UserID: "user-1"},
+	}
+	for i := range markers {
+		require.NoError(t, repo.Create(ctx, &markers[i]))
+	}
+
+	tests := []struct {
+		name           string
+		organisationID string
+		query          string
+		tag            string
+		want           []string
+	}{
+		{"Personal library", "", "", "", []string{"Clocktower", "Library plaque"}},
+		{"Organisation library", "org-1", "", "", []string{"School gate"}},
+		{"Search notes", "", "clock", "", []string{"Clocktower"}},
+		{"Search tags", "", "PLAQUE", "", []string{"Library plaque"}},
+		{"Exact tag", "", "", "plaque", []string{"Library plaque"}},
+		{"Tag is not a prefix", "org-1", "", "campus", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := repo.FindLibrary(ctx, "user-1", tt.organisationID, tt.query, tt.tag)
+			require.NoError(t, err)
+			names := []string{}
+			for _, marker := range found {
+				names = append(names, marker.Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
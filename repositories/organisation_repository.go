@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/uptrace/bun"
+)
+
+type OrganisationRepository interface {
+	// Create saves a new organisation to the database
+	Create(ctx context.Context, organisation *models.Organisation) error
+	// GetByID returns an organisation by its ID
+	GetByID(ctx context.Context, organisationID string) (*models.Organisation, error)
+	// FindByUserID returns the organisations a user belongs to, by name
+	FindByUserID(ctx context.Context, userID string) ([]models.Organisation, error)
+
+	// CreateMember adds a user to an organisation
+	CreateMember(ctx context.Context, member *models.OrganisationMember) error
+	// UpdateMember saves changes to a member's role
+	UpdateMember(ctx context.Context, member *models.OrganisationMember) error
+	// DeleteMember removes a user from an organisation
+	DeleteMember(ctx context.Context, memberID string) error
+	// GetMemberByID returns a member by their ID
+	GetMemberByID(ctx context.Context, memberID string) (*models.OrganisationMember, error)
+	// GetMember returns a user's membership of an organisation
+	GetMember(ctx context.Context, organisationID, userID string) (*models.OrganisationMember, error)
+	// FindMembers returns the members of an organisation, oldest first
+	FindMembers(ctx context.Context, organisationID string) ([]models.OrganisationMember, error)
+
+	// Usage totals what the organisation's instances use
+	Usage(ctx context.Context, organisationID string) (*models.OrganisationUsage, error)
+}
+
+type organisationRepository struct {
+	db *bun.DB
+}
+
+// NewOrganisationRepository creates a new OrganisationRepository.
+func NewOrganisationRepository(db *bun.DB) OrganisationRepository {
+	return &organisationRepository{
+		db: db,
+	}
+}
+
+// Create saves a new organisation to the database.
+func (r *organisationRepository) Create(ctx context.Context, organisation *models.Organisation) error {
+	if organisation.Name == "" {
+		return errors.New("name must be set")
+	}
+	if organisation.ID == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return fmt.Errorf("generating UUID: %w", err)
+		}
+		organisation.ID = id.String()
+	}
+	_, err := r.db.NewInsert().Model(organisation).Exec(ctx)
+	return err
+}
+
+// GetByID returns an organisation by its ID.
+func (r *organisationRepository) GetByID(ctx context.Context, organisationID string) (*models.Organisation, error) {
+	var organisation models.Organisation
+	err := r.db.NewSelect().
+		Model(&organisation).
+		Where("id = ?", organisationID).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &organisation, nil
+}
+
+// FindByUserID returns the organisations a user belongs to.
+func (r *organisationRepository) FindByUserID(ctx context.Context, userID string) ([]models.Organisation, error) {
+	var organisations []models.Organisation
+	err := r.db.NewSelect().
+		Model(&organisations).
+		Join("JOIN organisation_members AS om ON om.organisation_id = organisation.id").
+		Where("om.user_id = ?", userID).
+		Order("organisation.name ASC").
+		Scan(ctx)
+	return organisations, err
+}
+
+// CreateMember adds a user to an organisation.
+func (r *organisationRepository) CreateMember(ctx context.Context, member *models.OrganisationMember) error {
+	if member.OrganisationID == "" || member.UserID == "" {
+		return errors.New("organisation ID and user ID must be set")
+	}
+	if member.ID == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return fmt.Errorf("generating UUID: %w", err)
+		}
+		member.ID = id.String()
+	}
+	_, err := r.db.NewInsert().Model(member).Exec(ctx)
+	return err
+}
+
+// UpdateMember saves changes to a member's role.
+func (r *organisationRepository) UpdateMember(ctx context.Context, member *models.OrganisationMember) error {
+	member.UpdatedAt = time.Now().UTC()
+	_, err := r.db.NewUpdate().
+		Model(member).
+		Column("role", "updated_at").
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+// DeleteMember removes a user from an organisation.
+func (r *organisationRepository) DeleteMember(ctx context.Context, memberID string) error {
+	_, err := r.db.NewDelete().
+		Model(&models.OrganisationMember{}).
+		Where("id = ?", memberID).
+		Exec(ctx)
+	return err
+}
+
+// GetMemberByID returns a member by their ID.
+func (r *organisationRepository) GetMemberByID(ctx context.Context, memberID string) (*models.OrganisationMember, error) {
+	var member models.OrganisationMember
+	err := r.db.NewSelect().
+		Model(&member).
+		Where("organisation_member.id = ?", memberID).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetMember returns a user's membership of an organisation.
+func (r *organisationRepository) GetMember(ctx context.Context, organisationID, userID string) (*models.OrganisationMember, error) {
+	var member models.OrganisationMember
+	err := r.db.NewSelect().
+		Model(&member).
+		Where("organisation_id = ?", organisationID).
+		Where("user_id = ?", userID).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// FindMembers returns the members of an organisation.
+func (r *organisationRepository) FindMembers(ctx context.Context, organisationID string) ([]models.OrganisationMember, error) {
+	var members []models.OrganisationMember
+	err := r.db.NewSelect().
+		Model(&members).
+		Where("organisation_member.organisation_id = ?", organisationID).
+		Relation("User").
+		Order("organisation_member.created_at ASC").
+		Scan(ctx)
+	return members, err
+}
+
+// Usage totals what the organisation's instances use.
+func (r *organisationRepository) Usage(ctx context.Context, organisationID string) (*models.OrganisationUsage, error) {
+	usage := &models.OrganisationUsage{}
+	var err error
+
+	usage.Members, err = r.db.NewSelect().
+		Model((*models.OrganisationMember)(nil)).
+		Where("organisation_id = ?", organisationID).
+		Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("counting members: %w", err)
+	}
+
+	usage.Instances, err = r.db.NewSelect().
+		Model((*models.Instance)(nil)).
+		Where("organisation_id = ?", organisationID).
+		Where("is_template = ?", false).
+		Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("counting instances: %w", err)
+	}
+
+	usage.Locations, err = r.db.NewSelect().
+		Model((*models.Location)(nil)).
+		Join("JOIN instances AS i ON i.id = location.instance_id").
+		Where("i.organisation_id = ?", organisationID).
+		Where("i.is_template = ?", false).
+		Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("counting locations: %w", err)
+	}
+
+	usage.Teams, err = r.db.NewSelect().
+		Model((*models.Team)(nil)).
+		Join("JOIN instances AS i ON i.id = team.instance_id").
+		Where("i.organisation_id = ?", organisationID).
+		Where("i.is_template = ?", false).
+		Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("counting teams: %w", err)
+	}
+
+	return usage, nil
+}
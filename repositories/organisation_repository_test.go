@@ -0,0 +1,71 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganisationRepository(t *testing.T) {
+	db, cleanup := setupDB(t)
+	defer cleanup()
+	repo := repositories.NewOrganisationRepository(db)
+	instanceRepo := repositories.NewInstanceRepository(db)
+	locationRepo := repositories.NewLocationRepository(db)
+	ctx := context.Background()
+
+	err := repo.Create(ctx, &models.Organisation{})
+	assert.Error(t, err, "organisations need a name")
+
+	organisation := &models.Organisation{Name: "Example School"}
+	require.NoError(t, repo.Create(ctx, organisation))
+	assert.NotEmpty(t, organisation.ID)
+
+	adminID := gofakeit.UUID()
+	teacherID := gofakeit.UUID()
+	admin := &models.OrganisationMember{OrganisationID: organisation.ID, UserID: adminID, Role: models.OrgRoleAdmin}
+	require.NoError(t, repo.CreateMember(ctx, admin))
+	teacher := &models.OrganisationMember{OrganisationID: organisation.ID, UserID: teacherID, Role: models.OrgRoleMember}
+	require.NoError(t, repo.CreateMember(ctx, teacher))
+
+	organisations, err := repo.FindByUserID(ctx, teacherID)
+	require.NoError(t, err)
+	require.Len(t, organisations, 1)
+	assert.Equal(t, "Example School", organisations[0].Name)
+
+	teacher.Role = models.OrgRoleAdmin
+	require.NoError(t, repo.UpdateMember(ctx, teacher))
+	found, err := repo.GetMember(ctx, organisation.ID, teacherID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrgRoleAdmin, found.Role)
+
+	members, err := repo.FindMembers(ctx, organisation.ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	// Only the organisation's games count towards its usage
+	game := &models.Instance{Name: "Orientation", UserID: teacherID, OrganisationID: organisation.ID}
+	require.NoError(t, instanceRepo.Create(ctx, game))
+	require.NoError(t, instanceRepo.Create(ctx, &models.Instance{Name: "Personal", UserID: teacherID}))
+	require.NoError(t, locationRepo.Create(ctx, &models.Location{Name: "Library", InstanceID: game.ID, MarkerID: gofakeit.UUID()}))
+
+	instances, err := instanceRepo.FindByOrganisationID(ctx, organisation.ID)
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, game.ID, instances[0].ID)
+
+	usage, err := repo.Usage(ctx, organisation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrganisationUsage{Members: 2, Instances: 1, Locations: 1}, *usage)
+
+	require.NoError(t, repo.DeleteMember(ctx, teacher.ID))
+	_, err = repo.GetMember(ctx, organisation.ID, teacherID)
+	assert.Error(t, err)
+	_, err = repo.GetMemberByID(ctx, admin.ID)
+	assert.NoError(t, err)
+}
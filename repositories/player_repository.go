@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/uptrace/bun"
+)
+
+type PlayerRepository interface {
+	// Create saves a new player to the database
+	Create(ctx context.Context, player *models.Player) error
+	// Update saves changes to a player
+	Update(ctx context.Context, player *models.Player) error
+
+	// GetByID returns a player by their ID
+	GetByID(ctx context.Context, playerID string) (*models.Player, error)
+	// FindByTeamCode returns the players in a team, in the order they joined
+	FindByTeamCode(ctx context.Context, teamCode string) ([]models.Player, error)
+	// FindContributions returns the check-ins, submissions and points made by
+	// each player in a team
+	FindContributions(ctx context.Context, teamCode string) ([]models.PlayerContribution, error)
+}
+
+type playerRepository struct {
+	db *bun.DB
+}
+
+// NewPlayerRepository creates a new PlayerRepository.
+func NewPlayerRepository(db *bun.DB) PlayerRepository {
+	return &playerRepository{
+		db: db,
+	}
+}
+
+// Create saves a new player to the database.
+func (r *playerRepository) Create(ctx context.Context, player *models.Player) error {
+	if player.TeamCode == "" || player.InstanceID == "" {
+		return errors.New("team code and instance ID must be set")
+	}
+	if player.ID == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return fmt.Errorf("generating UUID: %w", err)
+		}
+		player.ID = id.String()
+	}
+	player.TeamCode = strings.ToUpper(player.TeamCode)
+	// Players are listed in the order they joined
+	if player.CreatedAt.IsZero() {
+		player.CreatedAt = time.Now().UTC()
+	}
+	if player.LastSeenAt.IsZero() {
+		player.LastSeenAt = player.CreatedAt
+	}
+	_, err := r.db.NewInsert().Model(player).Exec(ctx)
+	return err
+}
+
+// Update saves changes to a player.
+func (r *playerRepository) Update(ctx context.Context, player *models.Player) error {
+	if player.ID == "" {
+		return errors.New("player ID must be set")
+	}
+	player.UpdatedAt = time.Now().UTC()
+	_, err := r.db.NewUpdate().Model(player).WherePK().ExcludeColumn("created_at").Exec(ctx)
+	return err
+}
+
+// GetByID returns a player by their ID.
+func (r *playerRepository) GetByID(ctx context.Context, playerID string) (*models.Player, error) {
+	player := &models.Player{}
+	err := r.db.NewSelect().Model(player).Where("id = ?", playerID).Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return player, nil
+}
+
+// FindByTeamCode returns the players in a team, in the order they joined.
+func (r *playerRepository) FindByTeamCode(ctx context.Context, teamCode string) ([]models.Player, error) {
+	players := []models.Player{}
+	err := r.db.NewSelect().
+		Model(&players).
+		Where("team_code = ?", strings.ToUpper(teamCode)).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
+// FindContributions returns the check-ins, submissions and points made by
+// each player in a team, in the order they joined.
+// Points include location points for check-ins and points awarded for blocks.
+func (r *playerRepository) FindContributions(ctx context.Context, teamCode string) ([]models.PlayerContribution, error) {
+	players, err := r.FindByTeamCode(ctx, teamCode)
+	if err != nil {
+		return nil, fmt.Errorf("finding players: %w", err)
+	}
+	if len(players) == 0 {
+		return []models.PlayerContribution{}, nil
+	}
+
+	type tally struct {
+		PlayerID string `bun:"player_id"`
+		Count    int    `bun:"count"`
+		Points   int    `bun:"points"`
+	}
+
+	var checkIns []tally
+	err = r.db.NewSelect().
+		Model((*models.CheckIn)(nil)).
+		ColumnExpr("player_id, COUNT(*) AS count, COALESCE(SUM(points), 0) AS points").
+		Where("team_code = ?", strings.ToUpper(teamCode)).
+		Where("player_id IS NOT NULL").
+		Group("player_id").
+		Scan(ctx, &checkIns)
+	if err != nil {
+		return nil, fmt.Errorf("counting check-ins: %w", err)
+	}
+
+	var submissions []tally
+	err = r.db.NewSelect().
+		Model((*models.TeamBlockState)(nil)).
+		ColumnExpr("player_id, COUNT(*) AS count, COALESCE(SUM(points_awarded), 0) AS points").
+		Where("team_code = ?", strings.ToUpper(teamCode)).
+		Where("player_id IS NOT NULL").
+		Group("player_id").
+		Scan(ctx, &submissions)
+	if err != nil {
+		return nil, fmt.Errorf("counting submissions: %w", err)
+	}
+
+	contributions := make([]models.PlayerContribution, len(players))
+	index := make(map[string]int, len(players))
+	for i, player := range players {
+		contributions[i].Player = player
+		index[player.ID] = i
+	}
+	for _, t := range checkIns {
+		if i, ok := index[t.PlayerID]; ok {
+			contributions[i].CheckIns = t.Count
+			contributions[i].Points += t.Points
+		}
+	}
+	for _, t := range submissions {
+		if i, ok := index[t.PlayerID]; ok {
+			contributions[i].Submissions = t.Count
+			contributions[i].Points += t.Points
+		}
+	}
+	return contributions, nil
+}
@@ -0,0 +1,53 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayerRepository(t *testing.T) {
+	db, cleanup := setupDB(t)
+	defer cleanup()
+	repo := repositories.NewPlayerRepository(db)
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	teamCode := gofakeit.LetterN(4)
+
+	err := repo.Create(ctx, &models.Player{Name: "Nobody"})
+	assert.Error(t, err, "players need a team")
+
+	first := &models.Player{InstanceID: instanceID, TeamCode: teamCode, Name: "Ana"}
+	require.NoError(t, repo.Create(ctx, first))
+	assert.NotEmpty(t, first.ID)
+	assert.False(t, first.LastSeenAt.IsZero())
+	second := &models.Player{InstanceID: instanceID, TeamCode: teamCode, Name: "Ben", Email: "ben@example.com"}
+	require.NoError(t, repo.Create(ctx, second))
+
+	first.Email = "ana@example.com"
+	require.NoError(t, repo.Update(ctx, first))
+	found, err := repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ana@example.com", found.Email)
+
+	players, err := repo.FindByTeamCode(ctx, teamCode)
+	require.NoError(t, err)
+	require.Len(t, players, 2)
+	assert.Equal(t, "Ana", players[0].Name)
+	assert.Equal(t, "Ben", players[1].Name)
+
+	contributions, err := repo.FindContributions(ctx, teamCode)
+	require.NoError(t, err)
+	require.Len(t, contributions, 2)
+	assert.Zero(t, contributions[0].CheckIns)
+
+	contributions, err = repo.FindContributions(ctx, gofakeit.LetterN(4))
+	require.NoError(t, err)
+	assert.Empty(t, contributions)
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/uptrace/bun"
+)
+
+type PointsRepository interface {
+	// Create adds a transaction to the ledger
+	// Requires a transaction so the team's total can be updated with it
+	Create(ctx context.Context, tx *bun.Tx, transaction *models.PointsTransaction) error
+	// Undo marks a transaction as undone as part of a larger change
+	// It reports whether the transaction was marked, as each transaction can only be undone once
+	Undo(ctx context.Context, tx *bun.Tx, transactionID string, undoneAt time.Time) (bool, error)
+
+	// GetByID returns a transaction by its ID
+	GetByID(ctx context.Context, id string) (*models.PointsTransaction, error)
+	// FindByTeam returns the transactions for a team, oldest first
+	FindByTeam(ctx context.Context, teamCode string) ([]models.PointsTransaction, error)
+
+	// DeleteByTeamCodes removes the transactions for the given teams
+	DeleteByTeamCodes(ctx context.Context, tx *bun.Tx, instanceID string, teamCodes []string) error
+	// DeleteByInstanceID removes all transactions for an instance
+	DeleteByInstanceID(ctx context.Context, tx *bun.Tx, instanceID string) error
+}
+
+type pointsRepository struct {
+	db *bun.DB
+}
+
+// NewPointsRepository creates a new PointsRepository.
+func NewPointsRepository(db *bun.DB) PointsRepository {
+	return &pointsRepository{
+		db: db,
+	}
+}
+
+// Create adds a transaction to the ledger.
+func (r *pointsRepository) Create(ctx context.Context, tx *bun.Tx, transaction *models.PointsTransaction) error {
+	if transaction.InstanceID == "" || transaction.TeamCode == "" {
+		return errors.New("instance ID and team code must be set")
+	}
+	if transaction.ID == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return fmt.Errorf("generating UUID: %w", err)
+		}
+		transaction.ID = id.String()
+	}
+	_, err := tx.NewInsert().Model(transaction).Exec(ctx)
+	return err
+}
+
+// Undo marks a transaction as undone as part of a larger change.
+// Nothing is changed if the transaction has already been undone, so two
+// admins can never undo the same transaction twice.
+func (r *pointsRepository) Undo(ctx context.Context, tx *bun.Tx, transactionID string, undoneAt time.Time) (bool, error) {
+	if transactionID == "" {
+		return false, errors.New("transaction ID must be set")
+	}
+	res, err := tx.NewUpdate().
+		Model((*models.PointsTransaction)(nil)).
+		Set("undone_at = ?", undoneAt).
+		Where("id = ?", transactionID).
+		Where("undone_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// GetByID returns a transaction by its ID.
+func (r *pointsRepository) GetByID(ctx context.Context, id string) (*models.PointsTransaction, error) {
+	transaction := &models.PointsTransaction{}
+	err := r.db.
+		NewSelect().
+		Model(transaction).
+		Where("id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding points transaction: %w", err)
+	}
+	return transaction, nil
+}
+
+// FindByTeam returns the transactions for a team, oldest first.
+func (r *pointsRepository) FindByTeam(ctx context.Context, teamCode string) ([]models.PointsTransaction, error) {
+	transactions := []models.PointsTransaction{}
+	err := r.db.
+		NewSelect().
+		Model(&transactions).
+		Where("team_code = ?", teamCode).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding points transactions by team: %w", err)
+	}
+	return transactions, nil
+}
+
+// DeleteByTeamCodes removes the transactions for the given teams.
+func (r *pointsRepository) DeleteByTeamCodes(ctx context.Context, tx *bun.Tx, instanceID string, teamCodes []string) error {
+	_, err := tx.NewDelete().
+		Model(&models.PointsTransaction{}).
+		Where("instance_id = ? AND team_code IN (?)", instanceID, bun.In(teamCodes)).
+		Exec(ctx)
+	return err
+}
+
+// DeleteByInstanceID removes all transactions for an instance.
+func (r *pointsRepository) DeleteByInstanceID(ctx context.Context, tx *bun.Tx, instanceID string) error {
+	_, err := tx.NewDelete().
+		Model(&models.PointsTransaction{}).
+		Where("instance_id = ?", instanceID).
+		Exec(ctx)
+	return err
+}
@@ -0,0 +1,83 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointsRepository(t *testing.T) {
+	dbc, cleanup := setupDB(t)
+	defer cleanup()
+	repo := repositories.NewPointsRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+	transactor := db.NewTransactor(dbc)
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	team := models.Team{Code: strings.ToUpper(gofakeit.LetterN(4)), InstanceID: instanceID}
+	require.NoError(t, teamRepo.InsertBatch(ctx, []models.Team{team}))
+
+	tx, err := transactor.BeginTx(ctx, &sql.TxOptions{})
+	require.NoError(t, err)
+	for _, amount := range []int{10, -2, 5} {
+		require.NoError(t, repo.Create(ctx, tx, &models.PointsTransaction{
+			InstanceID: instanceID,
+			TeamCode:   team.Code,
+			Amount:     amount,
+		}))
+	}
+	err = repo.Create(ctx, tx, &models.PointsTransaction{Amount: 5})
+	assert.Error(t, err, "transactions need a team")
+	require.NoError(t, teamRepo.ReconcilePoints(ctx, tx, instanceID, []string{team.Code}))
+	require.NoError(t, tx.Commit())
+
+	found, err := teamRepo.GetByCode(ctx, team.Code)
+	require.NoError(t, err)
+	assert.Equal(t, 13, found.Points, "points are the sum of the ledger")
+
+	ledger, err := repo.FindByTeam(ctx, team.Code)
+	require.NoError(t, err)
+	require.Len(t, ledger, 3)
+
+	transaction, err := repo.GetByID(ctx, ledger[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, team.Code, transaction.TeamCode)
+
+	// undo marks the first transaction as undone in its own transaction
+	undo := func() bool {
+		tx, err := transactor.BeginTx(ctx, &sql.TxOptions{})
+		require.NoError(t, err)
+		undone, err := repo.Undo(ctx, tx, transaction.ID, time.Now().UTC())
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		return undone
+	}
+	assert.True(t, undo())
+	assert.False(t, undo(), "a transaction is only undone once")
+	transaction, err = repo.GetByID(ctx, transaction.ID)
+	require.NoError(t, err)
+	assert.False(t, transaction.UndoneAt.IsZero())
+
+	tx, err = transactor.BeginTx(ctx, &sql.TxOptions{})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteByTeamCodes(ctx, tx, instanceID, []string{team.Code}))
+	require.NoError(t, teamRepo.ReconcilePoints(ctx, tx, instanceID, []string{team.Code}))
+	require.NoError(t, tx.Commit())
+
+	ledger, err = repo.FindByTeam(ctx, team.Code)
+	require.NoError(t, err)
+	assert.Empty(t, ledger)
+	found, err = teamRepo.GetByCode(ctx, team.Code)
+	require.NoError(t, err)
+	assert.Equal(t, 0, found.Points)
+}
@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/uptrace/bun"
+)
+
+type RouteRepository interface {
+	// Save saves a route to the database
+	Save(ctx context.Context, route *models.Route) error
+	// InsertBatch saves multiple routes to the database
+	InsertBatch(ctx context.Context, tx *bun.Tx, routes []models.Route) error
+	// Update updates a route in the database
+	Update(ctx context.Context, route *models.Route) error
+
+	// GetByID returns a route by its ID
+	GetByID(ctx context.Context, routeID string) (*models.Route, error)
+	// FindByInstance returns all routes for an instance, oldest first
+	FindByInstance(ctx context.Context, instanceID string) ([]models.Route, error)
+
+	// AssignTeams sets the route for the given teams
+	// An empty routeID returns the teams to the default order
+	AssignTeams(ctx context.Context, tx *bun.Tx, instanceID string, routeID string, teamCodes []string) error
+
+	// Delete removes a route and returns its teams to the default order
+	Delete(ctx context.Context, tx *bun.Tx, routeID string) error
+	// DeleteByInstanceID removes all routes for an instance and returns its
+	// teams to the default order
+	DeleteByInstanceID(ctx context.Context, tx *bun.Tx, instanceID string) error
+}
+
+type routeRepository struct {
+	db *bun.DB
+}
+
+// NewRouteRepository creates a new RouteRepository.
+func NewRouteRepository(db *bun.DB) RouteRepository {
+	return &routeRepository{
+		db: db,
+	}
+}
+
+// Save saves a route to the database.
+func (r *routeRepository) Save(ctx context.Context, route *models.Route) error {
+	if route.InstanceID == "" {
+		return errors.New("instance ID must be set")
+	}
+	if route.ID == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return fmt.Errorf("generating UUID: %w", err)
+		}
+		route.ID = id.String()
+	}
+	_, err := r.db.NewInsert().Model(route).Exec(ctx)
+	return err
+}
+
+// InsertBatch saves multiple routes to the database.
+func (r *routeRepository) InsertBatch(ctx context.Context, tx *bun.Tx, routes []models.Route) error {
+	if len(routes) == 0 {
+		return nil
+	}
+	for i := range routes {
+		if routes[i].InstanceID == "" {
+			return errors.New("instance ID must be set")
+		}
+		if routes[i].ID == "" {
+			routes[i].ID = uuid.New().String()
+		}
+	}
+	_, err := tx.NewInsert().Model(&routes).Exec(ctx)
+	return err
+}
+
+// Update updates a route in the database.
+func (r *routeRepository) Update(ctx context.Context, route *models.Route) error {
+	_, err := r.db.NewUpdate().Model(route).WherePK().Exec(ctx)
+	return err
+}
+
+// GetByID returns a route by its ID.
+func (r *routeRepository) GetByID(ctx context.Context, routeID string) (*models.Route, error) {
+	route := &models.Route{}
+	err := r.db.
+		NewSelect().
+		Model(route).
+		Where("id = ?", routeID).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding route: %w", err)
+	}
+	return route, nil
+}
+
+// FindByInstance returns all routes for an instance, oldest first.
+func (r *routeRepository) FindByInstance(ctx context.Context, instanceID string) ([]models.Route, error) {
+	routes := []models.Route{}
+	err := r.db.
+		NewSelect().
+		Model(&routes).
+		Where("instance_id = ?", instanceID).
+		Order("created_at ASC", "name ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding routes by instance: %w", err)
+	}
+	return routes, nil
+}
+
+// AssignTeams sets the route for the given teams.
+func (r *routeRepository) AssignTeams(ctx context.Context, tx *bun.Tx, instanceID string, routeID string, teamCodes []string) error {
+	if len(teamCodes) == 0 {
+		return nil
+	}
+	query := tx.NewUpdate().
+		Model(&models.Team{}).
+		Where("instance_id = ? AND code IN (?)", instanceID, bun.In(teamCodes))
+	if routeID == "" {
+		query = query.Set("route_id = NULL")
+	} else {
+		query = query.Set("route_id = ?", routeID)
+	}
+	_, err := query.Exec(ctx)
+	return err
+}
+
+// Delete removes a route and returns its teams to the default order.
+func (r *routeRepository) Delete(ctx context.Context, tx *bun.Tx, routeID string) error {
+	_, err := tx.NewUpdate().
+		Model(&models.Team{}).
+		Set("route_id = NULL").
+		Where("route_id = ?", routeID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("unassigning teams: %w", err)
+	}
+	_, err = tx.NewDelete().
+		Model(&models.Route{}).
+		Where("id = ?", routeID).
+		Exec(ctx)
+	return err
+}
+
+// DeleteByInstanceID removes all routes for an instance and returns its teams
+// to the default order.
+func (r *routeRepository) DeleteByInstanceID(ctx context.Context, tx *bun.Tx, instanceID string) error {
+	_, err := tx.NewUpdate().
+		Model(&models.Team{}).
+		Set("route_id = NULL").
+		Where("instance_id = ?", instanceID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("unassigning teams: %w", err)
+	}
+	_, err = tx.NewDelete().
+		Model(&models.Route{}).
+		Where("instance_id = ?", instanceID).
+		Exec(ctx)
+	return err
+}
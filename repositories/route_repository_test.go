@@ -0,0 +1,119 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nathanhollows/Rapua/v3/db"
+	"github.com/nathanhollows/Rapua/v3/models"
+	"github.com/nathanhollows/Rapua/v3/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRouteRepo(t *testing.T) (repositories.RouteRepository, repositories.TeamRepository, db.Transactor, func()) {
+	t.Helper()
+	dbc, cleanup := setupDB(t)
+
+	transactor := db.NewTransactor(dbc)
+
+	routeRepo := repositories.NewRouteRepository(dbc)
+	teamRepo := repositories.NewTeamRepository(dbc)
+	return routeRepo, teamRepo, transactor, cleanup
+}
+
+func TestRouteRepository_SaveAndUpdate(t *testing.T) {
+	repo, _, _, cleanup := setupRouteRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	route := &models.Route{
+		InstanceID: gofakeit.UUID(),
+		Name:       "Route 1",
+		Locations:  models.StrArray{"AAAAA", "BBBBB"},
+	}
+	err := repo.Save(ctx, route)
+	require.NoError(t, err)
+	assert.NotEmpty(t, route.ID)
+
+	route.Locations = models.StrArray{"BBBBB", "AAAAA"}
+	err = repo.Update(ctx, route)
+	require.NoError(t, err)
+
+	found, err := repo.GetByID(ctx, route.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Route 1", found.Name)
+	assert.Equal(t, models.StrArray{"BBBBB", "AAAAA"}, found.Locations)
+
+	err = repo.Save(ctx, &models.Route{Name: "Missing instance"})
+	assert.Error(t, err)
+}
+
+func TestRouteRepository_AssignAndDelete(t *testing.T) {
+	repo, teamRepo, transactor, cleanup := setupRouteRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	instanceID := gofakeit.UUID()
+	routes := []models.Route{
+		{InstanceID: instanceID, Name: "Route 1"},
+		{InstanceID: instanceID, Name: "Route 2"},
+	}
+	tx, err := transactor.BeginTx(ctx, &sql.TxOptions{})
+	require.NoError(t, err)
+	err = repo.InsertBatch(ctx, tx, routes)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	found, err := repo.FindByInstance(ctx, instanceID)
+	require.NoError(t, err)
+	assert.Len(t, found, 2)
+
+	teams := []models.Team{
+		{Code: gofakeit.Password(false, true, false, false, false, 5), InstanceID: instanceID},
+		{Code: gofakeit.Password(false, true, false, false, false, 5), InstanceID: instanceID},
+	}
+	require.NoError(t, teamRepo.InsertBatch(ctx, teams))
+
+	tx, err = transactor.BeginTx(ctx, &sql.TxOptions{})
+	require.NoError(t, err)
+	err = repo.AssignTeams(ctx, tx, instanceID, routes[0].ID, []string{teams[0].Code})
+	require.NoError(t, err)
+	err = repo.AssignTeams(ctx, tx, instanceID, routes[1].ID, []string{teams[1].Code})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	team, err := teamRepo.GetByCode(ctx, teams[0].Code)
+	require.NoError(t, err)
+	assert.Equal(t, routes[0].ID, team.RouteID)
+	require.NoError(t, teamRepo.LoadRoute(ctx, team))
+	assert.Equal(t, "Route 1", team.Route.Name)
+
+	// Deleting a route returns its teams to the default order
+	tx, err = transactor.BeginTx(ctx, &sql.TxOptions{})
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(ctx, tx, routes[0].ID))
+	require.NoError(t, tx.Commit())
+
+	team, err = teamRepo.GetByCode(ctx, teams[0].Code)
+	require.NoError(t, err)
+	assert.Empty(t, team.RouteID)
+	team, err = teamRepo.GetByCode(ctx, teams[1].Code)
+	require.NoError(t, err)
+	assert.Equal(t, routes[1].ID, team.RouteID)
+
+	// Deleting by instance removes the rest
+	tx, err = transactor.BeginTx(ctx, &sql.TxOptions{})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteByInstanceID(ctx, tx, instanceID))
+	require.NoError(t, tx.Commit())
+
+	found, err = repo.FindByInstance(ctx, instanceID)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+	team, err = teamRepo.GetByCode(ctx, teams[1].Code)
+	require.NoError(t, err)
+	assert.Empty(t, team.RouteID)
+}
@@ -23,14 +23,19 @@ type TeamRepository interface {
 	FindAllWithScans(ctx context.Context, instanceID string) ([]models.Team, error)
 
 	// Update saves or updates a team in the database
+	// Points are not saved as they are derived from the points ledger
 	Update(ctx context.Context, t *models.Team) error
-	// Reset wipes a team's progress for re-use
+	// ReconcilePoints sets the points for the given teams to the sum of their ledger
+	ReconcilePoints(ctx context.Context, tx *bun.Tx, instanceID string, teamCodes []string) error
+	// ClearMustCheckOut lets a team continue without checking out of their current location
+	ClearMustCheckOut(ctx context.Context, tx *bun.Tx, instanceID string, teamCode string) error
+	// Reset wipes a team's progress and players for re-use
 	Reset(ctx context.Context, tx *bun.Tx, instanceID string, teamCodes []string) error
 
-	// Delete removes the team from the database
+	// Delete removes the team and its players from the database
 	// Requires a transaction as related data will also need to be deleted
 	Delete(ctx context.Context, tx *bun.Tx, instanceID string, teamCode string) error
-	// DeleteByInstanceID removes all teams for a specific instance
+	// DeleteByInstanceID removes all teams and players for a specific instance
 	// Requires a transaction as this implies a cascade delete and related data
 	// will also need to be deleted
 	DeleteByInstanceID(ctx context.Context, tx *bun.Tx, instanceID string) error
@@ -43,6 +48,10 @@ type TeamRepository interface {
 	LoadBlockingLocation(ctx context.Context, team *models.Team) error
 	// LoadMessages loads the messages for a team
 	LoadMessages(ctx context.Context, team *models.Team) error
+	// LoadBlockStates loads the block states for a team
+	LoadBlockStates(ctx context.Context, team *models.Team) error
+	// LoadRoute loads the route for a team, if one is assigned
+	LoadRoute(ctx context.Context, team *models.Team) error
 	// LoadRelations loads all relations for a team
 	LoadRelations(ctx context.Context, team *models.Team) error
 }
@@ -59,8 +68,32 @@ func NewTeamRepository(db *bun.DB) TeamRepository {
 }
 
 // Update saves or updates a team in the database.
+// Points are not saved as they are derived from the points ledger.
 func (r *teamRepository) Update(ctx context.Context, t *models.Team) error {
-	_, err := r.db.NewUpdate().Model(t).WherePK().Exec(ctx)
+	_, err := r.db.NewUpdate().Model(t).ExcludeColumn("points").WherePK().Exec(ctx)
+	return err
+}
+
+// ReconcilePoints sets the points for the given teams to the sum of their ledger.
+func (r *teamRepository) ReconcilePoints(ctx context.Context, tx *bun.Tx, instanceID string, teamCodes []string) error {
+	total := tx.NewSelect().
+		Model((*models.PointsTransaction)(nil)).
+		ColumnExpr("COALESCE(SUM(amount), 0)").
+		Where("points_transaction.team_code = team.code")
+	_, err := tx.NewUpdate().
+		Model((*models.Team)(nil)).
+		Set("points = (?)", total).
+		Where("instance_id = ? AND code IN (?)", instanceID, bun.In(teamCodes)).
+		Exec(ctx)
+	return err
+}
+
+// ClearMustCheckOut lets a team continue without checking out of their current location.
+func (r *teamRepository) ClearMustCheckOut(ctx context.Context, tx *bun.Tx, instanceID string, teamCode string) error {
+	_, err := tx.NewUpdate().Model((*models.Team)(nil)).
+		Set("must_scan_out = ''").
+		Where("instance_id = ? AND code = ?", instanceID, teamCode).
+		Exec(ctx)
 	return err
 }
 
@@ -71,16 +104,34 @@ func (r *teamRepository) Reset(ctx context.Context, tx *bun.Tx, instanceID strin
 		Set("has_started = false").
 		Set("must_scan_out = ''").
 		Set("points = 0").
+		Set("started_at = NULL").
+		Set("extra_time = 0").
 		Where("instance_id = ? AND code IN (?)", instanceID, bun.In(teamCodes)).
 		Exec(ctx)
+	if err != nil {
+		return err
+	}
 	if i, _ := res.RowsAffected(); i == 0 {
 		fmt.Println("No teams found to reset")
 	}
+
+	// A reset team starts again without any players
+	_, err = tx.NewDelete().Model(&models.Player{}).
+		Where("instance_id = ? AND team_code IN (?)", instanceID, bun.In(teamCodes)).
+		Exec(ctx)
 	return err
 }
 
 func (r *teamRepository) Delete(ctx context.Context, tx *bun.Tx, instanceID string, teamCode string) error {
 	_, err := tx.
+		NewDelete().
+		Model(&models.Player{}).
+		Where("team_code = ? AND instance_id = ?", teamCode, instanceID).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = tx.
 		NewDelete().
 		Model(&models.Team{}).
 		Where("code = ? AND instance_id = ?", teamCode, instanceID).
@@ -89,7 +140,11 @@ func (r *teamRepository) Delete(ctx context.Context, tx *bun.Tx, instanceID stri
 }
 
 func (r *teamRepository) DeleteByInstanceID(ctx context.Context, tx *bun.Tx, instanceID string) error {
-	_, err := tx.NewDelete().Model(&models.Team{}).Where("instance_id = ?", instanceID).Exec(ctx)
+	_, err := tx.NewDelete().Model(&models.Player{}).Where("instance_id = ?", instanceID).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = tx.NewDelete().Model(&models.Team{}).Where("instance_id = ?", instanceID).Exec(ctx)
 	return err
 }
 
@@ -127,6 +182,7 @@ func (r *teamRepository) GetByCode(ctx context.Context, code string) (*models.Te
 	var team models.Team
 	err := r.db.NewSelect().Model(&team).Where("team.code = ?", code).
 		Relation("Instance").
+		Relation("Instance.Settings").
 		Relation("BlockingLocation").
 		Relation("CheckIns", func(q *bun.SelectQuery) *bun.SelectQuery {
 			return q.Order("name ASC")
@@ -211,6 +267,29 @@ func (r *teamRepository) LoadMessages(ctx context.Context, team *models.Team) er
 	return nil
 }
 
+func (r *teamRepository) LoadBlockStates(ctx context.Context, team *models.Team) error {
+	err := r.db.NewSelect().Model(&team.Blocks).
+		Where("team_code = ?", team.Code).
+		Scan(ctx)
+	if err != nil {
+		return fmt.Errorf("LoadBlockStates: %v", err)
+	}
+	return nil
+}
+
+func (r *teamRepository) LoadRoute(ctx context.Context, team *models.Team) error {
+	if team.RouteID == "" || team.Route.ID == team.RouteID {
+		return nil
+	}
+	err := r.db.NewSelect().Model(&team.Route).
+		Where("id = ?", team.RouteID).
+		Scan(ctx)
+	if err != nil {
+		return fmt.Errorf("LoadRoute: %v", err)
+	}
+	return nil
+}
+
 func (r *teamRepository) LoadRelations(ctx context.Context, team *models.Team) error {
 	err := r.LoadInstance(ctx, team)
 	if err != nil {
@@ -232,5 +311,15 @@ func (r *teamRepository) LoadRelations(ctx context.Context, team *models.Team) e
 		return err
 	}
 
+	err = r.LoadBlockStates(ctx, team)
+	if err != nil {
+		return err
+	}
+
+	err = r.LoadRoute(ctx, team)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }